@@ -15,20 +15,52 @@ import (
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/pointer"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // Callees reports the possible callees of the function call site
 // identified by the specified source location.
 func callees(q *Query) error {
+	// First answer a static call from the query package alone,
+	// type-checked without the function bodies of its dependencies,
+	// before loading the whole program for the pointer analysis.
+	{
+		lconf := loader.Config{Build: q.Build}
+		allowErrors(&lconf)
+
+		lprog, err := loadQueryPackage(q, &lconf, nil)
+		if err != nil {
+			return err
+		}
+
+		qpos, err := parseQueryPos(lprog, q.Pos, true) // needs exact pos
+		if err != nil {
+			return err
+		}
+
+		e, callee, err := findCall(qpos)
+		if err != nil {
+			return err
+		}
+		if callee != nil {
+			q.Output(lprog.Fset, &calleesTypesResult{
+				site:   e,
+				callee: callee,
+			})
+			return nil
+		}
+
+		// Fall back on the pointer analysis.
+	}
+
 	lconf := loader.Config{Build: q.Build}
 
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -38,6 +70,54 @@ func callees(q *Query) error {
 		return err
 	}
 
+	e, _, err := findCall(qpos)
+	if err != nil {
+		return err
+	}
+
+	prog := createProgram(q, lprog, ssa.GlobalDebug)
+
+	ptaConfig, err := setupPTA(q, prog, lprog)
+	if err != nil {
+		return err
+	}
+
+	pkg := prog.Package(qpos.info.Pkg)
+	if pkg == nil {
+		return fmt.Errorf("no SSA package")
+	}
+
+	// Defer SSA construction till after errors are reported.
+	prog.Build()
+
+	// Ascertain calling function and call site.
+	callerFn := ssa.EnclosingFunction(pkg, qpos.path)
+	if callerFn == nil {
+		return fmt.Errorf("no SSA function built for this location (dead code?)")
+	}
+
+	// Find the call site.
+	site, err := findCallSite(callerFn, e)
+	if err != nil {
+		return err
+	}
+
+	funcs, err := findCallees(ptaConfig, site)
+	if err != nil {
+		return err
+	}
+
+	q.Output(lprog.Fset, &calleesSSAResult{
+		site:  site,
+		funcs: funcs,
+	})
+	return nil
+}
+
+// findCall returns the function call enclosing the query position and,
+// if it is an obviously static call, its callee.  Some static calls may
+// yet require SSA construction, e.g.  f := func(){}; f().
+func findCall(qpos *queryPos) (*ast.CallExpr, *types.Func, error) {
 	// Determine the enclosing call for the specified position.
 	var e *ast.CallExpr
 	for _, n := range qpos.path {
@@ -46,7 +126,7 @@ func callees(q *Query) error {
 		}
 	}
 	if e == nil {
-		return fmt.Errorf("there is no function call here")
+		return nil, nil, fmt.Errorf("there is no function call here")
 	}
 	// TODO(adonovan): issue an error if the call is "too far
 	// away" from the current selection, as this most likely is
@@ -54,25 +134,18 @@ func callees(q *Query) error {
 
 	// Reject type conversions.
 	if qpos.info.Types[e.Fun].IsType() {
-		return fmt.Errorf("this is a type conversion, not a function call")
+		return nil, nil, fmt.Errorf("this is a type conversion, not a function call")
 	}
 
-	// Deal with obviously static calls before constructing SSA form.
-	// Some static calls may yet require SSA construction,
-	// e.g.  f := func(){}; f().
 	switch funexpr := unparen(e.Fun).(type) {
 	case *ast.Ident:
 		switch obj := qpos.info.Uses[funexpr].(type) {
 		case *types.Builtin:
 			// Reject calls to built-ins.
-			return fmt.Errorf("this is a call to the built-in '%s' operator", obj.Name())
+			return nil, nil, fmt.Errorf("this is a call to the built-in '%s' operator", obj.Name())
 		case *types.Func:
 			// This is a static function call
-			q.Output(lprog.Fset, &calleesTypesResult{
-				site:   e,
-				callee: obj,
-			})
-			return nil
+			return e, obj, nil
 		}
 	case *ast.SelectorExpr:
 		sel := qpos.info.Selections[funexpr]
@@ -82,11 +155,7 @@ func callees(q *Query) error {
 			// or to top level function.
 			callee := qpos.info.Uses[funexpr.Sel]
 			if obj, ok := callee.(*types.Func); ok {
-				q.Output(lprog.Fset, &calleesTypesResult{
-					site:   e,
-					callee: obj,
-				})
-				return nil
+				return e, obj, nil
 			}
 		} else if sel.Kind() == types.MethodVal {
 			// Inspect the receiver type of the selected method.
@@ -97,52 +166,11 @@ func callees(q *Query) error {
 			recvtype := method.Type().(*types.Signature).Recv().Type()
 			if !types.IsInterface(recvtype) {
 				// static method call
-				q.Output(lprog.Fset, &calleesTypesResult{
-					site:   e,
-					callee: method,
-				})
-				return nil
+				return e, method, nil
 			}
 		}
 	}
-
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
-
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
-	if err != nil {
-		return err
-	}
-
-	pkg := prog.Package(qpos.info.Pkg)
-	if pkg == nil {
-		return fmt.Errorf("no SSA package")
-	}
-
-	// Defer SSA construction till after errors are reported.
-	prog.Build()
-
-	// Ascertain calling function and call site.
-	callerFn := ssa.EnclosingFunction(pkg, qpos.path)
-	if callerFn == nil {
-		return fmt.Errorf("no SSA function built for this location (dead code?)")
-	}
-
-	// Find the call site.
-	site, err := findCallSite(callerFn, e)
-	if err != nil {
-		return err
-	}
-
-	funcs, err := findCallees(ptaConfig, site)
-	if err != nil {
-		return err
-	}
-
-	q.Output(lprog.Fset, &calleesSSAResult{
-		site:  site,
-		funcs: funcs,
-	})
-	return nil
+	return e, nil, nil
 }
 
 func findCallSite(fn *ssa.Function, call *ast.CallExpr) (ssa.CallInstruction, error) {
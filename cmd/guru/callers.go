@@ -25,9 +25,10 @@ func callers(q *Query) error {
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -37,9 +38,9 @@ func callers(q *Query) error {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, 0)
+	prog := createProgram(q, lprog, 0)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := setupPTA(q, prog, lprog)
 	if err != nil {
 		return err
 	}
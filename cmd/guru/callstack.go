@@ -13,7 +13,6 @@ import (
 	"golang.org/x/tools/go/callgraph/static"
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // Callstack displays an arbitrary path from a root of the callgraph
@@ -27,15 +26,15 @@ import (
 // the analysis root.
 //
 func callstack(q *Query) error {
-	fset := token.NewFileSet()
-	lconf := loader.Config{Fset: fset, Build: q.Build}
+	lconf := loader.Config{Build: q.Build}
 
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -45,9 +44,9 @@ func callstack(q *Query) error {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, 0)
+	prog := createProgram(q, lprog, 0)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := setupPTA(q, prog, lprog)
 	if err != nil {
 		return err
 	}
@@ -96,7 +95,7 @@ func callstack(q *Query) error {
 		}
 	}
 
-	q.Output(fset, &callstackResult{
+	q.Output(lprog.Fset, &callstackResult{
 		qpos:     qpos,
 		target:   target,
 		callpath: callpath,
@@ -10,6 +10,7 @@ import (
 	"go/build"
 	"go/parser"
 	"go/token"
+	"go/types"
 	pathpkg "path"
 	"path/filepath"
 	"strconv"
@@ -26,7 +27,7 @@ func definition(q *Query) error {
 	// (Extending this approach to all the files of the package,
 	// resolved using ast.NewPackage, was not worth the effort.)
 	{
-		qpos, err := fastQueryPos(q.Build, q.Pos)
+		qpos, err := fastQueryPos(q)
 		if err != nil {
 			return err
 		}
@@ -46,7 +47,22 @@ func definition(q *Query) error {
 		}
 
 		// Qualified identifier?
-		if pkg := packageForQualIdent(qpos.path, id); pkg != "" {
+		if pkg := packageForQualIdent(qpos.path, id); pkg == "C" {
+			// The members of C are declared only in the output
+			// of cgo, which the type checker sees with -cgo=query.
+			// Without it, report the preamble of import "C".
+			if !q.CgoQuery {
+				pos, err := cgoPreamble(q.Build, qpos)
+				if err != nil {
+					return err
+				}
+				q.Output(qpos.fset, &definitionResult{
+					pos:   pos,
+					descr: fmt.Sprintf("C.%s in the cgo preamble (-cgo=query finds its Go declaration)", id.Name),
+				})
+				return nil // success
+			}
+		} else if pkg != "" {
 			srcdir := filepath.Dir(qpos.fset.File(qpos.start).Name())
 			tok, pos, err := findPackageMember(q.Build, qpos.fset, srcdir, pkg, id.Name)
 			if err != nil {
@@ -65,18 +81,22 @@ func definition(q *Query) error {
 	// Run the type checker.
 	lconf := loader.Config{Build: q.Build}
 	allowErrors(&lconf)
-
-	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
+	cgoPos, err := processCgo(q, &lconf)
+	if err != nil {
 		return err
 	}
 
 	// Load/parse/type-check the program.
-	lprog, err := lconf.Load()
+	lprog, err := loadQueryPackage(q, &lconf, cgoPos)
 	if err != nil {
 		return err
 	}
 
-	qpos, err := parseQueryPos(lprog, q.Pos, false)
+	pos, err := cgoPos.queryPos(lprog, q.Pos)
+	if err != nil {
+		return err
+	}
+	qpos, err := parseQueryPos(lprog, pos, false)
 	if err != nil {
 		return err
 	}
@@ -93,12 +113,18 @@ func definition(q *Query) error {
 	obj := qpos.info.Uses[id]
 	if obj == nil {
 		obj = qpos.info.Defs[id]
-		if obj == nil {
-			// Happens for y in "switch y := x.(type)",
-			// and the package declaration,
-			// but I think that's all.
-			return fmt.Errorf("no object for identifier")
-		}
+	}
+	var approx string
+	if obj == nil && len(qpos.info.Errors) > 0 {
+		// The type checker may have given up on the code around
+		// the identifier; guess from what it did resolve.
+		obj, approx = guessObject(qpos, id)
+	}
+	if obj == nil {
+		// Happens for y in "switch y := x.(type)",
+		// and the package declaration,
+		// but I think that's all.
+		return fmt.Errorf("no object for identifier")
 	}
 
 	if !obj.Pos().IsValid() {
@@ -106,12 +132,80 @@ func definition(q *Query) error {
 	}
 
 	q.Output(lprog.Fset, &definitionResult{
-		pos:   obj.Pos(),
-		descr: qpos.objectString(obj),
+		pos:    obj.Pos(),
+		descr:  qpos.objectString(obj),
+		approx: approx,
 	})
 	return nil
 }
 
+// guessObject returns the object to which id, left unresolved by the
+// type checker for the type errors of its package, likely refers, and
+// why the answer is approximate, or nil.  The X.id of a selection whose
+// X is of unknown type is the only field or method id of the types of
+// the package and of those it imports, if there is one; a lone id is
+// the object of that name in scope.
+func guessObject(qpos *queryPos, id *ast.Ident) (types.Object, string) {
+	if sel, ok := qpos.path[1].(*ast.SelectorExpr); ok && sel.Sel == id {
+		var found []types.Object
+		add := func(obj types.Object) {
+			if obj.Name() != id.Name || !obj.Pos().IsValid() {
+				return
+			}
+			for _, f := range found {
+				if f == obj {
+					return
+				}
+			}
+			found = append(found, obj)
+		}
+		for _, obj := range qpos.info.Defs {
+			switch obj := obj.(type) {
+			case *types.Var:
+				if obj.IsField() {
+					add(obj)
+				}
+			case *types.Func:
+				if obj.Type().(*types.Signature).Recv() != nil {
+					add(obj)
+				}
+			}
+		}
+		for _, imp := range qpos.info.Pkg.Imports() {
+			scope := imp.Scope()
+			for _, name := range scope.Names() {
+				named, ok := scope.Lookup(name).Type().(*types.Named)
+				if !ok {
+					continue
+				}
+				for i := 0; i < named.NumMethods(); i++ {
+					add(named.Method(i))
+				}
+				if st, ok := named.Underlying().(*types.Struct); ok {
+					for i := 0; i < st.NumFields(); i++ {
+						add(st.Field(i))
+					}
+				}
+			}
+		}
+		if len(found) != 1 {
+			return nil, ""
+		}
+		return found[0], fmt.Sprintf("the type errors of the package leave the type of %s unknown; this is the only field or method named %s", types.ExprString(sel.X), id.Name)
+	}
+	for _, n := range qpos.path {
+		scope := qpos.info.Scopes[n]
+		if scope == nil {
+			continue
+		}
+		if _, obj := scope.LookupParent(id.Name, id.Pos()); obj != nil {
+			return obj, fmt.Sprintf("the type errors of the package leave %s unresolved; this is the %s in scope", id.Name, id.Name)
+		}
+		break
+	}
+	return nil, ""
+}
+
 // packageForQualIdent returns the package p if id is X in a qualified
 // identifier p.X; it returns "" otherwise.
 //
@@ -120,8 +214,9 @@ func definition(q *Query) error {
 // package iff it is the basename of an import path (and not, say, a
 // package-level decl in another file or a predeclared identifier).
 func packageForQualIdent(path []ast.Node, id *ast.Ident) string {
-	if sel, ok := path[1].(*ast.SelectorExpr); ok && sel.Sel == id && ast.IsExported(id.Name) {
-		if pkgid, ok := sel.X.(*ast.Ident); ok && pkgid.Obj == nil {
+	if sel, ok := path[1].(*ast.SelectorExpr); ok && sel.Sel == id {
+		// (The members of C need not be exported.)
+		if pkgid, ok := sel.X.(*ast.Ident); ok && pkgid.Obj == nil && (ast.IsExported(id.Name) || pkgid.Name == "C") {
 			f := path[len(path)-1].(*ast.File)
 			for _, imp := range f.Imports {
 				path, _ := strconv.Unquote(imp.Path.Value)
@@ -138,6 +233,91 @@ func packageForQualIdent(path []ast.Node, id *ast.Ident) string {
 	return ""
 }
 
+// cgoMember returns the identifier of the member of C to which the
+// innermost node of path, an identifier or a selector, refers, or nil.
+func cgoMember(path []ast.Node) *ast.Ident {
+	if sel, ok := path[0].(*ast.SelectorExpr); ok {
+		path = append([]ast.Node{sel.Sel}, path...)
+	}
+	if id, ok := path[0].(*ast.Ident); ok && packageForQualIdent(path, id) == "C" {
+		return id
+	}
+	return nil
+}
+
+// cgoImport parses again the imports of the query file, with their
+// comments, and returns the import "C" and its preamble, the comment
+// preceding it, or nil if it has none.
+func cgoImport(ctxt *build.Context, qpos *queryPos) (*ast.ImportSpec, *ast.CommentGroup, error) {
+	filename := qpos.fset.File(qpos.start).Name()
+	f, err := buildutil.ParseFile(qpos.fset, ctxt, nil, "", filename, parser.ImportsOnly|parser.ParseComments)
+	if f == nil {
+		return nil, nil, err
+	}
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if spec.Path.Value != `"C"` {
+				continue
+			}
+			switch {
+			case spec.Doc != nil:
+				return spec, spec.Doc, nil
+			case decl.Doc != nil && !decl.Lparen.IsValid():
+				return spec, decl.Doc, nil
+			}
+			return spec, nil, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no import \"C\" in %s", filename)
+}
+
+// cgoPreamble returns the position of the preamble of the import "C" of
+// the query file, or that of the import if it has none.
+func cgoPreamble(ctxt *build.Context, qpos *queryPos) (token.Pos, error) {
+	spec, preamble, err := cgoImport(ctxt, qpos)
+	if err != nil {
+		return token.NoPos, err
+	}
+	if preamble != nil {
+		return preamble.Pos(), nil
+	}
+	return spec.Pos(), nil
+}
+
+// queryCgoPreamble returns the preamble of the import "C" of the query
+// file if the query lies within it, or nil.
+func queryCgoPreamble(ctxt *build.Context, qpos *queryPos) *ast.CommentGroup {
+	// The parser leaves the comments out of the path, so that of
+	// a position in the preamble ends at the file or the import.
+	switch n := qpos.path[0].(type) {
+	case *ast.File:
+	case *ast.GenDecl:
+		if n.Tok != token.IMPORT {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if !importsC(qpos.path[len(qpos.path)-1].(*ast.File)) {
+		return nil
+	}
+	_, preamble, err := cgoImport(ctxt, qpos)
+	if err != nil || preamble == nil {
+		return nil
+	}
+	// (The preamble is in a file of its own in qpos.fset.)
+	offset := func(pos token.Pos) int { return qpos.fset.Position(pos).Offset }
+	if offset(preamble.Pos()) <= offset(qpos.start) && offset(qpos.end) <= offset(preamble.End()) {
+		return preamble
+	}
+	return nil
+}
+
 // findPackageMember returns the type and position of the declaration of
 // pkg.member by loading and parsing the files of that package.
 // srcdir is the directory in which the import appears.
@@ -189,17 +369,24 @@ func findPackageMember(ctxt *build.Context, fset *token.FileSet, srcdir, pkg, me
 }
 
 type definitionResult struct {
-	pos   token.Pos // (nonzero) location of definition
-	descr string    // description of object it denotes
+	pos    token.Pos // (nonzero) location of definition
+	descr  string    // description of object it denotes
+	approx string    // why the definition is a guess, if it is
 }
 
 func (r *definitionResult) PrintPlain(printf printfFunc) {
+	if r.approx != "" {
+		printf(r.pos, "defined here as %s (approximate: %s)", r.descr, r.approx)
+		return
+	}
 	printf(r.pos, "defined here as %s", r.descr)
 }
 
 func (r *definitionResult) JSON(fset *token.FileSet) []byte {
 	return toJSON(&serial.Definition{
-		Desc:   r.descr,
-		ObjPos: fset.Position(r.pos).String(),
+		Desc:        r.descr,
+		ObjPos:      fset.Position(r.pos).String(),
+		ObjPhysPos:  physPos(fset, r.pos),
+		Approximate: r.approx,
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build"
 	exact "go/constant"
 	"go/token"
 	"go/types"
@@ -28,20 +29,43 @@ import (
 // - its type, fields, and methods (for an expression or type expression)
 //
 func describe(q *Query) error {
+	// Without -cgo=query, the type checker knows nothing of C: describe
+	// the references to its members, and the preamble of import "C",
+	// from the syntax alone, as definition does, without loading the
+	// program.
+	if !q.CgoQuery {
+		qpos, err := fastQueryPos(q)
+		if err != nil {
+			return err
+		}
+		qr, err := describeCgo(q.Build, qpos)
+		if err != nil {
+			return err
+		}
+		if qr != nil {
+			q.Output(qpos.fset, qr)
+			return nil
+		}
+	}
+
 	lconf := loader.Config{Build: q.Build}
 	allowErrors(&lconf)
-
-	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
+	cgoPos, err := processCgo(q, &lconf)
+	if err != nil {
 		return err
 	}
 
 	// Load/parse/type-check the program.
-	lprog, err := lconf.Load()
+	lprog, err := loadQueryPackage(q, &lconf, cgoPos)
 	if err != nil {
 		return err
 	}
 
-	qpos, err := parseQueryPos(lprog, q.Pos, true) // (need exact pos)
+	pos, err := cgoPos.queryPos(lprog, q.Pos)
+	if err != nil {
+		return err
+	}
+	qpos, err := parseQueryPos(lprog, pos, true) // (need exact pos)
 	if err != nil {
 		return err
 	}
@@ -95,6 +119,50 @@ func (r *describeUnknownResult) JSON(fset *token.FileSet) []byte {
 	})
 }
 
+// describeCgo describes the reference to a member of C, or the preamble
+// of import "C", at the query position, or returns nil if it is neither.
+func describeCgo(ctxt *build.Context, qpos *queryPos) (*describeCgoResult, error) {
+	if preamble := queryCgoPreamble(ctxt, qpos); preamble != nil {
+		return &describeCgoResult{
+			node: preamble,
+			desc: `cgo preamble of import "C"`,
+		}, nil
+	}
+	id := cgoMember(qpos.path)
+	if id == nil {
+		return nil, nil
+	}
+	pos, err := cgoPreamble(ctxt, qpos)
+	if err != nil {
+		return nil, err
+	}
+	return &describeCgoResult{
+		node:     qpos.path[0],
+		desc:     fmt.Sprintf("reference to C.%s, declared in the cgo preamble (-cgo=query finds its type)", id.Name),
+		preamble: pos,
+	}, nil
+}
+
+type describeCgoResult struct {
+	node     ast.Node  // the reference, or the preamble
+	desc     string    // description of node
+	preamble token.Pos // the preamble, for a reference; otherwise NoPos
+}
+
+func (r *describeCgoResult) PrintPlain(printf printfFunc) {
+	printf(r.node, "%s", r.desc)
+	if r.preamble.IsValid() {
+		printf(r.preamble, "defined here")
+	}
+}
+
+func (r *describeCgoResult) JSON(fset *token.FileSet) []byte {
+	return toJSON(&serial.Describe{
+		Desc: r.desc,
+		Pos:  fset.Position(r.node.Pos()).String(),
+	})
+}
+
 type action int
 
 const (
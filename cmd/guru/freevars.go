@@ -33,12 +33,8 @@ func freevars(q *Query) error {
 	lconf := loader.Config{Build: q.Build}
 	allowErrors(&lconf)
 
-	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
-		return err
-	}
-
 	// Load/parse/type-check the program.
-	lprog, err := lconf.Load()
+	lprog, err := loadQueryPackage(q, &lconf, nil)
 	if err != nil {
 		return err
 	}
@@ -136,9 +132,11 @@ func freevars(q *Query) error {
 					panic(obj)
 				}
 
+				// The type at the reference, which for an
+				// untyped constant is that it is converted to.
 				typ := qpos.info.TypeOf(n.(ast.Expr))
 				ref := freevarsRef{kind, printNode(lprog.Fset, n), typ, obj}
-				refsMap[ref.ref] = ref
+				refsMap[ref.ref+" "+types.TypeString(typ, nil)] = ref
 
 				if prune {
 					return false // don't descend
@@ -167,13 +165,56 @@ type freevarsResult struct {
 	refs []freevarsRef
 }
 
+// A freevarsRef is a free reference, listed once for each of the types
+// it has in the selection, as a constant may have several.
 type freevarsRef struct {
 	kind string
 	ref  string
-	typ  types.Type
+	typ  types.Type // the type at the reference
 	obj  types.Object
 }
 
+// declType returns the type of the declaration of the object of ref if
+// it differs from that at the reference, as for an untyped constant.
+func (ref *freevarsRef) declType() types.Type {
+	if ref.kind == "type" || ref.kind == "label" || ref.ref != ref.obj.Name() {
+		return nil
+	}
+	if types.Identical(ref.obj.Type(), ref.typ) {
+		return nil
+	}
+	return ref.obj.Type()
+}
+
+// localType returns the named type declared in a function, which its
+// name alone may not identify, that the type at the reference is, or
+// is composed of, or nil.
+func (ref *freevarsRef) localType() *types.TypeName {
+	t := ref.typ
+	for {
+		switch u := t.(type) {
+		case *types.Pointer:
+			t = u.Elem()
+			continue
+		case *types.Slice:
+			t = u.Elem()
+			continue
+		case *types.Array:
+			t = u.Elem()
+			continue
+		case *types.Chan:
+			t = u.Elem()
+			continue
+		case *types.Named:
+			obj := u.Obj()
+			if obj.Pkg() != nil && obj.Parent() != obj.Pkg().Scope() {
+				return obj
+			}
+		}
+		return nil
+	}
+}
+
 func (r *freevarsResult) PrintPlain(printf printfFunc) {
 	if len(r.refs) == 0 {
 		printf(r.qpos, "No free identifiers.")
@@ -186,6 +227,16 @@ func (r *freevarsResult) PrintPlain(printf printfFunc) {
 			if ref.kind != "type" && ref.kind != "label" {
 				typstr = " " + types.TypeString(ref.typ, qualifier)
 			}
+			if t := ref.declType(); t != nil {
+				typstr += " (declared " + types.TypeString(t, qualifier) + ")"
+			}
+			if obj := ref.localType(); obj != nil {
+				if ref.kind == "type" {
+					typstr += " (declared in the function)"
+				} else {
+					typstr += " (" + obj.Name() + " is declared in the function)"
+				}
+			}
 			printf(ref.obj, "%s %s%s", ref.kind, ref.ref, typstr)
 		}
 	}
@@ -197,12 +248,19 @@ func (r *freevarsResult) JSON(fset *token.FileSet) []byte {
 		if i > 0 {
 			buf.WriteByte('\n')
 		}
-		buf.Write(toJSON(serial.FreeVar{
+		fv := serial.FreeVar{
 			Pos:  fset.Position(ref.obj.Pos()).String(),
 			Kind: ref.kind,
 			Ref:  ref.ref,
 			Type: ref.typ.String(),
-		}))
+		}
+		if t := ref.declType(); t != nil {
+			fv.DeclType = t.String()
+		}
+		if obj := ref.localType(); obj != nil {
+			fv.TypePos = fset.Position(obj.Pos()).String()
+		}
+		buf.Write(toJSON(fv))
 	}
 	return buf.Bytes()
 }
@@ -211,9 +269,14 @@ func (r *freevarsResult) JSON(fset *token.FileSet) []byte {
 
 type byRef []freevarsRef
 
-func (p byRef) Len() int           { return len(p) }
-func (p byRef) Less(i, j int) bool { return p[i].ref < p[j].ref }
-func (p byRef) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p byRef) Len() int { return len(p) }
+func (p byRef) Less(i, j int) bool {
+	if p[i].ref != p[j].ref {
+		return p[i].ref < p[j].ref
+	}
+	return p[i].typ.String() < p[j].typ.String()
+}
+func (p byRef) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
 
 // printNode returns the pretty-printed syntax of n.
 func printNode(fset *token.FileSet, n ast.Node) string {
@@ -11,6 +11,7 @@ package main
 //   (&T{}, var t T, new(T), new(struct{array [3]T}), etc.
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"go/ast"
@@ -19,15 +20,21 @@ import (
 	"go/token"
 	"go/types"
 	"io"
+	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/pointer"
 	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/refactor/importgraph"
 )
 
 type printfFunc func(pos interface{}, format string, args ...interface{})
@@ -71,12 +78,54 @@ type Query struct {
 	Build *build.Context // package loading configuration
 
 	// pointer analysis options
-	Scope      []string  // main packages in (*loader.Config).FromArgs syntax
+	Scope      []string  // main packages in (*loader.Config).FromArgs syntax; also the packages referrers searches
 	PTALog     io.Writer // (optional) pointer-analysis log file
 	Reflection bool      // model reflection soundly (currently slow).
 
+	// CgoQuery causes the package of the query file, if the file
+	// imports "C", to be processed by cgo for the queries that only
+	// parse cgo files, as definition, describe and referrers do.
+	CgoQuery bool
+
+	// Verbose causes implements to report the near misses of the
+	// queried type too, the types related to it but for one method.
+	Verbose bool
+
+	// NoCgo causes the pointer analysis modes to only parse the cgo
+	// files, as the other modes do, instead of processing them with
+	// cgo, which needs a C compiler.  The code referring to C is left
+	// out of the analysis, with a warning.
+	NoCgo bool
+
 	// result-printing function
 	Output func(*token.FileSet, QueryResult)
+
+	// Cache, if not nil, holds the programs loaded by the earlier
+	// queries of a batch, for this one to use.
+	Cache *Cache
+}
+
+// A Cache holds the programs loaded by the queries of a batch, so that
+// a query of a package answered before need not load it again, nor,
+// for the pointer analysis, build its SSA program again.  The queries
+// of a batch run one after another, with the same Build context, and
+// the files they read must not change in the meantime.
+type Cache struct {
+	progs map[string]*loader.Program // by the key given to load
+	rev   importgraph.Graph          // the reverse import graph, once built
+
+	fset  *token.FileSet       // of the query files
+	files map[string]*ast.File // the query files parsed by fastQueryPos, by name
+
+	ssaProgs map[ssaKey]*ssa.Program          // by createProgram
+	noCgo    map[*loader.Program]*noCgoResult // the warnings of createProgram, nil if none
+	mains    map[*ssa.Program][]*ssa.Package  // the packages setupPTA analyzes
+}
+
+// An ssaKey identifies an SSA program of a Cache.
+type ssaKey struct {
+	lprog *loader.Program
+	mode  ssa.BuilderMode
 }
 
 // Run runs an guru query and populates its Fset and Result.
@@ -111,6 +160,12 @@ func Run(mode string, q *Query) error {
 	}
 }
 
+// scopeKey returns the key under which load caches the program of the
+// pointer analysis scope of q, set up by setPTAScope and noCgo.
+func scopeKey(q *Query) string {
+	return fmt.Sprintf("scope %s, nocgo %t", strings.Join(q.Scope, " "), q.NoCgo)
+}
+
 func setPTAScope(lconf *loader.Config, scope []string) error {
 	pkgs := buildutil.ExpandPatterns(lconf.Build, scope)
 	if len(pkgs) == 0 {
@@ -124,7 +179,34 @@ func setPTAScope(lconf *loader.Config, scope []string) error {
 
 // Create a pointer.Config whose scope is the initial packages of lprog
 // and their dependencies.
-func setupPTA(prog *ssa.Program, lprog *loader.Program, ptaLog io.Writer, reflection bool) (*pointer.Config, error) {
+func setupPTA(q *Query, prog *ssa.Program, lprog *loader.Program) (*pointer.Config, error) {
+	// In a batch, the test main packages of prog are created once.
+	var mains []*ssa.Package
+	if q.Cache != nil {
+		mains = q.Cache.mains[prog]
+	}
+	if mains == nil {
+		mains = ptaMains(prog, lprog)
+		if q.Cache != nil {
+			if q.Cache.mains == nil {
+				q.Cache.mains = make(map[*ssa.Program][]*ssa.Package)
+			}
+			q.Cache.mains[prog] = mains
+		}
+	}
+	if mains == nil {
+		return nil, fmt.Errorf("analysis scope has no main and no tests")
+	}
+	return &pointer.Config{
+		Log:        q.PTALog,
+		Reflection: q.Reflection,
+		Mains:      mains,
+	}, nil
+}
+
+// ptaMains returns the packages of prog for the pointer analysis of the
+// initial packages of lprog.  It creates the test main packages.
+func ptaMains(prog *ssa.Program, lprog *loader.Program) []*ssa.Package {
 	// For each initial package (specified on the command line),
 	// if it has a main function, analyze that,
 	// otherwise analyze its tests, if any.
@@ -139,21 +221,14 @@ func setupPTA(prog *ssa.Program, lprog *loader.Program, ptaLog io.Writer, reflec
 			mains = append(mains, main)
 		}
 	}
-	if mains == nil {
-		return nil, fmt.Errorf("analysis scope has no main and no tests")
-	}
-	return &pointer.Config{
-		Log:        ptaLog,
-		Reflection: reflection,
-		Mains:      mains,
-	}, nil
+	return mains
 }
 
 // importQueryPackage finds the package P containing the
 // query position and tells conf to import it.
 // It returns the package's path.
-func importQueryPackage(pos string, conf *loader.Config) (string, error) {
-	fqpos, err := fastQueryPos(conf.Build, pos)
+func importQueryPackage(q *Query, conf *loader.Config) (string, error) {
+	fqpos, err := fastQueryPos(q)
 	if err != nil {
 		return "", err // bad query
 	}
@@ -211,6 +286,64 @@ func pkgContainsFile(bp *build.Package, filename string) byte {
 	return 0 // not found
 }
 
+// loadQueryPackage tells lconf, set up by allowErrors, to import the
+// package of the query file, and loads it, processed by cgo if cgoPos
+// is not nil, as processCgo arranged.
+func loadQueryPackage(q *Query, lconf *loader.Config, cgoPos *cgoQueryPos) (*loader.Program, error) {
+	importPath, err := importQueryPackage(q, lconf)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s, cgo %t", queryPackageKey(q, importPath), cgoPos != nil)
+	return load(q, key, lconf)
+}
+
+// queryPackageKey returns the part of a key of load that names the
+// package of the query file, of the import path importQueryPackage
+// returned.
+func queryPackageKey(q *Query, importPath string) string {
+	if importPath == "command-line-arguments" {
+		// The query file alone, wherever it is.
+		filename, _, _, _ := parsePos(q.Pos)
+		return "file " + filename
+	}
+	return "package " + importPath
+}
+
+// load loads the program of lconf or, if q has a Cache, takes the one
+// loaded under the same key by an earlier query of the batch.  The key
+// identifies the packages of lconf and how they are processed.
+func load(q *Query, key string, lconf *loader.Config) (*loader.Program, error) {
+	if q.Cache == nil {
+		return lconf.Load()
+	}
+	if lprog := q.Cache.progs[key]; lprog != nil {
+		return lprog, nil
+	}
+	lprog, err := lconf.Load()
+	if err != nil {
+		return nil, err
+	}
+	if q.Cache.progs == nil {
+		q.Cache.progs = make(map[string]*loader.Program)
+	}
+	q.Cache.progs[key] = lprog
+	return lprog, nil
+}
+
+// reverseImportGraph returns the reverse import graph of the workspace,
+// built once for the queries of a batch.  Broken packages are ignored.
+func reverseImportGraph(q *Query) importgraph.Graph {
+	if q.Cache != nil && q.Cache.rev != nil {
+		return q.Cache.rev
+	}
+	_, rev, _ := importgraph.Build(q.Build)
+	if q.Cache != nil {
+		q.Cache.rev = rev
+	}
+	return rev
+}
+
 // ParseQueryPos parses the source query position pos and returns the
 // AST node of the loaded program lprog that it identifies.
 // If needExact, it must identify a single AST subtree;
@@ -252,10 +385,11 @@ func parseQueryPos(lprog *loader.Program, pos string, needExact bool) (*queryPos
 
 // ---------- Utilities ----------
 
-// loadWithSoftErrors calls lconf.Load, suppressing "soft" errors.  (See Go issue 16530.)
+// loadWithSoftErrors loads the program of lconf, as load does under key,
+// suppressing "soft" errors.  (See Go issue 16530.)
 // TODO(adonovan): Once the loader has an option to allow soft errors,
 // replace calls to loadWithSoftErrors with loader calls with that parameter.
-func loadWithSoftErrors(lconf *loader.Config) (*loader.Program, error) {
+func loadWithSoftErrors(q *Query, key string, lconf *loader.Config) (*loader.Program, error) {
 	lconf.AllowErrors = true
 
 	// Ideally we would just return conf.Load() here, but go/types
@@ -263,10 +397,20 @@ func loadWithSoftErrors(lconf *loader.Config) (*loader.Program, error) {
 	// As a workaround, we set AllowErrors=true and then duplicate
 	// the loader's error checking but allow soft errors.
 	// It would be nice if the loader API permitted "AllowErrors: soft".
-	prog, err := lconf.Load()
+	prog, err := load(q, key, lconf)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkSoftErrors(prog); err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+// checkSoftErrors returns an error naming the packages of prog, loaded with
+// AllowErrors, that contain hard errors, and enables SSA construction for
+// the others.
+func checkSoftErrors(prog *loader.Program) error {
 	var errpkgs []string
 	// Report hard errors in indirectly imported packages.
 	for _, info := range prog.AllPackages {
@@ -283,10 +427,10 @@ func loadWithSoftErrors(lconf *loader.Config) (*loader.Program, error) {
 			more = fmt.Sprintf(" and %d more", len(errpkgs)-3)
 			errpkgs = errpkgs[:3]
 		}
-		return nil, fmt.Errorf("couldn't load packages due to errors: %s%s",
+		return fmt.Errorf("couldn't load packages due to errors: %s%s",
 			strings.Join(errpkgs, ", "), more)
 	}
-	return prog, err
+	return nil
 }
 
 func containsHardErrors(errors []error) bool {
@@ -314,6 +458,288 @@ func allowErrors(lconf *loader.Config) {
 	lconf.TypeChecker.Error = func(err error) {}
 }
 
+// noCgo causes the cgo files of the program of lconf, with -nocgo, to be
+// only parsed, with import "C" declaring an empty package, the members of
+// which are of invalid type.  Such code is left out of the SSA program by
+// createProgram.
+func noCgo(q *Query, lconf *loader.Config) {
+	if !q.NoCgo {
+		return
+	}
+	lconf.FindPackage = importCgoAsGo
+	lconf.TypeChecker.FakeImportC = true
+}
+
+// createProgram returns the SSA program of lprog, as
+// ssautil.CreateProgram does.  With -nocgo, the functions and the
+// package-level variables whose code refers to C are first left out of
+// lprog, the functions becoming external, and a warning is output.
+// In a batch, the program is created once for lprog and mode, and the
+// warning is output for each query.
+func createProgram(q *Query, lprog *loader.Program, mode ssa.BuilderMode) *ssa.Program {
+	if q.NoCgo {
+		if warning := leaveOutCgo(q, lprog); warning != nil {
+			q.Output(lprog.Fset, warning)
+		}
+	}
+	if q.Cache == nil {
+		return newProgram(q, lprog, mode)
+	}
+	key := ssaKey{lprog, mode}
+	if prog := q.Cache.ssaProgs[key]; prog != nil {
+		return prog
+	}
+	prog := newProgram(q, lprog, mode)
+	if q.Cache.ssaProgs == nil {
+		q.Cache.ssaProgs = make(map[ssaKey]*ssa.Program)
+	}
+	q.Cache.ssaProgs[key] = prog
+	return prog
+}
+
+// leaveOutCgo leaves out of lprog the functions and the package-level
+// variables whose code refers to C, and returns the warning of it, or
+// nil if lprog has no cgo files.  In a batch, lprog is changed once and
+// the warning kept for the later queries.
+func leaveOutCgo(q *Query, lprog *loader.Program) *noCgoResult {
+	if q.Cache != nil {
+		if warning, ok := q.Cache.noCgo[lprog]; ok {
+			return warning
+		}
+	}
+	var cgoPkgs []string
+	var leftOut []cgoLeftOut
+	for _, info := range lprog.AllPackages {
+		cgo := false
+		for _, f := range info.Files {
+			if !importsC(f) {
+				continue
+			}
+			cgo = true
+			for _, decl := range f.Decls {
+				if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil && refersToC(info, decl.Body) {
+					decl.Body = nil
+					name := "func " + decl.Name.Name
+					if obj := info.Defs[decl.Name]; obj != nil {
+						name = types.ObjectString(obj, types.RelativeTo(info.Pkg))
+					}
+					leftOut = append(leftOut, cgoLeftOut{decl.Name.Pos(), name})
+				}
+			}
+		}
+		if !cgo {
+			continue
+		}
+		cgoPkgs = append(cgoPkgs, info.Pkg.Path())
+		var order []*types.Initializer
+		for _, init := range info.InitOrder {
+			if !refersToC(info, init.Rhs) {
+				order = append(order, init)
+				continue
+			}
+			for _, v := range init.Lhs {
+				leftOut = append(leftOut, cgoLeftOut{v.Pos(), types.ObjectString(v, types.RelativeTo(info.Pkg))})
+			}
+		}
+		info.InitOrder = order
+	}
+	var warning *noCgoResult
+	if cgoPkgs != nil {
+		sort.Strings(cgoPkgs)
+		sort.Slice(leftOut, func(i, j int) bool { return leftOut[i].pos < leftOut[j].pos })
+		warning = &noCgoResult{cgoPkgs, leftOut}
+	}
+	if q.Cache != nil {
+		if q.Cache.noCgo == nil {
+			q.Cache.noCgo = make(map[*loader.Program]*noCgoResult)
+		}
+		q.Cache.noCgo[lprog] = warning
+	}
+	return warning
+}
+
+// newProgram returns the SSA program of lprog, with -nocgo a package C
+// for each package importing "C".
+func newProgram(q *Query, lprog *loader.Program, mode ssa.BuilderMode) *ssa.Program {
+	prog := ssautil.CreateProgram(lprog, mode)
+	if q.NoCgo {
+		// Each package importing "C" has a package C of its own,
+		// empty, which the pointer analysis requires be complete.
+		for _, info := range lprog.AllPackages {
+			for _, imp := range info.Pkg.Imports() {
+				if imp.Path() == "C" && prog.Package(imp) == nil {
+					imp.MarkComplete()
+					prog.CreatePackage(imp, nil, nil, false)
+				}
+			}
+		}
+	}
+	return prog
+}
+
+// A cgoLeftOut is a function or a package-level variable left out of
+// the SSA program with -nocgo, for its code refers to C.
+type cgoLeftOut struct {
+	pos  token.Pos
+	name string // the declaration of the object, as types.ObjectString has it
+}
+
+// A noCgoResult is the warning of a pointer analysis query with -nocgo,
+// that the cgo files of its program were only parsed.
+type noCgoResult struct {
+	pkgs    []string // the packages with cgo files, sorted
+	leftOut []cgoLeftOut
+}
+
+func (r *noCgoResult) PrintPlain(printf printfFunc) {
+	printf(nil, "warning: -nocgo: the cgo files of %s were only parsed; the results may be imprecise", strings.Join(r.pkgs, ", "))
+	for _, l := range r.leftOut {
+		printf(l.pos, "\t%s refers to C and is left out of the analysis", l.name)
+	}
+}
+
+func (r *noCgoResult) JSON(fset *token.FileSet) []byte {
+	w := &serial.CgoWarning{Packages: r.pkgs}
+	for _, l := range r.leftOut {
+		w.LeftOut = append(w.LeftOut, serial.CgoLeftOut{
+			Pos:  fset.Position(l.pos).String(),
+			Name: l.name,
+		})
+	}
+	return toJSON(w)
+}
+
+// refersToC reports whether the syntax of n, in the package of info,
+// refers to a member of the package C.
+func refersToC(info *loader.PackageInfo, n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if pkgname, ok := info.Uses[id].(*types.PkgName); ok && pkgname.Imported().Path() == "C" {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// importsC reports whether the file imports "C".
+func importsC(f *ast.File) bool {
+	for _, imp := range f.Imports {
+		if imp.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
+
+// isModified reports whether the contents of filename in ctxt, which
+// may overlay the files on disk with those of the archive of -modified,
+// differ from those on disk.
+func isModified(ctxt *build.Context, filename string) bool {
+	if ctxt.OpenFile == nil {
+		return false
+	}
+	rc, err := ctxt.OpenFile(filename)
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return false
+	}
+	disk, err := ioutil.ReadFile(filename)
+	return err != nil || !bytes.Equal(data, disk)
+}
+
+// A cgoQueryPos is the query position, found in the output of cgo for
+// the query file, whose package is processed by cgo for Query.CgoQuery.
+type cgoQueryPos struct {
+	filename   string
+	posn       token.Position // of the start, in the query file
+	start, end int            // offsets in the query file
+}
+
+// processCgo causes lconf, set up by allowErrors, to process with cgo the
+// package of the query file if it imports "C", the packages it imports
+// being only parsed as usual.  It returns the query position, to be
+// found in the output of cgo, or nil if q.CgoQuery is not set or the
+// file does not import "C".
+func processCgo(q *Query, lconf *loader.Config) (*cgoQueryPos, error) {
+	if !q.CgoQuery {
+		return nil, nil
+	}
+	fqpos, err := fastQueryPos(q)
+	if err != nil {
+		return nil, err // bad query
+	}
+	if !importsC(fqpos.path[len(fqpos.path)-1].(*ast.File)) {
+		return nil, nil
+	}
+	filename := fqpos.fset.File(fqpos.start).Name()
+	dir := filepath.Dir(filename)
+
+	// cgo reads the files on disk: if those of the package are
+	// modified, as with -modified, only parse them, as usual.
+	if bp, err := q.Build.ImportDir(dir, 0); err == nil {
+		for _, file := range bp.CgoFiles {
+			if isModified(q.Build, filepath.Join(dir, file)) {
+				return nil, nil
+			}
+		}
+	}
+
+	lconf.FindPackage = func(ctxt *build.Context, path, srcDir string, mode build.ImportMode) (*build.Package, error) {
+		if bp, err := ctxt.Import(path, srcDir, mode); err == nil && sameFile(bp.Dir, dir) {
+			return bp, nil // the package of the query file
+		}
+		return importCgoAsGo(ctxt, path, srcDir, mode)
+	}
+	return &cgoQueryPos{
+		filename: filename,
+		posn:     fqpos.fset.Position(fqpos.start),
+		start:    fqpos.fset.Position(fqpos.start).Offset,
+		end:      fqpos.fset.Position(fqpos.end).Offset,
+	}, nil
+}
+
+// queryPos returns the query position, in command-line syntax, in the
+// output of cgo for the query file in lprog, or pos if p is nil.
+//
+// The output of cgo has the lines of the query file, but the references
+// to C rewritten, with line directives restoring the positions of what
+// follows them.  The query starts at the last offset of the output at
+// the line and column of its start, that of the rewritten reference if
+// it starts one, and keeps its length.
+func (p *cgoQueryPos) queryPos(lprog *loader.Program, pos string) (string, error) {
+	if p == nil {
+		return pos, nil
+	}
+	var file *token.File
+	lprog.Fset.Iterate(func(f *token.File) bool {
+		if sameFile(p.filename, f.Name()) {
+			file = f
+			return false // done
+		}
+		return true // continue
+	})
+	if file == nil {
+		return "", fmt.Errorf("file %s not found in loaded program", p.filename)
+	}
+	start := -1
+	for offset := 0; offset < file.Size(); offset++ {
+		if posn := file.Position(file.Pos(offset)); posn.Line == p.posn.Line && posn.Column == p.posn.Column {
+			start = offset
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("query position %s:%d:%d not found in the output of cgo", p.filename, p.posn.Line, p.posn.Column)
+	}
+	return fmt.Sprintf("%s:#%d,#%d", p.filename, start, start+p.end-p.start), nil
+}
+
 // ptrAnalysis runs the pointer analysis and returns its result.
 func ptrAnalysis(conf *pointer.Config) *pointer.Result {
 	result, err := pointer.Analyze(conf)
@@ -378,11 +804,11 @@ func fprintf(w io.Writer, fset *token.FileSet, pos interface{}, format string, a
 		panic(fmt.Sprintf("invalid pos: %T", pos))
 	}
 
-	if sp := fset.Position(start); start == end {
+	if sp := position(fset, start); start == end {
 		// (prints "-: " for token.NoPos)
 		fmt.Fprintf(w, "%s: ", sp)
 	} else {
-		ep := fset.Position(end)
+		ep := position(fset, end)
 		// The -1 below is a concession to Emacs's broken use of
 		// inclusive (not half-open) intervals.
 		// Other editors may not want it.
@@ -395,6 +821,33 @@ func fprintf(w io.Writer, fset *token.FileSet, pos interface{}, format string, a
 	io.WriteString(w, "\n")
 }
 
+// position returns the position of pos, as adjusted by //line
+// directives, unless the file they name does not exist, as for a file
+// generated from a grammar that is not at hand; then it returns the
+// position in the file holding pos, which editors can open.
+func position(fset *token.FileSet, pos token.Pos) token.Position {
+	posn := fset.Position(pos)
+	if !pos.IsValid() {
+		return posn
+	}
+	if phys := fset.PositionFor(pos, false); phys.Filename != posn.Filename {
+		if _, err := os.Stat(posn.Filename); err != nil {
+			return phys
+		}
+	}
+	return posn
+}
+
+// physPos returns the position of pos in the file holding it, in
+// JSON form, if //line directives adjust the position reported,
+// or "" if they do not.
+func physPos(fset *token.FileSet, pos token.Pos) string {
+	if phys := fset.PositionFor(pos, false); phys != fset.Position(pos) {
+		return phys.String()
+	}
+	return ""
+}
+
 func toJSON(x interface{}) []byte {
 	b, err := json.MarshalIndent(x, "", "\t")
 	if err != nil {
@@ -47,6 +47,7 @@ import (
 	"testing"
 
 	guru "github.com/frankreh/tools/cmd/guru"
+	"golang.org/x/tools/go/buildutil"
 )
 
 var updateFlag = flag.Bool("update", false, "Update the golden files.")
@@ -93,7 +94,8 @@ func parseQueries(t *testing.T, filename string) []*query {
 		if text == "" || text[0] != '@' {
 			continue
 		}
-		posn := fset.Position(c.Pos())
+		// (The position in the file, through any //line directive.)
+		posn := fset.PositionFor(c.Pos(), false)
 
 		// @verb id "regexp"
 		match := expectRe.FindStringSubmatch(text)
@@ -152,8 +154,9 @@ func parseQueries(t *testing.T, filename string) []*query {
 }
 
 // doQuery poses query q to the guru and writes its response and
-// error (if any) to out.
-func doQuery(out io.Writer, q *query, json bool) {
+// error (if any) to out.  The cache, if not nil, is that of the batch
+// of q.
+func doQuery(out io.Writer, q *query, json bool, cache *guru.Cache) {
 	fmt.Fprintf(out, "-------- @%s %s --------\n", q.verb, q.id)
 
 	var buildContext = build.Default
@@ -180,12 +183,24 @@ func doQuery(out io.Writer, q *query, json bool) {
 		}
 	}
 
+	// The scope of the pointer analysis is the package of the query,
+	// but a referrers query searches the whole workspace, unless its
+	// file has a scope of its own.
+	scope := []string{pkg}
+	if q.verb == "referrers" {
+		scope = referrersScopes[q.filename]
+	}
+
 	query := guru.Query{
 		Pos:        q.queryPos,
 		Build:      &buildContext,
-		Scope:      []string{pkg},
+		Scope:      scope,
 		Reflection: true,
+		CgoQuery:   pkg == "cgoquery",
+		Verbose:    strings.HasPrefix(pkg, "implements-verbose"),
+		NoCgo:      strings.HasPrefix(pkg, "nocgo"),
 		Output:     outputFn,
+		Cache:      cache,
 	}
 
 	if err := guru.Run(q.verb, &query); err != nil {
@@ -209,6 +224,24 @@ func doQuery(out io.Writer, q *query, json bool) {
 	}
 }
 
+// The -scope of the referrers queries of these files.
+var referrersScopes = map[string][]string{
+	"testdata/src/referrers-scope/main.go": {"referrers-scope"},
+}
+
+// The queries of each of these files are answered as one batch, as with
+// -batch, loading each program once.
+var batchFiles = map[string]bool{
+	"testdata/src/callees-static/main.go": true,
+	"testdata/src/cgo/cgo.go":             true,
+	"testdata/src/implements/main.go":     true,
+	"testdata/src/nocgo/main.go":          true,
+	"testdata/src/peers/main.go":          true,
+	"testdata/src/pointsto/main.go":       true,
+	"testdata/src/what/main.go":           true,
+	"testdata/src/whicherrs/main.go":      true,
+}
+
 func TestGuru(t *testing.T) {
 	switch runtime.GOOS {
 	case "android":
@@ -220,29 +253,39 @@ func TestGuru(t *testing.T) {
 	for _, filename := range []string{
 		"testdata/src/alias/alias.go", // iff guru.HasAlias (go1.9)
 		"testdata/src/calls/main.go",
+		"testdata/src/callees-static/main.go",
 		"testdata/src/describe/main.go",
 		"testdata/src/describe/main19.go", // iff go1.9
 		"testdata/src/freevars/main.go",
 		"testdata/src/implements/main.go",
 		"testdata/src/implements-methods/main.go",
+		"testdata/src/implements-verbose/main.go",
 		"testdata/src/imports/main.go",
 		"testdata/src/peers/main.go",
 		"testdata/src/pointsto/main.go",
 		"testdata/src/referrers/main.go",
+		"testdata/src/referrers-scope/main.go",
 		"testdata/src/reflection/main.go",
 		"testdata/src/what/main.go",
 		"testdata/src/whicherrs/main.go",
 		"testdata/src/softerrs/main.go",
 		"testdata/src/cgo/cgo.go",
+		"testdata/src/cgoquery/cgoquery.go", // iff cgo works
+		"testdata/src/nocgo/main.go",
 		// JSON:
 		// TODO(adonovan): most of these are very similar; combine them.
 		"testdata/src/calls-json/main.go",
 		"testdata/src/peers-json/main.go",
 		"testdata/src/definition-json/main.go",
 		"testdata/src/definition-json/main19.go",
+		"testdata/src/definition-errors-json/main.go",
 		"testdata/src/describe-json/main.go",
+		"testdata/src/freevars-json/main.go",
 		"testdata/src/implements-json/main.go",
 		"testdata/src/implements-methods-json/main.go",
+		"testdata/src/implements-verbose-json/main.go",
+		"testdata/src/linedirective-json/main.go",
+		"testdata/src/nocgo-json/main.go",
 		"testdata/src/pointsto-json/main.go",
 		"testdata/src/referrers-json/main.go",
 		"testdata/src/what-json/main.go",
@@ -255,6 +298,9 @@ func TestGuru(t *testing.T) {
 		if filename == "testdata/src/alias/alias.go" && !guru.HasAlias {
 			continue
 		}
+		if filename == "testdata/src/cgoquery/cgoquery.go" && !cgoWorks() {
+			continue
+		}
 		if strings.HasSuffix(filename, "19.go") && !contains(build.Default.ReleaseTags, "go1.9") {
 			// TODO(adonovan): recombine the 'describe' and 'definition'
 			// tests once we drop support for go1.8.
@@ -275,8 +321,12 @@ func TestGuru(t *testing.T) {
 
 		// Run the guru on each query, redirecting its output
 		// and error (if any) to the foo.got file.
+		var cache *guru.Cache
+		if batchFiles[filename] {
+			cache = new(guru.Cache)
+		}
 		for _, q := range queries {
-			doQuery(gotfh, q, json)
+			doQuery(gotfh, q, json, cache)
 		}
 
 		// Compare foo.got with foo.golden.
@@ -304,6 +354,101 @@ func TestGuru(t *testing.T) {
 	}
 }
 
+// TestCgoPreamble checks the queries within the preamble of import "C",
+// which those in the comments of the testdata files cannot select.
+func TestCgoPreamble(t *testing.T) {
+	if runtime.GOOS == "android" {
+		t.Skipf("skipping test on %q (no testdata dir)", runtime.GOOS)
+	}
+
+	filename := "testdata/src/cgo/type.go"
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := bytes.Index(data, []byte("W_SIZE"))
+	for _, test := range []struct {
+		verb, want string
+	}{
+		{"what", "comment group\nsource file\nmodes: [describe]\nsrcdir: testdata/src\nimport path: cgo\n"},
+		{"describe", "cgo preamble of import \"C\"\n"},
+	} {
+		q := &query{
+			id:       "preamble",
+			verb:     test.verb,
+			filename: filename,
+			queryPos: fmt.Sprintf("%s:#%d,#%d", filename, offset, offset+len("W_SIZE")),
+		}
+		var out bytes.Buffer
+		doQuery(&out, q, false, nil)
+		want := fmt.Sprintf("-------- @%s preamble --------\n%s\n", test.verb, test.want)
+		if got := out.String(); got != want {
+			t.Errorf("%s in the preamble: got\n%swant\n%s", test.verb, got, want)
+		}
+	}
+}
+
+// TestModifiedCgo checks that a query in a modified cgo file, as from
+// the archive of -modified, has the answer of the same query on disk.
+func TestModifiedCgo(t *testing.T) {
+	if runtime.GOOS == "android" {
+		t.Skipf("skipping test on %q (no testdata dir)", runtime.GOOS)
+	}
+
+	filename := "testdata/src/cgo/cgo.go"
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file, with a function added referring to W of type.go.
+	modified := append(data, "\nfunc modified_tests() {\n\tvar w W\n\tprint(w)\n}\n"...)
+
+	definition := func(ctxt *build.Context, src []byte, cgoQuery bool) string {
+		offset := bytes.LastIndex(src, []byte("var w W"))
+		if offset < 0 {
+			offset = bytes.Index(src, []byte(`var _ W // @definition`))
+		}
+		offset += len("var w ")
+		var out bytes.Buffer
+		query := guru.Query{
+			Pos:      fmt.Sprintf("%s:#%d", filename, offset),
+			Build:    ctxt,
+			CgoQuery: cgoQuery,
+			Output: func(fset *token.FileSet, qr guru.QueryResult) {
+				out.Write(qr.JSON(fset))
+			},
+		}
+		if err := guru.Run("definition", &query); err != nil {
+			return "Error: " + err.Error()
+		}
+		return out.String()
+	}
+
+	ctxt := build.Default
+	ctxt.GOPATH = "testdata"
+	want := definition(&ctxt, data, false)
+	overlay := buildutil.OverlayContext(&ctxt, map[string][]byte{filename: modified})
+	for _, cgoQuery := range []bool{false, true} {
+		if cgoQuery && !cgoWorks() {
+			continue
+		}
+		if got := definition(overlay, modified, cgoQuery); got != want {
+			t.Errorf("definition in the modified %s, with CgoQuery %t: got\n%s\nwant\n%s", filename, cgoQuery, got, want)
+		}
+	}
+}
+
+// cgoWorks reports whether cgo can process the files importing "C",
+// which needs a C compiler.
+func cgoWorks() bool {
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "gcc"
+	}
+	_, err := exec.LookPath(cc)
+	return build.Default.CgoEnabled && err == nil
+}
+
 func contains(haystack []string, needle string) bool {
 	for _, x := range haystack {
 		if needle == x {
@@ -16,7 +16,6 @@ import (
 	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/types/typeutil"
-	"golang.org/x/tools/refactor/importgraph"
 )
 
 // Implements displays the "implements" relation as it pertains to the
@@ -29,7 +28,7 @@ func implements(q *Query) error {
 	lconf := loader.Config{Build: q.Build}
 	allowErrors(&lconf)
 
-	qpkg, err := importQueryPackage(q.Pos, &lconf)
+	qpkg, err := importQueryPackage(q, &lconf)
 	if err != nil {
 		return err
 	}
@@ -44,8 +43,7 @@ func implements(q *Query) error {
 		// Otherwise inspect the forward and reverse
 		// transitive closure of the selected package.
 		// (In theory even this is incomplete.)
-		_, rev, _ := importgraph.Build(q.Build)
-		for path := range rev.Search(qpkg) {
+		for path := range reverseImportGraph(q).Search(qpkg) {
 			lconf.ImportWithTests(path)
 		}
 
@@ -59,7 +57,11 @@ func implements(q *Query) error {
 	}
 
 	// Load/parse/type-check the program.
-	lprog, err := lconf.Load()
+	key := "implements, " + queryPackageKey(q, qpkg)
+	if len(q.Scope) > 0 {
+		key += ", scope " + strings.Join(q.Scope, " ")
+	}
+	lprog, err := load(q, key, &lconf)
 	if err != nil {
 		return err
 	}
@@ -188,12 +190,86 @@ func implements(q *Query) error {
 		}
 	}
 
+	var nearMisses []nearMiss
+	if q.Verbose && method == nil {
+		nearMisses = findNearMisses(T, allNamed, &msets)
+	}
+
 	q.Output(lprog.Fset, &implementsResult{
-		qpos, T, pos, to, from, fromPtr, method, toMethod, fromMethod, fromPtrMethod,
+		qpos, T, pos, to, from, fromPtr, method, toMethod, fromMethod, fromPtrMethod, nearMisses,
 	})
 	return nil
 }
 
+// A nearMiss is a type that would implement the queried interface, or
+// be implemented by the queried concrete type, but for one method.
+type nearMiss struct {
+	t       types.Type  // the named type
+	missing *types.Func // the method of the interface
+	wrong   *types.Func // the method of that name but another type, if any
+}
+
+// findNearMisses returns the near misses of T among the named types:
+// for an interface T, the concrete types U such that *U lacks exactly
+// one of its methods; for a concrete type T, the interfaces whose
+// methods *T, or T if it is a pointer, has all but one of.  Interfaces
+// of a single method have no near misses.
+func findNearMisses(T types.Type, allNamed []*types.Named, msets *typeutil.MethodSetCache) []nearMiss {
+	var misses []nearMiss
+	for _, U := range allNamed {
+		var impl, iface types.Type
+		switch {
+		case isInterface(T) && !isInterface(U):
+			impl, iface = types.NewPointer(U), T
+		case !isInterface(T) && isInterface(U):
+			impl, iface = T, U
+			if _, ok := T.(*types.Pointer); !ok {
+				impl = types.NewPointer(T)
+			}
+		default:
+			continue
+		}
+		imset := msets.MethodSet(iface)
+		if imset.Len() < 2 || types.AssignableTo(impl, iface) {
+			continue
+		}
+		mset := msets.MethodSet(impl)
+		var miss nearMiss
+		n := 0
+		for i := 0; i < imset.Len(); i++ {
+			m := imset.At(i).Obj().(*types.Func)
+			sel := mset.Lookup(m.Pkg(), m.Name())
+			if sel == nil || !types.Identical(sel.Obj().Type(), m.Type()) {
+				n++
+				miss = nearMiss{t: U, missing: m}
+				if sel != nil {
+					miss.wrong = sel.Obj().(*types.Func)
+				}
+			}
+		}
+		if n == 1 {
+			misses = append(misses, miss)
+		}
+	}
+	sort.Slice(misses, func(i, j int) bool {
+		return misses[i].t.String() < misses[j].t.String()
+	})
+	return misses
+}
+
+// implementingMethods returns the methods of impl by which it
+// implements iface, in the order of those of iface.
+func implementingMethods(impl, iface types.Type) []*types.Selection {
+	mset := types.NewMethodSet(impl)
+	imset := types.NewMethodSet(iface)
+	var methods []*types.Selection
+	for i := 0; i < imset.Len(); i++ {
+		m := imset.At(i).Obj()
+		methods = append(methods, mset.Lookup(m.Pkg(), m.Name()))
+	}
+	return methods
+}
+
 type implementsResult struct {
 	qpos *queryPos
 
@@ -208,9 +284,30 @@ type implementsResult struct {
 	toMethod      []*types.Selection // method of type to[i], if any
 	fromMethod    []*types.Selection // method of type from[i], if any
 	fromPtrMethod []*types.Selection // method of type fromPtrMethod[i], if any
+
+	nearMisses []nearMiss // with Query.Verbose, if no method was queried
 }
 
 func (r *implementsResult) PrintPlain(printf printfFunc) {
+	r.printRelated(printf)
+
+	for _, miss := range r.nearMisses {
+		lack := fmt.Sprintf("lacking method %s", miss.missing.Name())
+		if miss.wrong != nil {
+			lack = fmt.Sprintf("whose method %s has another type", miss.wrong.Name())
+		}
+		if isInterface(r.t) {
+			printf(miss.t.(*types.Named).Obj(), "\tis nearly implemented by %s type %s, %s",
+				typeKind(miss.t), r.qpos.typeString(miss.t), lack)
+		} else {
+			printf(miss.t.(*types.Named).Obj(), "\tnearly implements %s, %s",
+				r.qpos.typeString(miss.t), lack)
+		}
+	}
+}
+
+// printRelated prints the queried type or method and those related to it.
+func (r *implementsResult) printRelated(printf printfFunc) {
 	relation := "is implemented by"
 
 	meth := func(sel *types.Selection) {
@@ -315,17 +412,50 @@ func (r *implementsResult) JSON(fset *token.FileSet) []byte {
 			Pos:  fset.Position(r.method.Pos()).String(),
 		}
 	}
+
+	// The methods by which each pair of types is related.
+	to := makeImplementsTypes(r.to, fset)
+	for i, sub := range r.to {
+		to[i].Methods = methodsToSerial(r.qpos.info.Pkg, implementingMethods(sub, r.t), fset)
+	}
+	from := makeImplementsTypes(r.from, fset)
+	for i, super := range r.from {
+		from[i].Methods = methodsToSerial(r.qpos.info.Pkg, implementingMethods(r.t, super), fset)
+	}
+	fromPtr := makeImplementsTypes(r.fromPtr, fset)
+	for i, psuper := range r.fromPtr {
+		fromPtr[i].Methods = methodsToSerial(r.qpos.info.Pkg, implementingMethods(types.NewPointer(r.t), psuper), fset)
+	}
+
+	var nearMisses []serial.ImplementsNearMiss
+	for _, miss := range r.nearMisses {
+		jmiss := serial.ImplementsNearMiss{
+			Type: makeImplementsType(miss.t, fset),
+			Missing: serial.DescribeMethod{
+				Name: r.qpos.objectString(miss.missing),
+				Pos:  fset.Position(miss.missing.Pos()).String(),
+			},
+		}
+		if miss.wrong != nil {
+			jmiss.Wrong = &serial.DescribeMethod{
+				Name: r.qpos.objectString(miss.wrong),
+				Pos:  fset.Position(miss.wrong.Pos()).String(),
+			}
+		}
+		nearMisses = append(nearMisses, jmiss)
+	}
+
 	return toJSON(&serial.Implements{
 		T:                       makeImplementsType(r.t, fset),
-		AssignableTo:            makeImplementsTypes(r.to, fset),
-		AssignableFrom:          makeImplementsTypes(r.from, fset),
-		AssignableFromPtr:       makeImplementsTypes(r.fromPtr, fset),
+		AssignableTo:            to,
+		AssignableFrom:          from,
+		AssignableFromPtr:       fromPtr,
 		AssignableToMethod:      methodsToSerial(r.qpos.info.Pkg, r.toMethod, fset),
 		AssignableFromMethod:    methodsToSerial(r.qpos.info.Pkg, r.fromMethod, fset),
 		AssignableFromPtrMethod: methodsToSerial(r.qpos.info.Pkg, r.fromPtrMethod, fset),
 		Method:                  method,
+		NearMisses:              nearMisses,
 	})
-
 }
 
 func makeImplementsTypes(tt []types.Type, fset *token.FileSet) []serial.ImplementsType {
@@ -23,6 +23,7 @@ import (
 	"strings"
 	"sync"
 
+	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/buildutil"
 )
 
@@ -34,6 +35,10 @@ var (
 	jsonFlag       = flag.Bool("json", false, "emit output in JSON format")
 	reflectFlag    = flag.Bool("reflect", false, "analyze reflection soundly (slow)")
 	cpuprofileFlag = flag.String("cpuprofile", "", "write CPU profile to `file`")
+	cgoFlag        = flag.String("cgo", "", "with -cgo=query, process the package of the query file with cgo if the file imports \"C\"")
+	verboseFlag    = flag.Bool("verbose", false, "with implements, also report the types related to the queried type but for one method")
+	nocgoFlag      = flag.Bool("nocgo", false, "with the pointer analysis modes, only parse cgo files instead of processing them with cgo")
+	batchFlag      = flag.Bool("batch", false, "read queries, one `mode position` per line, from standard input")
 )
 
 func init() {
@@ -44,6 +49,7 @@ const useHelp = "Run 'guru -help' for more information.\n"
 
 const helpMessage = `Go source code guru.
 Usage: guru [flags] <mode> <position>
+       guru [flags] -batch
 
 The mode argument determines the query to perform:
 
@@ -76,7 +82,26 @@ The -modified flag causes guru to read an archive from standard input.
 	the file system.  In this way, a text editor may supply guru
 	with the contents of its unsaved buffers.  Each archive entry
 	consists of the file name, a newline, the decimal file size,
-	another newline, and the contents of the file.
+	another newline, and the contents of the file.  With -cgo=query,
+	the cgo files of a package of which some are modified are only
+	parsed, as cgo reads the files on disk.
+
+The -batch flag causes guru to read its queries from standard input,
+	one per line, each a mode and a position, instead of taking one
+	from its arguments.  The program loaded for a query is used again
+	by the later queries of the same package, which makes them quick.
+	The results of each query follow a line "-: -------- mode position
+	--------", and its error, if any, a line "-: error: message";
+	with -json, they follow a BatchQuery object, and the error is a
+	BatchError object.  -batch cannot be used with -modified.
+
+The -nocgo flag causes the modes using pointer analysis (callees,
+	callers, callstack, peers, pointsto and whicherrs) to only parse
+	cgo files, as the other modes do, instead of processing them with
+	cgo, which needs a C compiler.  The functions and package-level
+	variables whose code refers to C are left out of the analysis,
+	which may make the results imprecise; a warning listing them
+	precedes the results.
 
 The -scope flag restricts analysis to the specified packages.
 	Its value is a comma-separated list of patterns of these forms:
@@ -86,6 +111,9 @@ The -scope flag restricts analysis to the specified packages.
 	A pattern preceded by '-' is negative, so the scope
 		encoding/...,-encoding/xml
 	matches all encoding packages except encoding/xml.
+	For referrers, it restricts the packages searched for references
+	to a package or its members, besides the declaring package;
+	the output then states the scope, as there may be others.
 
 User manual: http://golang.org/s/using-guru
 
@@ -117,11 +145,18 @@ func main() {
 	}
 
 	args := flag.Args()
-	if len(args) != 2 {
+	var mode, posn string
+	switch {
+	case *batchFlag && len(args) == 0:
+		if *modifiedFlag {
+			log.Fatal("-batch and -modified both read standard input")
+		}
+	case !*batchFlag && len(args) == 2:
+		mode, posn = args[0], args[1]
+	default:
 		flag.Usage()
 		os.Exit(2)
 	}
-	mode, posn := args[0], args[1]
 
 	if mode == "help" {
 		printHelp()
@@ -170,7 +205,8 @@ func main() {
 
 		// All I/O done by guru needs to consult the modified map.
 		// The ReadFile done by referrers does,
-		// but the loader's cgo preprocessing currently does not.
+		// but the loader's cgo preprocessing currently does not,
+		// so -cgo=query only parses a package with modified cgo files.
 
 		if len(modified) > 0 {
 			ctxt = buildutil.OverlayContext(ctxt, modified)
@@ -199,6 +235,10 @@ func main() {
 		scope = strings.Split(*scopeFlag, ",")
 	}
 
+	if *cgoFlag != "" && *cgoFlag != "query" {
+		log.Fatalf("invalid -cgo mode %q; only query is supported", *cgoFlag)
+	}
+
 	// Ask the guru.
 	query := Query{
 		Pos:        posn,
@@ -206,10 +246,57 @@ func main() {
 		Scope:      scope,
 		PTALog:     ptalog,
 		Reflection: *reflectFlag,
+		CgoQuery:   *cgoFlag == "query",
+		Verbose:    *verboseFlag,
+		NoCgo:      *nocgoFlag,
 		Output:     output,
 	}
 
+	if *batchFlag {
+		if err := runBatch(os.Stdin, query); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := Run(mode, &query); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// runBatch answers the queries read from in, one per line, each a mode
+// and a position, with the settings of query.  The queries share a
+// Cache of the programs they load.  The results of each query follow
+// a header naming it, and the failure of one does not stop the batch.
+func runBatch(in io.Reader, query Query) error {
+	query.Cache = new(Cache)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// (The position may hold spaces, in the name of the file.)
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return fmt.Errorf("invalid batch query %q: want a mode and a position", line)
+		}
+		mode := line[:i]
+		q := query // copy
+		q.Pos = strings.TrimSpace(line[i:])
+
+		if *jsonFlag {
+			fmt.Printf("%s\n", toJSON(&serial.BatchQuery{Mode: mode, Pos: q.Pos}))
+		} else {
+			fmt.Printf("-: -------- %s %s --------\n", mode, q.Pos)
+		}
+		if err := Run(mode, &q); err != nil {
+			if *jsonFlag {
+				fmt.Printf("%s\n", toJSON(&serial.BatchError{Error: err.Error()}))
+			} else {
+				fmt.Printf("-: error: %s\n", err)
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -29,9 +29,10 @@ func peers(q *Query) error {
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -41,9 +42,9 @@ func peers(q *Query) error {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := createProgram(q, lprog, ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := setupPTA(q, prog, lprog)
 	if err != nil {
 		return err
 	}
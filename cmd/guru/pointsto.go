@@ -16,7 +16,6 @@ import (
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/pointer"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // pointsto runs the pointer analysis on the selected expression,
@@ -32,9 +31,10 @@ func pointsto(q *Query) error {
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -44,9 +44,9 @@ func pointsto(q *Query) error {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := createProgram(q, lprog, ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := setupPTA(q, prog, lprog)
 	if err != nil {
 		return err
 	}
@@ -8,7 +8,7 @@ package main
 
 import (
 	"fmt"
-	"go/build"
+	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
@@ -106,19 +106,15 @@ func sameFile(x, y string) bool {
 	return false
 }
 
-// fastQueryPos parses the position string and returns a queryPos.
+// fastQueryPos parses the position string of q and returns a queryPos.
 // It parses only a single file and does not run the type checker.
-func fastQueryPos(ctxt *build.Context, pos string) (*queryPos, error) {
-	filename, startOffset, endOffset, err := parsePos(pos)
+func fastQueryPos(q *Query) (*queryPos, error) {
+	filename, startOffset, endOffset, err := parsePos(q.Pos)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the file, opening it the file via the build.Context
-	// so that we observe the effects of the -modified flag.
-	fset := token.NewFileSet()
-	cwd, _ := os.Getwd()
-	f, err := buildutil.ParseFile(fset, ctxt, nil, cwd, filename, parser.Mode(0))
+	fset, f, err := parseQueryFile(q, filename)
 	// ParseFile usually returns a partial file along with an error.
 	// Only fail if there is no file.
 	if f == nil {
@@ -140,3 +136,28 @@ func fastQueryPos(ctxt *build.Context, pos string) (*queryPos, error) {
 
 	return &queryPos{fset, start, end, path, exact, nil}, nil
 }
+
+// parseQueryFile parses the query file, opening it via the build.Context
+// so that we observe the effects of the -modified flag.  In a batch, it
+// takes the file parsed by an earlier query, if any.
+func parseQueryFile(q *Query, filename string) (*token.FileSet, *ast.File, error) {
+	if q.Cache == nil {
+		fset := token.NewFileSet()
+		cwd, _ := os.Getwd()
+		f, err := buildutil.ParseFile(fset, q.Build, nil, cwd, filename, parser.Mode(0))
+		return fset, f, err
+	}
+	if f := q.Cache.files[filename]; f != nil {
+		return q.Cache.fset, f, nil
+	}
+	if q.Cache.fset == nil {
+		q.Cache.fset = token.NewFileSet()
+		q.Cache.files = make(map[string]*ast.File)
+	}
+	cwd, _ := os.Getwd()
+	f, err := buildutil.ParseFile(q.Cache.fset, q.Build, nil, cwd, filename, parser.Mode(0))
+	if f != nil {
+		q.Cache.files[filename] = f
+	}
+	return q.Cache.fset, f, err
+}
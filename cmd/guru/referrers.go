@@ -20,27 +20,30 @@ import (
 	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
-	"golang.org/x/tools/refactor/importgraph"
 )
 
 // Referrers reports all identifiers that resolve to the same object
 // as the queried identifier, within any package in the workspace.
 func referrers(q *Query) error {
-	fset := token.NewFileSet()
-	lconf := loader.Config{Fset: fset, Build: q.Build}
+	lconf := loader.Config{Build: q.Build}
 	allowErrors(&lconf)
-
-	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
+	cgoPos, err := processCgo(q, &lconf)
+	if err != nil {
 		return err
 	}
 
 	// Load/parse/type-check the query package.
-	lprog, err := lconf.Load()
+	lprog, err := loadQueryPackage(q, &lconf, cgoPos)
 	if err != nil {
 		return err
 	}
+	fset := lprog.Fset
 
-	qpos, err := parseQueryPos(lprog, q.Pos, false)
+	pos, err := cgoPos.queryPos(lprog, q.Pos)
+	if err != nil {
+		return err
+	}
+	qpos, err := parseQueryPos(lprog, pos, false)
 	if err != nil {
 		return err
 	}
@@ -77,7 +80,9 @@ func referrers(q *Query) error {
 	// any package that transitively imports P.
 	if global, pkglevel := classify(obj); global {
 		// We'll use the the object's position to identify it in the larger program.
-		objposn := fset.Position(obj.Pos())
+		// (The position in the file, as //line directives may map
+		// several positions to one.)
+		objposn := fset.PositionFor(obj.Pos(), false)
 		defpkg := obj.Pkg().Path() // defining package
 		return globalReferrers(q, qpos.info.Pkg.Path(), defpkg, objposn, pkglevel)
 	}
@@ -113,12 +118,11 @@ func classify(obj types.Object) (global, pkglevel bool) {
 // throughout the workspace.
 func packageReferrers(q *Query, path string) error {
 	// Scan the workspace and build the import graph.
-	// Ignore broken packages.
-	_, rev, _ := importgraph.Build(q.Build)
+	rev := reverseImportGraph(q)
 
 	// Find the set of packages that directly import the query package.
 	// Only those packages need typechecking of function bodies.
-	users := rev[path]
+	users := scopeUsers(q, rev[path], path)
 
 	// Load the larger program.
 	fset := token.NewFileSet()
@@ -156,6 +160,7 @@ func packageReferrers(q *Query, path string) error {
 			q.Output(fset, &referrersInitialResult{
 				qinfo: info,
 				obj:   fakepkgname, // bogus
+				scope: q.Scope,
 			})
 		}
 
@@ -215,21 +220,22 @@ func outputUses(q *Query, fset *token.FileSet, refs []*ast.Ident, pkg *types.Pac
 // is defined at package-level.
 func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPkgLevel bool) error {
 	// Scan the workspace and build the import graph.
-	// Ignore broken packages.
-	_, rev, _ := importgraph.Build(q.Build)
+	rev := reverseImportGraph(q)
 
 	// Find the set of packages that depend on defpkg.
 	// Only function bodies in those packages need type-checking.
 	var users map[string]bool
 	if isPkgLevel {
-		users = rev[defpkg] // direct importers
-		if users == nil {
-			users = make(map[string]bool)
+		// (A copy, as the graph may serve other queries of a batch.)
+		users = make(map[string]bool)
+		for path := range rev[defpkg] {
+			users[path] = true // direct importers
 		}
 		users[defpkg] = true // plus the defining package itself
 	} else {
 		users = rev.Search(defpkg) // transitive importers
 	}
+	users = scopeUsers(q, users, defpkg)
 
 	// Prepare to load the larger program.
 	fset := token.NewFileSet()
@@ -266,13 +272,9 @@ func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPk
 		qinfo *loader.PackageInfo // info for qpkg
 	)
 
-	// For efficiency, we scan each package for references
-	// just after it has been type-checked.  The loader calls
-	// AfterTypeCheck (concurrently), providing us with a stream of
-	// packages.
-	lconf.AfterTypeCheck = func(info *loader.PackageInfo, files []*ast.File) {
-		// AfterTypeCheck may be called twice for the same package due to augmentation.
-
+	// scan looks for references to the query object in a package,
+	// the declaring package coming before those that depend on it.
+	scan := func(info *loader.PackageInfo) {
 		// Only inspect packages that depend on the declaring package
 		// (and thus were type-checked).
 		if lconf.TypeCheckFuncBodies(info.Pkg.Path()) {
@@ -293,6 +295,7 @@ func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPk
 				q.Output(fset, &referrersInitialResult{
 					qinfo: qinfo,
 					obj:   qobj,
+					scope: q.Scope,
 				})
 			}
 			obj := qobj
@@ -303,11 +306,39 @@ func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPk
 				outputUses(q, fset, usesOf(obj, info), info.Pkg)
 			}
 		}
-
-		clearInfoFields(info) // save memory
 	}
 
-	lconf.Load() // ignore error
+	if q.Cache != nil {
+		// In a batch, load the whole program, for the later queries
+		// about objects of defpkg to use too, and then scan it.
+		key := fmt.Sprintf("users of %s, package-level %t", defpkg, isPkgLevel)
+		lprog, err := load(q, key, &lconf)
+		if err != nil {
+			return err
+		}
+		fset = lprog.Fset
+		definfo := lprog.Package(defpkg)
+		if definfo != nil {
+			scan(definfo)
+		}
+		for _, info := range lprog.AllPackages {
+			if info != definfo {
+				scan(info)
+			}
+		}
+	} else {
+		// For efficiency, we scan each package for references
+		// just after it has been type-checked.  The loader calls
+		// AfterTypeCheck (concurrently), providing us with a stream of
+		// packages.
+		lconf.AfterTypeCheck = func(info *loader.PackageInfo, files []*ast.File) {
+			// AfterTypeCheck may be called twice for the same package due to augmentation.
+			scan(info)
+			clearInfoFields(info) // save memory
+		}
+
+		lconf.Load() // ignore error
+	}
 
 	if qobj == nil {
 		log.Fatal("query object not found during reloading")
@@ -316,13 +347,30 @@ func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPk
 	return nil // success
 }
 
+// scopeUsers returns the packages of users, those to search for the
+// references to a package or its members, restricted to those matching
+// the patterns of q.Scope, if any, and pkg, which declares them.
+func scopeUsers(q *Query, users map[string]bool, pkg string) map[string]bool {
+	if len(q.Scope) == 0 {
+		return users
+	}
+	scope := buildutil.ExpandPatterns(q.Build, q.Scope)
+	restricted := map[string]bool{pkg: true}
+	for path := range users {
+		if _, ok := scope[path]; ok {
+			restricted[path] = true
+		}
+	}
+	return restricted
+}
+
 // findObject returns the object defined at the specified position.
 func findObject(fset *token.FileSet, info *types.Info, objposn token.Position) types.Object {
 	good := func(obj types.Object) bool {
 		if obj == nil {
 			return false
 		}
-		posn := fset.Position(obj.Pos())
+		posn := fset.PositionFor(obj.Pos(), false)
 		return posn.Filename == objposn.Filename && posn.Offset == objposn.Offset
 	}
 	for _, obj := range info.Defs {
@@ -401,21 +449,29 @@ func (p byNamePos) Less(i, j int) bool {
 type referrersInitialResult struct {
 	qinfo *loader.PackageInfo
 	obj   types.Object // object it denotes
+	scope []string     // patterns of the packages searched, or nil for all
 }
 
 func (r *referrersInitialResult) PrintPlain(printf printfFunc) {
-	printf(r.obj, "references to %s",
-		types.ObjectString(r.obj, types.RelativeTo(r.qinfo.Pkg)))
+	var scope string
+	if r.scope != nil {
+		scope = fmt.Sprintf(" in the scope %s (there may be others)", strings.Join(r.scope, ","))
+	}
+	printf(r.obj, "references to %s%s",
+		types.ObjectString(r.obj, types.RelativeTo(r.qinfo.Pkg)), scope)
 }
 
 func (r *referrersInitialResult) JSON(fset *token.FileSet) []byte {
-	var objpos string
+	var objpos, objphyspos string
 	if pos := r.obj.Pos(); pos.IsValid() {
 		objpos = fset.Position(pos).String()
+		objphyspos = physPos(fset, pos)
 	}
 	return toJSON(&serial.ReferrersInitial{
-		Desc:   r.obj.String(),
-		ObjPos: objpos,
+		Desc:       r.obj.String(),
+		ObjPos:     objpos,
+		ObjPhysPos: objphyspos,
+		Scope:      r.scope,
 	})
 }
 
@@ -442,7 +498,7 @@ func (r *referrersPackageResult) foreachRef(f func(id *ast.Ident, text string))
 	// First pass: start the file reads concurrently.
 	sema := make(chan struct{}, 20) // counting semaphore to limit I/O concurrency
 	for _, ref := range r.refs {
-		posn := r.fset.Position(ref.Pos())
+		posn := position(r.fset, ref.Pos())
 		fi := fileinfosByName[posn.Filename]
 		if fi == nil {
 			fi = &fileinfo{data: make(chan interface{})}
@@ -514,8 +570,9 @@ func (r *referrersPackageResult) JSON(fset *token.FileSet) []byte {
 	refs := serial.ReferrersPackage{Package: r.pkg.Path()}
 	r.foreachRef(func(id *ast.Ident, text string) {
 		refs.Refs = append(refs.Refs, serial.Ref{
-			Pos:  fset.Position(id.NamePos).String(),
-			Text: text,
+			Pos:     fset.Position(id.NamePos).String(),
+			PhysPos: physPos(fset, id.NamePos),
+			Text:    text,
 		})
 	})
 	return toJSON(refs)
@@ -23,10 +23,44 @@
 //      what       What
 //      whicherrs  WhichErrs
 //
+// With -batch, a BatchQuery precedes the result stream of each query,
+// and a BatchError follows it if the query fails.
+//
+// With -nocgo, a CgoWarning precedes the result stream of a callees,
+// callers, callstack, peers, pointsto or whicherrs query whose program
+// has cgo files.
+//
 // All 'pos' strings in the output are of the form "file:line:col",
 // where line is the 1-based line number and col is the 1-based byte index.
 package serial
 
+// A BatchQuery, with -batch, names the query whose results follow it,
+// up to the next BatchQuery.
+type BatchQuery struct {
+	Mode string `json:"mode"` // the mode of the query
+	Pos  string `json:"pos"`  // the position of the query, as given
+}
+
+// A BatchError, with -batch, is the error of the query before it.
+type BatchError struct {
+	Error string `json:"error"`
+}
+
+// A CgoWarning, with -nocgo, warns that the cgo files of the program of
+// a pointer analysis query were only parsed, and lists the code left out
+// of the analysis for it refers to C.
+type CgoWarning struct {
+	Packages []string     `json:"packages"`          // the packages with cgo files
+	LeftOut  []CgoLeftOut `json:"leftout,omitempty"` // the code referring to C
+}
+
+// A CgoLeftOut is a function, or a package-level variable, whose code
+// refers to C.
+type CgoLeftOut struct {
+	Pos  string `json:"pos"`  // location of its declaration
+	Name string `json:"name"` // its declaration, e.g. "func f() int"
+}
+
 // A Peers is the result of a 'peers' query.
 // If Allocs is empty, the selected channel can't point to anything.
 type Peers struct {
@@ -42,23 +76,32 @@ type Peers struct {
 // more ReferrersPackage objects, one per package that contains a reference.
 type (
 	ReferrersInitial struct {
-		ObjPos string `json:"objpos,omitempty"` // location of the definition
-		Desc   string `json:"desc"`             // description of the denoted object
+		ObjPos     string   `json:"objpos,omitempty"`     // location of the definition
+		ObjPhysPos string   `json:"objphyspos,omitempty"` // location in the file, if a //line directive moves objpos
+		Desc       string   `json:"desc"`                 // description of the denoted object
+		Scope      []string `json:"scope,omitempty"`      // the -scope the search was limited to, if any
 	}
 	ReferrersPackage struct {
 		Package string `json:"package"`
 		Refs    []Ref  `json:"refs"` // non-empty list of references within this package
 	}
 	Ref struct {
-		Pos  string `json:"pos"`  // location of all references
-		Text string `json:"text"` // text of the referring line
+		Pos     string `json:"pos"`               // location of all references
+		PhysPos string `json:"physpos,omitempty"` // location in the file, if a //line directive moves pos
+		Text    string `json:"text"`              // text of the referring line
 	}
 )
 
 // A Definition is the result of a 'definition' query.
 type Definition struct {
-	ObjPos string `json:"objpos,omitempty"` // location of the definition
-	Desc   string `json:"desc"`             // description of the denoted object
+	ObjPos     string `json:"objpos,omitempty"`     // location of the definition
+	ObjPhysPos string `json:"objphyspos,omitempty"` // location in the file, if a //line directive moves objpos
+	Desc       string `json:"desc"`                 // description of the denoted object
+
+	// Approximate, if set, says why the definition is only a guess,
+	// as when the type errors of the package leave the identifier
+	// unresolved.
+	Approximate string `json:"approximate,omitempty"`
 }
 
 // A Callees is the result of a 'callees' query.
@@ -101,12 +144,17 @@ type CallStack struct {
 
 // A FreeVar is one element of the slice returned by a 'freevars'
 // query.  Each one identifies an expression referencing a local
-// identifier defined outside the selected region.
+// identifier defined outside the selected region.  An expression
+// having several types in the region, as a constant may, has a FreeVar
+// for each.
 type FreeVar struct {
 	Pos  string `json:"pos"`  // location of the identifier's definition
 	Kind string `json:"kind"` // one of {var,func,type,const,label}
 	Ref  string `json:"ref"`  // referring expression (e.g. "x" or "x.y.z")
-	Type string `json:"type"` // type of the expression
+	Type string `json:"type"` // type of the expression, as at the reference
+
+	DeclType string `json:"decltype,omitempty"` // type of the declaration, if another, as for an untyped constant
+	TypePos  string `json:"typepos,omitempty"`  // location of the definition of the named type of the expression, if declared in a function
 }
 
 // An Implements contains the result of an 'implements' query.
@@ -128,13 +176,30 @@ type Implements struct {
 	AssignableToMethod      []DescribeMethod `json:"to_method,omitempty"`
 	AssignableFromMethod    []DescribeMethod `json:"from_method,omitempty"`
 	AssignableFromPtrMethod []DescribeMethod `json:"fromptr_method,omitempty"`
+
+	// NearMisses is set only with -verbose, if the query was not a
+	// method.
+	NearMisses []ImplementsNearMiss `json:"nearmisses,omitempty"`
 }
 
 // An ImplementsType describes a single type as part of an 'implements' query.
+// In the lists of types related to the queried type, Methods[i] is the
+// method by which one of the two implements the i-th method of the
+// other, an interface.
 type ImplementsType struct {
-	Name string `json:"name"` // full name of the type
-	Pos  string `json:"pos"`  // location of its definition
-	Kind string `json:"kind"` // "basic", "array", etc
+	Name    string           `json:"name"`              // full name of the type
+	Pos     string           `json:"pos"`               // location of its definition
+	Kind    string           `json:"kind"`              // "basic", "array", etc
+	Methods []DescribeMethod `json:"methods,omitempty"` // the implementing methods
+}
+
+// An ImplementsNearMiss is a type that would implement the queried
+// interface, or be implemented by the queried concrete type, but for
+// one method of the interface, which it lacks or has with another type.
+type ImplementsNearMiss struct {
+	Type    ImplementsType  `json:"type"`            // the near miss
+	Missing DescribeMethod  `json:"missing"`         // the method of the interface
+	Wrong   *DescribeMethod `json:"wrong,omitempty"` // the method of that name, if any
 }
 
 // A SyntaxNode is one element of a stack of enclosing syntax nodes in
@@ -0,0 +1,5 @@
+package broken
+
+func F() {
+	var _ int = "not an int" // a type error in a function body
+}
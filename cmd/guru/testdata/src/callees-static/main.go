@@ -0,0 +1,27 @@
+package main
+
+// Tests of 'callees' query, answering a static call from the query
+// package alone, and a dynamic one by the pointer analysis of the whole
+// program, which the type error of package broken makes fail.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+import "callees-static/broken"
+
+type I interface {
+	f()
+}
+
+type T int
+
+func (T) f() {}
+
+func main() {
+	broken.F() // @callees callees-static-func "F"
+
+	var t T
+	t.f() // @callees callees-static-method "f"
+
+	var i I = t
+	i.f() // @callees callees-static-dynamic "f"
+}
@@ -20,6 +20,7 @@ package cgo
 //	freevars
 //	implements
 //	referrers
+//	what
 //
 // The remaining guru modes are not tested as doing anything special with cgo
 // files; they are expected to incur the normal cgo processing overhead:
@@ -29,15 +30,16 @@ package cgo
 //	callstack
 //	peers
 //	pointsto
-//	what
 //	whicherrs
 //
-// A future direction for guru's cgo handling could be to allow the queryPos
-// file itself, if it is a cgo file, to be processed by cgo.  Presumably
-// someone working directly in a cgo file is not going to mind a few extra
-// tenths of a second per guru command if it means their results are more
-// accurate.  But this concern may be unfounded - I haven't run into a cgo file
-// that required cgo processing for at least the guru definition command.
+// With -nocgo, these modes only parse the cgo files too, leaving the code
+// referring to C out of the analysis.  See the nocgo package for those tests.
+//
+// With -cgo=query, the guru lets the queryPos file itself, if it is a cgo
+// file, be processed by cgo, for the definition, describe and referrers
+// modes.  Presumably someone working directly in a cgo file is not going to
+// mind a few extra tenths of a second per guru command if it means their
+// results are more accurate.  See the cgoquery package for those tests.
 
 import (
 	"libc"
@@ -124,9 +126,10 @@ func referrs_tests() {
 	var _ W // @referrers cgo-ref-other-local-file "W"
 
 	cs := libc.Cfoo()
-	// Two referrers tests that seem too long to be worth it, each can be about .3s
-	ct := cs.Method() // toolong... cgo-ref-other-cgo-pkg-method-level1 "Method"
-	ct.Method()       // toolong... cgo-ref-other-cgo-pkg-method-level2 "Method"
+	// Two referrers tests that would each take about .3s alone, but take
+	// little in the batch of the queries of this file.
+	ct := cs.Method() // @referrers cgo-ref-other-cgo-pkg-method-level1 "Method"
+	ct.Method()       // @referrers cgo-ref-other-cgo-pkg-method-level2 "Method"
 
 	var v libc.Type = libc.Const // @referrers cgo-ref-package "libc"
 	_ = v.Method                 // @referrers cgo-ref-method "Method"
@@ -140,6 +143,18 @@ func referrs_tests() {
 	s2.f = 1
 }
 
+// Test the members of C, with the cgo files only parsed: their definition
+// is the preamble of import "C", or the import if it has none, describe
+// finds no type for them, and what leaves out the modes needing one.
+// (TestCgoPreamble queries the preamble of type.go.)
+
+func cselector_tests() {
+	C.puts(nil) // @definition cgo-definition-c-selector "puts"
+	C.puts(nil) // @describe cgo-describe-c-selector "puts"
+	C.puts(nil) // @describe cgo-describe-c-selector-expr "C.puts"
+	C.puts(nil) // @what cgo-what-c-selector "puts"
+}
+
 // Test //line directives:
 
 type V int // @referrers cgo-ref-type-V "V"
@@ -1,5 +1,6 @@
 package cgo
 
+// #define W_SIZE sizeof(int)
 import "C"
 
 type W int
@@ -0,0 +1,30 @@
+package cgoquery
+
+// Tests of queries with -cgo=query, which processes with cgo the package
+// of the query file, as it imports "C".
+// See golang.org/x/tools/cmd/guru/guru_test.go for explanation.
+// See cgoquery.golden for expected query results.
+//
+// Unlike those of the cgo package, these tests require the C compiler:
+// the references to C resolve only in the output of cgo.
+
+/*
+struct point { int x, y; };
+
+static int twice(int v) { return 2*v; }
+*/
+import "C"
+
+func f() int {
+	var p C.struct_point // @describe cgoquery-describe-struct "struct_point"
+	p.x = 1              // @describe cgoquery-describe-field "x"
+	p.y = p.x            // @referrers cgoquery-ref-field "x"
+
+	return int(C.twice(p.y)) // @definition cgoquery-definition-func "twice"
+}
+
+func g() int {
+	var q C.struct_point // @definition cgoquery-definition-type "struct_point"
+	q.x = 2
+	return int(C.twice(q.x)) // @describe cgoquery-describe-func "twice"
+}
@@ -0,0 +1,37 @@
+package definition
+
+// Tests of 'definition' query, -json output, in a package with type
+// errors, which leave some identifiers unresolved.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+import "lib"
+
+type T struct{ field int }
+
+func (T) method() {}
+
+func (T) String() string { return "T" }
+
+type S int
+
+func (S) String() string { return "S" }
+
+var _ int = "not an int" // an unrelated type error
+
+func f() {
+	var t T
+	t.method() // @definition definition-errors-method "method"
+
+	u := undefined()
+	print(u.field) // @definition definition-errors-unknown-field "field"
+
+	for _, v := range missing {
+		v.method() // @definition definition-errors-unknown-method "method"
+	}
+
+	undefined().Method(nil) // @definition definition-errors-unknown-lib-method "Method"
+
+	var w = undefined
+	print(w.String()) // @definition definition-errors-ambiguous "String"
+}
@@ -0,0 +1,20 @@
+package main
+
+// Tests of 'freevars' query, -json output.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+type C int
+
+func main() {
+	type C float64
+	const k = 2
+	var c C = k
+	var cs []C
+	x := 1
+	{
+		x := "shadow"
+		println(x, c*k, int(k), cs) // @freevars fvj "println.*cs."
+	}
+	println(x)
+}
@@ -0,0 +1,40 @@
+package main
+
+// Tests of 'implements' queries with -verbose,
+// which reports the near misses too: the types that would implement an
+// interface, or be implemented by a type, but for one method.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+func main() {
+}
+
+type F interface { // @implements F "F"
+	f()
+}
+
+type FG interface { // @implements FG "FG"
+	f()
+	g() []int
+}
+
+type FGH interface { // @implements FGH "FGH"
+	f()
+	g() []int
+	h()
+}
+
+type A int // @implements A "A"
+type B int // @implements B "B"
+type C int // @implements C "C"
+
+func (A) f()       {}
+func (A) g() []int { return nil }
+func (A) h()       {}
+
+func (*B) f()      {}
+func (B) g() []int { return nil }
+
+func (C) f()     {}
+func (C) g() int { return 0 }
+func (C) h()     {}
@@ -0,0 +1,16 @@
+package main
+
+// Tests of 'definition' and 'referrers' queries, in JSON format,
+// through //line directives.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+type T int // @referrers ref-T "T"
+
+func main() {
+	var x T = y // @definition def-y "y"
+	_ = x
+}
+
+//line nosuchfile.y:10
+var y T // @definition def-T "T"
@@ -0,0 +1,12 @@
+package main
+
+// Tests of the pointer analysis modes with -nocgo, with -json.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+import "libc"
+
+func main() {
+	s := libc.Cfoo()
+	print(s) // @pointsto nocgo-json-pointsto "s"
+}
@@ -0,0 +1,15 @@
+package main
+
+// #include <unistd.h>
+import "C"
+
+// cgoErr refers to C, so -nocgo leaves its code out.
+func cgoErr() error {
+	if C.getpid() < 0 {
+		return errType("c")
+	}
+	return nil
+}
+
+// sizes refers to C, so -nocgo leaves its initialization out.
+var sizes = []int{int(C.sizeof_int)}
@@ -0,0 +1,34 @@
+package main
+
+// Tests of the pointer analysis modes with -nocgo, which only parse cgo
+// files, as when no C compiler is at hand.  This package and libc both
+// have cgo files; only the code of this one refers to C.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+import "libc"
+
+type errType string
+
+func (e errType) Error() string { return string(e) }
+
+var errGo error = errType("go")
+
+func main() {
+	s := libc.Cfoo()
+	print(s) // @pointsto nocgo-pointsto-libc "s"
+
+	f := cgoErr
+	err := f() // @callees nocgo-callees-cgo "f"
+	print(err) // @whicherrs nocgo-whicherrs-cgo "err"
+
+	g := goErr
+	err = g()  // @callees nocgo-callees-go "g"
+	print(err) // @whicherrs nocgo-whicherrs-go "err"
+
+	print(sizes) // @pointsto nocgo-pointsto-cgo-var "sizes"
+}
+
+func goErr() error {
+	return errGo
+}
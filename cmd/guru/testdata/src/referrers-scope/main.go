@@ -0,0 +1,16 @@
+package main
+
+// Tests of 'referrers' queries with -scope, limiting the search to the
+// packages of the scope, this one, and the one declaring the object:
+// the references in the referrers package, among others, are left out.
+// See go.tools/guru/guru_test.go for explanation.
+// See main.golden for expected query results.
+
+import "lib"
+
+func main() {
+	var v lib.Type = lib.Const // @referrers ref-scope-package "lib"
+	_ = v.Method               // @referrers ref-scope-method "Method"
+	_ = v.Method
+	var _ lib.Type // @referrers ref-scope-type "Type"
+}
@@ -25,7 +25,7 @@ import (
 // the selected location.
 //
 func what(q *Query) error {
-	qpos, err := fastQueryPos(q.Build, q.Pos)
+	qpos, err := fastQueryPos(q)
 	if err != nil {
 		return err
 	}
@@ -33,6 +33,17 @@ func what(q *Query) error {
 	// (ignore errors)
 	srcdir, importPath, _ := guessImportPath(qpos.fset.File(qpos.start).Name(), q.Build)
 
+	// Within the preamble of import "C", only describe applies.
+	if preamble := queryCgoPreamble(q.Build, qpos); preamble != nil {
+		q.Output(qpos.fset, &whatResult{
+			path:       append([]ast.Node{preamble}, qpos.path...),
+			srcdir:     srcdir,
+			importPath: importPath,
+			modes:      []string{"describe"},
+		})
+		return nil
+	}
+
 	// Determine which query modes are applicable to the selection.
 	enable := map[string]bool{
 		"describe": true, // any syntax; always enabled
@@ -99,6 +110,13 @@ func what(q *Query) error {
 		}
 	}
 
+	// Without -cgo=query, the type checker knows nothing of the
+	// members of C, which only definition and describe find.
+	if !q.CgoQuery && cgoMember(qpos.path) != nil {
+		delete(enable, "implements")
+		delete(enable, "referrers")
+	}
+
 	// If we don't have an exact selection, disable modes that need one.
 	if !qpos.exact {
 		enable["callees"] = false
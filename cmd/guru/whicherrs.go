@@ -35,9 +35,10 @@ func whicherrs(q *Query) error {
 	if err := setPTAScope(&lconf, q.Scope); err != nil {
 		return err
 	}
+	noCgo(q, &lconf)
 
 	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	lprog, err := loadWithSoftErrors(q, scopeKey(q), &lconf)
 	if err != nil {
 		return err
 	}
@@ -47,9 +48,9 @@ func whicherrs(q *Query) error {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := createProgram(q, lprog, ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := setupPTA(q, prog, lprog)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,90 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle constants whose values come from the C pseudo-package.
+
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// The values accepted by the -cgo flag.
+const (
+	cgoProcess = "process" // Run cgo to obtain the values of C constants.
+	cgoSkip    = "skip"    // Omit constants whose values come from C.
+)
+
+// cgoConfig returns a configuration that loads the ad hoc package made of
+// the named files as an import, so that the loader runs cgo on the files
+// that import "C". The loader does not cgo-process ad hoc packages.
+func cgoConfig(filenames []string) (*loader.Config, error) {
+	dir, err := filepath.Abs(filepath.Dir(filenames[0]))
+	if err != nil {
+		return nil, err
+	}
+	bp, err := buildContext().ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	named := make(map[string]bool)
+	for _, filename := range filenames {
+		if d, _ := filepath.Abs(filepath.Dir(filename)); d != dir {
+			return nil, fmt.Errorf("cgo processing needs all files in one directory: %s", filename)
+		}
+		named[filepath.Base(filename)] = true
+	}
+	bp.GoFiles = onlyNamed(bp.GoFiles, named)
+	bp.CgoFiles = onlyNamed(bp.CgoFiles, named)
+
+	// The loader only accepts relative paths for packages outside GOPATH.
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(cwd, dir)
+	if err != nil {
+		return nil, err
+	}
+	path := "./" + filepath.ToSlash(rel)
+
+	conf := stringerConfig()
+	conf.Cwd = cwd
+	conf.FindPackage = func(ctxt *build.Context, importPath, fromDir string, mode build.ImportMode) (*build.Package, error) {
+		if importPath == path {
+			return bp, nil
+		}
+		return ctxt.Import(importPath, fromDir, mode)
+	}
+	conf.TypeCheckFuncBodies = func(p string) bool { return p == bp.ImportPath }
+	conf.Import(path)
+	return conf, nil
+}
+
+// onlyNamed returns the files of the list that are in the named set.
+func onlyNamed(files []string, named map[string]bool) []string {
+	var r []string
+	for _, file := range files {
+		if named[file] {
+			r = append(r, file)
+		}
+	}
+	return r
+}
+
+// importCgoAsGo wraps (*build.Context).Import so cgo files are parsed as
+// ordinary Go files and never processed by cgo. Used with -cgo=skip.
+func importCgoAsGo(ctxt *build.Context, path, srcDir string, mode build.ImportMode) (*build.Package, error) {
+	bp, err := ctxt.Import(path, srcDir, mode)
+	if bp != nil {
+		bp.GoFiles = append(bp.GoFiles, bp.CgoFiles...)
+		bp.CgoFiles = nil
+	}
+	return bp, err
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines implement the -diff flag, which prints the changes the
+// generated files would make instead of writing them.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Whether, with -diff, a generated file differs from the one it replaces.
+var changed = false
+
+// The line of the files stringer generates recording its command.
+var generatedLine = regexp.MustCompile(`(?m)^// Code generated by "stringer .*"; DO NOT EDIT\.$`)
+
+// diffSource prints to stdout the unified diff from the contents of filename,
+// empty if there is no such file, to src. The lines recording the command of
+// stringer are not compared, as its arguments may differ.
+func diffSource(filename string, src []byte) error {
+	old, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if i, j := generatedLine.FindIndex(old), generatedLine.FindIndex(src); i != nil && j != nil {
+		src = append(append(append([]byte(nil), src[:j[0]]...), old[i[0]:i[1]]...), src[j[1]:]...)
+	}
+	if bytes.Equal(old, src) {
+		return nil
+	}
+	changed = true
+	d, err := diff(old, src, displayName(filename))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(d)
+	return err
+}
+
+// diff returns the output of diff -u from b1 to b2, the contents of the file
+// named filename before and after.
+func diff(b1, b2 []byte, filename string) ([]byte, error) {
+	f1, err := writeTempFile(b1)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f1)
+	f2, err := writeTempFile(b2)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f2)
+
+	data, err := exec.Command("diff", "-u", f1, f2).CombinedOutput()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("computing diff for %s: %v", filename, err)
+	}
+	// diff exits with a non-zero status when the files don't match.
+	// Name the file in place of the temporary files, without times.
+	lines := bytes.SplitN(data, []byte("\n"), 3)
+	if len(lines) < 3 {
+		return nil, fmt.Errorf("unexpected diff for %s: %s", filename, data)
+	}
+	f := filepath.ToSlash(filename)
+	return append([]byte(fmt.Sprintf("--- %s.orig\n+++ %s\n", f, f)), lines[2]...), nil
+}
+
+// writeTempFile writes data to a temporary file and returns its name.
+func writeTempFile(data []byte) (string, error) {
+	f, err := ioutil.TempFile("", "stringer")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// displayName returns filename relative to the current directory if it is
+// beneath it.
+func displayName(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return filename
+}
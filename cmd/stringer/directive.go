@@ -0,0 +1,293 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle -fromdirective, which runs stringer as each
+// //go:generate directive of the packages running it asks, as go generate
+// would, without go generate building it again for each directive.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/build"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A directive is a //go:generate line running stringer.
+type directive struct {
+	file string   // The path of the file holding it.
+	line int      // Its line number.
+	pkg  string   // The name of the package of the file.
+	args []string // The arguments of stringer.
+}
+
+// splitDirective returns the words of the command of a //go:generate line,
+// without the prefix, as go generate splits them: at spaces and tabs, but
+// for double-quoted strings, which are unquoted.
+func splitDirective(line string) ([]string, error) {
+	var words []string
+Words:
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return words, nil
+		}
+		if line[0] == '"' {
+			for i := 1; i < len(line); i++ {
+				switch line[i] {
+				case '\\':
+					i++
+				case '"':
+					word, err := strconv.Unquote(line[:i+1])
+					if err != nil {
+						return nil, fmt.Errorf("bad quoted string %s", line[:i+1])
+					}
+					words = append(words, word)
+					line = line[i+1:]
+					if line != "" && line[0] != ' ' && line[0] != '\t' {
+						return nil, fmt.Errorf("expect space after quoted argument")
+					}
+					continue Words
+				}
+			}
+			return nil, fmt.Errorf("mismatched quoted string")
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			i = len(line)
+		}
+		words = append(words, line[:i])
+		line = line[i:]
+	}
+}
+
+// stringerArgs returns the arguments of stringer in the words of a command,
+// if it runs stringer: as stringer, by its path, or with go run.
+func stringerArgs(words []string) ([]string, bool) {
+	isStringer := func(word string) bool {
+		if i := strings.LastIndex(word, "@"); i >= 0 {
+			word = word[:i] // The version of go run.
+		}
+		return filepath.Base(filepath.FromSlash(word)) == "stringer"
+	}
+	switch {
+	case len(words) > 0 && isStringer(words[0]):
+		return words[1:], true
+	case len(words) > 2 && words[0] == "go" && words[1] == "run" && isStringer(words[2]):
+		return words[3:], true
+	}
+	return nil, false
+}
+
+// fileDirectives returns the directives running stringer in the file, with
+// the variables of go generate expanded in their arguments.
+func fileDirectives(path, pkg string) ([]directive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var directives []directive
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if !strings.HasPrefix(line, "//go:generate ") && !strings.HasPrefix(line, "//go:generate\t") {
+			continue
+		}
+		words, err := splitDirective(line[len("//go:generate "):])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, n, err)
+		}
+		args, ok := stringerArgs(words)
+		if !ok {
+			continue
+		}
+		d := directive{file: path, line: n, pkg: pkg}
+		for _, arg := range args {
+			d.args = append(d.args, os.Expand(arg, d.expandVar))
+		}
+		directives = append(directives, d)
+	}
+	return directives, scanner.Err()
+}
+
+// expandVar returns the value of a variable in the directive, as go generate
+// sets it, or else that of the environment.
+func (d directive) expandVar(name string) string {
+	switch name {
+	case "GOFILE":
+		return filepath.Base(d.file)
+	case "GOLINE":
+		return strconv.Itoa(d.line)
+	case "GOPACKAGE":
+		return d.pkg
+	case "DOLLAR":
+		return "$"
+	case "GOARCH":
+		return build.Default.GOARCH
+	case "GOOS":
+		return build.Default.GOOS
+	}
+	return os.Getenv(name)
+}
+
+// env returns the environment the directive runs in, as with go generate.
+func (d directive) env() []string {
+	env := os.Environ()
+	for _, name := range []string{"GOFILE", "GOLINE", "GOPACKAGE", "GOARCH", "GOOS", "DOLLAR"} {
+		env = append(env, name+"="+d.expandVar(name))
+	}
+	return env
+}
+
+// packageDirectives returns the directives running stringer in the files of
+// the packages, or in the files, of args, in the order of the packages, then
+// of their files, by name, and of their lines.
+func packageDirectives(ctxt *build.Context, args []string) ([]directive, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var directives []directive
+	var files []string
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") {
+			files = append(files, arg)
+			continue
+		}
+		bp, err := ctxt.Import(arg, cwd, 0)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, list := range [][]string{bp.GoFiles, bp.CgoFiles, bp.TestGoFiles, bp.XTestGoFiles} {
+			names = append(names, list...)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			pkg := bp.Name
+			if contains(bp.XTestGoFiles, name) {
+				pkg += "_test"
+			}
+			ds, err := fileDirectives(filepath.Join(bp.Dir, name), pkg)
+			if err != nil {
+				return nil, err
+			}
+			directives = append(directives, ds...)
+		}
+	}
+	if len(files) > 0 {
+		bp, err := ctxt.ImportDir(filepath.Dir(files[0]), 0)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			ds, err := fileDirectives(file, bp.Name)
+			if err != nil {
+				return nil, err
+			}
+			directives = append(directives, ds...)
+		}
+	}
+	return directives, nil
+}
+
+// contains reports whether the list holds s.
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// setsFromDirective reports whether the arguments set -fromdirective, which
+// would run the directives again.
+func setsFromDirective(args []string) bool {
+	for _, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if arg != name && (name == "fromdirective" || strings.HasPrefix(name, "fromdirective=")) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromDirectives runs stringer for the directives of the packages of the
+// arguments, with -fromdirective, and exits with status 1 if one of them
+// failed, or with -diff would change a file.
+func fromDirectives() {
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "fromdirective" && !unrecordedFlags[f.Name] {
+			log.Fatalf("-%s cannot be set with -fromdirective, which runs stringer with the flags of each directive", f.Name)
+		}
+	})
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	args, err := expandPatterns(buildContext(), args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
+		os.Exit(1)
+	}
+	if !runDirectives(args) {
+		os.Exit(1)
+	}
+}
+
+// runDirectives runs stringer for each directive of the packages of args, in
+// the directory of its file, adding the flags set of those that do not change
+// the output, as -diff. It reports whether all of them succeeded.
+func runDirectives(args []string) bool {
+	directives, err := packageDirectives(buildContext(), args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
+		return false
+	}
+	var extra []string
+	flag.Visit(func(f *flag.Flag) {
+		if unrecordedFlags[f.Name] {
+			extra = append(extra, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
+		return false
+	}
+	ok := true
+	for _, d := range directives {
+		if setsFromDirective(d.args) {
+			log.Printf("warning: %s:%d: skipping a directive with -fromdirective", d.file, d.line)
+			continue
+		}
+		verbosef("%s:%d: stringer %s", d.file, d.line, strings.Join(d.args, " "))
+		cmd := exec.Command(self, append(extra, d.args...)...)
+		cmd.Dir = filepath.Dir(d.file)
+		cmd.Env = d.env()
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if _, exited := err.(*exec.ExitError); !exited {
+				fmt.Fprintf(os.Stderr, "stringer: %s:%d: %v\n", d.file, d.line, err)
+			}
+			ok = false
+		}
+	}
+	if len(directives) == 0 {
+		log.Printf("no //go:generate directive runs stringer in %s", strings.Join(args, " "))
+	}
+	return ok
+}
@@ -9,6 +9,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"go/build"
 	"io"
@@ -17,6 +18,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -25,18 +27,20 @@ import (
 // we run stringer -type X and then compile and run the program. The resulting
 // binary panics if the String method for X is not correct, including for error cases.
 
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if stringerDir != "" {
+		os.RemoveAll(stringerDir)
+	}
+	os.Exit(code)
+}
+
 func TestEndToEnd(t *testing.T) {
-	dir, err := ioutil.TempDir("", "stringer")
-	if err != nil {
-		t.Fatal(err)
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
 	}
+	dir := tempDir(t)
 	defer os.RemoveAll(dir)
-	// Create stringer in temporary directory.
-	stringer := filepath.Join(dir, "stringer.exe")
-	err = run("go", "build", "-o", stringer)
-	if err != nil {
-		t.Fatalf("building stringer: %s", err)
-	}
 	// Read the testdata directory.
 	fd, err := os.Open("testdata")
 	if err != nil {
@@ -49,6 +53,10 @@ func TestEndToEnd(t *testing.T) {
 	}
 	// Generate, compile, and run the test programs.
 	for _, name := range names {
+		if fi, err := os.Stat(filepath.Join("testdata", name)); err == nil && fi.IsDir() {
+			// A directory has a test of its own, such as TestEndToEndCgo.
+			continue
+		}
 		if !strings.HasSuffix(name, ".go") {
 			t.Errorf("%s is not a Go file", name)
 			continue
@@ -57,34 +65,912 @@ func TestEndToEnd(t *testing.T) {
 			t.Logf("cgo is not enabled for %s", name)
 			continue
 		}
-		// Names are known to be ASCII and long enough.
-		typeName := fmt.Sprintf("%c%s", name[0]+'A'-'a', name[1:len(name)-len(".go")])
-		stringerCompileAndRun(t, dir, stringer, typeName, name)
+		stringerCompileAndRun(t, dir, typeNameOf(name), name)
 	}
 }
 
-// stringerCompileAndRun runs stringer for the named file and compiles and
-// runs the target binary in directory dir. That binary will panic if the String method is incorrect.
-func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string) {
-	t.Logf("run: %s %s\n", fileName, typeName)
-	source := filepath.Join(dir, fileName)
-	err := copy(source, filepath.Join("testdata", fileName))
+// TestEndToEndBitflag generates the bitflag String method for each program
+// in testdata/bitflag, as for TestEndToEnd, with each combination of -nocache
+// and -notable, with an unbounded cache and with -precompute, and vets,
+// compiles and runs the program.
+func TestEndToEndBitflag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	names, err := filepath.Glob(filepath.Join("testdata", "bitflag", "*.go"))
 	if err != nil {
-		t.Fatalf("copying file to temporary directory: %s", err)
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		name, _ = filepath.Rel("testdata", name)
+		typeName := typeNameOf(filepath.Base(name))
+		for _, flags := range [][]string{nil, {"-nocache"}, {"-notable"}, {"-nocache", "-notable"}, {"-cachesize=0"}, {"-notable", "-cachesize=0"}, {"-precompute"}} {
+			generateAndRun(t, dir, name, append([]string{"-type", typeName, "-bitflag"}, flags...))
+		}
+	}
+}
+
+// TestEndToEndBitOrder generates, with -bitorder=msb, the bitflag String,
+// Flags and UnmarshalText methods for each program in testdata/bitorder, which
+// checks the names are printed from the highest bit down and read back, and
+// vets, compiles and runs it.
+func TestEndToEndBitOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	names, err := filepath.Glob(filepath.Join("testdata", "bitorder", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		name, _ = filepath.Rel("testdata", name)
+		typeName := typeNameOf(filepath.Base(name))
+		for _, flags := range [][]string{nil, {"-notable"}, {"-nocache", "-notable"}, {"-precompute"}} {
+			generateAndRun(t, dir, name, append([]string{"-type", typeName, "-bitflag", "-bitorder=msb", "-text"}, flags...))
+		}
+	}
+}
+
+// TestEndToEndBitflagFormat generates, with -bitflagformat, the bitflag
+// String, UnmarshalText and GoString methods for each program in
+// testdata/bitflagformat, which checks the names are printed and read back
+// in that format, and vets, compiles and runs it.
+func TestEndToEndBitflagFormat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		typeName, fileName, format string
+	}{
+		{"Comma", "comma.go", "sep=,,brackets=none"},
+		{"Square", "square.go", "sep= | ,brackets=square"},
+	} {
+		for _, flags := range [][]string{nil, {"-notable"}, {"-nocache", "-notable"}, {"-precompute"}} {
+			args := []string{"-type", test.typeName, "-bitflag", "-text", "-gostring", "-bitflagformat", test.format}
+			generateAndRun(t, dir, filepath.Join("bitflagformat", test.fileName), append(args, flags...))
+		}
+	}
+}
+
+// TestEndToEndUnknownBase generates the bitflag String and Flags methods
+// of testdata/unknownbase/days.go in each base of -unknownbase, which the
+// program, given the base, checks the bits with no name are printed in, and
+// vets, compiles and runs it.
+func TestEndToEndUnknownBase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, base := range []string{"hex", "dec", "bin"} {
+		for _, flags := range [][]string{nil, {"-notable"}, {"-nocache", "-notable"}, {"-precompute"}} {
+			args := append([]string{"-type", "Days", "-bitflag", "-unknownbase", base}, flags...)
+			generateAndRun(t, dir, filepath.Join("unknownbase", "days.go"), args, base)
+		}
+	}
+}
+
+// TestEndToEndExportTables generates the arrays of -exporttables for each
+// program in testdata/exporttables, which checks they list the constants and
+// their names as printed by String, with the flags changing the layout of the
+// names, and vets, compiles and runs it.
+func TestEndToEndExportTables(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		typeName, fileName string
+		flags              []string
+	}{
+		{"Pill", "pill.go", nil},
+		{"Pill", "pill.go", []string{"-sort=name", "-transform=snake"}},
+		{"Days", "days.go", []string{"-bitflag"}},
+		{"Days", "days.go", []string{"-bitflag", "-nocache"}},
+		{"Days", "days.go", []string{"-bitflag", "-notable", "-bitorder=msb"}},
+		{"Days", "days.go", []string{"-bitflag", "-precompute"}},
+	} {
+		args := append([]string{"-type", test.typeName, "-exporttables"}, test.flags...)
+		generateAndRun(t, dir, filepath.Join("exporttables", test.fileName), args)
 	}
+}
+
+func TestEndToEndFuncScope(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	generateAndRun(t, dir, filepath.Join("funcscope", "kind.go"), []string{"-type", "Kind", "-funcscope"})
+}
+
+// TestEndToEndIndexEncoding generates the String method of programs of one
+// run and of several with -indexencoding=string, and vets, compiles and runs
+// them. Those of testdata/indexencoding have offsets of more than one byte.
+func TestEndToEndIndexEncoding(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		typeName, fileName string
+	}{
+		{"Day", "day.go"},
+		{"Gap", "gap.go"},
+		{"Num", "num.go"},
+		{"Unum", "unum.go"},
+		{"Prime", "prime.go"},
+		{"Long", filepath.Join("indexencoding", "long.go")},
+	} {
+		generateAndRun(t, dir, test.fileName, []string{"-type", test.typeName, "-indexencoding=string"})
+	}
+}
+
+// TestEndToEndGenBench generates the String method and its benchmarks with
+// -genbench for programs of testdata and testdata/bitflag, and runs each
+// benchmark once.
+func TestEndToEndGenBench(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		typeName, fileName string
+		flags              []string
+	}{
+		{"Day", "day.go", nil},
+		{"Prime", "prime.go", nil},
+		{"Days", filepath.Join("bitflag", "days.go"), []string{"-bitflag"}},
+	} {
+		runDir := generate(t, dir, test.fileName, append([]string{"-type", test.typeName, "-genbench"}, test.flags...))
+		err := run("go", append([]string{"test", "-run=NONE", "-bench=.", "-benchtime=1x"}, goFiles(t, runDir)...)...)
+		if err != nil {
+			t.Fatalf("go test %s: %s", test.fileName, err)
+		}
+	}
+}
+
+// stringerCompileAndRun runs stringer for the named file and vets, compiles
+// and runs the target binary in directory dir. That binary will panic if the String method is incorrect.
+func stringerCompileAndRun(t *testing.T, dir, typeName, fileName string) {
+	t.Logf("run: %s %s\n", fileName, typeName)
+	source := filepath.Join(dir, fileName)
+	copyTestdata(t, dir, "", fileName)
 	stringSource := filepath.Join(dir, typeName+"_string.go")
 	// Run stringer in temporary directory.
-	err = run(stringer, "-type", typeName, "-output", stringSource, source)
+	runStringer(t, "", "-type", typeName, "-output", stringSource, source)
+	// Vet the generated code, then run the binary in the temporary directory.
+	err := run("go", "vet", stringSource, source)
 	if err != nil {
 		t.Fatal(err)
 	}
-	// Run the binary in the temporary directory.
 	err = run("go", "run", stringSource, source)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
+// TestEndToEndCgo generates the String method for testdata/libc, a package
+// whose cgo file declares constants valued from C, once with each -cgo mode,
+// and compiles and runs the program to check the result.
+func TestEndToEndCgo(t *testing.T) {
+	if !build.Default.CgoEnabled {
+		t.Skip("cgo is not enabled")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "libc", "lib.go", "lib_c.go")
+	for _, mode := range []string{"process", "skip"} {
+		t.Logf("run: libc -cgo=%s\n", mode)
+		runStringer(t, dir, "-type", "Libc", "-cgo", mode, "-output", "libc_string.go", "lib.go", "lib_c.go")
+		err := run("go", append(append([]string{"run"}, goFiles(t, dir)...), mode)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEndToEndJSON generates the String and JSON methods for the programs in
+// testdata/json, which marshal every constant and check that it round-trips.
+// Bitflags are generated both with and without the table-driven common code.
+func TestEndToEndJSON(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		typeName, fileName string
+		flags              []string
+	}{
+		{"Gap", "gap.go", nil},
+		{"Perm", "perm.go", []string{"-bitflag"}},
+		{"Perm", "perm.go", []string{"-bitflag", "-notable"}},
+	} {
+		runDir := generate(t, dir, filepath.Join("json", test.fileName), append([]string{"-json", "-type", test.typeName}, test.flags...))
+		err := run("go", append([]string{"run"}, goFiles(t, runDir)...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEndToEndHelpers generates the String and helper methods for
+// testdata/helpers/perm.go, whose Toggle method is its own, and compiles and
+// runs the program. Stringer is run twice on the directory, as the second run
+// reads the methods generated by the first, which must not count as declared.
+func TestEndToEndHelpers(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "helpers", "perm.go")
+	for i := 0; i < 2; i++ {
+		runStringer(t, dir, "-type", "Perm", "-bitflag", "-notable", "-bitflaghelpers", "-output", "perm_string.go")
+		err := run("go", append([]string{"run"}, goFiles(t, dir)...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEndToEndSplitFiles generates the String methods of the two types of
+// testdata/split into a file for each with -splitfiles, and compiles each of
+// those with the declaration of its type.
+func TestEndToEndSplitFiles(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "split", "pill.go", "dose.go")
+	runStringer(t, dir, "-type", "Pill,Dose", "-splitfiles")
+	for _, name := range []string{"pill", "dose"} {
+		err := run("go", "build", filepath.Join(dir, name+".go"), filepath.Join(dir, name+"_string.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEndToEndMulti generates the String methods of two types, each defined
+// in one of the packages of testdata/multi, naming the packages and then with
+// ./..., and compiles each package with its output file.
+func TestEndToEndMulti(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "multi", filepath.Join("a", "a.go"), filepath.Join("b", "b.go"))
+	for _, args := range [][]string{{"./a", "./b"}, {"./..."}} {
+		t.Logf("run: stringer %s\n", strings.Join(args, " "))
+		runStringer(t, dir, append([]string{"-type", "Alpha,Beta"}, args...)...)
+		for _, pkg := range []struct{ name, output string }{
+			{"a", "alpha_string.go"},
+			{"b", "beta_string.go"},
+		} {
+			stringSource := filepath.Join(dir, pkg.name, pkg.output)
+			err := run("go", "build", filepath.Join(dir, pkg.name, pkg.name+".go"), stringSource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(stringSource); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A type defined in no package is an error.
+	if err := runIn(dir, buildStringer(t), "-type", "Alpha,Gamma", "./..."); err == nil {
+		t.Error("no error for type Gamma, defined in no package")
+	}
+}
+
+// TestEndToEndQualified generates, in a GOPATH holding the packages of
+// testdata/qualified, the function LevelString for the constants of log.Level
+// declared in package loglevels and the String method of log.Priority, given
+// qualified by its own package, then vets the packages and runs check.
+func TestEndToEndQualified(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src", "levels")
+	copyTestdata(t, src, "qualified", filepath.Join("log", "log.go"), filepath.Join("loglevels", "loglevels.go"), filepath.Join("check", "check.go"))
+	stringer := buildStringer(t)
+	runInGOPATH(t, dir, filepath.Join(src, "loglevels"), stringer, "-type=log.Level")
+	runInGOPATH(t, dir, filepath.Join(src, "log"), stringer, "-type=log.Priority")
+	out, err := ioutil.ReadFile(filepath.Join(src, "loglevels", "level_string.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "func LevelString(i log.Level) string {") {
+		t.Errorf("loglevels: no function LevelString:\n%s", out)
+	}
+	runInGOPATH(t, dir, src, "go", "vet", "levels/...")
+	runInGOPATH(t, dir, src, "go", "run", "levels/check")
+}
+
+// TestEndToEndImport generates the String method of the type of package
+// enums of testdata/import as a function of package enums/enumsstr, with -pkg
+// and -import, and runs the program checking it.
+func TestEndToEndImport(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	copyTestdata(t, src, "import", filepath.Join("enums", "enums.go"), filepath.Join("check", "check.go"))
+	if err := os.Mkdir(filepath.Join(src, "enums", "enumsstr"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runInGOPATH(t, dir, filepath.Join(src, "enums"), buildStringer(t), "-type=Pill", "-values", "-output=enumsstr", "-pkg=enumsstr", "-import=enums")
+	out, err := ioutil.ReadFile(filepath.Join(src, "enums", "enumsstr", "pill_string.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "func PillString(i enums.Pill) string {") {
+		t.Errorf("enumsstr: no function PillString:\n%s", out)
+	}
+	runInGOPATH(t, dir, src, "go", "vet", "enums/...", "check")
+	runInGOPATH(t, dir, src, "go", "run", "check")
+}
+
+// TestEndToEndShuffle generates the code of the package of testdata/shuffle,
+// whose files are in two directories, giving the files in each order, and
+// checks that the file is written to the same directory with the same bytes.
+func TestEndToEndShuffle(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	files := []string{filepath.Join("a", "colors.go"), filepath.Join("b", "more.go")}
+	copyTestdata(t, dir, "shuffle", files...)
+	output := filepath.Join(dir, "a", "color_string.go")
+	var first []byte
+	for _, args := range [][]string{files, {files[1], files[0]}} {
+		runStringer(t, dir, append([]string{"-type=Color", "-values", "-sort=decl", "-aliases"}, args...)...)
+		got, err := ioutil.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(output); err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = got
+			continue
+		}
+		if !bytes.Equal(got, first) {
+			t.Errorf("files %s: got\n%s\nwant\n%s", strings.Join(args, " "), got, first)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b", "color_string.go")); err == nil {
+		t.Errorf("color_string.go is written to b")
+	}
+	for _, want := range []string{`// Code generated by "stringer -aliases -sort=decl -type=Color -values ../b/more.go colors.go"`, `_Color_name = "RedGreenBlueCyan"`} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("no %s in\n%s", want, first)
+		}
+	}
+}
+
+// TestEndToEndXTest generates the String methods of the type Kind of
+// testdata/xtest, declared in both the package and its external test
+// package, with and without -output, and runs the tests of the package.
+func TestEndToEndXTest(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "xtest", "kind.go", "kind_internal_test.go", "kind_test.go")
+	for _, test := range []struct {
+		args  []string
+		files []string
+	}{
+		{nil, []string{"kind_string.go", "kind_string_test.go"}},
+		{[]string{"-output", "generated.go"}, []string{"generated.go", "generated_test.go"}},
+	} {
+		t.Logf("run: stringer %s\n", strings.Join(test.args, " "))
+		runStringer(t, dir, append([]string{"-type", "Kind"}, test.args...)...)
+		err := runIn(dir, "go", "test", ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range test.files {
+			if err := os.Remove(filepath.Join(dir, file)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// TestEndToEndTableCommon generates the table-driven String method for
+// testdata/table/days.go, with the common code written to the file named by
+// -tablecommon, and compiles and runs the program. With -tablecommon empty,
+// no common file is written.
+func TestEndToEndTableCommon(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "table", "days.go")
+	common := filepath.Join(dir, "common.go")
+	runStringer(t, dir, "-type", "Days", "-bitflag", "-tablecommon", "common.go", "days.go")
+	err := run("go", append([]string{"run"}, goFiles(t, dir)...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(common); err != nil {
+		t.Fatal(err)
+	}
+	runStringer(t, dir, "-type", "Days", "-bitflag", "-tablecommon=", "days.go")
+	for _, name := range []string{"common.go", defaultTableCommon} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s written with -tablecommon empty", name)
+		}
+	}
+}
+
+// TestEndToEndTablePrefix generates the table-driven String method for
+// testdata/table/days.go with -tableprefix, and compiles and runs the
+// program with the common code. The common code is then moved to another
+// file, which must keep it from being written again.
+func TestEndToEndTablePrefix(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "table", "days.go")
+	common := filepath.Join(dir, defaultTableCommon)
+	runStringer(t, dir, "-type", "Days", "-bitflag", "-tableprefix", "_acmeStringer")
+	for _, file := range []string{filepath.Join(dir, "days_string.go"), common} {
+		out, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(out), "_acmeStringerBitflag") {
+			t.Errorf("%s does not use the type _acmeStringerBitflag", file)
+		}
+	}
+	err := run("go", append([]string{"run"}, goFiles(t, dir)...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := filepath.Join(dir, "shared.go")
+	if err := os.Rename(common, shared); err != nil {
+		t.Fatal(err)
+	}
+	runStringer(t, dir, "-type", "Days", "-bitflag", "-tableprefix", "_acmeStringer")
+	if _, err := os.Stat(common); !os.IsNotExist(err) {
+		t.Errorf("%s written, with the common code declared in %s", common, shared)
+	}
+	err = run("go", append([]string{"run"}, goFiles(t, dir)...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEndToEndMethod generates both the String method and, with -method, the
+// Name method for the type of testdata/method, and compiles and runs the
+// program comparing them.
+func TestEndToEndMethod(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "method", "pill.go")
+	runStringer(t, dir, "-type", "Pill")
+	runStringer(t, dir, "-type", "Pill", "-method", "Name", "-output", "pill_name.go")
+	err := runIn(dir, "go", "run", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEndToEndForce generates the String method for the type of
+// testdata/force, which has one already, with and without -force. Only with
+// it is the file written, or the String method of -method generated.
+func TestEndToEndForce(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "force", "pill.go")
+	stringSource := filepath.Join(dir, "pill_string.go")
+	err := runIn(dir, buildStringer(t), "-type", "Pill")
+	if err == nil {
+		t.Fatal("stringer succeeded for a type with a String method")
+	}
+	if _, err := os.Stat(stringSource); !os.IsNotExist(err) {
+		t.Errorf("%s written for a type with a String method", stringSource)
+	}
+	runStringer(t, dir, "-type", "Pill", "-method", "Name")
+	runStringer(t, dir, "-type", "Pill", "-force")
+	if _, err := os.Stat(stringSource); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestEndToEndStrict generates the String method for testdata/strict, whose
+// package declares a constant that is not printed, with and without -strict.
+// Only without it is the file written.
+func TestEndToEndStrict(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "strict", "perm.go")
+	stringSource := filepath.Join(dir, "perm_string.go")
+	err := runIn(dir, buildStringer(t), "-type", "Perm", "-bitflag", "-strict")
+	if err == nil {
+		t.Fatal("-strict: stringer succeeded with a constant not printed")
+	}
+	if _, err := os.Stat(stringSource); !os.IsNotExist(err) {
+		t.Errorf("-strict: %s written", stringSource)
+	}
+	runStringer(t, dir, "-type", "Perm", "-bitflag")
+	if _, err := os.Stat(stringSource); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestEndToEndPartial runs stringer for the three types of testdata/partial,
+// one of which fails, checking that the code of the other two is written and
+// the failure reported with its position.
+func TestEndToEndPartial(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "partial", "kinds.go")
+	cmd := exec.Command(buildStringer(t), "-type", "Fruit,Weight,Color,Size", "-output", "kinds_string.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+		t.Errorf("got error %v, want exit status 1", err)
+	}
+	want := "stringer: the code of these types was not generated:\n" +
+		"\t" + filepath.Join(dir, "kinds.go") + ":19:7: can't handle non-integer, non-string constant type Weight\n" +
+		"\tcouldn't find type Size\n"
+	if string(out) != want {
+		t.Errorf("got output\n%s\nwant\n%s", out, want)
+	}
+	src, err := ioutil.ReadFile(filepath.Join(dir, "kinds_string.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"_Fruit_name", "_Color_name"} {
+		if !strings.Contains(string(src), name) {
+			t.Errorf("%s is not generated", name)
+		}
+	}
+	if strings.Contains(string(src), "func (i Weight)") {
+		t.Errorf("the code of Weight is generated:\n%s", src)
+	}
+}
+
+// TestEndToEndTypeErrors runs stringer on testdata/broken, whose package has
+// two type errors, checking they are printed prefixed by the package up to
+// -maxtypeerrors and that the code is generated, unless -stricttypecheck is set.
+func TestEndToEndTypeErrors(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "broken", "level.go")
+	file := filepath.Join(dir, "level.go")
+	output := filepath.Join(dir, "level_string.go")
+	first := "stringer: broken: " + file + ":19:10: undefined: unit\n"
+	second := "stringer: broken: " + file + ":20:17: undefined: unit\n"
+	for _, test := range []struct {
+		flags []string
+		want  string
+		fail  bool
+	}{
+		{nil, first + second, false},
+		{[]string{"-maxtypeerrors", "1"}, first + "stringer: (1 more errors not printed; see -maxtypeerrors)\n", false},
+		{[]string{"-maxtypeerrors", "0"}, first + second, false},
+		{[]string{"-stricttypecheck"}, first + second + "stringer: not generating: 2 errors loading the packages and -stricttypecheck is set\n", true},
+	} {
+		os.Remove(output)
+		cmd := exec.Command(buildStringer(t), append(test.flags, "-type", "Level", "level.go")...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if test.fail {
+			if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+				t.Errorf("%v: got error %v, want exit status 1", test.flags, err)
+			}
+		} else if err != nil {
+			t.Errorf("%v: %v", test.flags, err)
+		}
+		if string(out) != test.want {
+			t.Errorf("%v: got output\n%s\nwant\n%s", test.flags, out, test.want)
+		}
+		_, err = os.Stat(output)
+		if test.fail && err == nil {
+			t.Errorf("%v: %s is written", test.flags, output)
+		} else if !test.fail && err != nil {
+			t.Errorf("%v: %v", test.flags, err)
+		}
+	}
+}
+
+// TestEndToEndBrokenOutput runs stringer for testdata/day.go next to the
+// day_string.go of testdata/brokenoutput, an earlier output that is not valid
+// Go, for the package and for the files, checking that it is not loaded, with
+// a warning, and is written again.
+func TestEndToEndBrokenOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, args := range [][]string{nil, {"day.go", "day_string.go"}} {
+		runDir, err := ioutil.TempDir(dir, "day")
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := filepath.Join(runDir, "day_string.go")
+		copyTestdata(t, runDir, "", "day.go")
+		copyTestdata(t, runDir, "brokenoutput", "day_string.go")
+		cmd := exec.Command(buildStringer(t), append([]string{"-type", "Day", "-stricttypecheck"}, args...)...)
+		cmd.Dir = runDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+		path := output
+		if args != nil {
+			path = "day_string.go"
+		}
+		want := "stringer: warning: not loading " + path + ", written by an earlier run of stringer, as it is not valid Go\n"
+		if string(out) != want {
+			t.Errorf("%v: got output\n%s\nwant\n%s", args, out, want)
+		}
+		err = run("go", "run", filepath.Join(runDir, "day.go"), output)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+}
+
+// TestEndToEndFromDirective runs stringer with -fromdirective for
+// testdata/fromdirective, whose two directives run stringer with different
+// flags, and vets, compiles and runs the program with the files they write.
+func TestEndToEndFromDirective(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "fromdirective", "pill.go")
+	cmd := exec.Command(buildStringer(t), "-fromdirective", "-type", "Pill")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("-fromdirective with -type succeeded:\n%s", out)
+	}
+	runStringer(t, dir, "-fromdirective")
+	for _, name := range []string{"pill_string.go", "main_dose.go"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	vetAndRun(t, dir)
+}
+
+// TestEndToEndInline runs stringer with -inline for each type of
+// testdata/inline, then for the first again, and vets, compiles and runs the
+// program with the one file written.
+func TestEndToEndInline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "inline", "pill.go")
+	for _, args := range [][]string{
+		{"-type", "Pill", "-inline"},
+		{"-type", "Dose", "-trimprefix", "Dose", "-inline"},
+		{"-type", "Pill", "-inline", "-linecomment"},
+	} {
+		runStringer(t, dir, args...)
+	}
+	inline := filepath.Join(dir, "zz_generated_stringer.go")
+	src, err := ioutil.ReadFile(inline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(src), "// stringer:begin "); n != 2 {
+		t.Errorf("%s has %d regions, want 2:\n%s", inline, n, src)
+	}
+	vetAndRun(t, dir)
+}
+
+// TestEndToEndDiff runs stringer with -diff for testdata/diff, whose
+// day_string.go is stale, checking the diff printed and that no file is
+// written, then again once it is regenerated, when nothing would change.
+func TestEndToEndDiff(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "diff", "day.go", "day_string.go")
+	stringSource := filepath.Join(dir, "day_string.go")
+	stale, err := ioutil.ReadFile(stringSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `--- day_string.go.orig
++++ day_string.go
+@@ -4,9 +4,9 @@
+ 
+ import "strconv"
+ 
+-const _Day_name = "MondayTuesday"
++const _Day_name = "MondayTuesdayWednesday"
+ 
+-var _Day_index = [...]uint8{0, 6, 13}
++var _Day_index = [...]uint8{0, 6, 13, 22}
+ 
+ func (i Day) String() string {
+ 	if i < 0 || i >= Day(len(_Day_index)-1) {
+`
+	stringer := buildStringer(t)
+	cmd := exec.Command(stringer, "-type", "Day", "-diff")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+		t.Errorf("-diff of a stale file: got error %v, want exit status 1", err)
+	}
+	if string(out) != want {
+		t.Errorf("-diff of a stale file: got\n%s\nwant\n%s", out, want)
+	}
+	if got, err := ioutil.ReadFile(stringSource); err != nil || string(got) != string(stale) {
+		t.Errorf("-diff modified %s", stringSource)
+	}
+
+	// The header differs, but it is not compared.
+	runStringer(t, dir, "-type=Day")
+	cmd = exec.Command(stringer, "-type", "Day", "-diff")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err = cmd.Output()
+	if err != nil || len(out) != 0 {
+		t.Errorf("-diff of an up-to-date file: got error %v and output %q", err, out)
+	}
+}
+
+// TestEndToEndTags generates the String method for testdata/tags, a package
+// whose files declare different constants depending on the build tag foo,
+// with and without -tags=foo, and compiles and runs the program each time.
+func TestEndToEndTags(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	copyTestdata(t, dir, "tags", "tag.go", "tag_foo.go", "tag_nofoo.go")
+	// The output of the first run, constrained to the tag foo, must be
+	// left out by the second.
+	for _, test := range []struct {
+		tags, constraint string
+	}{
+		{"foo", "//go:build foo\n"},
+		{"", "//go:build !foo\n"},
+	} {
+		tags := test.tags
+		t.Logf("run: tags -tags=%s\n", tags)
+		runStringer(t, dir, "-type", "Tag", "-tags", tags)
+		out, err := ioutil.ReadFile(filepath.Join(dir, "tag_string.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(out), test.constraint) {
+			t.Errorf("-tags=%s: output does not contain %q", tags, test.constraint)
+		}
+		err = runIn(dir, "go", "run", "-tags", tags, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// The stringer built once for the tests, in stringerDir, which TestMain
+// removes.
+var (
+	stringerOnce sync.Once
+	stringerDir  string
+	stringerPath string
+	stringerErr  error
+)
+
+// buildStringer returns the path of stringer, built by the first test that
+// needs it.
+func buildStringer(t *testing.T) string {
+	t.Helper()
+	stringerOnce.Do(func() {
+		stringerDir, stringerErr = ioutil.TempDir("", "stringer")
+		if stringerErr != nil {
+			return
+		}
+		stringerPath = filepath.Join(stringerDir, "stringer.exe")
+		stringerErr = run("go", "build", "-o", stringerPath)
+	})
+	if stringerErr != nil {
+		t.Fatalf("building stringer: %s", stringerErr)
+	}
+	return stringerPath
+}
+
+// runStringer runs stringer with args in directory dir, the current one if
+// empty, and fails the test if it does not succeed.
+func runStringer(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if err := runIn(dir, buildStringer(t), args...); err != nil {
+		t.Fatalf("stringer %s: %s", strings.Join(args, " "), err)
+	}
+}
+
+// generate copies the file name of testdata to a directory of its own in
+// dir, runs stringer there with args, and returns the directory.
+func generate(t *testing.T, dir, name string, args []string) string {
+	t.Helper()
+	t.Logf("run: %s %s\n", name, strings.Join(args, " "))
+	base := filepath.Base(name)
+	runDir, err := ioutil.TempDir(dir, strings.TrimSuffix(base, ".go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyTestdata(t, runDir, filepath.Dir(name), base)
+	runStringer(t, runDir, args...)
+	return runDir
+}
+
+// generateAndRun is like generate, then vets, compiles and runs the program
+// of the files written with that of testdata, passing it runArgs.
+func generateAndRun(t *testing.T, dir, name string, args []string, runArgs ...string) {
+	t.Helper()
+	vetAndRun(t, generate(t, dir, name, args), runArgs...)
+}
+
+// vetAndRun vets, compiles and runs the program of the Go files of dir,
+// passing it args.
+func vetAndRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	files := goFiles(t, dir)
+	if err := run("go", append([]string{"vet"}, files...)...); err != nil {
+		t.Fatalf("go vet %s: %s", strings.Join(files, " "), err)
+	}
+	if err := run("go", append(append([]string{"run"}, files...), args...)...); err != nil {
+		t.Fatalf("go run %s %s: %s", strings.Join(files, " "), strings.Join(args, " "), err)
+	}
+}
+
+// goFiles returns the Go files of dir.
+func goFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// typeNameOf returns the name of the type of the program of testdata named
+// name: X for x.go. Names are known to be ASCII and long enough.
+func typeNameOf(name string) string {
+	return fmt.Sprintf("%c%s", name[0]+'A'-'a', name[1:len(name)-len(".go")])
+}
+
+// tempDir returns a new temporary directory, for the caller to remove.
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// copyTestdata copies the files of the directory from of testdata, named by
+// their paths in it, to the same paths in directory to.
+func copyTestdata(t *testing.T, to, from string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.MkdirAll(filepath.Join(to, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := copy(filepath.Join(to, name), filepath.Join("testdata", from, name)); err != nil {
+			t.Fatalf("copying file to temporary directory: %s", err)
+		}
+	}
+}
+
+// runInGOPATH runs the command name with args in directory dir, with GOPATH
+// set to gopath, and fails the test with its output if it does not succeed.
+func runInGOPATH(t *testing.T, gopath, dir, name string, arg ...string) {
+	t.Helper()
+	cmd := exec.Command(name, arg...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(arg, " "), err, out)
+	}
+}
+
 // copy copies the from file to the to file.
 func copy(to, from string) error {
 	toFd, err := os.Create(to)
@@ -104,7 +990,13 @@ func copy(to, from string) error {
 // run runs a single command and returns an error if it does not succeed.
 // os/exec should have this function, to be honest.
 func run(name string, arg ...string) error {
+	return runIn("", name, arg...)
+}
+
+// runIn is like run but runs the command in directory dir.
+func runIn(dir, name string, arg ...string) error {
 	cmd := exec.Command(name, arg...)
+	cmd.Dir = dir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
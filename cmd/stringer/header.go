@@ -0,0 +1,105 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines build the command recorded in the generated files, which
+// generates them again.
+
+package main
+
+import (
+	"flag"
+	"go/build"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The flags left out of the command recorded in the generated files, as
+// they do not change what is generated.
+var unrecordedFlags = map[string]bool{
+	"allowinvalid": true,
+	"diff":         true,
+	"v":            true,
+}
+
+// commandLine returns the stringer command recorded in the files generated
+// for the package in dir, with the flags set in fs, in the order of their
+// names, and the args, to be run in dir, the files in the order of their
+// names. The paths, given relative to the current directory, are made
+// relative to dir, so the command does not depend on where stringer was run
+// or where the package is.
+func commandLine(fs *flag.FlagSet, args []string, dir string) string {
+	words := []string{"stringer"}
+	fs.Visit(func(f *flag.Flag) {
+		if unrecordedFlags[f.Name] {
+			return
+		}
+		value := f.Value.String()
+		switch f.Name {
+		case "output":
+			value = relPath(value, dir)
+		case "tablecommon":
+			// Relative to the package directory unless absolute.
+			if filepath.IsAbs(value) {
+				value = relPath(value, dir)
+			}
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() && value == "true" {
+			words = append(words, "-"+f.Name)
+			return
+		}
+		words = append(words, quoteWord("-"+f.Name+"="+value))
+	})
+	var files []string
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") {
+			files = append(files, relPath(arg, dir))
+			continue
+		}
+		if build.IsLocalImport(arg) || filepath.IsAbs(arg) {
+			// A directory or pattern, which must stay local.
+			if arg = relPath(arg, dir); arg == "." {
+				continue
+			}
+			if !filepath.IsAbs(arg) && !build.IsLocalImport(arg) {
+				arg = "./" + arg
+			}
+		}
+		words = append(words, quoteWord(arg))
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		words = append(words, quoteWord(file))
+	}
+	return strings.Join(words, " ")
+}
+
+// relPath returns path, relative to the current directory unless absolute,
+// as a slash-separated path relative to dir, or as given if there is none,
+// as on another volume.
+func relPath(path, dir string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDir, abs)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// quoteWord quotes word as a Go string, as go generate accepts, if it would
+// not otherwise be read as one word.
+func quoteWord(word string) string {
+	if word == "" || strings.ContainsAny(word, " \t\n\"'\\") {
+		return strconv.Quote(word)
+	}
+	return word
+}
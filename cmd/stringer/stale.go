@@ -0,0 +1,106 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines leave out of the packages loaded the files an earlier run
+// of stringer wrote that are not valid Go, as with -allowinvalid, which this
+// run writes again. Loaded, they would only fail to type-check.
+
+package main
+
+import (
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The comment marking a file generated by stringer.
+var generatedByStringer = regexp.MustCompile(`(?m)^// Code generated by "stringer .*"; DO NOT EDIT\.$`)
+
+// outputNames are the names, without their directory, of the files this run
+// may write, set by main.
+var outputNames map[string]bool
+
+// brokenSeen records the files left out, each reported once.
+var brokenSeen = make(map[string]bool)
+
+// outputFileNames returns the names of the files written for the types: that
+// of -output, that of -inline, or those of each type, and the file of common bitflag code, in
+// a package or in its external test package. An -output naming a directory
+// is where the files named as by default are written.
+func outputFileNames(typeNames []string) map[string]bool {
+	names := make(map[string]bool)
+	add := func(name string) {
+		names[name] = true
+		names[strings.TrimSuffix(name, ".go")+"_test.go"] = true
+	}
+	if *output != "" && !isDir(*output) {
+		add(filepath.Base(*output))
+	} else if *inline {
+		add(defaultInlineFile)
+	} else {
+		for _, typeName := range typeNames {
+			typeName = typeName[strings.LastIndex(typeName, ".")+1:]
+			add(strings.ToLower(typeName + "_" + *method + ".go"))
+		}
+	}
+	if *bitflag && *tablecommon != "" {
+		add(filepath.Base(*tablecommon))
+	}
+	return names
+}
+
+// brokenOutput reports whether the file at path is one this run writes,
+// generated by stringer, that does not parse.
+func brokenOutput(path string) bool {
+	if !outputNames[filepath.Base(path)] {
+		return false
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil || !generatedByStringer.Match(src) {
+		return false
+	}
+	_, err = parser.ParseFile(token.NewFileSet(), path, src, 0)
+	return err != nil
+}
+
+// dropBrokenOutputs returns the files, in dir, that are not broken outputs,
+// with a warning about each of the others.
+func dropBrokenOutputs(dir string, files []string) []string {
+	var kept []string
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if !brokenOutput(path) {
+			kept = append(kept, file)
+			continue
+		}
+		if !brokenSeen[path] {
+			brokenSeen[path] = true
+			log.Printf("warning: not loading %s, written by an earlier run of stringer, as it is not valid Go", path)
+		}
+	}
+	return kept
+}
+
+// skipBrokenOutputs wraps find, a FindPackage function of the loader, or
+// (*build.Context).Import if nil, so that the packages found leave out the
+// broken outputs.
+func skipBrokenOutputs(find func(*build.Context, string, string, build.ImportMode) (*build.Package, error)) func(*build.Context, string, string, build.ImportMode) (*build.Package, error) {
+	if find == nil {
+		find = (*build.Context).Import
+	}
+	return func(ctxt *build.Context, path, srcDir string, mode build.ImportMode) (*build.Package, error) {
+		bp, err := find(ctxt, path, srcDir, mode)
+		if bp != nil {
+			bp.GoFiles = dropBrokenOutputs(bp.Dir, bp.GoFiles)
+			bp.TestGoFiles = dropBrokenOutputs(bp.Dir, bp.TestGoFiles)
+			bp.XTestGoFiles = dropBrokenOutputs(bp.Dir, bp.XTestGoFiles)
+		}
+		return bp, err
+	}
+}
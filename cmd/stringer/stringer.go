@@ -43,21 +43,131 @@
 //
 //	//go:generate stringer -type=Pill
 //
+// The flag -fromdirective, with no -type, runs stringer as each such
+// directive in the files of the packages asks instead, with its flags, in the
+// directory of its file, as go generate would, without building stringer
+// again for each directive or running the other directives:
+//
+//	stringer -fromdirective ./...
+//
+// A directive may run stringer by its name, by a path, or with go run.
+// Only -diff, -v and -allowinvalid may be set along with -fromdirective;
+// they are passed on to each run.
+//
 // If multiple constants have the same value, the lexically first matching name will
 // be used (in the example, Acetaminophen will print as "Paracetamol").
 //
 // With no arguments, it processes the package in the current directory.
-// Otherwise, the arguments must name a single directory holding a Go package
-// or a set of Go source files that represent a single Go package.
+// Otherwise, the arguments must name directories or import paths of Go
+// packages, or a set of Go source files that represent a single Go package.
+// An argument ending in /..., such as ./..., names the packages beneath it.
+// Each package defining some of the types gets an output file of its own.
 //
 // The -type flag accepts a comma-separated list of types so a single run can
 // generate methods for multiple types. The default output file is t_string.go,
 // where t is the lower-cased name of the first type listed. It can be overridden
-// with the -output flag.
+// with the -output flag. Types found in an external test package, such as
+// package painkiller_test, are written to a test file: t_string_test.go, or
+// x_test.go for -output=x.go. With the -splitfiles flag and no -output, each type
+// is written to a file of its own instead, such as pill_string.go and
+// dose_string.go for -type=Pill,Dose, so each can be regenerated alone.
+// An -output naming an existing directory, such as -output=generated, writes
+// the files named as by default, with -splitfiles a file for each type, to
+// that directory instead of the package directory. The flag -pkg sets the
+// package clause of the generated files, as for a package of its own there.
+// An output file is replaced whole, so an interrupted run leaves it as it was.
+// Generated code that is not valid Go, a bug of stringer, is not written
+// unless the flag -allowinvalid is set. An output file left so by an earlier
+// run is not loaded with its package, with a warning, rather than failing to
+// type-check before it is written again.
+//
+// A generated file starts with a comment such as
+//
+//	// Code generated by "stringer -type=Pill"; DO NOT EDIT.
+//
+// which marks it as generated and records the command generating it again,
+// when run in the package directory. Paths are made relative to that
+// directory, files are listed in the order of their names, and flags that do
+// not change the output, such as -diff and -v, are left out, so the comment
+// does not depend on where stringer was run or where the package is. The
+// files of a package are read in the order of their names too, and by default
+// the output is written to the directory of the first, so the order they are
+// given in does not change the output.
+// The flag -header adds a comment of its own before it, such as a copyright
+// notice, of one line or several separated by newlines.
+//
+// The flag -diff writes no file. It prints the changes that would be made to
+// each file as a unified diff, leaving out the line recording the command of
+// stringer, and exits with status 1 if there are any, so that stale generated
+// files can be found without modifying the tree.
+//
+// The flag -method names the generated method, for a type with a String method
+// of its own: -method=Name generates
+//
+//	func (Pill) Name() string
+//
+// in the file pill_name.go. The identifiers declared for it are named apart
+// from those of String, and the Flags method of a bitflag type is named
+// NameFlags, so both can be generated for one type, each with a run of
+// stringer.
+//
+// Stringer fails, naming the file and line of the declaration, if the package
+// already declares an identifier of the generated code, or a method of the
+// type with the name of one it generates, outside of the output file. With
+// the flag -force, the String method is generated even if the type has one,
+// as when that one is about to be removed. The error tells when the method
+// the type has is on a pointer receiver, as the generated methods, including
+// the _string method of a bitflag type, are on a value receiver.
+//
+// The constants of a type may also be strings, as for type Region string. The
+// String method then returns the value itself, and the methods of other flags,
+// such as -isvalid, -values and -text, work from the list of the constants in
+// lexical order. Such a type cannot be a bitflag.
+//
+// If a constant of the type is written with a rune literal, as in
+// LParen Token = '(', a value with no name prints its character, quoted:
+// Token('*'), not Token(42).
+//
+// The names of a run of consecutive values are sliced from one string by an
+// array of offsets, a variable. With the flag -indexencoding=string, the
+// offsets are a string constant instead, each of one, two or four bytes as
+// the names need, so the table is held in read-only data and needs no
+// relocation when the program starts. String reads an offset of more than
+// one byte a byte at a time.
+//
+// The flag -linecomment prints the text of the comment following a constant
+// instead of its name, the lines of a comment spanning several joined by
+// spaces. With -doccomment as well, a constant with no such comment is printed
+// as the text of its doc comment, if it has one. A blank comment is an error,
+// as is the same text for constants of different values.
+//
+// The flag -trimprefix trims a prefix from the printed names: the first of
+// a comma-separated list that matches, as in -trimprefix=StateOld,St. The flag
+// -trimsuffix does the same with suffixes. It is an error if trimming leaves a
+// name empty, or the same as that of a constant with another value. An entry
+// of the list may apply to a single type, as in -trimprefix=Color:Col,Shape:Sh
+// for -type=Color,Shape; the others apply to all types.
+//
+// The flags -include and -exclude filter the constants by regular expressions
+// matched against their names, once trimmed: only those matching -include, if
+// set, and not matching -exclude are generated, as with -exclude=Sentinel$.
+// A match may be anywhere in a name unless anchored with ^ and $.
+//
+// Only the constants declared at package level are generated, unless the flag
+// -funcscope is set: it adds those of the type declared in function bodies,
+// as in a function of a file with build tags.
+//
+// The flag -transform prints the names in another case style: snake_case,
+// kebab-case, UPPER, lower or Title Case, after any prefix is trimmed. Words
+// are split where the case changes, so HTTPServer is http_server in snake
+// case. The text of a line comment is printed as written. The names read
+// back by the methods of other flags, such as UnmarshalText, are the same.
+// Two constants of different values may not print alike once transformed.
 //
 // Custom support for constant sets that are bit patterns is enabled through the use
-// of the flag -bitflag. This works for single bit pattern flags. Multi-bit patterns
-// are silently ignored. For example:
+// of the flag -bitflag. Multi-bit (composite) constants are printed by name when all
+// of their bits are set, after the names of the remaining single bits. Where composites
+// overlap, the one with more bits set is preferred. For example:
 //
 //	//go:generate stringer -bitflag -type=Days
 //	type Days int
@@ -69,7 +179,7 @@
 //		Fri
 //		Sat
 //		Sun
-//		Weekend = Sat | Sun
+//		Weekend Days = Sat | Sun
 //	)
 //
 //		...
@@ -77,21 +187,212 @@
 //		fmt.Println(d)       -> Wed
 //		d |= Sun | Mon
 //		fmt.Println(d)       -> "(Mon|Wed|Sun)"
-//		fmt.Println(Weekend) -> "(Sat|Sun)"
+//		fmt.Println(Weekend) -> "Weekend"
+//		d |= Sat
+//		fmt.Println(d)       -> "(Mon|Wed|Weekend)"
+//
+// A Flags method returns the same names as a slice, so d.Flags() above returns
+// []string{"Mon", "Wed", "Weekend"}. Bits with no name are its last element.
+//
+// The names of the bits set are printed from the lowest bit up. The flag
+// -bitorder=msb prints them from the highest down instead, so d above prints
+// as "(Sun|Wed|Mon)"; the names of composites and any bits with no name still
+// come after them.
+//
+// The flag -bitflagformat sets how several names are printed: sep= sets the
+// separator, | by default, and brackets= the brackets around the names, paren
+// by default, square or none. The separator runs up to a comma starting the
+// next key, so -bitflagformat=sep=,,brackets=none prints d above as
+// "Mon,Wed,Sun". The names of -text and the other flags parsing them are read
+// in the same format; a name holding the separator is an error. The zero value
+// and the bits with no name print as without the flag.
+//
+// Bits with no name print as the conversion of their number to the type, in
+// hexadecimal as in Days(0x80). The flag -unknownbase=dec prints it in decimal,
+// Days(128), and -unknownbase=bin in binary, Days(0b10000000).
+//
+// The flag -bitflaghelpers adds the methods Has, Set, Clear and Toggle, doing
+// the bit operations on values of the type. A method the type already has is
+// not generated, with a warning.
+//
+// A bitflag type should be unsigned: the bits of a negative constant are
+// printed as if it were, and stringer warns about it, giving its position. It
+// also warns about a constant with the value of another, as only the first one
+// declared is printed. The flag -strict makes these warnings errors, so no
+// file is written. The flag -v lists, for each type, the constants left out
+// of its names and why, including the zero value, whose name is printed only
+// for 0. A constant with several bits set, such as ReadWrite = Read | Write,
+// is printed by name when all of its bits are set.
+//
+// By default, the generated String method of a bitflag type reads a table
+// describing the type. The code reading the tables, shared by the types of the
+// package, is written to the file stringerbitflag.go in the package directory,
+// or to the file named by the flag -tablecommon; -tablecommon= leaves it out,
+// for a package that keeps the code elsewhere. The shared types are named
+// _stringerBitflag and _stringerBitflagCache; the flag -tableprefix replaces
+// _stringer, so -tableprefix=_acmeStringer names them _acmeStringerBitflag and
+// _acmeStringerBitflagCache. The file is not written if the package declares
+// them in another file. The flag -notable generates
+// self-contained code for each type instead.
 //
 // By default, the generated stringer code for bitflags caches computed values in a map.
 // The flag -nocache specifies that generated code should not employ a cache.
+// When the cache holds 256 names, a limit set with -cachesize, it starts over,
+// keeping the previous names until it fills again; names used in the meantime
+// are kept longer. -cachesize=0 lets the cache grow without limit, for types
+// with few combinations of flags; the generated code then never checks the
+// size of the cache or keeps previous names.
+//
+// The flag -precompute computes the names of every value of the bits named by
+// the constants of a bitflag type, if there are at most 8 such bits, when the
+// code is generated. String then looks the name up in an array, without a
+// cache or locking, and loops over the flags only for values with other bits
+// set. That code is self-contained, as with -notable. Types naming more bits
+// are generated as without -precompute.
+//
+// The flag -text adds MarshalText and UnmarshalText methods, so values are
+// encoded by name, for example by encoding/json. UnmarshalText accepts the names
+// printed by the String method, including the "(A|B)" form for bitflags, and
+// returns an error for any other name.
+//
+// The flag -json adds MarshalJSON and UnmarshalJSON methods that do the same
+// with a quoted name, without making the generated file import encoding/json.
+// A value with no name marshals as printed, for example "Pill(7)", but such a
+// string does not unmarshal.
+//
+// The flag -sql adds the Value and Scan methods of database/sql/driver.Valuer
+// and database/sql.Scanner, storing values by name. Scan accepts a string or a
+// []byte; it returns an error for NULL and leaves the value unchanged.
+//
+// The flag -isvalid adds a method reporting whether a value is that of a
+// constant, rather than checking whether String returns "Pill(7)":
+//
+//	func (Pill) IsValid() bool
+//
+// For bitflags, it reports whether String prints each set bit by name.
+//
+// The flag -values adds functions listing the constants in increasing order,
+// each value once, and their names as printed by String:
+//
+//	func PillValues() []Pill
+//	func PillNames() []string
+//
+// The flag -sort lists them in another order: -sort=decl in the order the
+// constants are declared, by file and line, and -sort=name in the order of
+// their printed names. It also orders the map declared by String for values
+// too sparse for a table.
+//
+// The flag -exporttables declares the same lists as exported arrays instead,
+// to range over without calling a function or String:
+//
+//	var PillNames = [...]string{...}
+//	var PillValues = [...]Pill{...}
+//
+// The names are sliced from the strings of names String returns, so they are
+// not held twice. As both declare PillValues, -exporttables cannot be used
+// with -values.
+//
+// The flag -gostring adds a GoString method, so that %#v prints a value as the
+// constant names qualified by the package, such as painkiller.Aspirin, or
+// painkiller.Pill(7) for a value with no name. For bitflags, the names are
+// separated by |, as in days.Sat|days.Sun. As it relies on the names printed
+// by String, -gostring cannot be used with flags changing them, such as
+// -trimprefix or -linecomment, unless the names are left as declared.
+//
+// The flag -parse adds a function mapping a name back to its value,
+//
+//	func DaysString(s string) (Days, error)
+//
+// It accepts the same names as UnmarshalText and, like it, the names of
+// constants that alias another one, such as Acetaminophen = Paracetamol.
+// The names of a type with at most 10 of them are found with a switch, and
+// those of a larger one in a map sliced from the names String prints, so the
+// names are not stored twice. Bitflag types always use the map.
+// The flag -aliases lists these in a comment of the generated file, after the
+// names they print as.
+//
+// The constants of a type may be declared in another package than the type,
+// as when it would otherwise make an import cycle. The type is then given
+// qualified by the name or path of its package, as -type=log.Level, and the
+// code is generated in the package of the constants. Methods cannot be
+// declared there, so the String method is generated as the function
+//
+//	func LevelString(i log.Level) string
+//
+// and the flags generating other methods, or the function of -parse, cannot
+// be used. Qualified by its own package, a type has its String method.
+//
+// The code of the types may be generated in another package too, as when
+// helpers the methods would call import the package of the types. The flag
+// -pkg names that package, and -import the import path of the package of the
+// types, which it imports; -pkg naming another package than that of the types
+// requires it. The String methods are then generated as functions, as above:
+//
+//	//go:generate stringer -type=Pill -output=enumsstr -pkg=enumsstr -import=example.com/enums
+//
+// writes enumsstr/pill_string.go, declaring
+//
+//	func PillString(i enums.Pill) string
+//
+// The flag -genbench also writes a test file of benchmarks of String next to
+// each output file, such as pill_string_bench_test.go, to compare the code of
+// flags such as -nocache and -notable for the constants at hand:
+//
+//	go test -run=NONE -bench=PillString
+//
+// Each value of a constant is a sub-benchmark, named after the constant, as
+// are the single bits of a bitflag type set together, named combined, and a
+// value with no name, named unknown. Allocations are reported.
+//
+// The flag -inline writes the code of all the types to one file,
+// zz_generated_stringer.go in the package directory unless -output names
+// another, each type in a region between marker comments:
+//
+//	// stringer:begin Pill 5d41402abc4b2a76
+//	...
+//	// stringer:end Pill
+//
+// A run of stringer replaces the regions of its types, or adds them at the
+// end of the file, and leaves the rest of the file, such as the regions of
+// other types, as it is, but for its imports. The begin marker records a
+// checksum of the code of the region: stringer fails rather than replace a
+// region edited by hand, as it does for markers that do not pair up.
+//
+// The flag -tags applies build tags, as for the go command, so that constants
+// declared in files with build constraints are found. The generated file is
+// constrained to build with the same tags, and where the files declaring the
+// constants build, according to their build lines and _GOOS and _GOARCH name
+// suffixes. If these constraints conflict, a warning is printed instead.
+//
+// Constants whose values come from the C pseudo-package, such as
+//
+//	const MaxThing Thing = C.MAX_THING
+//
+// can only be evaluated by running cgo. By default (-cgo=process) stringer runs
+// cgo on the package when one of its constants refers to C. With -cgo=skip, no
+// cgo processing is done and such constants are omitted with a warning.
+//
+// Errors loading the packages, such as an undeclared name, are printed
+// prefixed by the path of the package, at most 5 of them unless the flag
+// -maxtypeerrors sets another limit, 0 for none. Stringer still generates the
+// code from what it could type-check, which may leave out constants whose
+// values are in error. The flag -stricttypecheck makes it fail instead.
+//
+// The flag -v logs the packages loaded, with the time taken to load and
+// type-check them, the files scanned, the number of constants of each type
+// and the files declaring them, the shape of the code generated for each
+// type, such as a map or a table-driven bitflag, and why, and the files
+// written.
+//
+// The code is generated by the package golang.org/x/tools/stringer, whose
+// Options are these flags, for programs that load packages themselves.
 package main // import "github.com/frankreh/tools/cmd/stringer"
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
-	"go/ast"
-	exact "go/constant"
-	"go/format"
+	"go/build"
 	"go/parser"
-	"go/token"
 	"go/types"
 	"io/ioutil"
 	"log"
@@ -100,24 +401,77 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/stringer"
 )
 
 var (
-	typeNames   = flag.String("type", "", "comma-separated list of type names; must be set")
-	output      = flag.String("output", "", "output file name; default srcdir/<type>_string.go")
-	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
+	typeNames   = flag.String("type", "", "comma-separated list of type names; must be set unless -fromdirective is")
+	output      = flag.String("output", "", "output file name, or an existing directory of the files named as by default; default srcdir/<type>_string.go")
+	splitfiles  = flag.Bool("splitfiles", false, "write each type to its own srcdir/<type>_string.go unless -output names a file")
+	pkgName     = flag.String("pkg", "", "the `name` of the package clause of the generated files; default that of the package of the types")
+	importPath  = flag.String("import", "", "with -pkg naming another package, the import `path` of the package of the types, whose String methods are generated as functions")
+	trimprefix  = flag.String("trimprefix", "", "trim the first matching of the comma-separated `prefixes`, each for all types or given as Type:prefix, from the generated constant names")
+	trimsuffix  = flag.String("trimsuffix", "", "trim the first matching of the comma-separated `suffixes`, each for all types or given as Type:suffix, from the generated constant names")
 	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
+	doccomment  = flag.Bool("doccomment", false, "with -linecomment, use doc comment text when there is no line comment")
+	transform   = flag.String("transform", "none", "case `style` of the printed names: snake, kebab, upper, lower, title or none")
 	bitflag     = flag.Bool("bitflag", false, "handle constants as bitflags")
 	nocache     = flag.Bool("nocache", false, "do not gen code for bitflag that uses cache")
 	notable     = flag.Bool("notable", false, "do not gen code for bitflag that is table driven")
+	tableprefix = flag.String("tableprefix", defaultTablePrefix, "the `prefix` of the names of the types shared by bitflag tables")
+	tablecommon = flag.String("tablecommon", defaultTableCommon, "the `file`, in the package directory unless absolute, of the code shared by bitflag tables; empty to not write it")
+	cachesize   = flag.Int("cachesize", defaultCacheSize, "the most `number` of bitflag names cached, 0 for no limit")
+	precompute  = flag.Bool("precompute", false, "with -bitflag, compute the names of types of at most 8 named bits when generating")
+	bitformat   = flag.String("bitflagformat", "", "with -bitflag, the `format` of several names: sep=<separator>,brackets=paren|square|none")
+	unknownBase = flag.String("unknownbase", "hex", "with -bitflag, the `base` of the number of the bits with no name: hex, dec or bin")
+	bitorder    = flag.String("bitorder", "lsb", "with -bitflag, the `order` of the names of the bits set: lsb, from the lowest bit, or msb, from the highest")
+	text        = flag.Bool("text", false, "also generate MarshalText and UnmarshalText methods")
+	parse       = flag.Bool("parse", false, "also generate a <type>String function returning the value of a name")
+	jsonFlag    = flag.Bool("json", false, "also generate MarshalJSON and UnmarshalJSON methods")
+	sql         = flag.Bool("sql", false, "also generate Value and Scan methods for database/sql")
+	isvalid     = flag.Bool("isvalid", false, "also generate an IsValid method reporting whether a value has a name")
+	listValues  = flag.Bool("values", false, "also generate <type>Values and <type>Names functions listing the constants")
+	exportTabs  = flag.Bool("exporttables", false, "also declare exported <type>Names and <type>Values arrays listing the constants")
+	gostring    = flag.Bool("gostring", false, "also generate a GoString method printing the constant names qualified by the package")
+	include     = flag.String("include", "", "only generate the constants whose names, once trimmed, match the `regexp`")
+	exclude     = flag.String("exclude", "", "do not generate the constants whose names, once trimmed, match the `regexp`")
+	header      = flag.String("header", "", "`text`, such as a copyright notice, of a comment starting the generated files")
+	diffFlag    = flag.Bool("diff", false, "print the changes to the generated files as unified diffs instead of writing them; exit 1 if there are any")
+	invalid     = flag.Bool("allowinvalid", false, "write the generated code even if it is not valid Go, for debugging stringer")
+	aliasFlag   = flag.Bool("aliases", false, "also list in a comment the constants printed as the name of another with the same value")
+	sortFlag    = flag.String("sort", "value", "`order` of the values listed by -values and in maps: value, decl or name")
+	force       = flag.Bool("force", false, "generate the String method, or that of -method, even if the type declares one")
+	method      = flag.String("method", "String", "the `name` of the generated method returning the names, also replacing string in the default output file name")
+	helpers     = flag.Bool("bitflaghelpers", false, "with -bitflag, also generate Has, Set, Clear and Toggle methods")
+	strict      = flag.Bool("strict", false, "fail instead of warning when constants are not printed as declared")
+	maxTypeErrs = flag.Int("maxtypeerrors", 5, "the most `number` of errors loading the packages printed, 0 for no limit")
+	strictCheck = flag.Bool("stricttypecheck", false, "fail instead of generating when loading the packages reports errors")
+	verbose     = flag.Bool("v", false, "log the packages loaded, the constants found, how the code of each type is generated and the files written")
+	buildTags   = flag.String("tags", "", "comma-separated list of build `tags` to apply")
+	cgo         = flag.String("cgo", cgoProcess, "handling of constants valued from package C: `mode` process runs cgo, skip omits them")
+	funcScope   = flag.Bool("funcscope", false, "also generate the constants of the type declared in function bodies")
+	fromDir     = flag.Bool("fromdirective", false, "run stringer as each //go:generate directive of the packages asks, instead of setting -type")
+	genBench    = flag.Bool("genbench", false, "also write a test file of benchmarks of String, <output>_bench_test.go")
+	inline      = flag.Bool("inline", false, "write the code of each type to its region, between marker comments, of one file, -output or srcdir/zz_generated_stringer.go")
+	indexEnc    = flag.String("indexencoding", "slice", "`encoding` of the offsets of the names: slice, an array variable, or string, a constant")
 )
 
-var (
-	// The file created when -bitflag -notable are set.
-	stringerBitflagFilename = "stringerbitflag.go"
-)
+// The default of the -cachesize flag.
+const defaultCacheSize = 256
+
+// The default of the -tableprefix flag, naming the types _stringerBitflag
+// and _stringerBitflagCache.
+const defaultTablePrefix = "_stringer"
+
+// The default of the -tablecommon flag.
+const defaultTableCommon = "stringerbitflag.go"
+
+// The file of -inline in the package directory, unless -output is set.
+const defaultInlineFile = "zz_generated_stringer.go"
 
 // Usage is a replacement usage function for the flags package.
 func Usage() {
@@ -135,10 +489,25 @@ func main() {
 	log.SetPrefix("stringer: ")
 	flag.Usage = Usage
 	flag.Parse()
+	if *fromDir {
+		fromDirectives()
+		return
+	}
 	if len(*typeNames) == 0 {
 		flag.Usage()
 		os.Exit(2)
 	}
+	if *cachesize < 0 {
+		log.Fatalf("invalid -cachesize %d; must not be negative", *cachesize)
+	}
+	opts := options()
+	typeList := uniqueTypes(*typeNames)
+	if err := opts.Check(typeList); err != nil {
+		log.Fatal(err)
+	}
+	if *cgo != cgoProcess && *cgo != cgoSkip {
+		log.Fatalf("invalid -cgo mode %q; must be %s or %s", *cgo, cgoProcess, cgoSkip)
+	}
 
 	args := flag.Args()
 	if len(args) == 0 {
@@ -146,12 +515,26 @@ func main() {
 		args = []string{"."}
 	}
 
+	args, err := expandPatterns(buildContext(), args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
+		os.Exit(1)
+	}
+
 	conf := stringerConfig()
+	if *cgo == cgoSkip {
+		conf.FindPackage = importCgoAsGo
+	}
+	outputNames = outputFileNames(typeList)
+	conf.FindPackage = skipBrokenOutputs(conf.FindPackage)
 
 	if _, err := conf.FromArgs(args, true); err != nil {
 		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
 		os.Exit(1)
 	}
+	for i := range conf.CreatePkgs {
+		conf.CreatePkgs[i].Filenames = dropBrokenOutputs("", conf.CreatePkgs[i].Filenames)
+	}
 
 	// Optimization: don't type-check the bodies of functions in our
 	// dependencies, since we only need exported package members.
@@ -159,521 +542,399 @@ func main() {
 		return conf.ImportPkgs[p] || conf.ImportPkgs[strings.TrimSuffix(p, "_test")]
 	}
 
+	start := time.Now()
 	prog, err := conf.Load()
 	if err != nil {
+		loadErrors.report(nil, *maxTypeErrs)
 		fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(conf.ImportPkgs) > 1 {
-		flag.Usage()
-		os.Exit(2)
+	// The loader does not run cgo for a package given as a list of files.
+	// If one of its constants needs C, load the files again as an import.
+	if *cgo == cgoProcess && len(conf.CreatePkgs) == 1 && stringer.NeedsCgo(prog.Created[0]) {
+		loadErrors.reset()
+		conf, err = cgoConfig(conf.CreatePkgs[0].Filenames)
+		if err == nil {
+			prog, err = conf.Load()
+		}
+		if err != nil {
+			loadErrors.report(nil, *maxTypeErrs)
+			fmt.Fprintf(os.Stderr, "stringer: %v\n", err)
+			os.Exit(1)
+		}
 	}
-
-	// Each type name must be used once.
-	unseen := make(map[string]bool)
-	for _, typeName := range strings.Split(*typeNames, ",") {
-		unseen[typeName] = true
+	if n := loadErrors.report(prog, *maxTypeErrs); n > 0 && *strictCheck {
+		log.Fatalf("not generating: %d errors loading the packages and -stricttypecheck is set", n)
 	}
 
-	// Have common bitflag code written out one time if tables are used.
-	writeStringerBitflagFile = *bitflag && !*notable
+	verbosef("loaded and type-checked %s in %v", strings.Join(args, " "), time.Since(start).Round(time.Millisecond))
 
-	for _, info := range prog.InitialPackages() {
-		// Find types defined in this package.
-		// For determinism, loop over flag (slice), not unseen (map).
-		var names []*types.TypeName
-		for _, typeName := range strings.Split(*typeNames, ",") {
-			t, ok := info.Pkg.Scope().Lookup(typeName).(*types.TypeName)
-			if ok && unseen[typeName] {
-				delete(unseen, typeName)
-				names = append(names, t)
-			}
+	pkgs, found, unseen := findTypes(prog.InitialPackages(), typeList)
+	for _, info := range pkgs {
+		var files []string
+		for _, file := range info.Files {
+			files = append(files, filepath.Base(prog.Fset.File(file.Pos()).Name()))
 		}
+		verbosef("package %s: %d files scanned: %s; types %s", info.Pkg.Path(), len(files), strings.Join(files, ", "), strings.Join(found[info], ", "))
+	}
+
+	// The package each file is written for, so none is written twice, and
+	// the directory of the package each file of common bitflag code is
+	// written for, as a package and its external test package share it.
+	written := make(map[string]string)
+	commonWritten := make(map[string]string)
+
+	// The errors of the types whose code is not generated, reported once
+	// the files of the other types are written.
+	var failed []string
+
+	for _, info := range pkgs {
+		names := found[info]
 		if names == nil {
 			continue
 		}
+		dir := stringer.PackageDir(prog.Fset, info)
+		outDir := dir
+		if isDir(*output) {
+			outDir = *output
+		}
+		common := *tablecommon
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(outDir, common)
+		}
 
-		// Write output file for the found types.
-		outputName := *output
-		if outputName == "" {
-			suffix := "_string.go"
-			if strings.HasSuffix(info.Pkg.Path(), "_test") {
-				suffix = "_string_test.go"
+		opts.Command = commandLine(flag.CommandLine, flag.Args(), dir)
+		start := time.Now()
+		files, err := stringer.Generate(prog.Fset, info, names, opts)
+		verbosef("generated package %s in %v", info.Pkg.Path(), time.Since(start).Round(time.Microsecond))
+		if errs, ok := err.(stringer.TypeErrors); ok {
+			for _, err := range errs {
+				failed = append(failed, err.Error())
 			}
-			dir := filepath.Dir(prog.Fset.File(info.Files[0].Pos()).Name())
-			baseName := names[0].Name() + suffix
-			outputName = filepath.Join(dir, strings.ToLower(baseName))
-		}
-		if err := genFile(outputName, info, names); err != nil {
-			log.Fatalf("writing output: %s", err)
+		} else if err != nil {
+			log.Fatal(err)
 		}
-		if err := genStringerBitflagFile(info.Pkg.Name()); err != nil {
-			log.Fatalf("writing output: %s", err)
+		// For determinism, write the files in the order of their names.
+		var filenames []string
+		for filename := range files {
+			filenames = append(filenames, filename)
 		}
-	}
-
-	if len(unseen) > 0 {
-		var names []string
-		for name := range unseen {
-			names = append(names, name)
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			if filename == common {
+				// Write out this file one time for each package directory.
+				if d, ok := commonWritten[filename]; ok {
+					if d != dir {
+						log.Fatalf("writing output: the common code of the packages in %s and %s cannot both be written to %s", d, dir, filename)
+					}
+					continue
+				}
+				commonWritten[filename] = dir
+			} else {
+				if path, ok := written[filename]; ok {
+					log.Fatalf("types are found in packages %s and %s, which cannot both be written to %s; remove -output to write a file for each", path, info.Pkg.Path(), filename)
+				}
+				written[filename] = info.Pkg.Path()
+			}
+			verbosef("writing %s", filename)
+			if err := writeSource(filename, files[filename]); err != nil {
+				log.Fatalf("writing output: %s", err)
+			}
 		}
-		log.Fatalf("couldn't find type %s", strings.Join(names, ", "))
 	}
-}
 
-func stringerConfig() *loader.Config {
-	conf := loader.Config{
-		AllowErrors: true,
-		ParserMode:  parser.ParseComments,
+	for _, name := range unseen {
+		failed = append(failed, fmt.Sprintf("couldn't find type %s", name))
 	}
-	conf.TypeChecker.FakeImportC = true
-	conf.TypeChecker.Error = maxErrors(5)
-	return &conf
-}
-
-// genFile generates a file defining String methods for the specified
-// typeNames belonging to package info.
-func genFile(filename string, info *loader.PackageInfo, typeNames []*types.TypeName) error {
-	g := Generator{
-		trimPrefix:  *trimprefix,
-		lineComment: *linecomment,
-		bitflag:     *bitflag,
-		cache:       *bitflag && !*nocache, // cache is only relevant when bitflag is also set
-		table:       !*notable,
-	}
-
-	// Print the header and package clause.
-	g.Printf("// generated by stringer %s; DO NOT EDIT\n", strings.Join(os.Args[1:], " "))
-	g.Printf("\n")
-	g.Printf("package %s\n", info.Pkg.Name())
-	g.Printf("\n")
-	if !*bitflag || *notable {
-		g.Printf("import \"strconv\"\n") // Used by all methods.
-		if g.bitflag && g.cache {
-			g.Printf("import \"sync\"\n")
+	if len(failed) > 0 {
+		log.Printf("the code of these types was not generated:")
+		for _, msg := range failed {
+			fmt.Fprintf(os.Stderr, "\t%s\n", msg)
 		}
+		os.Exit(1)
 	}
-
-	// Run generate for each type.
-	for _, typeName := range typeNames {
-		g.generate(info, typeName.Name())
+	if changed {
+		os.Exit(1)
 	}
-
-	// Format the output.
-	src := g.format()
-
-	return ioutil.WriteFile(filename, src, 0644)
-}
-
-// Generator holds the state of the analysis. Primarily used to buffer
-// the output for format.Source.
-type Generator struct {
-	buf         bytes.Buffer // Accumulated output.
-	trimPrefix  string
-	lineComment bool
-	bitflag     bool
-	cache       bool
-	table       bool
 }
 
-func (g *Generator) Printf(format string, args ...interface{}) {
-	fmt.Fprintf(&g.buf, format, args...)
+// verbosef logs, with -v, what stringer does.
+func verbosef(format string, args ...interface{}) {
+	if *verbose {
+		log.Printf(format, args...)
+	}
 }
 
-// generate produces the String method for the named type.
-func (g *Generator) generate(info *loader.PackageInfo, typeName string) {
-	values := make([]Value, 0, 100)
-	addValue := func(vspec *ast.ValueSpec, v Value) {
-		if c := vspec.Comment; g.lineComment && c != nil && len(c.List) == 1 {
-			v.name = strings.TrimSpace(c.Text())
+// uniqueTypes returns the type names of the comma-separated list, in order,
+// with a warning about each name listed again, which is left out.
+func uniqueTypes(list string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if seen[name] {
+			log.Printf("warning: type %s is listed more than once", name)
+			continue
 		}
-		v.name = strings.TrimPrefix(v.name, g.trimPrefix)
-		values = append(values, v)
-	}
-
-	for _, file := range info.Files {
-		ast.Inspect(file, func(node ast.Node) bool {
-			if decl, ok := node.(*ast.GenDecl); ok && decl.Tok == token.CONST {
-				constValues(decl, info, typeName, addValue)
-				return false
-			}
-			return true
-		})
-	}
-
-	if len(values) == 0 {
-		log.Fatalf("no values defined for type %s", typeName)
-	}
-	if g.bitflag {
-		g.buildBitflag(values, typeName)
-		return
-	}
-	runs := splitIntoRuns(values)
-	// The decision of which pattern to use depends on the number of
-	// runs in the numbers. If there's only one, it's easy. For more than
-	// one, there's a tradeoff between complexity and size of the data
-	// and code vs. the simplicity of a map. A map takes more space,
-	// but so does the code. The decision here (crossover at 10) is
-	// arbitrary, but considers that for large numbers of runs the cost
-	// of the linear scan in the switch might become important, and
-	// rather than use yet another algorithm such as binary search,
-	// we punt and use a map. In any case, the likelihood of a map
-	// being necessary for any realistic example other than bitmasks
-	// is very low.
-	switch {
-	case len(runs) == 1:
-		g.buildOneRun(runs, typeName)
-	case len(runs) <= 10:
-		g.buildMultipleRuns(runs, typeName)
-	default:
-		g.buildMap(runs, typeName)
+		seen[name] = true
+		names = append(names, name)
 	}
+	return names
 }
 
-// splitIntoRuns breaks the values into runs of contiguous sequences.
-// For example, given 1,2,3,5,6,7 it returns {1,2,3},{5,6,7}.
-// The input slice is known to be non-empty.
-func splitIntoRuns(values []Value) [][]Value {
-	// We use stable sort so the lexically first name is chosen for equal elements.
-	sort.Stable(byValue(values))
-	// Remove duplicates. Stable sort has put the one we want to print first,
-	// so use that one. The String method won't care about which named constant
-	// was the argument, so the first name for the given value is the only one to keep.
-	// We need to do this because identical values would cause the switch or map
-	// to fail to compile.
-	j := 1
-	for i := 1; i < len(values); i++ {
-		if values[i].value != values[i-1].value {
-			values[j] = values[i]
-			j++
+// findTypes returns the packages in the order of their paths, a package
+// before its external test package, with the names of the types each of
+// them declares, in the order of typeNames, and the names declared by none.
+// A type of another package, as log.Level, is taken as declared by the
+// packages declaring constants of it.
+func findTypes(pkgs []*loader.PackageInfo, typeNames []string) (sorted []*loader.PackageInfo, found map[*loader.PackageInfo][]string, unseen []string) {
+	sorted = append(sorted, pkgs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Pkg.Path() < sorted[j].Pkg.Path() })
+	found = make(map[*loader.PackageInfo][]string)
+	for _, typeName := range typeNames {
+		seen := false
+		for _, info := range sorted {
+			obj, err := stringer.LookupType(info.Pkg, typeName)
+			if err != nil || obj.Pkg() != info.Pkg && !declaresConstants(info, obj.Type()) {
+				continue
+			}
+			found[info] = append(found[info], typeName)
+			seen = true
 		}
-	}
-	values = values[:j]
-	runs := make([][]Value, 0, 10)
-	for len(values) > 0 {
-		// One contiguous sequence per outer loop.
-		i := 1
-		for i < len(values) && values[i].value == values[i-1].value+1 {
-			i++
+		if !seen {
+			unseen = append(unseen, typeName)
 		}
-		runs = append(runs, values[:i])
-		values = values[i:]
 	}
-	return runs
+	return sorted, found, unseen
 }
 
-// format returns the gofmt-ed contents of the generated buffer.
-func (g *Generator) format() []byte {
-	return formatBytes(g.buf.Bytes())
-}
-
-// formatBytes returns the gofmt-ed contents of the buffer.
-func formatBytes(b []byte) []byte {
-	src, err := format.Source(b)
-	if err != nil {
-		// Should never happen, but can arise when developing this code.
-		// The user can compile the output to see the error.
-		log.Printf("warning: internal error: invalid Go generated: %s", err)
-		log.Printf("warning: compile the package to analyze the error")
-		return b
+// declaresConstants reports whether package info declares constants of type typ.
+func declaresConstants(info *loader.PackageInfo, typ types.Type) bool {
+	for _, obj := range info.Defs {
+		if c, ok := obj.(*types.Const); ok && types.Identical(c.Type(), typ) {
+			return true
+		}
 	}
-	return src
-}
-
-// Value represents a declared constant.
-type Value struct {
-	name string // The name of the constant.
-	// The value is stored as a bit pattern alone. The boolean tells us
-	// whether to interpret it as an int64 or a uint64; the only place
-	// this matters is when sorting.
-	// Much of the time the str field is all we need; it is printed
-	// by Value.String.
-	value  uint64 // Will be converted to int64 when needed.
-	signed bool   // Whether the constant is a signed type.
-	str    string // The string representation given by the "go/exact" package.
-}
-
-func (v *Value) String() string {
-	return v.str
+	return false
 }
 
-// byValue lets us sort the constants into increasing order.
-// We take care in the Less method to sort in signed or unsigned order,
-// as appropriate.
-type byValue []Value
-
-func (b byValue) Len() int      { return len(b) }
-func (b byValue) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
-func (b byValue) Less(i, j int) bool {
-	if b[i].signed {
-		return int64(b[i].value) < int64(b[j].value)
+func stringerConfig() *loader.Config {
+	conf := loader.Config{
+		Build:       buildContext(),
+		AllowErrors: true,
+		ParserMode:  parser.ParseComments,
 	}
-	return b[i].value < b[j].value
+	conf.TypeChecker.FakeImportC = true
+	conf.TypeChecker.Error = loadErrors.add
+	return &conf
 }
 
-// constValues calls addValue for each value of type typeName in const declaration decl.
-func constValues(decl *ast.GenDecl, info *loader.PackageInfo, typeName string, addValue func(*ast.ValueSpec, Value)) {
-	// The name of the type of the constants we are declaring.
-	// Can change if this is a multi-element declaration.
-	typ := ""
-	// Loop over the elements of the declaration. Each element is a ValueSpec:
-	// a list of names possibly followed by a type, possibly followed by values.
-	// If the type and value are both missing, we carry down the type (and value,
-	// but the "go/types" package takes care of that).
-	for _, spec := range decl.Specs {
-		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
-		if vspec.Type == nil && len(vspec.Values) > 0 {
-			// "X = 1". With no type but a value, the constant is untyped.
-			// Skip this vspec and reset the remembered type.
-			typ = ""
+// expandPatterns replaces each argument ending in "...", such as ./..., with
+// the packages it matches: for a relative path, the directories beneath it
+// holding a package, and otherwise the import paths it matches.
+func expandPatterns(ctxt *build.Context, args []string) ([]string, error) {
+	var expanded []string
+	for _, arg := range args {
+		if !strings.HasSuffix(arg, "...") {
+			expanded = append(expanded, arg)
 			continue
 		}
-		if vspec.Type != nil {
-			// "X T". We have a type. Remember it.
-			ident, ok := vspec.Type.(*ast.Ident)
-			if !ok {
-				continue
+		if !build.IsLocalImport(arg) {
+			var paths []string
+			for path := range buildutil.ExpandPatterns(ctxt, []string{arg}) {
+				paths = append(paths, path)
 			}
-			typ = ident.Name
-		}
-		if typ != typeName {
-			// This is not the type we're looking for.
+			sort.Strings(paths)
+			expanded = append(expanded, paths...)
 			continue
 		}
-		// We now have a list of names (from one line of source code) all being
-		// declared with the desired type.
-		// Grab their names and actual values and store them in f.values.
-		for _, name := range vspec.Names {
-			if name.Name == "_" {
-				continue
-			}
-			// This dance lets the type checker find the values for us. It's a
-			// bit tricky: look up the object declared by the name, find its
-			// types.Const, and extract its value.
-			obj, ok := info.Info.Defs[name]
-			if !ok {
-				log.Fatalf("no value for constant %s", name)
+		root := filepath.FromSlash(strings.TrimSuffix(strings.TrimSuffix(arg, "..."), "/"))
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || !fi.IsDir() {
+				return err
 			}
-			info := obj.Type().Underlying().(*types.Basic).Info()
-			if info&types.IsInteger == 0 {
-				log.Fatalf("can't handle non-integer constant type %s", typ)
+			// Skip the directories the go command ignores.
+			if name := fi.Name(); path != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
 			}
-			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
-			if value.Kind() != exact.Int {
-				log.Fatalf("can't happen: constant is not an integer %s", name)
+			if _, err := ctxt.ImportDir(path, 0); err == nil {
+				path = filepath.ToSlash(path)
+				if !build.IsLocalImport(path) {
+					path = "./" + path
+				}
+				expanded = append(expanded, path)
 			}
-			i64, isInt := exact.Int64Val(value)
-			u64, isUint := exact.Uint64Val(value)
-			if !isInt && !isUint {
-				log.Fatalf("internal error: value of %s is not an integer: %s", name, value.String())
-			}
-			if !isInt {
-				u64 = uint64(i64)
-			}
-			v := Value{
-				name:   name.Name,
-				value:  u64,
-				signed: info&types.IsUnsigned == 0,
-				str:    value.String(),
-			}
-			addValue(vspec, v)
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
+	return expanded, nil
 }
 
-// Helpers
-
-// usize returns the number of bits of the smallest unsigned integer
-// type that will hold n. Used to create the smallest possible slice of
-// integers to use as indexes into the concatenated strings.
-func usize(n int) int {
-	switch {
-	case n < 1<<8:
-		return 8
-	case n < 1<<16:
-		return 16
-	default:
-		// 2^32 is enough constants for anyone.
-		return 32
+// tagList returns the build tags of the -tags flag.
+func tagList() []string {
+	var tags []string
+	for _, tag := range strings.Split(*buildTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
 	}
+	return tags
 }
 
-// declareIndexAndNameVars declares the index slices and concatenated names
-// strings representing the runs of values.
-func (g *Generator) declareIndexAndNameVars(runs [][]Value, typeName string) {
-	var indexes, names []string
-	for i, run := range runs {
-		index, name := g.createIndexAndNameDecl(run, typeName, fmt.Sprintf("_%d", i))
-		if len(run) != 1 {
-			indexes = append(indexes, index)
-		}
-		names = append(names, name)
-	}
-	g.Printf("const (\n")
-	for _, name := range names {
-		g.Printf("\t%s\n", name)
-	}
-	g.Printf(")\n\n")
+// buildContext returns the default build context with the tags of -tags.
+func buildContext() *build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = append(tagList(), ctxt.BuildTags...)
+	return &ctxt
+}
 
-	if len(indexes) > 0 {
-		g.Printf("var (")
-		for _, index := range indexes {
-			g.Printf("\t%s\n", index)
-		}
-		g.Printf(")\n\n")
+// options returns the options of the generated code set by the flags.
+func options() stringer.Options {
+	cacheSize := *cachesize
+	if cacheSize == 0 {
+		cacheSize = -1 // No limit.
+	}
+	return stringer.Options{
+		Output:         *output,
+		SplitFiles:     *splitfiles,
+		Package:        *pkgName,
+		Import:         *importPath,
+		TrimPrefix:     *trimprefix,
+		TrimSuffix:     *trimsuffix,
+		LineComment:    *linecomment,
+		DocComment:     *doccomment,
+		Transform:      *transform,
+		Bitflag:        *bitflag,
+		NoCache:        *nocache,
+		NoTable:        *notable,
+		TablePrefix:    *tableprefix,
+		TableCommon:    *tablecommon,
+		NoTableCommon:  *tablecommon == "",
+		CacheSize:      cacheSize,
+		Precompute:     *precompute,
+		Text:           *text,
+		Parse:          *parse,
+		JSON:           *jsonFlag,
+		SQL:            *sql,
+		IsValid:        *isvalid,
+		Values:         *listValues,
+		GoString:       *gostring,
+		ExportTables:   *exportTabs,
+		Include:        *include,
+		Exclude:        *exclude,
+		Header:         *header,
+		AllowInvalid:   *invalid,
+		Aliases:        *aliasFlag,
+		Sort:           *sortFlag,
+		Force:          *force,
+		Method:         *method,
+		BitflagHelpers: *helpers,
+		BitOrder:       *bitorder,
+		BitflagFormat:  *bitformat,
+		UnknownBase:    *unknownBase,
+		Strict:         *strict,
+		Verbose:        *verbose,
+		Tags:           *buildTags,
+		SkipCgo:        *cgo == cgoSkip,
+		FuncScope:      *funcScope,
+		IndexEncoding:  *indexEnc,
+		GenBench:       *genBench,
+		Inline:         *inline,
 	}
 }
 
-// declareIndexAndNameVar is the single-run version of declareIndexAndNameVars
-func (g *Generator) declareIndexAndNameVar(run []Value, typeName string) {
-	index, name := g.createIndexAndNameDecl(run, typeName, "")
-	g.Printf("const %s\n", name)
-	g.Printf("var %s\n", index)
+// isDir reports whether name is an existing directory.
+func isDir(name string) bool {
+	fi, err := os.Stat(name)
+	return err == nil && fi.IsDir()
 }
 
-// createIndexAndNameDecl returns the pair of declarations for the run. The caller will add "const" and "var".
-func (g *Generator) createIndexAndNameDecl(run []Value, typeName string, suffix string) (string, string) {
-	b := new(bytes.Buffer)
-	indexes := make([]int, len(run))
-	for i := range run {
-		b.WriteString(run[i].name)
-		indexes[i] = b.Len()
-	}
-	nameConst := fmt.Sprintf("_%s_name%s = %q", typeName, suffix, b.String())
-	nameLen := b.Len()
-	b.Reset()
-	fmt.Fprintf(b, "_%s_index%s = [...]uint%d{0, ", typeName, suffix, usize(nameLen))
-	for i, v := range indexes {
-		if i > 0 {
-			fmt.Fprintf(b, ", ")
-		}
-		fmt.Fprintf(b, "%d", v)
+// writeSource writes src to filename. It writes a temporary file in the same
+// directory and renames it over filename, so that an interrupted run leaves
+// the file as it was. With -diff, it prints the changes instead.
+func writeSource(filename string, src []byte) error {
+	if *diffFlag {
+		return diffSource(filename, src)
 	}
-	fmt.Fprintf(b, "}")
-	return b.String(), nameConst
-}
-
-// declareNameVars declares the concatenated names string representing all the values in the runs.
-func (g *Generator) declareNameVars(runs [][]Value, typeName string, suffix string) {
-	g.Printf("const _%s_name%s = \"", typeName, suffix)
-	for _, run := range runs {
-		for i := range run {
-			g.Printf("%s", run[i].name)
-		}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
 	}
-	g.Printf("\"\n")
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
 }
 
-// buildOneRun generates the variables and String method for a single run of contiguous values.
-func (g *Generator) buildOneRun(runs [][]Value, typeName string) {
-	values := runs[0]
-	g.Printf("\n")
-	g.declareIndexAndNameVar(values, typeName)
-	// The generated code is simple enough to write as a Printf format.
-	lessThanZero := ""
-	if values[0].signed {
-		lessThanZero = "i < 0 || "
-	}
-	if values[0].value == 0 { // Signed or unsigned, 0 is still 0.
-		g.Printf(stringOneRun, typeName, usize(len(values)), lessThanZero)
-	} else {
-		g.Printf(stringOneRunWithOffset, typeName, values[0].String(), usize(len(values)), lessThanZero)
-	}
+// loadErrors collects the errors of loading the packages, printed by report
+// once they are loaded.
+var loadErrors typeErrors
+
+type typeErrors struct {
+	mu   sync.Mutex
+	errs []error
 }
 
-// Arguments to format are:
-//	[1]: type name
-//	[2]: size of index element (8 for uint8 etc.)
-//	[3]: less than zero check (for signed types)
-const stringOneRun = `func (i %[1]s) String() string {
-	if %[3]si >= %[1]s(len(_%[1]s_index)-1) {
-		return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
-	}
-	return _%[1]s_name[_%[1]s_index[i]:_%[1]s_index[i+1]]
+func (e *typeErrors) add(err error) {
+	e.mu.Lock()
+	e.errs = append(e.errs, err)
+	e.mu.Unlock()
 }
-`
-
-// Arguments to format are:
-//	[1]: type name
-//	[2]: lowest defined value for type, as a string
-//	[3]: size of index element (8 for uint8 etc.)
-//	[4]: less than zero check (for signed types)
-/*
- */
-const stringOneRunWithOffset = `func (i %[1]s) String() string {
-	i -= %[2]s
-	if %[4]si >= %[1]s(len(_%[1]s_index)-1) {
-		return "%[1]s(" + strconv.FormatInt(int64(i + %[2]s), 10) + ")"
-	}
-	return _%[1]s_name[_%[1]s_index[i] : _%[1]s_index[i+1]]
+
+// reset drops the errors, as the packages are loaded again.
+func (e *typeErrors) reset() {
+	e.mu.Lock()
+	e.errs = nil
+	e.mu.Unlock()
 }
-`
-
-// buildMultipleRuns generates the variables and String method for multiple runs of contiguous values.
-// For this pattern, a single Printf format won't do.
-func (g *Generator) buildMultipleRuns(runs [][]Value, typeName string) {
-	g.Printf("\n")
-	g.declareIndexAndNameVars(runs, typeName)
-	g.Printf("func (i %s) String() string {\n", typeName)
-	g.Printf("\tswitch {\n")
-	for i, values := range runs {
-		if len(values) == 1 {
-			g.Printf("\tcase i == %s:\n", &values[0])
-			g.Printf("\t\treturn _%s_name_%d\n", typeName, i)
-			continue
+
+// report prints the errors of the packages of prog, if not nil, prefixed with
+// the path of the package, in the order of the paths, then the errors of no
+// package, such as one not found. Unless max is 0, only the first max errors
+// are printed. It returns the number of errors.
+func (e *typeErrors) report(prog *loader.Program, max int) int {
+	var lines []string
+	seen := make(map[string]bool)
+	if prog != nil {
+		var pkgs []*loader.PackageInfo
+		for _, info := range prog.AllPackages {
+			pkgs = append(pkgs, info)
 		}
-		g.Printf("\tcase %s <= i && i <= %s:\n", &values[0], &values[len(values)-1])
-		if values[0].value != 0 {
-			g.Printf("\t\ti -= %s\n", &values[0])
+		sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Pkg.Path() < pkgs[j].Pkg.Path() })
+		for _, info := range pkgs {
+			for _, err := range info.Errors {
+				// Errors are not all comparable, so they are matched by text.
+				seen[err.Error()] = true
+				lines = append(lines, fmt.Sprintf("%s: %s", info.Pkg.Path(), err))
+			}
 		}
-		g.Printf("\t\treturn _%s_name_%d[_%s_index_%d[i]:_%s_index_%d[i+1]]\n",
-			typeName, i, typeName, i, typeName, i)
 	}
-	g.Printf("\tdefault:\n")
-	g.Printf("\t\treturn \"%s(\" + strconv.FormatInt(int64(i), 10) + \")\"\n", typeName)
-	g.Printf("\t}\n")
-	g.Printf("}\n")
-}
-
-// buildMap handles the case where the space is so sparse a map is a reasonable fallback.
-// It's a rare situation but has simple code.
-func (g *Generator) buildMap(runs [][]Value, typeName string) {
-	g.Printf("\n")
-	g.declareNameVars(runs, typeName, "")
-	g.Printf("\nvar _%s_map = map[%s]string{\n", typeName, typeName)
-	n := 0
-	for _, values := range runs {
-		for _, value := range values {
-			g.Printf("\t%s: _%s_name[%d:%d],\n", &value, typeName, n, n+len(value.name))
-			n += len(value.name)
+	e.mu.Lock()
+	for _, err := range e.errs {
+		if !seen[err.Error()] {
+			lines = append(lines, err.Error())
 		}
 	}
-	g.Printf("}\n\n")
-	g.Printf(stringMap, typeName)
-}
-
-// Argument to format is the type name.
-const stringMap = `func (i %[1]s) String() string {
-	if str, ok := _%[1]s_map[i]; ok {
-		return str
-	}
-	return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
-}
-`
-
-// maxErrors returns an error handler for the type checker.
-func maxErrors(max int) func(err error) {
-	var mu sync.Mutex // guards n, os.Stderr
-	var n int
-	return func(err error) {
-		mu.Lock()
-		defer mu.Unlock()
-		n++
-		if n < max {
-			fmt.Fprintln(os.Stderr, err)
-		} else if n == max {
-			fmt.Fprintln(os.Stderr, "\t(suppressing additional type errors)")
+	e.mu.Unlock()
+	for i, line := range lines {
+		if max != 0 && i == max {
+			log.Printf("(%d more errors not printed; see -maxtypeerrors)", len(lines)-max)
+			break
 		}
+		log.Print(line)
 	}
+	return len(lines)
 }
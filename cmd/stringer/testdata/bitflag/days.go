@@ -0,0 +1,45 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with a gap and a composite constant.
+
+package main
+
+import "fmt"
+
+type Days uint8
+
+const (
+	Mon Days = 1 << iota
+	Tue
+	Wed
+	Sat Days = 1 << 5
+	Sun Days = 1 << 6
+
+	Weekend Days = Sat | Sun
+)
+
+func main() {
+	ck(Mon, "Mon")
+	ck(Tue, "Tue")
+	ck(Wed, "Wed")
+	ck(Sat, "Sat")
+	ck(Sun, "Sun")
+	ck(Weekend, "Weekend")
+	ck(Mon|Wed, "(Mon|Wed)")
+	ck(Tue|Weekend, "(Tue|Weekend)")
+	ck(Mon|Sun, "(Mon|Sun)")
+	ck(Sat|8, "(Sat|Days(0x8))")
+	ck(1<<7, "Days(0x80)")
+	ck(0, "Days(0)")
+	// Again, as the cache may answer now.
+	ck(Mon|Wed, "(Mon|Wed)")
+	ck(Sat|8, "(Sat|Days(0x8))")
+}
+
+func ck(days Days, str string) {
+	if fmt.Sprint(days) != str {
+		panic("days.go: " + str)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Unsigned 64-bit bitflags, one with the top bit set.
+
+package main
+
+import "fmt"
+
+type Mask uint64
+
+const (
+	MaskLow Mask = 1 << iota
+	MaskMid
+	MaskTop Mask = 1 << 63
+)
+
+func main() {
+	ck(MaskLow, "MaskLow")
+	ck(MaskMid, "MaskMid")
+	ck(MaskTop, "MaskTop")
+	ck(MaskLow|MaskTop, "(MaskLow|MaskTop)")
+	ck(MaskLow|MaskMid|MaskTop, "(MaskLow|MaskMid|MaskTop)")
+	ck(MaskTop|1<<62, "(MaskTop|Mask(0x4000000000000000))")
+	ck(1<<40, "Mask(0x10000000000)")
+	ck(0, "Mask(0)")
+}
+
+func ck(mask Mask, str string) {
+	if fmt.Sprint(mask) != str {
+		panic("mask.go: " + str)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Signed bitflags with a constant for zero.
+
+package main
+
+import "fmt"
+
+type Perm int16
+
+const (
+	None Perm = 0
+	Read Perm = 1 << iota
+	Write
+	Exec
+)
+
+func main() {
+	ck(None, "None")
+	ck(Read, "Read")
+	ck(Write, "Write")
+	ck(Exec, "Exec")
+	ck(Read|Write, "(Read|Write)")
+	ck(Read|Write|Exec, "(Read|Write|Exec)")
+	ck(Exec|1<<10, "(Exec|Perm(0x400))")
+	ck(1<<12, "Perm(0x1000)")
+}
+
+func ck(perm Perm, str string) {
+	if fmt.Sprint(perm) != str {
+		panic("perm.go: " + str)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with a gap and a composite constant, printed with
+// -bitflagformat=sep=,,brackets=none and read back by UnmarshalText.
+
+package main
+
+import "fmt"
+
+type Comma uint8
+
+const (
+	Mon Comma = 1 << iota
+	Tue
+	Wed
+	Sat Comma = 1 << 5
+	Sun Comma = 1 << 6
+
+	Weekend Comma = Sat | Sun
+)
+
+func main() {
+	ck(Mon, "Mon")
+	ck(Weekend, "Weekend")
+	ck(Mon|Wed, "Mon,Wed")
+	ck(Tue|Weekend, "Tue,Weekend")
+	ck(Mon|Wed|Sun, "Mon,Wed,Sun")
+	ck(Sat|8, "Sat,Comma(0x8)")
+	ck(1<<7, "Comma(0x80)")
+	ck(0, "Comma(0)")
+	if s := fmt.Sprintf("%#v", Mon|Sun); s != "main.Mon|main.Sun" {
+		panic("comma.go: GoString " + s)
+	}
+}
+
+func ck(c Comma, str string) {
+	if fmt.Sprint(c) != str {
+		panic("comma.go: " + str)
+	}
+	if c&^(Mon|Tue|Wed|Weekend) != 0 {
+		return
+	}
+	var got Comma
+	if err := got.UnmarshalText([]byte(str)); err != nil || got != c {
+		panic(fmt.Sprintf("comma.go: %s read back as %#x, %v", str, uint8(got), err))
+	}
+}
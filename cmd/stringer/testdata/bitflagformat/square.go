@@ -0,0 +1,43 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with a gap, printed with -bitflagformat="sep= | ,brackets=square"
+// and read back by UnmarshalText.
+
+package main
+
+import "fmt"
+
+type Square uint16
+
+const (
+	Read Square = 1 << iota
+	Write
+	Exec Square = 1 << 9
+)
+
+func main() {
+	ck(Read, "Read")
+	ck(Read|Write, "[Read | Write]")
+	ck(Read|Write|Exec, "[Read | Write | Exec]")
+	ck(Exec|4, "[Exec | Square(0x4)]")
+	ck(1<<15, "Square(0x8000)")
+	ck(0, "Square(0)")
+	if s := fmt.Sprintf("%#v", Read|Exec); s != "main.Read|main.Exec" {
+		panic("square.go: GoString " + s)
+	}
+}
+
+func ck(sq Square, str string) {
+	if fmt.Sprint(sq) != str {
+		panic("square.go: " + str)
+	}
+	if sq&^(Read|Write|Exec) != 0 {
+		return
+	}
+	var got Square
+	if err := got.UnmarshalText([]byte(str)); err != nil || got != sq {
+		panic(fmt.Sprintf("square.go: %s read back as %#x, %v", str, uint16(got), err))
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with a gap and a composite constant, named from the highest bit
+// down by -bitorder=msb and read back by UnmarshalText.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Days uint8
+
+const (
+	Mon Days = 1 << iota
+	Tue
+	Wed
+	Sat Days = 1 << 5
+	Sun Days = 1 << 6
+
+	Weekend Days = Sat | Sun
+)
+
+func main() {
+	ck(Mon, "Mon")
+	ck(Sun, "Sun")
+	ck(Weekend, "Weekend")
+	ck(Mon|Wed, "(Wed|Mon)")
+	ck(Tue|Weekend, "(Tue|Weekend)")
+	ck(Mon|Sun, "(Sun|Mon)")
+	ck(Mon|Tue|Wed|Sun, "(Sun|Wed|Tue|Mon)")
+	ck(Sat|8, "(Sat|Days(0x8))")
+	ck(1<<7|Tue, "(Tue|Days(0x80))")
+	ck(0, "Days(0)")
+	if f := strings.Join((Mon | Sun | 8).Flags(), ","); f != "Sun,Mon,Days(0x8)" {
+		panic("days.go: Flags " + f)
+	}
+}
+
+func ck(days Days, str string) {
+	if fmt.Sprint(days) != str {
+		panic("days.go: " + str)
+	}
+	if days&^(Mon|Tue|Wed|Weekend) != 0 {
+		return
+	}
+	var got Days
+	if err := got.UnmarshalText([]byte(str)); err != nil || got != days {
+		panic(fmt.Sprintf("days.go: %s read back as %#x, %v", str, uint8(got), err))
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with gaps, the largest from the top bit, and no composites, named
+// from the highest bit down by -bitorder=msb and read back by UnmarshalText.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Gap uint64
+
+const (
+	Low  Gap = 1 << 0
+	Next Gap = 1 << 1
+	Mid  Gap = 1 << 9
+	Top  Gap = 1 << 63
+)
+
+func main() {
+	ck(Low, "Low")
+	ck(Top, "Top")
+	ck(Low|Next, "(Next|Low)")
+	ck(Low|Mid|Top, "(Top|Mid|Low)")
+	ck(Next|Mid, "(Mid|Next)")
+	ck(Mid|4, "(Mid|Gap(0x4))")
+	ck(1<<62, "Gap(0x4000000000000000)")
+	ck(0, "Gap(0)")
+	if f := strings.Join((Low | Top | 4).Flags(), ","); f != "Top,Low,Gap(0x4)" {
+		panic("gap.go: Flags " + f)
+	}
+}
+
+func ck(gap Gap, str string) {
+	if fmt.Sprint(gap) != str {
+		panic("gap.go: " + str)
+	}
+	if gap&^(Low|Next|Mid|Top) != 0 {
+		return
+	}
+	var got Gap
+	if err := got.UnmarshalText([]byte(str)); err != nil || got != gap {
+		panic(fmt.Sprintf("gap.go: %s read back as %#x, %v", str, uint64(got), err))
+	}
+}
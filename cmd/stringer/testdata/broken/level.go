@@ -0,0 +1,21 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The variables refer to an undeclared name, so the package has type errors.
+// Stringer prints them and, unless -stricttypecheck is set, generates the
+// names of the constants of Level.
+
+package broken
+
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
+
+var (
+	step  = unit
+	limit = High + unit
+)
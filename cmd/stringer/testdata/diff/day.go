@@ -0,0 +1,15 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Day has gained a constant since day_string.go was generated.
+
+package diff
+
+type Day int
+
+const (
+	Monday Day = iota
+	Tuesday
+	Wednesday
+)
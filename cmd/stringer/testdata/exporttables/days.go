@@ -0,0 +1,34 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The arrays of -exporttables list the bitflag constants, the zero value and
+// the composites too, and their names, as printed by String.
+
+package main
+
+import "fmt"
+
+type Days uint8
+
+const (
+	None Days = 0
+	Mon  Days = 1 << iota
+	Tue
+	Wed
+	Sat Days = 1 << 5
+	Sun Days = 1 << 6
+
+	Weekend Days = Sat | Sun
+)
+
+func main() {
+	if len(DaysNames) != 7 || len(DaysValues) != 7 {
+		panic(fmt.Sprintf("days.go: %d names and %d values, want 7", len(DaysNames), len(DaysValues)))
+	}
+	for i, v := range DaysValues {
+		if DaysNames[i] != v.String() {
+			panic(fmt.Sprintf("days.go: DaysNames[%d] is %q, want %q", i, DaysNames[i], v.String()))
+		}
+	}
+}
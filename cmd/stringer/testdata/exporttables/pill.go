@@ -0,0 +1,33 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The arrays of -exporttables list the constants and their names, as printed
+// by String, in several runs.
+
+package main
+
+import "fmt"
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+	Acetaminophen      = Paracetamol
+	Vitamin       Pill = 10
+	Zinc          Pill = 12
+)
+
+func main() {
+	if len(PillNames) != 6 || len(PillValues) != 6 {
+		panic(fmt.Sprintf("pill.go: %d names and %d values, want 6", len(PillNames), len(PillValues)))
+	}
+	for i, v := range PillValues {
+		if PillNames[i] != v.String() {
+			panic(fmt.Sprintf("pill.go: PillNames[%d] is %q, want %q", i, PillNames[i], v.String()))
+		}
+	}
+}
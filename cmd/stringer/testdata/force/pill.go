@@ -0,0 +1,20 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A type with a String method of its own, which stringer does not replace
+// unless -force is set.
+
+package force
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+)
+
+func (p Pill) String() string {
+	return "pill"
+}
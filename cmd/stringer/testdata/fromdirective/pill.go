@@ -0,0 +1,41 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Two directives, of different flags, each writing its own file.
+
+package main
+
+import "fmt"
+
+//go:generate stringer -type=Pill
+//go:generate go run golang.org/x/tools/cmd/stringer "-type=Dose" -trimprefix=Dose -output=${GOPACKAGE}_dose.go
+
+// Not run by -fromdirective.
+//go:generate echo Pill
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+)
+
+type Dose int
+
+const (
+	DoseLow Dose = iota
+	DoseHigh
+)
+
+func main() {
+	ck(Aspirin.String(), "Aspirin")
+	ck(DoseHigh.String(), "High")
+	ck(Dose(2).String(), "Dose(2)")
+}
+
+func ck(got, want string) {
+	if got != want {
+		panic(fmt.Sprintf("pill.go: got %q, want %q", got, want))
+	}
+}
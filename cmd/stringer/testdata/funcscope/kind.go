@@ -0,0 +1,29 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// With -funcscope, the constants declared in a function body are printed
+// too, and those declared after a var block always are.
+
+package main
+
+import "fmt"
+
+type Kind int
+
+var kinds = []Kind{KindA, KindB}
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+func main() {
+	const KindC Kind = 2
+	kinds = append(kinds, KindC)
+	for i, want := range []string{"KindA", "KindB", "KindC"} {
+		if got := kinds[i].String(); got != want {
+			panic(fmt.Sprintf("kind.go: Kind(%d) is %q, want %q", i, got, want))
+		}
+	}
+}
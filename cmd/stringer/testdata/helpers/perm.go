@@ -0,0 +1,44 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The helper methods of a bitflag type, generated with -bitflag
+// -bitflaghelpers. The type declares its own Toggle method, which
+// must not be generated again.
+
+package main
+
+import "fmt"
+
+type Perm uint8
+
+const (
+	Read  Perm = 1
+	Write Perm = 2
+	Exec  Perm = 4
+)
+
+// Toggle turns on the flags of f if none is set in m, else turns them off.
+func (m Perm) Toggle(f Perm) Perm {
+	if m&f == 0 {
+		return m | f
+	}
+	return m &^ f
+}
+
+func main() {
+	m := Read.Set(Exec)
+	ck(m, "(Read|Exec)")
+	if !m.Has(Read) || !m.Has(Read|Exec) || m.Has(Read|Write) {
+		panic("perm: Has")
+	}
+	ck(m.Clear(Read|Write), "Exec")
+	ck(m.Toggle(Read|Write), "Exec")
+	ck(Exec.Toggle(Read|Write), "(Read|Write|Exec)")
+}
+
+func ck(perm Perm, str string) {
+	if fmt.Sprint(perm) != str {
+		panic("perm: " + str)
+	}
+}
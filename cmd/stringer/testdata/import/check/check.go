@@ -0,0 +1,31 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check the names of enums.Pill, printed by enumsstr.PillString, and listed
+// by enumsstr.PillNames.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"enums"
+	"enums/enumsstr"
+)
+
+func main() {
+	ck(enumsstr.PillString(enums.Placebo), "Placebo")
+	ck(enumsstr.PillString(enums.Paracetamol), "Paracetamol")
+	ck(enumsstr.PillString(-1), "Pill(-1)")
+	ck(enumsstr.PillString(4), "Pill(4)")
+	ck(strings.Join(enumsstr.PillNames(), ","), "Placebo,Aspirin,Ibuprofen,Paracetamol")
+	ck(fmt.Sprint(enumsstr.PillValues()), "[0 1 2 3]")
+}
+
+func ck(str, want string) {
+	if str != want {
+		panic(fmt.Sprintf("got %q, want %q", str, want))
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package enums declares Pill, whose String method is generated as a
+// function of package enums/enumsstr.
+
+package enums
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+)
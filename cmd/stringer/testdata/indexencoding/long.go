@@ -0,0 +1,59 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// With -indexencoding=string, the names of the first run are more than 255
+// bytes, so their offsets are of two bytes, read for a type of 8 bits.
+
+package main
+
+import "fmt"
+
+type Long int8
+
+const (
+	LongValueA Long = iota + 1
+	LongValueB
+	LongValueC
+	LongValueD
+	LongValueE
+	LongValueF
+	LongValueG
+	LongValueH
+	LongValueI
+	LongValueJ
+	LongValueK
+	LongValueL
+	LongValueM
+	LongValueN
+	LongValueO
+	LongValueP
+	LongValueQ
+	LongValueR
+	LongValueS
+	LongValueT
+	LongValueU
+	LongValueV
+	LongValueW
+	LongValueX
+	LongValueY
+	LongValueZ
+	LongEnd Long = 100
+)
+
+func main() {
+	for i := LongValueA; i <= LongValueZ; i++ {
+		ck(i, fmt.Sprintf("LongValue%c", 'A'+i-1))
+	}
+	ck(LongEnd, "LongEnd")
+	ck(0, "Long(0)")
+	ck(27, "Long(27)")
+	ck(-128, "Long(-128)")
+	ck(127, "Long(127)")
+}
+
+func ck(long Long, str string) {
+	if fmt.Sprint(long) != str {
+		panic("long.go: " + str)
+	}
+}
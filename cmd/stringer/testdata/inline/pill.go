@@ -0,0 +1,35 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Two types whose code is written to one file, each by a run of stringer.
+
+package main
+
+import "fmt"
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+)
+
+type Dose int
+
+const (
+	DoseLow Dose = iota
+	DoseHigh
+)
+
+func main() {
+	ck(Aspirin.String(), "Aspirin")
+	ck(DoseHigh.String(), "High")
+	ck(Dose(2).String(), "Dose(2)")
+}
+
+func ck(got, want string) {
+	if got != want {
+		panic(fmt.Sprintf("pill.go: got %q, want %q", got, want))
+	}
+}
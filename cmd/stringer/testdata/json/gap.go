@@ -0,0 +1,71 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON encoding of a type with gaps, whose methods are generated with -json.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Gap int
+
+const (
+	Two    Gap = 2
+	Three  Gap = 3
+	Five   Gap = 5
+	Six    Gap = 6
+	Seven  Gap = 7
+	Eight  Gap = 8
+	Nine   Gap = 9
+	Eleven Gap = 11
+	Once   Gap = 11 // An alias; prints as Eleven.
+)
+
+func main() {
+	for _, gap := range []Gap{Two, Three, Five, Six, Seven, Eight, Nine, Eleven} {
+		ck(gap, fmt.Sprintf("%q", gap))
+	}
+	ck(Once, `"Eleven"`)
+	ckUnmarshal(`"Once"`, Once)
+	ckMarshal(4, `"Gap(4)"`)
+	ckError(`"Gap(4)"`)
+	ckError(`"Four"`)
+	ckError(`4`)
+
+	// Values embedded in other types use the methods too.
+	b, err := json.Marshal(map[string][]Gap{"k": {Two, Nine}})
+	if err != nil || string(b) != `{"k":["Two","Nine"]}` {
+		panic(fmt.Sprintf("gap: marshal slice: %s %v", b, err))
+	}
+}
+
+// ck checks that gap marshals to str and back.
+func ck(gap Gap, str string) {
+	ckMarshal(gap, str)
+	ckUnmarshal(str, gap)
+}
+
+func ckMarshal(gap Gap, str string) {
+	b, err := json.Marshal(gap)
+	if err != nil || string(b) != str {
+		panic(fmt.Sprintf("gap: marshal %d: %s %v", int(gap), b, err))
+	}
+}
+
+func ckUnmarshal(str string, gap Gap) {
+	var g Gap
+	if err := json.Unmarshal([]byte(str), &g); err != nil || g != gap {
+		panic(fmt.Sprintf("gap: unmarshal %s: %d %v", str, int(g), err))
+	}
+}
+
+func ckError(str string) {
+	var g Gap
+	if err := json.Unmarshal([]byte(str), &g); err == nil {
+		panic("gap: unmarshal " + str + ": no error")
+	}
+}
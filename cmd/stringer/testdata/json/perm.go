@@ -0,0 +1,64 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON encoding of a bitflag type, whose methods are generated with
+// -bitflag -json.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Perm uint8
+
+const (
+	None  Perm = 0
+	Read  Perm = 1
+	Write Perm = 2
+	Exec  Perm = 4
+	RW    Perm = Read | Write
+)
+
+func main() {
+	ck(None, `"None"`)
+	ck(Read, `"Read"`)
+	ck(Write, `"Write"`)
+	ck(Exec, `"Exec"`)
+	ck(RW, `"RW"`)
+	ck(RW|Exec, `"(Exec|RW)"`)
+	ckUnmarshal(`"(Read|Write)"`, RW)
+	ckMarshal(Exec|8, `"(Exec|Perm(0x8))"`)
+	ckError(`"(Exec|Perm(0x8))"`)
+	ckError(`"Read|Delete"`)
+	ckError(`7`)
+}
+
+// ck checks that perm marshals to str and back.
+func ck(perm Perm, str string) {
+	ckMarshal(perm, str)
+	ckUnmarshal(str, perm)
+}
+
+func ckMarshal(perm Perm, str string) {
+	b, err := json.Marshal(perm)
+	if err != nil || string(b) != str {
+		panic(fmt.Sprintf("perm: marshal %d: %s %v", uint8(perm), b, err))
+	}
+}
+
+func ckUnmarshal(str string, perm Perm) {
+	var p Perm
+	if err := json.Unmarshal([]byte(str), &p); err != nil || p != perm {
+		panic(fmt.Sprintf("perm: unmarshal %s: %d %v", str, uint8(p), err))
+	}
+}
+
+func ckError(str string) {
+	var p Perm
+	if err := json.Unmarshal([]byte(str), &p); err == nil {
+		panic("perm: unmarshal " + str + ": no error")
+	}
+}
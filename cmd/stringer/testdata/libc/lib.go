@@ -0,0 +1,41 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants of type Libc are declared in this file and in the cgo file
+// lib_c.go, whose values come from C. Run with the argument "skip" when
+// the String method was generated with -cgo=skip.
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type Libc int
+
+const (
+	Zero Libc = iota
+	One
+)
+
+func main() {
+	skip := len(os.Args) > 1 && os.Args[1] == "skip"
+	ck(Zero, "Zero")
+	ck(One, "One")
+	if skip {
+		ck(Two, "Libc(2)")
+		ck(Three, "Libc(3)")
+	} else {
+		ck(Two, "Two")
+		ck(Three, "Three")
+	}
+	ck(4, "Libc(4)")
+}
+
+func ck(libc Libc, str string) {
+	if fmt.Sprint(libc) != str {
+		panic("libc: " + str)
+	}
+}
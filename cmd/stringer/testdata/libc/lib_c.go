@@ -0,0 +1,16 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+/*
+#define LIBC_TWO 2
+#define LIBC_THREE 3
+*/
+import "C"
+
+const (
+	Two   Libc = C.LIBC_TWO
+	Three Libc = C.LIBC_THREE
+)
@@ -0,0 +1,32 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Unsigned 64-bit values with the top bit set.
+
+package main
+
+import "fmt"
+
+type Mask uint64
+
+const (
+	MaskLow Mask = iota
+	MaskMid
+	MaskTop Mask = 1 << 63
+)
+
+func main() {
+	ck(MaskLow, "MaskLow")
+	ck(MaskMid, "MaskMid")
+	ck(MaskTop, "MaskTop")
+	ck(2, "Mask(2)")
+	ck(MaskTop+1, "Mask(9223372036854775809)")
+	ck(^Mask(0), "Mask(18446744073709551615)")
+}
+
+func ck(mask Mask, str string) {
+	if fmt.Sprint(mask) != str {
+		panic("mask.go: " + str)
+	}
+}
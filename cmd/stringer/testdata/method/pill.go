@@ -0,0 +1,28 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A type with both a String method and, generated with -method=Name, a
+// Name method, which print the same names.
+
+package main
+
+import "fmt"
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+	Acetaminophen = Paracetamol
+)
+
+func main() {
+	for p := Placebo - 1; p <= Paracetamol+1; p++ {
+		if p.String() != p.Name() {
+			panic(fmt.Sprintf("%d: String %q, Name %q", int(p), p.String(), p.Name()))
+		}
+	}
+}
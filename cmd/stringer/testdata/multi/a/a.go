@@ -0,0 +1,14 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Packages a and b each define one of the types given to a single run.
+
+package a
+
+type Alpha int
+
+const (
+	Alef Alpha = iota
+	Bet
+)
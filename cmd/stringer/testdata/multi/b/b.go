@@ -0,0 +1,12 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+type Beta uint8
+
+const (
+	Bass Beta = iota + 1
+	Baritone
+)
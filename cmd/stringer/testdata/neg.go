@@ -0,0 +1,38 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A run of negative values, shifted up to zero by an addition, checked for
+// every value of the type, so that those the addition wraps around are
+// printed as they were.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Neg int8
+
+const (
+	m3 Neg = iota - 3
+	m2
+	m1
+)
+
+func main() {
+	for n := -128; n < 128; n++ {
+		str := "Neg(" + strconv.Itoa(n) + ")"
+		if -3 <= n && n <= -1 {
+			str = "m" + strconv.Itoa(-n)
+		}
+		ck(Neg(n), str)
+	}
+}
+
+func ck(neg Neg, str string) {
+	if fmt.Sprint(neg) != str {
+		panic("neg.go: " + str)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Three types, of which Weight has no integer constants. Stringer generates
+// the code of the others and reports Weight.
+
+package partial
+
+type Fruit int
+
+const (
+	Apple Fruit = iota
+	Banana
+)
+
+type Weight float64
+
+const Light Weight = 0.5
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
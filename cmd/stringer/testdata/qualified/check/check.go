@@ -0,0 +1,30 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check the names of log.Level, printed by loglevels.LevelString, and of
+// log.Priority, printed by its String method.
+
+package main
+
+import (
+	"fmt"
+
+	"levels/log"
+	"levels/loglevels"
+)
+
+func main() {
+	ck(loglevels.LevelString(loglevels.Debug), "Debug")
+	ck(loglevels.LevelString(loglevels.Error), "Error")
+	ck(loglevels.LevelString(loglevels.Fatal), "Fatal")
+	ck(loglevels.LevelString(5), "Level(5)")
+	ck(log.High.String(), "High")
+	ck(fmt.Sprint(log.Low), "Low")
+}
+
+func ck(str, want string) {
+	if str != want {
+		panic(fmt.Sprintf("got %q, want %q", str, want))
+	}
+}
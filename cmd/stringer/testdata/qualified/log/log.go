@@ -0,0 +1,17 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package log declares Level, whose constants are declared in package
+// loglevels, and Priority, whose constants are its own.
+
+package log
+
+type Level int
+
+type Priority int
+
+const (
+	Low Priority = iota
+	High
+)
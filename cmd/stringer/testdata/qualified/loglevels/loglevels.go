@@ -0,0 +1,18 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package loglevels declares the constants of log.Level, which cannot have
+// methods declared here, so stringer generates the function LevelString.
+
+package loglevels
+
+import "levels/log"
+
+const (
+	Debug log.Level = iota
+	Info
+	Warn
+	Error
+	Fatal log.Level = 10
+)
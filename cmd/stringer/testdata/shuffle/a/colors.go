@@ -0,0 +1,16 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The files of package shuffle are in two directories, given in either order,
+// each declaring constants of Color, including two of the same value.
+
+package shuffle
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Crimson Color = 0
+)
@@ -0,0 +1,11 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shuffle
+
+const (
+	Blue    Color = 2
+	Scarlet Color = 0
+	Cyan    Color = 3
+)
@@ -0,0 +1,16 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pill and Dose are generated together with -splitfiles, each into a file
+// of its own that must compile with just the declaration of its type.
+
+package split
+
+type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+)
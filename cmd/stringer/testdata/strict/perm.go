@@ -0,0 +1,17 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A bitflag type with a constant that is not printed, as it has the value
+// of another. With -strict, stringer fails instead of warning about it.
+
+package strict
+
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+	Modify Perm = Write // Not printed.
+)
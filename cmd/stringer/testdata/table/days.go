@@ -0,0 +1,36 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A table-driven bitflag type, whose common code is written to a file
+// named with -tablecommon.
+
+package main
+
+import "fmt"
+
+type Days uint8
+
+const (
+	Mon Days = 1 << iota
+	Tue
+	Wed
+	Sat Days = 1 << 5
+	Sun Days = 1 << 6
+
+	Weekend Days = Sat | Sun
+)
+
+func main() {
+	ck(Mon, "Mon")
+	ck(Mon|Wed, "(Mon|Wed)")
+	ck(Tue|Weekend, "(Tue|Weekend)")
+	ck(Sat|8, "(Sat|Days(0x8))")
+	ck(0, "Days(0)")
+}
+
+func ck(days Days, str string) {
+	if fmt.Sprint(days) != str {
+		panic("days.go: " + str)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants split across build-tag variants: the value 2 is named Two in
+// tag_foo.go, built with the tag foo, and Deux in tag_nofoo.go otherwise.
+
+package main
+
+import "fmt"
+
+type Tag int
+
+const (
+	Zero Tag = iota
+	One
+)
+
+func main() {
+	ck(Zero, "Zero")
+	ck(One, "One")
+	ck(two, twoName)
+	ck(3, "Tag(3)")
+}
+
+func ck(tag Tag, str string) {
+	if fmt.Sprint(tag) != str {
+		panic("tag: " + str)
+	}
+}
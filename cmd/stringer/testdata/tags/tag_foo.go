@@ -0,0 +1,15 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build foo
+// +build foo
+
+package main
+
+const Two Tag = 2
+
+const (
+	two     = Two
+	twoName = "Two"
+)
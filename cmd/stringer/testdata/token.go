@@ -0,0 +1,39 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants written as rune literals, whose unknown values print as
+// quoted characters.
+
+package main
+
+import "fmt"
+
+type Token rune
+
+const (
+	EOF    Token = 0
+	LParen Token = '('
+	RParen Token = ')'
+	Plus   Token = '+'
+	Pi     Token = 'π'
+)
+
+func main() {
+	ck(EOF, "EOF")
+	ck(LParen, "LParen")
+	ck(RParen, "RParen")
+	ck(Plus, "Plus")
+	ck(Pi, "Pi")
+	ck('*', `Token('*')`)
+	ck('\n', `Token('\n')`)
+	ck('\x7f', `Token('\x7f')`)
+	ck('λ', `Token('λ')`)
+	ck(-1, `Token('�')`)
+}
+
+func ck(token Token, str string) {
+	if fmt.Sprint(token) != str {
+		panic("token.go: " + str)
+	}
+}
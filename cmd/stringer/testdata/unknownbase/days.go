@@ -0,0 +1,48 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bitflags with bits with no name, printed in the base of -unknownbase,
+// given as the argument.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Days uint16
+
+const (
+	Mon Days = 1 << iota
+	Tue
+	Wed
+	Sat Days = 1 << 5
+	Sun Days = 1 << 6
+
+	Weekend Days = Sat | Sun
+)
+
+func main() {
+	unknown := map[string][]string{
+		"hex": {"Days(0x280)", "Days(0x8)", "Days(0x80)"},
+		"dec": {"Days(640)", "Days(8)", "Days(128)"},
+		"bin": {"Days(0b1010000000)", "Days(0b1000)", "Days(0b10000000)"},
+	}[os.Args[1]]
+	ck(0x280, unknown[0])
+	ck(Mon|8, "(Mon|"+unknown[1]+")")
+	ck(Weekend|0x80, "(Weekend|"+unknown[2]+")")
+	ck(Mon|Sun, "(Mon|Sun)")
+	ck(0, "Days(0)")
+	if f := strings.Join((Tue | 8).Flags(), ","); f != "Tue,"+unknown[1] {
+		panic("days.go: Flags " + f)
+	}
+}
+
+func ck(days Days, str string) {
+	if fmt.Sprint(days) != str {
+		panic("days.go: " + str)
+	}
+}
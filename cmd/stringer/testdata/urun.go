@@ -0,0 +1,40 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A run of unsigned values starting above zero, checked for every value
+// of the type, so none below the run wraps around into it.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Urun uint8
+
+const (
+	u10 Urun = iota + 10
+	u11
+	u12
+	u13
+	u14
+	u15
+)
+
+func main() {
+	for n := 0; n < 256; n++ {
+		str := "Urun(" + strconv.Itoa(n) + ")"
+		if 10 <= n && n <= 15 {
+			str = "u" + strconv.Itoa(n)
+		}
+		ck(Urun(n), str)
+	}
+}
+
+func ck(urun Urun, str string) {
+	if fmt.Sprint(urun) != str {
+		panic("urun.go: " + str)
+	}
+}
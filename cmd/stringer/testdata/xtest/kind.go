@@ -0,0 +1,15 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The type Kind is declared in both the package and its external test
+// package, each getting a file of its own.
+
+package xtest
+
+type Kind int
+
+const (
+	Small Kind = iota
+	Large
+)
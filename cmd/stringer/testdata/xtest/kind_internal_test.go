@@ -0,0 +1,13 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xtest
+
+import "testing"
+
+func TestKind(t *testing.T) {
+	if got := Large.String(); got != "Large" {
+		t.Errorf("Large.String() = %q", got)
+	}
+}
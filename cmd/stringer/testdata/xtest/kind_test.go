@@ -0,0 +1,20 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xtest_test
+
+import "testing"
+
+type Kind int
+
+const (
+	Up Kind = iota
+	Down
+)
+
+func TestExternalKind(t *testing.T) {
+	if got := Down.String(); got != "Down" {
+		t.Errorf("Down.String() = %q", got)
+	}
+}
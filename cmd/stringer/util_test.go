@@ -7,71 +7,193 @@
 package main
 
 import (
-	"fmt"
+	"flag"
+	"go/ast"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"golang.org/x/tools/go/loader"
 )
 
-// Helpers to save typing in the test cases.
-type u []uint64
-type uu [][]uint64
+func TestWriteSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "day_string.go")
+	original := "package p\n\nconst day = 1\n"
+	if err := ioutil.WriteFile(filename, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check := func(want string) {
+		t.Helper()
+		got, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s contains %q, want %q", filename, got, want)
+		}
+		if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 1 {
+			t.Errorf("files left in %s: %q", dir, files)
+		}
+	}
 
-type SplitTest struct {
-	input  u
-	output uu
-	signed bool
+	src := "package p\n\nconst day = 2\n"
+	if err := writeSource(filename, []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	check(src)
 }
 
-var (
-	m2  = uint64(2)
-	m1  = uint64(1)
-	m0  = uint64(0)
-	m_1 = ^uint64(0)     // -1 when signed.
-	m_2 = ^uint64(0) - 1 // -2 when signed.
-)
+func TestCommandLine(t *testing.T) {
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-type", "Day"}, "stringer -type=Day"},
+		// Flags in order, paths relative to dir, unrecorded flags left out.
+		{
+			[]string{"-v", "-type=Day", "-diff", "-linecomment", "-output", filepath.Join(dir, "day_string.go"), dir},
+			"stringer -linecomment -output=day_string.go -type=Day",
+		},
+		{
+			[]string{"-type=Day", "-trimprefix", "Day Of", "-tablecommon=", "-linecomment=false", "testdata/day.go"},
+			`stringer -linecomment=false -tablecommon= "-trimprefix=Day Of" -type=Day day.go`,
+		},
+		{[]string{"-type=Day", "./testdata/...", "fmt"}, "stringer -type=Day ./... fmt"},
+		// Files in the order of their names, paths outside of dir relative to it.
+		{
+			[]string{"-type=Day", "-output", filepath.Join(dir, "..", "gen", "day.go"), "testdata/b.go", filepath.Join(dir, "a.go")},
+			"stringer -output=../gen/day.go -type=Day a.go b.go",
+		},
+		{[]string{"-type=Day", "./testdata/../..."}, "stringer -type=Day ../..."},
+		{
+			[]string{"-type=Day", "-header", "Copyright 2026 Acme.\n\n// All rights reserved.\n"},
+			`stringer "-header=Copyright 2026 Acme.\n\n// All rights reserved.\n" -type=Day`,
+		},
+	} {
+		fs := flag.NewFlagSet("stringer", flag.ContinueOnError)
+		for _, name := range []string{"type", "output", "trimprefix", "tablecommon", "header"} {
+			fs.String(name, "", "")
+		}
+		for _, name := range []string{"v", "diff", "linecomment"} {
+			fs.Bool(name, false, "")
+		}
+		if err := fs.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := commandLine(fs, fs.Args(), dir); got != test.want {
+			t.Errorf("%q: got command %s, want %s", test.args, got, test.want)
+		}
+	}
+}
 
-var splitTests = []SplitTest{
-	// No need for a test for the empty case; that's picked off before splitIntoRuns.
-	// Single value.
-	{u{1}, uu{u{1}}, false},
-	// Out of order.
-	{u{3, 2, 1}, uu{u{1, 2, 3}}, true},
-	// Out of order.
-	{u{3, 2, 1}, uu{u{1, 2, 3}}, false},
-	// A gap at the beginning.
-	{u{1, 33, 32, 31}, uu{u{1}, u{31, 32, 33}}, true},
-	// A gap in the middle, in mixed order.
-	{u{33, 7, 32, 31, 9, 8}, uu{u{7, 8, 9}, u{31, 32, 33}}, true},
-	// Gaps throughout
-	{u{33, 44, 1, 32, 45, 31}, uu{u{1}, u{31, 32, 33}, u{44, 45}}, true},
-	// Unsigned values spanning 0.
-	{u{m1, m0, m_1, m2, m_2}, uu{u{m0, m1, m2}, u{m_2, m_1}}, false},
-	// Signed values spanning 0
-	{u{m1, m0, m_1, m2, m_2}, uu{u{m_2, m_1, m0, m1, m2}}, true},
+func TestUniqueTypes(t *testing.T) {
+	for _, test := range []struct {
+		list string
+		want []string
+	}{
+		{"Pill", []string{"Pill"}},
+		{"Pill,Pill", []string{"Pill"}},
+		{"Pill,Day,Pill,Day,Kind", []string{"Pill", "Day", "Kind"}},
+	} {
+		if got := uniqueTypes(test.list); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %q, want %q", test.list, got, test.want)
+		}
+	}
 }
 
-func TestSplitIntoRuns(t *testing.T) {
-Outer:
-	for n, test := range splitTests {
-		values := make([]Value, len(test.input))
-		for i, v := range test.input {
-			values[i] = Value{"", v, test.signed, fmt.Sprint(v)}
+func TestFindTypes(t *testing.T) {
+	conf := loader.Config{}
+	var files [][]*ast.File
+	for _, src := range []string{
+		"package p_test\ntype Day int\ntype Test int\n",
+		"package p\ntype Pill int\ntype Day int\n",
+	} {
+		f, err := conf.ParseFile("p.go", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, []*ast.File{f})
+	}
+	conf.CreateFromFiles("p_test", files[0]...)
+	conf.CreateFromFiles("p", files[1]...)
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs, found, unseen := findTypes(prog.InitialPackages(), []string{"Test", "Month", "Day", "Pill", "Year"})
+	var paths []string
+	for _, info := range pkgs {
+		paths = append(paths, info.Pkg.Path())
+	}
+	if want := []string{"p", "p_test"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got packages %q, want %q", paths, want)
+	}
+	want := [][]string{{"Day", "Pill"}, {"Test", "Day"}}
+	for i, info := range pkgs {
+		if i < len(want) && !reflect.DeepEqual(found[info], want[i]) {
+			t.Errorf("%s: got types %q, want %q", info.Pkg.Path(), found[info], want[i])
 		}
-		runs := splitIntoRuns(values)
-		if len(runs) != len(test.output) {
-			t.Errorf("#%d: %v: got %d runs; expected %d", n, test.input, len(runs), len(test.output))
+	}
+	// In the order listed.
+	if want := []string{"Month", "Year"}; !reflect.DeepEqual(unseen, want) {
+		t.Errorf("got unseen types %q, want %q", unseen, want)
+	}
+}
+
+func TestSplitDirective(t *testing.T) {
+	for _, test := range []struct {
+		line string
+		args []string // nil if not running stringer
+		err  bool
+	}{
+		{"stringer -type=Pill", []string{"-type=Pill"}, false},
+		{"\tstringer  -type Pill\t-output=pill.go", []string{"-type", "Pill", "-output=pill.go"}, false},
+		{`stringer "-linecomment" "-trimprefix=a b"`, []string{"-linecomment", "-trimprefix=a b"}, false},
+		{"../../bin/stringer -type=Pill", []string{"-type=Pill"}, false},
+		{"go run golang.org/x/tools/cmd/stringer@v0.1.0 -type=Pill", []string{"-type=Pill"}, false},
+		{"go run ./stringer.go -type=Pill", nil, false},
+		{"echo stringer -type=Pill", nil, false},
+		{"go", nil, false},
+		{`stringer "-type=Pill`, nil, true},
+		{`stringer "-type"=Pill`, nil, true},
+	} {
+		words, err := splitDirective(test.line)
+		if (err != nil) != test.err {
+			t.Errorf("%q: got error %v", test.line, err)
 			continue
 		}
-		for i, run := range runs {
-			if len(run) != len(test.output[i]) {
-				t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
-				continue Outer
-			}
-			for j, v := range run {
-				if v.value != test.output[i][j] {
-					t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
-					continue Outer
-				}
-			}
+		args, ok := stringerArgs(words)
+		if ok != (test.args != nil) || ok && !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%q: got %q, %v; want %q", test.line, args, ok, test.args)
+		}
+	}
+}
+
+func TestSetsFromDirective(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-type=Pill"}, false},
+		{[]string{"-type=Pill", "-fromdirective"}, true},
+		{[]string{"--fromdirective=false"}, true},
+		{[]string{"-fromdirectives"}, false},
+		{[]string{"fromdirective"}, false},
+	} {
+		if got := setsFromDirective(test.args); got != test.want {
+			t.Errorf("%q: got %v, want %v", test.args, got, test.want)
 		}
 	}
 }
@@ -80,6 +80,8 @@ type Node struct {
 	ID   int           // 0-based sequence number
 	In   []*Edge       // unordered set of incoming call edges (n.In[*].Callee == n)
 	Out  []*Edge       // unordered set of outgoing call edges (n.Out[*].Caller == n)
+
+	callees map[*Node]int // number of edges of n.Out by callee, maintained by AddEdge
 }
 
 func (n *Node) String() string {
@@ -126,4 +128,8 @@ func AddEdge(caller *Node, site ssa.CallInstruction, callee *Node) {
 	e := &Edge{caller, site, callee}
 	callee.In = append(callee.In, e)
 	caller.Out = append(caller.Out, e)
+	if caller.callees == nil {
+		caller.callees = make(map[*Node]int)
+	}
+	caller.callees[callee]++
 }
@@ -4,7 +4,11 @@
 
 package callgraph
 
-import "golang.org/x/tools/go/ssa"
+import (
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
 
 // This file provides various utilities over call graphs, such as
 // visitation and path search.
@@ -78,6 +82,119 @@ func PathSearch(start *Node, isEnd func(*Node) bool) []*Edge {
 	return search(start)
 }
 
+// ShortestPath finds a shortest path of calls from function from to
+// function to, using breadth-first search.  On success, it returns the
+// path as an ordered list of edges and true; the path is empty if from
+// and to are the same function.  It returns nil and false if either
+// function is not in the graph or to is unreachable from from.
+//
+func (g *Graph) ShortestPath(from, to *ssa.Function) ([]*Edge, bool) {
+	start, end := g.Nodes[from], g.Nodes[to]
+	if start == nil || end == nil {
+		return nil, false
+	}
+	// via[n] is the edge by which n was first reached.
+	via := map[*Node]*Edge{start: nil}
+	queue := []*Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == end {
+			var path []*Edge
+			for e := via[n]; e != nil; e = via[e.Caller] {
+				path = append(path, e)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, true
+		}
+		for _, e := range n.Out {
+			if _, ok := via[e.Callee]; !ok {
+				via[e.Callee] = e
+				queue = append(queue, e.Callee)
+			}
+		}
+	}
+	return nil, false
+}
+
+// Callers returns the distinct nodes that call function fn, in order
+// of node ID.  It returns nil if fn is not in the graph.
+//
+func (g *Graph) Callers(fn *ssa.Function) []*Node {
+	n := g.Nodes[fn]
+	if n == nil {
+		return nil
+	}
+	callers := make(map[*Node]bool)
+	for _, e := range n.In {
+		callers[e.Caller] = true
+	}
+	return sortedNodes(callers)
+}
+
+// Callees returns the distinct nodes called by function fn, in order
+// of node ID.  It returns nil if fn is not in the graph.
+//
+func (g *Graph) Callees(fn *ssa.Function) []*Node {
+	n := g.Nodes[fn]
+	if n == nil {
+		return nil
+	}
+	return sortedNodes(CalleesOf(n))
+}
+
+// HasEdge reports whether the graph has an edge from function caller
+// to function callee.  It looks the callee up in the index of callees
+// that AddEdge keeps for each node, rather than scanning the edges.
+//
+func (g *Graph) HasEdge(caller, callee *ssa.Function) bool {
+	n, m := g.Nodes[caller], g.Nodes[callee]
+	if n == nil || m == nil {
+		return false
+	}
+	return n.callees[m] > 0
+}
+
+// Visit calls f once for each distinct (caller, callee) pair of the
+// graph, ordered by caller node ID and then by callee node ID, so the
+// order does not depend on map iteration.  If f returns false,
+// visitation stops.
+//
+func (g *Graph) Visit(f func(caller, callee *Node) bool) {
+	all := make(map[*Node]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		all[n] = true
+	}
+	for _, caller := range sortedNodes(all) {
+		for _, callee := range sortedNodes(CalleesOf(caller)) {
+			if !f(caller, callee) {
+				return
+			}
+		}
+	}
+}
+
+// sortedNodes returns the nodes of the set in order of node ID.
+func sortedNodes(set map[*Node]bool) []*Node {
+	if len(set) == 0 {
+		return nil
+	}
+	nodes := make([]*Node, 0, len(set))
+	for n := range set {
+		nodes = append(nodes, n)
+	}
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []*Node
+
+func (s byID) Len() int           { return len(s) }
+func (s byID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+func (s byID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 // DeleteSyntheticNodes removes from call graph g all nodes for
 // synthetic functions (except g.Root and package initializers),
 // preserving the topology.  In effect, calls to synthetic wrappers
@@ -146,6 +263,7 @@ func (n *Node) deleteOuts() {
 		removeInEdge(e)
 	}
 	n.Out = nil
+	n.callees = nil
 }
 
 // removeOutEdge removes edge.Caller's outgoing edge 'edge'.
@@ -158,6 +276,9 @@ func removeOutEdge(edge *Edge) {
 			caller.Out[i] = caller.Out[n-1]
 			caller.Out[n-1] = nil // aid GC
 			caller.Out = caller.Out[:n-1]
+			if caller.callees[edge.Callee]--; caller.callees[edge.Callee] == 0 {
+				delete(caller.callees, edge.Callee)
+			}
 			return
 		}
 	}
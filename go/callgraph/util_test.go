@@ -0,0 +1,198 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const input = `package main
+
+func main() {}
+func a()    {}
+func b()    {}
+func c()    {}
+func d()    {}
+`
+
+// testGraph returns a hand-built call graph over the functions of
+// input, containing the cycle a -> b -> a:
+//
+//	main -> a, a -> b, b -> a, b -> c, a -> c (twice), c -> c
+//
+// Function d is in the graph but neither calls nor is called.
+func testGraph(t *testing.T) (*callgraph.Graph, map[string]*ssa.Function) {
+	var conf loader.Config
+	f, err := conf.ParseFile("input.go", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("main", f)
+	iprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(iprog, 0)
+	pkg := prog.Package(iprog.Created[0].Pkg)
+
+	funcs := make(map[string]*ssa.Function)
+	for _, name := range []string{"main", "a", "b", "c", "d"} {
+		funcs[name] = pkg.Func(name)
+	}
+	g := callgraph.New(funcs["main"])
+	for _, name := range []string{"a", "b", "c", "d"} {
+		g.CreateNode(funcs[name])
+	}
+	for _, e := range []struct{ caller, callee string }{
+		{"main", "a"},
+		{"a", "b"},
+		{"b", "a"},
+		{"b", "c"},
+		{"a", "c"},
+		{"a", "c"},
+		{"c", "c"},
+	} {
+		callgraph.AddEdge(g.Nodes[funcs[e.caller]], nil, g.Nodes[funcs[e.callee]])
+	}
+	return g, funcs
+}
+
+// names returns the space-separated function names of the nodes.
+func names(nodes []*callgraph.Node) string {
+	var s []string
+	for _, n := range nodes {
+		s = append(s, n.Func.Name())
+	}
+	return strings.Join(s, " ")
+}
+
+func TestShortestPath(t *testing.T) {
+	g, funcs := testGraph(t)
+	for _, test := range []struct {
+		from, to string
+		want     string // path as caller->callee edges; "-" if not found
+	}{
+		{"main", "c", "main->a a->c"}, // shortest, not main->a->b->c
+		{"main", "b", "main->a a->b"},
+		{"b", "b", ""},
+		{"b", "a", "b->a"}, // follows the cycle
+		{"c", "c", ""},
+		{"c", "a", "-"},
+		{"d", "a", "-"},
+		{"main", "d", "-"},
+	} {
+		path, ok := g.ShortestPath(funcs[test.from], funcs[test.to])
+		got := "-"
+		if ok {
+			var edges []string
+			for _, e := range path {
+				edges = append(edges, e.Caller.Func.Name()+"->"+e.Callee.Func.Name())
+			}
+			got = strings.Join(edges, " ")
+		}
+		if got != test.want {
+			t.Errorf("ShortestPath(%s, %s) = %q, want %q", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestCallersCallees(t *testing.T) {
+	g, funcs := testGraph(t)
+	for _, test := range []struct {
+		fn               string
+		callers, callees string
+	}{
+		{"main", "", "a"},
+		{"a", "main b", "b c"},
+		{"b", "a", "a c"},
+		{"c", "a b c", "c"},
+		{"d", "", ""},
+	} {
+		if got := names(g.Callers(funcs[test.fn])); got != test.callers {
+			t.Errorf("Callers(%s) = %q, want %q", test.fn, got, test.callers)
+		}
+		if got := names(g.Callees(funcs[test.fn])); got != test.callees {
+			t.Errorf("Callees(%s) = %q, want %q", test.fn, got, test.callees)
+		}
+	}
+}
+
+func TestHasEdge(t *testing.T) {
+	g, funcs := testGraph(t)
+	for _, test := range []struct {
+		caller, callee string
+		want           bool
+	}{
+		{"main", "a", true},
+		{"a", "main", false},
+		{"a", "c", true}, // two edges
+		{"b", "a", true},
+		{"c", "c", true},
+		{"main", "c", false}, // not direct
+		{"d", "d", false},
+	} {
+		if got := g.HasEdge(funcs[test.caller], funcs[test.callee]); got != test.want {
+			t.Errorf("HasEdge(%s, %s) = %t, want %t", test.caller, test.callee, got, test.want)
+		}
+	}
+}
+
+// TestHasEdgeDeleteNode checks that deleting a node removes its edges
+// from the index of HasEdge, both as caller and as callee.
+func TestHasEdgeDeleteNode(t *testing.T) {
+	g, funcs := testGraph(t)
+	g.DeleteNode(g.Nodes[funcs["b"]])
+	for _, test := range []struct {
+		caller, callee string
+		want           bool
+	}{
+		{"main", "a", true},
+		{"a", "b", false},
+		{"b", "a", false},
+		{"b", "c", false},
+		{"a", "c", true},
+	} {
+		if got := g.HasEdge(funcs[test.caller], funcs[test.callee]); got != test.want {
+			t.Errorf("HasEdge(%s, %s) = %t, want %t", test.caller, test.callee, got, test.want)
+		}
+	}
+	// Re-adding the edge makes it visible again.
+	b := g.CreateNode(funcs["b"])
+	callgraph.AddEdge(g.Nodes[funcs["a"]], nil, b)
+	if !g.HasEdge(funcs["a"], funcs["b"]) {
+		t.Errorf("HasEdge(a, b) = false after AddEdge, want true")
+	}
+}
+
+func TestVisit(t *testing.T) {
+	g, _ := testGraph(t)
+	var pairs []string
+	g.Visit(func(caller, callee *callgraph.Node) bool {
+		pairs = append(pairs, fmt.Sprintf("%s->%s", caller.Func.Name(), callee.Func.Name()))
+		return true
+	})
+	got := strings.Join(pairs, " ")
+	want := "main->a a->b a->c b->a b->c c->c"
+	if got != want {
+		t.Errorf("Visit: got %q, want %q", got, want)
+	}
+
+	// Visitation stops when the function returns false.
+	n := 0
+	g.Visit(func(caller, callee *callgraph.Node) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Errorf("Visit did not stop: %d calls, want 3", n)
+	}
+}
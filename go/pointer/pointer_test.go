@@ -14,16 +14,25 @@ package pointer_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"go/ast"
 	"go/token"
 	"go/types"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/loader"
@@ -33,6 +42,11 @@ import (
 	"golang.org/x/tools/go/types/typeutil"
 )
 
+var (
+	strictExpect = flag.Bool("strictexpect", false, "Report the print() calls of the inputs with no expectation, and the expectations not checked")
+	jsonResults  = flag.String("jsonresults", "", "Write the result of each expectation of the inputs, as JSON, to this file")
+)
+
 var inputs = []string{
 	"testdata/a_test.go",
 	"testdata/another.go",
@@ -53,22 +67,47 @@ var inputs = []string{
 	"testdata/issue9002.go",
 	"testdata/mapreflect.go",
 	"testdata/maps.go",
+	"testdata/multifile.go",
+	"testdata/nocalls.go",
 	"testdata/panic.go",
+	"testdata/probes.go",
 	"testdata/recur.go",
 	"testdata/reflect.go",
 	"testdata/rtti.go",
 	"testdata/structreflect.go",
 	"testdata/structs.go",
+	"testdata/warnings.go",
 	// "testdata/timer.go", // TODO(adonovan): fix broken assumptions about runtime timers
 }
 
+// An input may hold several files of package main, each following a
+// line "// +file: name.go".  The positions in the file name.go of the
+// input testdata/x.go are those of the file testdata/x.go/name.go, e.g.
+// in labels.
+
 // Expectation grammar:
 //
 // @calls f -> g
 //
 //   A 'calls' expectation asserts that edge (f, g) appears in the
 //   callgraph.  f and g are notated as per Function.String(), which
-//   may contain spaces (e.g. promoted method in anon struct).
+//   may contain spaces (e.g. promoted method in anon struct).  They
+//   must name functions of the program, checked before the analysis
+//   runs, or <root> for the root of the callgraph.
+//
+// @nocalls f -> g
+//
+//   A 'nocalls' expectation asserts that edge (f, g) does not appear
+//   in the callgraph, e.g. to check that a dynamic call cannot reach
+//   an impossible callee.  f and g are notated as for @calls.  If f is
+//   not in the callgraph at all, the expectation holds trivially, and
+//   a note is logged.
+//
+// @callees f -> g | h | i
+//
+//   A 'callees' expectation asserts that the callees of f in the
+//   callgraph are exactly {g,h,i}, notated as for @calls.  If one of
+//   them is "...", it asserts that f calls at least the others.
 //
 // @pointsto a | b | c
 //
@@ -82,6 +121,13 @@ var inputs = []string{
 //   If one of the strings is "...", the expectation asserts that the
 //   points-to set at least the other labels.
 //
+//   A line may hold several print(x) statements.  Their expectations
+//   are then numbered by the order of the calls on the line, as in
+//   'print(x); print(y) // @pointsto#1 a // @pointsto#2 b', or, if
+//   there is one for each call, follow the order of the calls.
+//   This holds for all the expectations on print(x) statements.  The
+//   expectations of a line with one print(x) call are all about it.
+//
 //   We use '|' because label names may contain spaces, e.g.  methods
 //   of anonymous structs.
 //
@@ -89,6 +135,15 @@ var inputs = []string{
 //   labels too, but they are represented differently and so have a
 //   different expectation, @types, below.
 //
+// @size n
+//
+//   A 'size' expectation asserts that the points-to set of its operand
+//   has exactly n labels, without naming them, e.g. to guard the
+//   precision of a set whose labels hold fragile positions.  It must
+//   appear on the same line as a print(x) statement, as for
+//   'pointsto'; it may follow one, as in
+//   'print(x) // @pointsto main.a | main.b // @size 2'.
+//
 // @types t | u | v
 //
 //   A 'types' expectation asserts that the set of possible dynamic
@@ -109,8 +164,13 @@ var inputs = []string{
 // @warning "regexp"
 //
 //   A 'warning' expectation asserts that the analysis issues a
-//   warning that matches the regular expression within the string
-//   literal.
+//   warning at the current line that matches the regular expression
+//   within the string literal.
+//
+// @warning@any "regexp"
+//
+//   The same, for a warning at any position, e.g. one whose position
+//   is in another package.
 //
 // @line id
 //
@@ -121,59 +181,128 @@ var inputs = []string{
 //   (NB, anon functions still include line numbers.)
 //
 type expectation struct {
-	kind     string // "pointsto" | "pointstoquery" | "types" | "calls" | "warning"
+	kind     string // "pointsto" | "pointstoquery" | "size" | "types" | "calls" | "nocalls" | "callees" | "warning"
 	filename string
 	linenum  int // source line number, 1-based
 	args     []string
 	query    string           // extended query
 	extended *pointer.Pointer // extended query pointer
 	types    []types.Type     // for types
+	size     int              // for size
+	caller   *ssa.Function    // for calls, nocalls and callees; nil for <root>
+	callee   *ssa.Function    // for calls and nocalls
+	callees  []*ssa.Function  // for callees, but "..."
+	ordinal  int              // of the print(x) call on the line, 1-based; 0 if not given
+	byOrder  int              // if not 0, ordinal is by order of byOrder expectations on the line
+	anyLine  bool             // for warning: the warning may be anywhere, not on this line
+	failures []string         // the errors reported about the expectation
 }
 
 func (e *expectation) String() string {
+	if e.anyLine {
+		return fmt.Sprintf("@%s@any[%s]", e.kind, strings.Join(e.args, " | "))
+	}
+	if e.ordinal != 0 && e.byOrder == 0 {
+		return fmt.Sprintf("@%s#%d[%s]", e.kind, e.ordinal, strings.Join(e.args, " | "))
+	}
 	return fmt.Sprintf("@%s[%s]", e.kind, strings.Join(e.args, " | "))
 }
 
-func (e *expectation) errorf(format string, args ...interface{}) {
-	fmt.Printf("%s:%d: ", e.filename, e.linenum)
-	fmt.Printf(format, args...)
-	fmt.Println()
+func (e *expectation) errorf(t testing.TB, format string, args ...interface{}) {
+	t.Helper()
+	msg := fmt.Sprintf(format, args...)
+	e.failures = append(e.failures, msg)
+	t.Errorf("%s:%d: %s", e.filename, e.linenum, msg)
 }
 
 func (e *expectation) needsProbe() bool {
-	return e.kind == "pointsto" || e.kind == "pointstoquery" || e.kind == "types"
+	return e.kind == "pointsto" || e.kind == "pointstoquery" || e.kind == "size" || e.kind == "types"
 }
 
-// Find probe (call to print(x)) of same source file/line as expectation.
-func findProbe(prog *ssa.Program, probes map[*ssa.CallCommon]bool, queries map[ssa.Value]pointer.Pointer, e *expectation) (site *ssa.CallCommon, pts pointer.PointsToSet) {
+// Find probe (call to print(x)) of same source file/line as expectation,
+// the one of its ordinal if the line has several, ordered by column.
+// It returns nil if there is none, e.g. analysis didn't reach this call,
+// and an error if the expectation does not tell which of several it is.
+func findProbe(prog *ssa.Program, probes map[*ssa.CallCommon]bool, e *expectation) (*ssa.CallCommon, error) {
+	var onLine []*ssa.CallCommon
 	for call := range probes {
 		pos := prog.Fset.Position(call.Pos())
 		if pos.Line == e.linenum && pos.Filename == e.filename {
-			// TODO(adonovan): send this to test log (display only on failure).
-			// fmt.Printf("%s:%d: info: found probe for %s: %s\n",
-			// 	e.filename, e.linenum, e, p.arg0) // debugging
-			return call, queries[call.Args[0]].PointsTo()
+			onLine = append(onLine, call)
 		}
 	}
-	return // e.g. analysis didn't reach this call
+	sort.Slice(onLine, func(i, j int) bool { return onLine[i].Pos() < onLine[j].Pos() })
+	if len(onLine) == 0 {
+		return nil, nil
+	}
+	if e.byOrder != 0 {
+		switch len(onLine) {
+		case 1:
+			return onLine[0], nil // all the expectations are about it
+		case e.byOrder:
+			return onLine[e.ordinal-1], nil
+		}
+		return nil, fmt.Errorf("%d expectations for the %d print() calls on the line; number them, as @%s#2", e.byOrder, len(onLine), e.kind)
+	}
+	switch {
+	case e.ordinal == 0 && len(onLine) > 1:
+		return nil, fmt.Errorf("ambiguous expectation %s for the %d print() calls on the line; number it, as @%s#2", e, len(onLine), e.kind)
+	case e.ordinal > len(onLine):
+		return nil, fmt.Errorf("no print() call #%d on the line, only %d", e.ordinal, len(onLine))
+	case e.ordinal == 0:
+		return onLine[0], nil
+	}
+	// TODO(adonovan): send this to test log (display only on failure).
+	// fmt.Printf("%s:%d: info: found probe for %s: %s\n",
+	// 	e.filename, e.linenum, e, p.arg0) // debugging
+	return onLine[e.ordinal-1], nil
+}
+
+// A section is a file of an input.
+type section struct {
+	filename string // of the input, or input/name.go for a +file section
+	text     string
+}
+
+var fileSeparator = regexp.MustCompile(`(?m)^// \+file: (\S+)\n`)
+
+// splitSections splits an input, the contents of filename, into the
+// files that lines "// +file: name.go" separate, the first one
+// keeping the filename of the input.
+func splitSections(filename, input string) []section {
+	var sections []section
+	start, name := 0, filename
+	for _, loc := range fileSeparator.FindAllStringSubmatchIndex(input, -1) {
+		sections = append(sections, section{name, input[start:loc[0]]})
+		start, name = loc[1], filename+"/"+input[loc[2]:loc[3]]
+	}
+	return append(sections, section{name, input[start:]})
 }
 
-func doOneInput(input, filename string) bool {
+// doOneInput checks the expectations of the input, the contents of
+// filename, and returns the result of each. It fails the input if its
+// analysis takes over budget.
+func doOneInput(t testing.TB, input, filename string, budget time.Duration) *inputResult {
 	var conf loader.Config
 
 	// Parsing.
-	f, err := conf.ParseFile(filename, input)
-	if err != nil {
-		fmt.Println(err)
-		return false
+	sections := splitSections(filename, input)
+	var files []*ast.File
+	for _, sec := range sections {
+		f, err := conf.ParseFile(sec.filename, sec.text)
+		if err != nil {
+			t.Error(err)
+			return &inputResult{File: filename, Log: err.Error()}
+		}
+		files = append(files, f)
 	}
 
-	// Create single-file main package and import its dependencies.
-	conf.CreateFromFiles("main", f)
+	// Create main package of the files and import its dependencies.
+	conf.CreateFromFiles("main", files...)
 	iprog, err := conf.Load()
 	if err != nil {
-		fmt.Println(err)
-		return false
+		t.Error(err)
+		return &inputResult{File: filename, Log: err.Error()}
 	}
 	mainPkgInfo := iprog.Created[0].Pkg
 
@@ -191,8 +320,11 @@ func doOneInput(input, filename string) bool {
 	// Find all calls to the built-in print(x).  Analytically,
 	// print is a no-op, but it's a convenient hook for testing
 	// the PTS of an expression, so our tests use it.
+	// Index the functions by name, for @calls.
 	probes := make(map[*ssa.CallCommon]bool)
+	funcs := make(map[string][]*ssa.Function)
 	for fn := range ssautil.AllFunctions(prog) {
+		funcs[fn.String()] = append(funcs[fn.String()], fn)
 		if fn.Pkg == mainpkg {
 			for _, b := range fn.Blocks {
 				for _, instr := range b.Instrs {
@@ -213,82 +345,157 @@ func doOneInput(input, filename string) bool {
 
 	// Parse expectations in this input.
 	var exps []*expectation
-	re := regexp.MustCompile("// *@([a-z]*) *(.*)$")
-	lines := strings.Split(input, "\n")
-	for linenum, line := range lines {
-		linenum++ // make it 1-based
-		if matches := re.FindAllStringSubmatch(line, -1); matches != nil {
-			match := matches[0]
-			kind, rest := match[1], match[2]
-			e := &expectation{kind: kind, filename: filename, linenum: linenum}
-
-			if kind == "line" {
-				if rest == "" {
+	var parsed []*expectation // exps, and those in error
+	re := regexp.MustCompile("// *@([a-z]*)(@any)?(?:#([0-9]+))? *((?:[^/]|/[^/])*)")
+	for _, sec := range sections {
+		lines := strings.Split(sec.text, "\n")
+		for linenum, line := range lines {
+			linenum++                 // make it 1-based
+			var onLine []*expectation // needing a probe
+			for _, match := range re.FindAllStringSubmatch(line, -1) {
+				kind, rest := match[1], strings.TrimSpace(match[4])
+				e := &expectation{kind: kind, filename: sec.filename, linenum: linenum, anyLine: match[2] != ""}
+				parsed = append(parsed, e)
+				if e.anyLine && kind != "warning" {
 					ok = false
-					e.errorf("@%s expectation requires identifier", kind)
-				} else {
-					lineMapping[fmt.Sprintf("%s:%d", filename, linenum)] = rest
+					e.errorf(t, "@%s@any: only @warning expectations may be anywhere", kind)
+					continue
+				}
+				if match[3] != "" {
+					e.ordinal, _ = strconv.Atoi(match[3])
+					if e.ordinal == 0 || !e.needsProbe() {
+						ok = false
+						e.errorf(t, "@%s#%s: only the expectations of print(x) calls are numbered, from 1", kind, match[3])
+						continue
+					}
 				}
-				continue
-			}
-
-			if e.needsProbe() && !strings.Contains(line, "print(") {
-				ok = false
-				e.errorf("@%s expectation must follow call to print(x)", kind)
-				continue
-			}
 
-			switch kind {
-			case "pointsto":
-				e.args = split(rest, "|")
-
-			case "pointstoquery":
-				args := strings.SplitN(rest, " ", 2)
-				e.query = args[0]
-				e.args = split(args[1], "|")
-			case "types":
-				for _, typstr := range split(rest, "|") {
-					var t types.Type = types.Typ[types.Invalid] // means "..."
-					if typstr != "..." {
-						tv, err := types.Eval(prog.Fset, mainpkg.Pkg, f.Pos(), typstr)
-						if err != nil {
-							ok = false
-							// Don't print err since its location is bad.
-							e.errorf("'%s' is not a valid type: %s", typstr, err)
-							continue
-						}
-						t = tv.Type
+				if kind == "line" {
+					if rest == "" {
+						ok = false
+						e.errorf(t, "@%s expectation requires identifier", kind)
+					} else {
+						lineMapping[fmt.Sprintf("%s:%d", sec.filename, linenum)] = rest
 					}
-					e.types = append(e.types, t)
+					continue
 				}
 
-			case "calls":
-				e.args = split(rest, "->")
-				// TODO(adonovan): eagerly reject the
-				// expectation if fn doesn't denote
-				// existing function, rather than fail
-				// the expectation after analysis.
-				if len(e.args) != 2 {
+				if e.needsProbe() && !strings.Contains(line, "print(") {
 					ok = false
-					e.errorf("@calls expectation wants 'caller -> callee' arguments")
+					e.errorf(t, "@%s expectation must follow call to print(x)", kind)
 					continue
 				}
 
-			case "warning":
-				lit, err := strconv.Unquote(strings.TrimSpace(rest))
-				if err != nil {
+				switch kind {
+				case "pointsto":
+					e.args = split(rest, "|")
+
+				case "size":
+					n, err := strconv.Atoi(rest)
+					if err != nil || n < 0 {
+						ok = false
+						e.errorf(t, "@size expectation wants a number of labels, got %q", rest)
+						continue
+					}
+					e.size = n
+
+				case "pointstoquery":
+					args := strings.SplitN(rest, " ", 2)
+					e.query = args[0]
+					e.args = split(args[1], "|")
+				case "types":
+					for _, typstr := range split(rest, "|") {
+						var typ types.Type = types.Typ[types.Invalid] // means "..."
+						if typstr != "..." {
+							tv, err := types.Eval(prog.Fset, mainpkg.Pkg, files[0].Pos(), typstr)
+							if err != nil {
+								ok = false
+								// Don't print err since its location is bad.
+								e.errorf(t, "'%s' is not a valid type: %s", typstr, err)
+								continue
+							}
+							typ = tv.Type
+						}
+						e.types = append(e.types, typ)
+					}
+
+				case "calls", "nocalls":
+					e.args = split(rest, "->")
+					if len(e.args) != 2 {
+						ok = false
+						e.errorf(t, "@%s expectation wants 'caller -> callee' arguments", kind)
+						continue
+					}
+					var err error
+					if e.caller, err = lookupFunc(funcs, e.args[0]); err == nil {
+						e.callee, err = lookupFunc(funcs, e.args[1])
+					}
+					if err != nil {
+						ok = false
+						e.errorf(t, "%s", err)
+						continue
+					}
+
+				case "callees":
+					args := split(rest, "->")
+					if len(args) != 2 {
+						ok = false
+						e.errorf(t, "@%s expectation wants 'caller -> callee | ...' arguments", kind)
+						continue
+					}
+					e.args = append([]string{args[0]}, split(args[1], "|")...)
+					var err error
+					e.caller, err = lookupFunc(funcs, e.args[0])
+					for _, name := range e.args[1:] {
+						if err != nil {
+							break
+						}
+						if name != "..." {
+							var callee *ssa.Function
+							callee, err = lookupFunc(funcs, name)
+							e.callees = append(e.callees, callee)
+						}
+					}
+					if err != nil {
+						ok = false
+						e.errorf(t, "%s", err)
+						continue
+					}
+
+				case "warning":
+					lit, err := strconv.Unquote(strings.TrimSpace(rest))
+					if err != nil {
+						ok = false
+						e.errorf(t, "couldn't parse @warning operand: %s", err.Error())
+						continue
+					}
+					e.args = append(e.args, lit)
+
+				default:
 					ok = false
-					e.errorf("couldn't parse @warning operand: %s", err.Error())
+					e.errorf(t, "unknown expectation kind: %s", e)
 					continue
 				}
-				e.args = append(e.args, lit)
-
-			default:
-				ok = false
-				e.errorf("unknown expectation kind: %s", e)
-				continue
+				exps = append(exps, e)
+				if e.needsProbe() {
+					onLine = append(onLine, e)
+				}
+			}
+			// Unnumbered expectations of several print(x) calls
+			// on the line follow their order.
+			if len(onLine) > 1 {
+				numbered := false
+				for _, e := range onLine {
+					if e.ordinal != 0 {
+						numbered = true
+					}
+				}
+				if !numbered {
+					for i, e := range onLine {
+						e.ordinal, e.byOrder = i+1, len(onLine)
+					}
+				}
 			}
-			exps = append(exps, e)
 		}
 	}
 
@@ -305,9 +512,11 @@ func doOneInput(input, filename string) bool {
 probeLoop:
 	for probe := range probes {
 		v := probe.Args[0]
-		pos := prog.Fset.Position(probe.Pos())
 		for _, e := range exps {
-			if e.linenum == pos.Line && e.filename == pos.Filename && e.kind == "pointstoquery" {
+			if e.kind != "pointstoquery" {
+				continue
+			}
+			if site, _ := findProbe(prog, probes, e); site == probe {
 				var err error
 				e.extended, err = config.AddExtendedQuery(v, e.query)
 				if err != nil {
@@ -325,65 +534,248 @@ probeLoop:
 	complete := false
 	defer func() {
 		if !complete || !ok {
-			log.WriteTo(os.Stderr)
+			t.Log(log.String())
 		}
 	}()
 
-	result, err := pointer.Analyze(config)
+	result, elapsed, err := analyze(config, budget)
+	if err == errOverBudget {
+		// The analysis may still be writing to the log.
+		complete = true
+		t.Errorf("%s: the analysis took %s, over the budget of %s", filename, elapsed, budget)
+		return &inputResult{File: filename, Log: err.Error(), Duration: elapsed}
+	}
 	if err != nil {
 		panic(err) // internal error in pointer analysis
 	}
+	stats := analysisStats(log.String())
+	t.Logf("%s: analysis took %s for %d constraints, %d nodes and %d points-to sets",
+		filename, elapsed, stats["constraints"], stats["nodes"], stats["ptsets"])
 
 	// Check the expectations.
+	consumed := make(map[*ssa.CallCommon]bool) // probes of the expectations
+	ran := make(map[*expectation]bool)
 	for _, e := range exps {
 		var call *ssa.CallCommon
 		var pts pointer.PointsToSet
 		var tProbe types.Type
 		if e.needsProbe() {
-			if call, pts = findProbe(prog, probes, result.Queries, e); call == nil {
+			call, err = findProbe(prog, probes, e)
+			if err != nil {
+				ok = false
+				e.errorf(t, "%s", err)
+				continue
+			}
+			if call == nil {
 				ok = false
-				e.errorf("unreachable print() statement has expectation %s", e)
+				e.errorf(t, "unreachable print() statement has expectation %s", e)
 				continue
 			}
+			consumed[call] = true
+			pts = result.Queries[call.Args[0]].PointsTo()
 			if e.extended != nil {
 				pts = e.extended.PointsTo()
 			}
 			tProbe = call.Args[0].Type()
 			if !pointer.CanPoint(tProbe) {
 				ok = false
-				e.errorf("expectation on non-pointerlike operand: %s", tProbe)
+				e.errorf(t, "expectation on non-pointerlike operand: %s", tProbe)
 				continue
 			}
 		}
 
+		ran[e] = true
 		switch e.kind {
 		case "pointsto", "pointstoquery":
-			if !checkPointsToExpectation(e, pts, lineMapping, prog) {
+			if !checkPointsToExpectation(t, e, pts, lineMapping, prog) {
+				ok = false
+			}
+
+		case "size":
+			if !checkSizeExpectation(t, e, pts, lineMapping, prog) {
 				ok = false
 			}
 
 		case "types":
-			if !checkTypesExpectation(e, pts, tProbe) {
+			if !checkTypesExpectation(t, e, pts, tProbe) {
 				ok = false
 			}
 
 		case "calls":
-			if !checkCallsExpectation(prog, e, result.CallGraph) {
+			if !checkCallsExpectation(t, prog, e, result.CallGraph) {
+				ok = false
+			}
+
+		case "nocalls":
+			if !checkNoCallsExpectation(t, prog, e, result.CallGraph) {
+				ok = false
+			}
+
+		case "callees":
+			if !checkCalleesExpectation(t, e, result.CallGraph) {
 				ok = false
 			}
 
 		case "warning":
-			if !checkWarningExpectation(prog, e, result.Warnings) {
+			if !checkWarningExpectation(t, prog, e, result.Warnings) {
 				ok = false
 			}
 		}
 	}
 
+	if *strictExpect {
+		auditExpectations(t, prog, probes, consumed, exps, ran)
+	}
+
 	complete = true
 
 	// ok = false // debugging: uncomment to always see log
 
-	return ok
+	res := &inputResult{File: filename, OK: ok, Duration: elapsed, Stats: stats}
+	if !ok {
+		res.Log = log.String()
+	}
+	for _, e := range parsed {
+		r := expectResult{File: e.filename, Line: e.linenum, Kind: e.kind, Detail: strings.Join(e.failures, "; ")}
+		switch {
+		case len(e.failures) > 0:
+			r.Status = "fail"
+		case ran[e]:
+			r.Status = "pass"
+		case e.kind == "line":
+			continue // not an expectation
+		default:
+			r.Status = "unchecked"
+		}
+		res.Expectations = append(res.Expectations, r)
+	}
+	return res
+}
+
+// An inputResult is the record of an input in the file of -jsonresults.
+type inputResult struct {
+	File         string         `json:"file"`
+	OK           bool           `json:"ok"`
+	Log          string         `json:"log,omitempty"` // of the analysis, if the input failed
+	Duration     time.Duration  `json:"duration"`      // of the analysis, by its threadClock, in nanoseconds
+	Stats        map[string]int `json:"stats,omitempty"`
+	Expectations []expectResult `json:"expectations"`
+}
+
+// The time the analysis of an input may take, by its threadClock,
+// unless $POINTER_ANALYSIS_BUDGET sets another, e.g. "30s".
+const defaultAnalysisBudget = 2 * time.Minute
+
+// How often analyze checks the time of an analysis against its budget.
+const budgetCheckInterval = 100 * time.Millisecond
+
+func analysisBudget() (time.Duration, error) {
+	s := os.Getenv("POINTER_ANALYSIS_BUDGET")
+	if s == "" {
+		return defaultAnalysisBudget, nil
+	}
+	budget, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid $POINTER_ANALYSIS_BUDGET: %s", err)
+	}
+	return budget, nil
+}
+
+var errOverBudget = errors.New("the analysis went over budget and was abandoned")
+
+// analyze runs the analysis of config on a goroutine locked to its OS
+// thread and returns the result and the time it took by a threadClock
+// of that thread, which, where it counts the CPU time of the thread,
+// the inputs analyzed in parallel do not inflate. Once the time goes
+// over budget, analyze stops waiting and returns errOverBudget; the
+// analysis runs on to no purpose, so the caller must not use the Log
+// of config again.
+func analyze(config *pointer.Config, budget time.Duration) (*pointer.Result, time.Duration, error) {
+	type analysis struct {
+		result  *pointer.Result
+		elapsed time.Duration
+		err     error
+	}
+	clocks := make(chan *threadClock)
+	done := make(chan analysis, 1) // an abandoned analysis must not block
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		clock := startThreadClock()
+		clocks <- clock
+		result, err := pointer.Analyze(config)
+		done <- analysis{result, clock.elapsed(), err}
+	}()
+	clock := <-clocks
+	tick := time.NewTicker(budgetCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case a := <-done:
+			if a.elapsed > budget {
+				return nil, a.elapsed, errOverBudget
+			}
+			return a.result, a.elapsed, a.err
+		case <-tick.C:
+			if elapsed := clock.elapsed(); elapsed > budget {
+				return nil, elapsed, errOverBudget
+			}
+		}
+	}
+}
+
+var statLine = regexp.MustCompile(`(?m)^# ([a-z]+):\t([0-9]+)$`)
+
+// analysisStats returns the sizes of the constraint system in the log
+// of the analysis, by name: "constraints", "nodes" and "ptsets".
+func analysisStats(log string) map[string]int {
+	stats := make(map[string]int)
+	for _, m := range statLine.FindAllStringSubmatch(log, -1) {
+		stats[m[1]], _ = strconv.Atoi(m[2])
+	}
+	return stats
+}
+
+// An expectResult is the record of an expectation of an input.
+type expectResult struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`           // "pass", "fail" or "unchecked"
+	Detail string `json:"detail,omitempty"` // the errors, if it failed
+}
+
+// writeResults writes the results of the inputs, ordered by file, as
+// JSON to filename.
+func writeResults(filename string, results []*inputResult) error {
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+	data, err := json.MarshalIndent(results, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// auditExpectations reports the probes no expectation is about, and the
+// expectations that were not checked, as the probe of their line could
+// not be found.
+func auditExpectations(t testing.TB, prog *ssa.Program, probes, consumed map[*ssa.CallCommon]bool, exps []*expectation, ran map[*expectation]bool) {
+	var stray []token.Pos
+	for call := range probes {
+		if !consumed[call] {
+			stray = append(stray, call.Pos())
+		}
+	}
+	sort.Slice(stray, func(i, j int) bool { return stray[i] < stray[j] })
+	for _, pos := range stray {
+		posn := prog.Fset.Position(pos)
+		t.Errorf("%s:%d: print() call has no expectation", posn.Filename, posn.Line)
+	}
+	for _, e := range exps {
+		if !ran[e] {
+			e.errorf(t, "expectation %s was never checked", e)
+		}
+	}
 }
 
 func labelString(l *pointer.Label, lineMapping map[string]string, prog *ssa.Program) string {
@@ -408,7 +800,7 @@ func labelString(l *pointer.Label, lineMapping map[string]string, prog *ssa.Prog
 	return str
 }
 
-func checkPointsToExpectation(e *expectation, pts pointer.PointsToSet, lineMapping map[string]string, prog *ssa.Program) bool {
+func checkPointsToExpectation(t testing.TB, e *expectation, pts pointer.PointsToSet, lineMapping map[string]string, prog *ssa.Program) bool {
 	expected := make(map[string]int)
 	surplus := make(map[string]int)
 	exact := true
@@ -434,21 +826,34 @@ func checkPointsToExpectation(e *expectation, pts pointer.PointsToSet, lineMappi
 	for _, count := range expected {
 		if count > 0 {
 			ok = false
-			e.errorf("value does not alias these expected labels: %s", join(expected))
+			e.errorf(t, "value does not alias these expected labels: %s", join(expected))
 			break
 		}
 	}
 	for _, count := range surplus {
 		if count > 0 {
 			ok = false
-			e.errorf("value may additionally alias these labels: %s", join(surplus))
+			e.errorf(t, "value may additionally alias these labels: %s", join(surplus))
 			break
 		}
 	}
 	return ok
 }
 
-func checkTypesExpectation(e *expectation, pts pointer.PointsToSet, typ types.Type) bool {
+func checkSizeExpectation(t testing.TB, e *expectation, pts pointer.PointsToSet, lineMapping map[string]string, prog *ssa.Program) bool {
+	labels := pts.Labels()
+	if len(labels) == e.size {
+		return true
+	}
+	names := make(map[string]int)
+	for _, label := range labels {
+		names[labelString(label, lineMapping, prog)]++
+	}
+	e.errorf(t, "value may alias %d labels, not %d: %s", len(labels), e.size, join(names))
+	return false
+}
+
+func checkTypesExpectation(t testing.TB, e *expectation, pts pointer.PointsToSet, typ types.Type) bool {
 	var expected typeutil.Map
 	var surplus typeutil.Map
 	exact := true
@@ -461,7 +866,7 @@ func checkTypesExpectation(e *expectation, pts pointer.PointsToSet, typ types.Ty
 	}
 
 	if !pointer.CanHaveDynamicTypes(typ) {
-		e.errorf("@types expectation requires an interface- or reflect.Value-typed operand, got %s", typ)
+		e.errorf(t, "@types expectation requires an interface- or reflect.Value-typed operand, got %s", typ)
 		return false
 	}
 
@@ -478,75 +883,233 @@ func checkTypesExpectation(e *expectation, pts pointer.PointsToSet, typ types.Ty
 	ok := true
 	if expected.Len() > 0 {
 		ok = false
-		e.errorf("interface cannot contain these types: %s", expected.KeysString())
+		e.errorf(t, "interface cannot contain these types: %s", expected.KeysString())
 	}
 	if surplus.Len() > 0 {
 		ok = false
-		e.errorf("interface may additionally contain these types: %s", surplus.KeysString())
+		e.errorf(t, "interface may additionally contain these types: %s", surplus.KeysString())
 	}
 	return ok
 }
 
-var errOK = errors.New("OK")
+// callerOf returns the function of the caller of a @calls, @nocalls or
+// @callees expectation, that of the root of the callgraph for <root>.
+func callerOf(e *expectation, cg *callgraph.Graph) *ssa.Function {
+	if e.caller != nil {
+		return e.caller
+	}
+	return cg.Root.Func
+}
+
+// calleeNames returns the names of the callees of fn in the callgraph,
+// in order of node ID, separated by " | ".
+func calleeNames(cg *callgraph.Graph, fn *ssa.Function) string {
+	var names []string
+	for _, n := range cg.Callees(fn) {
+		names = append(names, n.Func.String())
+	}
+	return strings.Join(names, " | ")
+}
+
+func checkCallsExpectation(t testing.TB, prog *ssa.Program, e *expectation, cg *callgraph.Graph) bool {
+	caller := callerOf(e, cg)
+	if cg.HasEdge(caller, e.callee) {
+		return true
+	}
+	found := calleeNames(cg, caller)
+	if found == "" {
+		e.errorf(t, "didn't find any calls from %s", e.args[0])
+	}
+	e.errorf(t, "found no call from %s to %s, but only to %s",
+		e.args[0], e.args[1], found)
+	return false
+}
+
+func checkNoCallsExpectation(t testing.TB, prog *ssa.Program, e *expectation, cg *callgraph.Graph) bool {
+	caller := callerOf(e, cg)
+	if cg.Nodes[caller] == nil {
+		t.Logf("%s:%d: info: @nocalls holds trivially: %s is not in the callgraph",
+			e.filename, e.linenum, e.args[0])
+		return true
+	}
+	if cg.HasEdge(caller, e.callee) {
+		e.errorf(t, "found a call from %s to %s; all its callees are %s",
+			e.args[0], e.args[1], calleeNames(cg, caller))
+		return false
+	}
+	return true
+}
 
-func checkCallsExpectation(prog *ssa.Program, e *expectation, cg *callgraph.Graph) bool {
-	found := make(map[string]int)
-	err := callgraph.GraphVisitEdges(cg, func(edge *callgraph.Edge) error {
-		// Name-based matching is inefficient but it allows us to
-		// match functions whose names that would not appear in an
-		// index ("<root>") or which are not unique ("func@1.2").
-		if edge.Caller.Func.String() == e.args[0] {
-			calleeStr := edge.Callee.Func.String()
-			if calleeStr == e.args[1] {
-				return errOK // expectation satisfied; stop the search
+func checkCalleesExpectation(t testing.TB, e *expectation, cg *callgraph.Graph) bool {
+	exact := true
+	for _, name := range e.args[1:] {
+		if name == "..." {
+			exact = false
+		}
+	}
+	expected := make(map[*ssa.Function]bool)
+	for _, callee := range e.callees {
+		expected[callee] = true
+	}
+	caller := callerOf(e, cg)
+	// Report set difference:
+	var missing, surplus []string
+	for _, callee := range e.callees {
+		if !cg.HasEdge(caller, callee) {
+			missing = append(missing, callee.String())
+		}
+	}
+	if exact {
+		for _, n := range cg.Callees(caller) {
+			if !expected[n.Func] {
+				surplus = append(surplus, n.Func.String())
 			}
-			found[calleeStr]++
 		}
-		return nil
+	}
+	if len(missing) > 0 {
+		e.errorf(t, "found no call from %s to these expected callees: %s", e.args[0], strings.Join(missing, " | "))
+	}
+	if len(surplus) > 0 {
+		e.errorf(t, "%s may additionally call these callees: %s", e.args[0], strings.Join(surplus, " | "))
+	}
+	return len(missing) == 0 && len(surplus) == 0
+}
+
+// lookupFunc returns the function of funcs, by Function.String(), of name,
+// or nil for the <root> of the callgraph.  If there is none, the error
+// suggests the closest names.
+func lookupFunc(funcs map[string][]*ssa.Function, name string) (*ssa.Function, error) {
+	if name == "<root>" {
+		return nil, nil
+	}
+	switch fns := funcs[name]; len(fns) {
+	case 0:
+		var names []string
+		for name := range funcs {
+			names = append(names, name)
+		}
+		if near := nearMisses(name, names, 3); len(near) > 0 {
+			return nil, fmt.Errorf("no such function %s; did you mean %s?", name, strings.Join(near, " or "))
+		}
+		return nil, fmt.Errorf("no such function %s", name)
+	case 1:
+		return fns[0], nil
+	default:
+		return nil, fmt.Errorf("%s names %d functions", name, len(fns))
+	}
+}
+
+// nearMisses returns at most n of the names closest to name by edit
+// distance, closest first, leaving out those that share little with it.
+func nearMisses(name string, names []string, n int) []string {
+	dist := make(map[string]int)
+	var near []string
+	for _, s := range names {
+		if d := editDistance(name, s); d <= len(name)/2 {
+			dist[s] = d
+			near = append(near, s)
+		}
+	}
+	sort.Slice(near, func(i, j int) bool {
+		if dist[near[i]] != dist[near[j]] {
+			return dist[near[i]] < dist[near[j]]
+		}
+		return near[i] < near[j]
 	})
-	if err == errOK {
-		return true
+	if len(near) > n {
+		near = near[:n]
 	}
-	if len(found) == 0 {
-		e.errorf("didn't find any calls from %s", e.args[0])
+	return near
+}
+
+// editDistance returns the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
 	}
-	e.errorf("found no call from %s to %s, but only to %s",
-		e.args[0], e.args[1], join(found))
-	return false
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(x, y, z int) int {
+	if y < x {
+		x = y
+	}
+	if z < x {
+		x = z
+	}
+	return x
 }
 
-func checkWarningExpectation(prog *ssa.Program, e *expectation, warnings []pointer.Warning) bool {
-	// TODO(adonovan): check the position part of the warning too?
+func checkWarningExpectation(t testing.TB, prog *ssa.Program, e *expectation, warnings []pointer.Warning) bool {
 	re, err := regexp.Compile(e.args[0])
 	if err != nil {
-		e.errorf("invalid regular expression in @warning expectation: %s", err.Error())
+		e.errorf(t, "invalid regular expression in @warning expectation: %s", err.Error())
 		return false
 	}
 
 	if len(warnings) == 0 {
-		e.errorf("@warning %q expectation, but no warnings", e.args[0])
+		e.errorf(t, "@warning %q expectation, but no warnings", e.args[0])
 		return false
 	}
 
 	for _, w := range warnings {
-		if re.MatchString(w.Message) {
-			return true
+		if !re.MatchString(w.Message) {
+			continue
 		}
+		if !e.anyLine {
+			pos := prog.Fset.Position(w.Pos)
+			if pos.Filename != e.filename || pos.Line != e.linenum {
+				continue
+			}
+		}
+		return true
 	}
 
-	e.errorf("@warning %q expectation not satisfied; found these warnings though:", e.args[0])
+	where := "on this line"
+	if e.anyLine {
+		where = "anywhere"
+	}
+	e.errorf(t, "@warning %q expectation not satisfied %s; found these warnings though:", e.args[0], where)
 	for _, w := range warnings {
-		fmt.Printf("%s: warning: %s\n", prog.Fset.Position(w.Pos), w.Message)
+		t.Logf("%s: warning: %s", prog.Fset.Position(w.Pos), w.Message)
 	}
 	return false
 }
 
+func TestNearMisses(t *testing.T) {
+	names := []string{"main.func1", "main.func1$1", "main.func2", "(*main.T).f", "(*main.T).g$thunk", "fmt.Println"}
+	for _, test := range []struct {
+		name string
+		want []string
+	}{
+		{"main.fucn1", []string{"main.func1", "main.func1$1", "main.func2"}},
+		{"(*main.T).g", []string{"(*main.T).f"}},
+		{"main.func1$1", []string{"main.func1$1", "main.func1", "main.func2"}},
+		{"runtime.SetFinalizer", nil},
+	} {
+		if got := nearMisses(test.name, names, 3); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("nearMisses(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
 func TestInput(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping in short mode; this test requires tons of memory; golang.org/issue/14113")
 	}
-	ok := true
-
 	wd, err := os.Getwd()
 	if err != nil {
 		t.Errorf("os.Getwd: %s", err)
@@ -559,19 +1122,131 @@ func TestInput(t *testing.T) {
 	// make sense of them.
 	fmt.Fprintf(os.Stderr, "Entering directory `%s'\n", wd)
 
+	budget, err := analysisBudget()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var results []*inputResult
+	if *jsonResults != "" {
+		// Cleanup runs when all the parallel subtests are done.
+		t.Cleanup(func() {
+			if err := writeResults(*jsonResults, results); err != nil {
+				t.Error(err)
+			}
+		})
+	}
 	for _, filename := range inputs {
-		content, err := ioutil.ReadFile(filename)
-		if err != nil {
-			t.Errorf("couldn't read file '%s': %s", filename, err)
-			continue
+		filename := filename
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			t.Parallel()
+			content, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("couldn't read file '%s': %s", filename, err)
+			}
+			res := doOneInput(t, string(content), filename, budget)
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		})
+	}
+}
+
+// TestAnalyzeBudget checks that analyze returns the result of an
+// analysis within budget, and errOverBudget for one over.
+func TestAnalyzeBudget(t *testing.T) {
+	var conf loader.Config
+	f, err := conf.ParseFile("main.go", "package main; func main() { main() }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("main", f)
+	iprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(iprog, 0)
+	prog.Build()
+	mainpkg := prog.Package(iprog.Created[0].Pkg)
+
+	for _, test := range []struct {
+		budget time.Duration
+		want   error
+	}{
+		{defaultAnalysisBudget, nil},
+		{0, errOverBudget},
+	} {
+		config := &pointer.Config{Mains: []*ssa.Package{mainpkg}}
+		result, _, err := analyze(config, test.budget)
+		if err != test.want || (err == nil) != (result != nil) {
+			t.Errorf("analyze with a budget of %s: got result %v and error %v, want error %v", test.budget, result, err, test.want)
 		}
+	}
+}
 
-		if !doOneInput(string(content), filename) {
-			ok = false
+// A recorder is a testing.TB that records the errors instead of
+// failing, and drops the log.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Error(args ...interface{}) { r.errors = append(r.errors, fmt.Sprint(args...)) }
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Log(args ...interface{})                 {}
+func (r *recorder) Logf(format string, args ...interface{}) {}
+
+func TestResultsJSON(t *testing.T) {
+	const filename = "testdata/failing.go"
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{TB: t}
+	res := doOneInput(rec, string(content), filename, defaultAnalysisBudget)
+	for _, err := range rec.errors {
+		if !strings.HasPrefix(err, filename+":13: ") {
+			t.Errorf("got error %q, want only those of the forced failure", err)
 		}
 	}
-	if !ok {
-		t.Fail()
+
+	dir, err := ioutil.TempDir("", "pointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "results.json")
+	if err := writeResults(out, []*inputResult{res}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []inputResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d inputs, want 1:\n%s", len(results), data)
+	}
+	got := results[0]
+	if got.File != filename || got.OK || !strings.Contains(got.Log, "Input: "+filename) {
+		t.Errorf("got input %s, ok %t, log %q; want %s, failed, with the analysis log", got.File, got.OK, got.Log, filename)
+	}
+	if got.Duration <= 0 || got.Stats["constraints"] == 0 || got.Stats["nodes"] == 0 {
+		t.Errorf("got duration %s and stats %v, want those of the analysis", got.Duration, got.Stats)
+	}
+	want := []expectResult{
+		{File: filename, Line: 12, Kind: "pointsto", Status: "pass"},
+		{File: filename, Line: 13, Kind: "pointsto", Status: "fail", Detail: "value does not alias these expected labels: main.b; value may additionally alias these labels: main.a"},
+	}
+	if !reflect.DeepEqual(got.Expectations, want) {
+		t.Errorf("got expectations %+v, want %+v", got.Expectations, want)
 	}
 }
 
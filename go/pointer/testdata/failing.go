@@ -0,0 +1,14 @@
+// +build ignore
+
+package main
+
+// An input with a failing expectation, for TestResultsJSON.
+// It is not one of the inputs of TestInput.
+
+var a, b int
+
+func main() {
+	p := &a
+	print(p) // @pointsto main.a
+	print(p) // @pointsto main.b
+}
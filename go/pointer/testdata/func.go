@@ -31,6 +31,7 @@ func func1() {
 // @calls main.func1 -> main.func1$2
 // @calls main.func1 -> main.func1$1
 // @calls main.func1$2 ->  main.func1$1
+// @callees main.func1 -> main.func1$1 | ...
 
 func func2() {
 	var x, y *int
@@ -115,6 +116,7 @@ func func5() {
 // @calls main.func5 -> (*main.T).f
 // @calls main.func5 -> (*main.T).g$thunk
 // @calls main.func5 -> (*main.T).h$thunk
+// @callees main.func5 -> (*main.T).f | (*main.T).g$thunk | (*main.T).h$thunk
 
 func func6() {
 	A := &a
@@ -150,6 +152,8 @@ func func7() {
 	methodExpr := D.f
 	methodExpr(d)
 	// @calls main.func7 -> (main.D).f$thunk
+	// @callees main.func7 -> (main.I).f$bound | (main.D).f$bound | (main.D).f$thunk
+	// @callees (main.D).f$thunk -> (main.D).f
 }
 
 func func8(x ...int) {
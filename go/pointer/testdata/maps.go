@@ -11,16 +11,16 @@ func maps1() {
 	m2 := make(map[*int]*int)   // @line m1m2
 	m2[&b] = &a
 
-	print(m1[nil]) // @pointsto main.b | main.c
+	print(m1[nil]) // @pointsto main.b | main.c // @size 2
 	print(m2[nil]) // @pointsto main.a
 
-	print(m1) // @pointsto makemap@m1m1:21
+	print(m1) // @pointsto makemap@m1m1:21 // @size 1
 	print(m2) // @pointsto makemap@m1m2:12
 
 	m1[&b] = &c
 
 	for k, v := range m1 {
-		print(k) // @pointsto main.a | main.b
+		print(k) // @pointsto main.a | main.b // @size 2
 		print(v) // @pointsto main.b | main.c
 	}
 
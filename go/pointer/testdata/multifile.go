@@ -0,0 +1,35 @@
+// +build ignore
+
+package main
+
+// Test of an input of several files: the globals of this file are
+// initialized from those of the next, of an unexported type.
+
+var a = &x
+var c = t.p
+
+func main() {
+	print(a)   // @pointsto main.x
+	print(b)   // @pointsto new@nb:12
+	print(c)   // @pointsto main.y
+	print(t.p) // @pointsto main.y
+	g()
+}
+
+// +file: b.go
+
+package main
+
+var x, y int
+
+var b = new(int) // @line nb
+
+var t = newT() // @calls main.init -> main.newT
+
+type pair struct{ p, q *int }
+
+func newT() pair { return pair{&y, b} }
+
+func g() {
+	print(t.q) // @pointsto new@nb:12
+}
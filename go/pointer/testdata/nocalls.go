@@ -0,0 +1,62 @@
+// +build ignore
+
+package main
+
+// Tests of @nocalls expectations: the dynamic calls below cannot reach
+// the methods of the types never converted to the interface.
+
+type I interface {
+	f()
+}
+
+type C int
+
+func (*C) f() {}
+
+type D struct{}
+
+func (D) f() {}
+
+type E struct{}
+
+func (*E) f() {}
+
+var unknown bool // defeat dead-code elimination
+
+func callI(i I) {
+	i.f()
+}
+
+// @calls main.callI -> (*main.C).f
+// @calls main.callI -> (main.D).f
+// @nocalls main.callI -> (*main.E).f
+// @nocalls main.callI -> (*main.D).f
+
+func callFunc(fn func()) {
+	fn()
+}
+
+func g() {}
+
+func h() {}
+
+// @calls main.callFunc -> main.g
+// @nocalls main.callFunc -> main.h
+
+func unreached() {
+	h()
+}
+
+// @nocalls main.unreached -> main.h
+
+func main() {
+	var c C
+	callI(&c)
+	callI(D{})
+	callFunc(g)
+	if unknown {
+		h()
+	}
+	var e E
+	e.f()
+}
@@ -0,0 +1,30 @@
+// +build ignore
+
+package main
+
+// Tests of several print(x) probes on one line.
+
+var a, b, c int
+
+var unknown bool // defeat dead-code elimination
+
+func probes1() {
+	x, y := &a, &b
+	if unknown {
+		y = &c
+	}
+	print(x); print(y) // @pointsto#1 main.a // @pointsto#2 main.b | main.c
+	print(y); print(x) // @pointsto#2 main.a // @pointsto#1 main.b | main.c
+	print(x); print(y) // @pointsto main.a // @pointsto main.b | main.c
+}
+
+func probes2() {
+	var i, j interface{} = &a, 1
+	print(i); print(j) // @types *int // @types int
+	print(j); print(i.(*int)) // @types#1 int // @pointsto#2 main.a
+}
+
+func main() {
+	probes1()
+	probes2()
+}
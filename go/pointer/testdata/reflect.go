@@ -18,11 +18,9 @@ func reflectIndirect() {
 
 func reflectNewAt() {
 	var x [8]byte
-	print(reflect.NewAt(reflect.TypeOf(3), unsafe.Pointer(&x)).Interface()) // @types *int
+	print(reflect.NewAt(reflect.TypeOf(3), unsafe.Pointer(&x)).Interface()) // @types *int // @warning "unsound: main.reflectNewAt contains a reflect.NewAt.. call"
 }
 
-// @warning "unsound: main.reflectNewAt contains a reflect.NewAt.. call"
-
 func reflectTypeOf() {
 	t := reflect.TypeOf(3)
 	if unknown {
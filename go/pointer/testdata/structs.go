@@ -16,7 +16,7 @@ func (a A) m1() {
 }
 
 func (a *A) m2() {
-	print(a)   // @pointsto complit.A@struct1s:9
+	print(a)   // @pointsto complit.A@struct1s:9 // @size 1
 	print(a.f) // @pointsto main.p
 }
 
@@ -67,7 +67,7 @@ func structs2() {
 	print(&s.a[0].x) // @pointsto s.a[*].x@s2s:6
 	print(&s.a[0].y) // @pointsto s.a[*].y@s2s:6
 	print(&s.b)      // @pointsto s.b@s2s:6
-	print(&s.b[0])   // @pointsto
+	print(&s.b[0])   // @pointsto // @size 0
 	print(&s.b[0].x) // @pointsto
 	print(&s.b[0].y) // @pointsto
 	print(&s.c)      // @pointsto s.c@s2s:6
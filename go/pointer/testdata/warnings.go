@@ -0,0 +1,23 @@
+// +build ignore
+
+package main
+
+// Tests of @warning expectations: each call to a function without a
+// body is warned about at the call, and at the declaration.
+
+func extern() *int // @warning "declared here"
+
+func f() {
+	extern() // @warning "unsound call to unknown intrinsic: main.extern"
+}
+
+func g() {
+	extern() // @warning "unsound call to unknown intrinsic: main.extern"
+}
+
+func main() {
+	f()
+	g()
+}
+
+// @warning@any "unknown intrinsic"
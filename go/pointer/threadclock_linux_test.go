@@ -0,0 +1,64 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !android
+
+package pointer_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// A threadClock measures the CPU time of an OS thread, from its
+// schedstat file in /proc, or the wall-clock time if there is none.
+type threadClock struct {
+	stat  string        // the schedstat file of the thread, or "" if unreadable
+	cpu   time.Duration // the CPU time of the thread at the start
+	start time.Time
+}
+
+// startThreadClock starts a clock of the current thread, to which the
+// caller must be locked.
+func startThreadClock() *threadClock {
+	c := &threadClock{
+		stat:  fmt.Sprintf("/proc/self/task/%d/schedstat", syscall.Gettid()),
+		start: time.Now(),
+	}
+	cpu, err := c.threadCPU()
+	if err != nil {
+		c.stat = ""
+	}
+	c.cpu = cpu
+	return c
+}
+
+// elapsed returns the time the thread has run since the clock started.
+func (c *threadClock) elapsed() time.Duration {
+	if c.stat != "" {
+		if cpu, err := c.threadCPU(); err == nil {
+			return cpu - c.cpu
+		}
+	}
+	return time.Since(c.start)
+}
+
+// threadCPU returns the CPU time of the thread, the first field of its
+// schedstat file, in nanoseconds.
+func (c *threadClock) threadCPU() (time.Duration, error) {
+	data, err := ioutil.ReadFile(c.stat)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("%s is empty", c.stat)
+	}
+	ns, err := strconv.ParseInt(fields[0], 10, 64)
+	return time.Duration(ns), err
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux,!android
+
+package pointer_test
+
+import "time"
+
+// A threadClock measures the wall-clock time, as the CPU time of an OS
+// thread is not read on this platform.
+type threadClock struct {
+	start time.Time
+}
+
+// startThreadClock starts a clock of the current thread, to which the
+// caller must be locked.
+func startThreadClock() *threadClock {
+	return &threadClock{start: time.Now()}
+}
+
+// elapsed returns the time the thread has run since the clock started.
+func (c *threadClock) elapsed() time.Duration {
+	return time.Since(c.start)
+}
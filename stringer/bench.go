@@ -0,0 +1,176 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate, with -genbench, a test file of benchmarks of the
+// String method of each type, for the value of each constant, of all the
+// single bits of a bitflag type together, and of a value with no name, so
+// that the code of several flags can be compared for the constants at hand.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// benchFilename returns the name of the file of the benchmarks of the types
+// of filename, a test file for the package of filename or for its external
+// test package alike.
+func benchFilename(filename string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filename, ".go"), "_test") + "_bench_test.go"
+}
+
+// intBits returns the number of bits of the values of typ, an integer type,
+// those of int and uint being taken as 32 to hold on all platforms.
+func intBits(typ types.Type) uint {
+	switch typ.Underlying().(*types.Basic).Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int64, types.Uint64:
+		return 64
+	}
+	return 32
+}
+
+// unknownValue returns the literal of a value of the type, of bits bits,
+// with no name, if there is one: a bit set in none of the values of a bitflag
+// type, or the value following one of the values, in increasing order with no
+// duplicates, or the one preceding them.
+func unknownValue(values []Value, bits uint, bitflag bool) (string, bool) {
+	signed := values[0].signed
+	if signed {
+		bits-- // The sign bit.
+	}
+	if bitflag {
+		var mask uint64
+		for _, v := range values {
+			mask |= v.value
+		}
+		for b := uint(0); b < bits; b++ {
+			if mask&(1<<b) == 0 {
+				return fmt.Sprintf("%#x", uint64(1)<<b), true
+			}
+		}
+		return "", false
+	}
+	literal := func(x uint64) string {
+		if signed {
+			return strconv.FormatInt(int64(x), 10)
+		}
+		return strconv.FormatUint(x, 10)
+	}
+	for i, v := range values {
+		x := v.value + 1
+		if i+1 < len(values) && values[i+1].value == x {
+			continue
+		}
+		if signed && int64(x) > int64(v.value) && (bits == 63 || int64(x) < 1<<bits) {
+			return literal(x), true
+		}
+		if !signed && x > v.value && (bits == 64 || x < 1<<bits) {
+			return literal(x), true
+		}
+		break
+	}
+	x := values[0].value - 1
+	if signed && int64(x) < int64(values[0].value) && (bits == 63 || int64(x) >= -1<<bits) {
+		return literal(x), true
+	}
+	if !signed && values[0].value > 0 {
+		return literal(x), true
+	}
+	return "", false
+}
+
+// buildBench generates the benchmark of the String method of type obj, as
+// written typeName in the package, for the values of its constants.
+func (g *Generator) buildBench(obj *types.TypeName, typeName string, values []Value) {
+	values = append([]Value(nil), values...)
+	isString := isStringType(obj.Type())
+	if isString {
+		sort.Stable(byString(values))
+	} else {
+		sort.Stable(byValue(values))
+	}
+	cases := new(bytes.Buffer)
+	j := 0
+	for i, v := range values {
+		if i > 0 && (v.value == values[j-1].value && v.str == values[j-1].str) {
+			continue
+		}
+		values[j] = v
+		j++
+		fmt.Fprintf(cases, "\t\t{%s, %s},\n", strconv.Quote(v.name), v.str)
+	}
+	values = values[:j]
+	if g.bitflag {
+		var combined uint64
+		n := 0
+		for _, v := range values {
+			if v.value != 0 && v.value&(v.value-1) == 0 {
+				combined |= v.value
+				n++
+			}
+		}
+		if n > 1 {
+			fmt.Fprintf(cases, "\t\t{\"combined\", %#x},\n", combined)
+		}
+	}
+	if !isString {
+		if unknown, ok := unknownValue(values, intBits(obj.Type()), g.bitflag); ok {
+			fmt.Fprintf(cases, "\t\t{\"unknown\", %s},\n", unknown)
+		}
+	}
+
+	// The benchmark is named after the type and the method, made to start
+	// with a letter go test takes for a benchmark.
+	name := obj.Name() + g.stringMethod()
+	if r, _ := utf8.DecodeRuneInString(name); unicode.IsLower(r) {
+		name = "_" + name
+	}
+	call := "bench.value." + g.stringMethod() + "()"
+	if typeName != obj.Name() {
+		// The String method of a type of another package is a function.
+		call = obj.Name() + g.stringMethod() + "(bench.value)"
+	}
+	sink := "_" + obj.Name() + "_bench"
+	if g.method != "" && g.method != defaultMethod {
+		sink = "_" + obj.Name() + "_" + g.method + "_bench"
+	}
+	fmt.Fprintf(&g.benchBuf, benchString, name, typeName, cases, call, sink)
+}
+
+// Arguments to format are:
+//	[1]: benchmark name, after Benchmark
+//	[2]: type name, as written in the package
+//	[3]: the values, one per line, with their names
+//	[4]: call returning the name of bench.value
+//	[5]: variable the names are assigned to
+const benchString = `
+var %[5]s string
+
+func Benchmark%[1]s(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value %[2]s
+	}{
+%[3]s	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				%[5]s = %[4]s
+			}
+		})
+	}
+}
+`
@@ -0,0 +1,275 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"testing"
+)
+
+const (
+	day_bench = `// Code generated by "stringer -type=Day"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Day_bench string
+
+func BenchmarkDayString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Day
+	}{
+		{"Monday", 0},
+		{"Tuesday", 1},
+		{"Wednesday", 2},
+		{"Thursday", 3},
+		{"Friday", 4},
+		{"Saturday", 5},
+		{"Sunday", 6},
+		{"unknown", 7},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Day_bench = bench.value.String()
+			}
+		})
+	}
+}
+`
+	gap_bench = `// Code generated by "stringer -type=Gap"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Gap_bench string
+
+func BenchmarkGapString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Gap
+	}{
+		{"Two", 2},
+		{"Three", 3},
+		{"Five", 5},
+		{"Six", 6},
+		{"Seven", 7},
+		{"Eight", 8},
+		{"Nine", 9},
+		{"Eleven", 11},
+		{"unknown", 4},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Gap_bench = bench.value.String()
+			}
+		})
+	}
+}
+`
+	unum_bench = `// Code generated by "stringer -type=Unum"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Unum_bench string
+
+func BenchmarkUnumString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Unum
+	}{
+		{"m0", 0},
+		{"m1", 1},
+		{"m2", 2},
+		{"m_2", 253},
+		{"m_1", 254},
+		{"unknown", 3},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Unum_bench = bench.value.String()
+			}
+		})
+	}
+}
+`
+	days_bench = `// Code generated by "stringer -type=Days"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Days_bench string
+
+func BenchmarkDaysString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Days
+	}{
+		{"Monday", 1},
+		{"Tuesday", 2},
+		{"Wednesday", 4},
+		{"Thursday", 8},
+		{"Friday", 16},
+		{"Saturday", 32},
+		{"Sunday", 64},
+		{"combined", 0x7f},
+		{"unknown", 0x80},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Days_bench = bench.value.String()
+			}
+		})
+	}
+}
+`
+	region_bench = `// Code generated by "stringer -type=Region"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Region_bench string
+
+func BenchmarkRegionString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Region
+	}{
+		{"APSouth", "ap-south"},
+		{"EUCentral", "eu-central"},
+		{"Quoted", "say \"hi\""},
+		{"USWest", "us-west"},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Region_bench = bench.value.String()
+			}
+		})
+	}
+}
+`
+	pill_bench_name = `// Code generated by "stringer -type=Pill"; DO NOT EDIT.
+
+package test
+
+import "testing"
+
+var _Pill_Name_bench string
+
+func BenchmarkPillName(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value Pill
+	}{
+		{"Placebo", 0},
+		{"Aspirin", 1},
+		{"Ibuprofen", 2},
+		{"Paracetamol", 3},
+		{"unknown", 4},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Pill_Name_bench = bench.value.Name()
+			}
+		})
+	}
+}
+`
+	weekday_bench = `// Code generated by "stringer -type=time.Weekday"; DO NOT EDIT.
+
+package test
+
+import "testing"
+import "time"
+
+var _Weekday_bench string
+
+func BenchmarkWeekdayString(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		value time.Weekday
+	}{
+		{"Sun", 0},
+		{"Mon", 1},
+		{"Tue", 2},
+		{"Fri", 5},
+		{"unknown", 3},
+	} {
+		bench := bench
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_Weekday_bench = WeekdayString(bench.value)
+			}
+		})
+	}
+}
+`
+)
+
+// benchFile runs Generate on input, declaring the type, with GenBench, and
+// returns the contents of the file of the benchmarks.
+func benchFile(t *testing.T, opts Options, name, input, typeName string) string {
+	t.Helper()
+	info, fset := loadPackage(t, name, map[string]string{name + ".go": "package test\n" + input})
+	opts.Output = name + "_string.go"
+	opts.GenBench = true
+	files, err := Generate(fset, info, []string{typeName}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(files[name+"_string_bench_test.go"])
+}
+
+func TestGoldenBench(t *testing.T) {
+	for _, test := range []struct {
+		opts                          Options
+		name, input, typeName, output string
+	}{
+		{Options{}, "day", day_in, "Day", day_bench},
+		{Options{}, "gap", gap_in, "Gap", gap_bench},
+		{Options{}, "unum", unum_in, "Unum", unum_bench},
+		{Options{Bitflag: true}, "days", days_in_bitflag, "Days", days_bench},
+		{Options{}, "region", region_in, "Region", region_bench},
+		{Options{Method: "Name"}, "pill", pill_in, "Pill", pill_bench_name},
+		{Options{}, "weekday", weekday_in, "time.Weekday", weekday_bench},
+	} {
+		got := benchFile(t, test.opts, test.name, test.input, test.typeName)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====\n%s", test.name, got, test.output)
+		}
+	}
+}
+
+func TestBenchFilename(t *testing.T) {
+	for _, test := range []struct {
+		filename, want string
+	}{
+		{"day_string.go", "day_string_bench_test.go"},
+		{"day_string_test.go", "day_string_bench_test.go"},
+		{"dir/out.go", "dir/out_bench_test.go"},
+	} {
+		if got := benchFilename(test.filename); got != test.want {
+			t.Errorf("benchFilename(%q) = %q, want %q", test.filename, got, test.want)
+		}
+	}
+}
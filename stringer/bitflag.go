@@ -0,0 +1,1282 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines implement the bulk of the bitflag code generation for stringer.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// buildBitflag generates the variables and String method for bitflag values.
+func (g *Generator) buildBitflag(info *loader.PackageInfo, values []Value, typeName string) {
+	all := append([]Value(nil), values...) // Before duplicates are removed.
+	zero, runs, composites, rejects := splitIntoBitflagRuns(values)
+	g.reportRejects(rejects, typeName)
+	printed := append(runs, composites)
+	if zero != nil {
+		printed = append([][]Value{{*zero}}, printed...)
+	}
+	if g.aliases {
+		g.buildAliasComment(printed, aliases(all, printed), typeName)
+	}
+
+	// A name holding the separator of another format could not be told
+	// apart from two names.
+	if g.nameFormat.sep != defaultBitflagFormat.sep {
+		for _, run := range printed {
+			for _, v := range run {
+				if strings.Contains(v.name, g.nameFormat.sep) {
+					failAt(v.pos, "the name %q holds the separator %q of -bitflagformat", v.name, g.nameFormat.sep)
+				}
+			}
+		}
+	}
+
+	zeroName := typeName + "(0)"
+	if zero != nil {
+		zeroName = escape(zero.name) // Printed within quotes.
+	}
+	// With -bitorder=msb the single bits are named from the highest down:
+	// the runs, and the values in them, are reversed and v shifts right.
+	order := runs
+	if g.msb {
+		order = reverseRuns(runs)
+	}
+	// Without single-bit constants, there are no runs for the value of
+	// the first bit; only the zero value and composites are named.
+	initialValue := "1"
+	if len(order) != 0 {
+		initialValue = order[0][0].String()
+	}
+
+	name, offsets, skips := g.nameAndRest(order)
+	name, cindex := compositeNames(name, composites)
+
+	// The names of a type with few named bits are computed here. The loop
+	// they fall back to for other bits is self-contained, as with -notable,
+	// and needs no cache.
+	positions := namedBits(runs, composites)
+	precompute := g.precompute && len(positions) <= maxPrecomputedBits
+	if precompute {
+		defer func(table, cache bool) { g.table, g.cache = table, cache }(g.table, g.cache)
+		g.table, g.cache = false, false
+	}
+	g.verbosef("%s: bitflag, %d single bits in %d runs and %d composites: %s", typeName, len(offsets)-len(skips), len(runs), len(composites), g.bitflagShape(precompute, len(positions)))
+
+	g.Printf("\n")
+	code := ""
+
+	if g.table {
+		skip := ""
+		if g.msb {
+			skip = "\n\tmsb: true,"
+		}
+		skip += g.nameFormat.tableFields()
+		if len(skips) != 0 {
+			skip += fmt.Sprintf("\n\tskips: []uint8{%s},", intString(skips))
+		}
+		if len(composites) != 0 {
+			skip += fmt.Sprintf("\n\tcomposites: []uint64{%s},\n\tcindex: []uint16{%s},",
+				compositeMasks(composites, false), intString(cindex))
+		}
+		// An unbounded cache is made at once, so it needs no checks.
+		cache, lookup := fmt.Sprintf("size: %d,", g.cacheSize), "mstring"
+		if g.cacheSize == 0 {
+			cache, lookup = "cached: make(map[uint64]string), // Unbounded: names are never dropped.", "mstringUnbounded"
+		}
+		if g.cache {
+			code = stringBitflagTableDrivenCached
+		} else {
+			code = stringBitflagTableDrivenNotCached
+		}
+		g.Printf(code, typeName, zeroName, initialValue, escape(name), intString(offsets), skip, cache, g.tableTypePrefix(), lookup)
+	} else {
+		g.declareNameAndRest(typeName, name, offsets, skips, composites, cindex)
+
+		method := "String"
+		switch {
+		case precompute:
+			g.buildBitflagPrecomputed(typeName, zero, order, composites, positions)
+			method = "_string"
+		case g.cache:
+			g.buildBitflagCache(typeName)
+			method = "_string"
+		}
+		if len(composites) != 0 {
+			g.buildBitflagComposite(typeName, method, zeroName, initialValue, len(skips) != 0)
+		} else {
+			if len(skips) == 0 {
+				code = stringBitflagCode
+			} else {
+				code = stringBitflagCodeWithSkips
+			}
+			f := g.nameFormat
+			g.Printf(code, typeName, method, zeroName, 0, initialValue, g.shiftOp(),
+				bracketCode(f.open(), 3), f.sepCode(), bracketCode(f.close(), 3), bracketCode(f.close(), 1), f.unknownCode(typeName, "m"))
+		}
+	}
+
+	g.buildBitflagFlags(info, typeName, initialValue, len(skips) != 0, len(composites) != 0)
+	if g.isValid {
+		g.buildBitflagIsValid(runs, composites, typeName, all[0].signed)
+	}
+	if g.values {
+		g.buildValues(bitflagValues(zero, runs, composites), typeName)
+	}
+	if g.parsing() {
+		g.buildBitflagParse(order, composites, aliases(all, printed), typeName, zeroName)
+	}
+	if g.exported {
+		g.buildBitflagExportTables(zero, order, composites, typeName)
+	}
+	if g.goString {
+		g.buildGoString(typeName, info.Pkg.Name())
+	}
+}
+
+// bitflagShape describes, for -v, the code generated for a bitflag type of
+// the number of named bits.
+func (g *Generator) bitflagShape(precompute bool, named int) string {
+	if precompute {
+		return fmt.Sprintf("the names of the %d named bits precomputed, at most %d, falling back to a loop", named, maxPrecomputedBits)
+	}
+	var shape string
+	if g.precompute {
+		shape = fmt.Sprintf("%d named bits, more than %d to precompute; ", named, maxPrecomputedBits)
+	}
+	if g.table {
+		shape += "table-driven, sharing the code of " + g.tableTypePrefix() + "Bitflag"
+	} else {
+		shape += "self-contained"
+	}
+	switch {
+	case !g.cache:
+		shape += ", not cached"
+	case g.cacheSize == 0:
+		shape += ", cached without limit"
+	default:
+		shape += fmt.Sprintf(", cached up to %d names", g.cacheSize)
+	}
+	return shape
+}
+
+// reportRejects warns about the rejected constants not printed as declared,
+// and with -v lists all of them.
+func (g *Generator) reportRejects(rejects []bitflagReject, typeName string) {
+	for _, r := range rejects {
+		if r.reason != rejectZero {
+			g.warnConstant(r.v, "%s", r)
+		}
+	}
+	if g.verbose && len(rejects) != 0 {
+		log.Printf("%s: constants left out of the names:", typeName)
+		for _, r := range rejects {
+			log.Printf("\t%s%s", g.position(r.v), r)
+		}
+	}
+}
+
+// warnConstant logs a warning about the constant v, at its position in the
+// source, and counts it for -strict.
+func (g *Generator) warnConstant(v Value, format string, args ...interface{}) {
+	log.Printf("warning: %s%s", g.position(v), fmt.Sprintf(format, args...))
+	g.dropped++
+}
+
+// position returns the file and line of the constant v followed by ": ",
+// or "" if they are not known.
+func (g *Generator) position(v Value) string {
+	if g.fset == nil || !v.pos.IsValid() {
+		return ""
+	}
+	p := g.fset.Position(v.pos)
+	return fmt.Sprintf("%s:%d: ", p.Filename, p.Line)
+}
+
+// The default of Options.TableCommon.
+const defaultTableCommon = "stringerbitflag.go"
+
+// genStringerBitflagFile returns the name and contents of the file with the
+// common stringer bitfield code for the package in directory dir:
+// opts.TableCommon, within dir unless it is an absolute path. If the package
+// declares the code in another file, as for another tool, the contents are
+// nil.
+func genStringerBitflagFile(fset *token.FileSet, dir string, pkg *types.Package, opts Options) (string, []byte, error) {
+	filename := opts.TableCommon
+	if filename == "" {
+		filename = defaultTableCommon
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+	prefix := opts.TablePrefix
+	if prefix == "" {
+		prefix = defaultTablePrefix
+	}
+
+	if obj := pkg.Scope().Lookup(prefix + "Bitflag"); obj != nil {
+		if declared := fset.Position(obj.Pos()).Filename; !sameFile(declared, filename) {
+			log.Printf("not writing %s: %s is declared in %s", filename, obj.Name(), declared)
+			return filename, nil, nil
+		}
+	}
+
+	buf := commentLines(opts.Header) + fmt.Sprintf(stringBitflagTableDrivenCommon, packageName(pkg, opts), prefix)
+	src, err := formatSource(filename, []byte(buf), opts.AllowInvalid)
+	return filename, src, err
+}
+
+// sameFile reports whether the file names name the same file.
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
+
+// Why a constant is left out of the names of a bitflag type, or, if
+// negative, why its name is not printed as expected.
+const (
+	rejectNegative  = "negative"
+	rejectZero      = "zero"
+	rejectDuplicate = "duplicate"
+)
+
+// A bitflagReject is a constant left out of the names of a bitflag type.
+type bitflagReject struct {
+	v      Value
+	reason string // One of the reject constants.
+	of     string // For a duplicate, the name printed instead.
+}
+
+func (r bitflagReject) String() string {
+	switch r.reason {
+	case rejectNegative:
+		return fmt.Sprintf("%s is negative (%d); its bits are printed as if unsigned", r.v.name, int64(r.v.value))
+	case rejectZero:
+		return fmt.Sprintf("%s is printed for the zero value only", r.v.name)
+	}
+	return fmt.Sprintf("%s is not printed; it has the value of %s", r.v.name, r.of)
+}
+
+// byPos sorts rejects in the order the constants are declared.
+type byPos []bitflagReject
+
+func (b byPos) Len() int           { return len(b) }
+func (b byPos) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPos) Less(i, j int) bool { return b[i].v.decl < b[j].v.decl }
+
+// splitIntoBitflagRuns sorts values from lowest to highest, removing
+// duplicates.  The zero value, the runs of single-bit values and the
+// multi-bit (composite) values are returned, the composites in the order
+// they are preferred when formatting, followed by the constants left out
+// of the runs and composites, or negative, in the order declared.  The
+// input slice is known to be non-empty and is modified in place.
+func splitIntoBitflagRuns(values []Value) (*Value, [][]Value, []Value, []bitflagReject) {
+	var rejects []bitflagReject
+
+	// If any are signed, this is probably messed up. Just drop the sign.
+	for i := range values {
+		if values[i].signed && int64(values[i].value) < 0 {
+			rejects = append(rejects, bitflagReject{v: values[i], reason: rejectNegative})
+		}
+		values[i].signed = false
+	}
+	// We use stable sort so the lexically first name is chosen for equal elements.
+	sort.Stable(byValue(values))
+
+	var zero *Value
+	if values[0].value == 0 {
+		zero = &values[0]
+		rejects = append(rejects, bitflagReject{v: *zero, reason: rejectZero})
+		for values = values[1:]; len(values) > 0 && values[0].value == 0; values = values[1:] {
+			rejects = append(rejects, bitflagReject{v: values[0], reason: rejectDuplicate, of: zero.name})
+		}
+	}
+
+	// Remove duplicates. Stable sort has put the one we want to print first,
+	// so use that one. Any zero values have been removed from the front.
+	// Also move any with multiple bits set to the composites.
+	var composites []Value
+	j := 0
+	kept := ""
+	for i := range values {
+		if i > 0 && values[i].value == values[i-1].value {
+			rejects = append(rejects, bitflagReject{v: values[i], reason: rejectDuplicate, of: kept})
+			continue
+		}
+		kept = values[i].name
+		if singleBitSet(values[i].value) {
+			values[j] = values[i]
+			j++
+		} else {
+			composites = append(composites, values[i])
+		}
+	}
+	values = values[:j]
+	// Composites with more bits set are preferred over those they overlap.
+	sort.Stable(byBitCount(composites))
+	sort.Stable(byPos(rejects))
+
+	runs := make([][]Value, 0, 10)
+	for len(values) > 0 {
+		// One contiguous sequence per outer loop.
+		i := 1
+		for i < len(values) && values[i].value == values[i-1].value<<1 {
+			i++
+		}
+		runs = append(runs, values[:i])
+		values = values[i:]
+	}
+	return zero, runs, composites, rejects
+}
+
+// singleBitSet returns true when one and only one bit is set in the value v.
+func singleBitSet(v uint64) bool {
+	return v != 0 && (v&(v-1)) == 0
+}
+
+// byBitCount sorts composite values by decreasing number of bits set.
+type byBitCount []Value
+
+func (b byBitCount) Len() int      { return len(b) }
+func (b byBitCount) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byBitCount) Less(i, j int) bool {
+	return bits.OnesCount64(b[i].value) > bits.OnesCount64(b[j].value)
+}
+
+// compositeNames returns the name string with the names of the composites
+// appended, and the index of each composite name within it: the name of
+// composite i is name[cindex[i]:cindex[i+1]].
+func compositeNames(name string, composites []Value) (string, []int) {
+	if len(composites) == 0 {
+		return name, nil
+	}
+	cindex := []int{len(name)}
+	for _, c := range composites {
+		name += c.name
+		cindex = append(cindex, len(name))
+	}
+	if len(name) >= 1<<16 {
+		failf("the names are too long (%d bytes, at most %d)", len(name), 1<<16-1)
+	}
+	return name, cindex
+}
+
+// compositeMasks returns the list of composite masks. If typed, the masks are
+// the constants as declared, otherwise they are their uint64 bit patterns.
+func compositeMasks(composites []Value, typed bool) string {
+	r := new(bytes.Buffer)
+	sep := ""
+	for i := range composites {
+		if typed {
+			fmt.Fprintf(r, "%s%s", sep, &composites[i])
+		} else {
+			fmt.Fprintf(r, "%s%d", sep, composites[i].value)
+		}
+		sep = ", "
+	}
+	return r.String()
+}
+
+// tableTypePrefix returns the prefix of the names of the types declared by
+// the common stringer bitfield code.
+func (g *Generator) tableTypePrefix() string {
+	if g.tablePrefix == "" {
+		return defaultTablePrefix
+	}
+	return g.tablePrefix
+}
+
+// buildBitflagCache generates the String method that looks the name up in
+// the cache of the named type, calling _string to build it when missing. If
+// the size of the cache is limited, a new generation of the cache starts when
+// it is full. The names of the previous generation are kept until the next
+// one starts, and are moved to the new generation as they are used, so names
+// in use survive. Otherwise the cache keeps every name and is never checked.
+func (g *Generator) buildBitflagCache(typeName string) {
+	if g.cacheSize == 0 {
+		g.Printf(stringBitflagCacheUnlimitedWrapper, typeName)
+		return
+	}
+	g.Printf(stringBitflagCacheWrapper, typeName, g.cacheSize)
+}
+
+// buildBitflagComposite generates the String method, or the named method it
+// calls, for bitflag values some of which are named by composite constants.
+func (g *Generator) buildBitflagComposite(typeName, method, zeroName, initialValue string, skips bool) {
+	skip, skipIndex := "", ""
+	if skips {
+		skip = fmt.Sprintf(stringBitflagSkip, typeName, g.shiftOp())
+		skipIndex = "\n\tsi := 0"
+	}
+	f := g.nameFormat
+	g.Printf(stringBitflagCompositeCode, typeName, method, zeroName, skip, initialValue, skipIndex, g.shiftOp(),
+		f.sepCode(), f.unknownCode(typeName, "c"), f.joinedCode())
+}
+
+// maxPrecomputedBits is the most bits named by the constants of a type whose
+// names -precompute computes, one for each of the 1<<maxPrecomputedBits
+// values of the bits.
+const maxPrecomputedBits = 8
+
+// namedBits returns the positions of the bits set in the single-bit and
+// composite values, from lowest to highest.
+func namedBits(runs [][]Value, composites []Value) []uint {
+	var mask uint64
+	for _, run := range runs {
+		for _, v := range run {
+			mask |= v.value
+		}
+	}
+	for _, v := range composites {
+		mask |= v.value
+	}
+	var positions []uint
+	for ; mask != 0; mask &= mask - 1 {
+		positions = append(positions, uint(bits.TrailingZeros64(mask)))
+	}
+	return positions
+}
+
+// buildBitflagPrecomputed generates the String method that looks the name of
+// a value of the named bits up in an array, computed here, indexed by those
+// bits packed together, and calls _string for the other values.
+func (g *Generator) buildBitflagPrecomputed(typeName string, zero *Value, runs [][]Value, composites []Value, positions []uint) {
+	var mask uint64
+	for _, p := range positions {
+		mask |= 1 << p
+	}
+	names := new(bytes.Buffer)
+	for i := 0; i < 1<<uint(len(positions)); i++ {
+		// Unpack the index into the value of the bits.
+		var m uint64
+		for j, p := range positions {
+			if i&(1<<uint(j)) != 0 {
+				m |= 1 << p
+			}
+		}
+		fmt.Fprintf(names, "	%q,\n", bitflagString(typeName, zero, runs, composites, m, g.nameFormat))
+	}
+	g.Printf(stringBitflagPrecomputed, typeName, names, fmt.Sprintf("%#x", mask), packBits(positions))
+}
+
+// bitflagString returns the name of m, as the generated String method prints
+// it: the names of the single bits not covered by a composite, the names of
+// the composites, then any bits left over, separated by | and, if more than
+// one, in parentheses, or as f sets, as is the base of the bits left over.
+func bitflagString(typeName string, zero *Value, runs [][]Value, composites []Value, m uint64, f bitflagFormat) string {
+	if m == 0 {
+		if zero != nil {
+			return zero.name
+		}
+		return typeName + "(0)"
+	}
+	c := m
+	for _, k := range composites {
+		if c&k.value == k.value {
+			c &^= k.value
+		}
+	}
+	var names []string
+	for _, run := range runs {
+		for _, v := range run {
+			if c&v.value != 0 {
+				c ^= v.value
+				names = append(names, v.name)
+			}
+		}
+	}
+	x := m
+	for _, k := range composites {
+		if x&k.value == k.value {
+			x &^= k.value
+			names = append(names, k.name)
+		}
+	}
+	if c != 0 {
+		names = append(names, f.unknown(typeName, c))
+	}
+	return f.join(names)
+}
+
+// packBits returns the expression packing the bits of m at the positions,
+// from lowest to highest, into the bits of an index, a term for each run of
+// adjacent bits: m>>5&0x3<<1 moves bits 5 and 6 to bits 1 and 2.
+func packBits(positions []uint) string {
+	var terms []string
+	packed := uint(0)
+	for i := 0; i < len(positions); {
+		j := i + 1
+		for j < len(positions) && positions[j] == positions[j-1]+1 {
+			j++
+		}
+		term := "m"
+		if positions[i] != 0 {
+			term += fmt.Sprintf(">>%d", positions[i])
+		}
+		term += fmt.Sprintf("&%#x", uint64(1)<<uint(j-i)-1)
+		if packed != 0 {
+			term += fmt.Sprintf("<<%d", packed)
+		}
+		terms = append(terms, term)
+		packed += uint(j - i)
+		i = j
+	}
+	return strings.Join(terms, " | ")
+}
+
+// buildBitflagFlags generates the Flags method, unless the type declares one.
+func (g *Generator) buildBitflagFlags(info *loader.PackageInfo, typeName, initialValue string, skips, composites bool) {
+	if name := g.flagsMethod(); g.declaredMethods(info, typeName)[name] {
+		log.Printf("warning: not generating %s.%s: the method is already declared", typeName, name)
+		return
+	}
+	if g.table {
+		g.Printf(flagsBitflagTableDriven, typeName, basePrefix(g.nameFormat.base))
+		return
+	}
+	skip, skipIndex := "", ""
+	if skips {
+		skip = fmt.Sprintf(stringBitflagSkip, typeName, g.shiftOp())
+		skipIndex = "\n\tsi := 0"
+	}
+	removal, names := "", ""
+	if composites {
+		removal = fmt.Sprintf(flagsBitflagCompositeRemoval, typeName)
+		names = fmt.Sprintf(flagsBitflagCompositeNames, typeName)
+	}
+	g.Printf(flagsBitflagCode, typeName, initialValue, skip, skipIndex, removal, names, g.shiftOp(),
+		basePrefix(g.nameFormat.base), g.nameFormat.unknownCode(typeName, "c"))
+}
+
+// The orders of the -bitorder flag, in which the names of the bits set are
+// printed.
+const (
+	bitOrderLSB = "lsb"
+	bitOrderMSB = "msb"
+)
+
+// reverseRuns returns the runs, and the values in each, in reverse order,
+// from the highest bit down.
+func reverseRuns(runs [][]Value) [][]Value {
+	reversed := make([][]Value, len(runs))
+	for r, run := range runs {
+		rev := make([]Value, len(run))
+		for i, v := range run {
+			rev[len(run)-1-i] = v
+		}
+		reversed[len(runs)-1-r] = rev
+	}
+	return reversed
+}
+
+// shiftOp returns the operator shifting v in a loop over the single bits:
+// left from the lowest bit, or right from the highest with -bitorder=msb.
+func (g *Generator) shiftOp() string {
+	if g.msb {
+		return ">>"
+	}
+	return "<<"
+}
+
+// nameAndRest returns the name string for the runs, and the list of offsets and skips.
+func (g *Generator) nameAndRest(runs [][]Value) (name string, offsets []int, skips []int) {
+	var names []string
+	for r, run := range runs {
+		for i := range run {
+			n := run[i].name
+			o := len(n)
+
+			names = append(names, n)
+			if o >= 1<<16 {
+				failAt(run[i].pos, "the name is too long (%d bytes, at most %d)", o, 1<<16-1)
+			}
+			offsets = append(offsets, o)
+		}
+
+		if r < len(runs)-1 {
+			// Handle gap to next run by appending the skip amount in the skips
+			// list and appending a zero to the offsets list.
+			// The runs are from the highest bit down with -bitorder=msb.
+			low, high := runLastValue(run).value, runs[r+1][0].value
+			if low > high {
+				low, high = high, low
+			}
+			skips = append(skips, shiftCount(low, high)-1)
+			offsets = append(offsets, 0) // 0 will signal to skip.
+		}
+	}
+	name = strings.Join(names, "")
+	return
+}
+
+// shiftCount returns number of times prev needs to be shifted to meet or exceed next.
+func shiftCount(prev, next uint64) int {
+	count := 0
+	for prev < next {
+		count++
+		prev <<= 1
+	}
+	return count
+}
+
+func runLastValue(run []Value) *Value {
+	return &run[len(run)-1]
+}
+
+// declareNameAndRest declares the names and the offsets, the type of whose
+// elements is as small as the longest name allows, skips and composites.
+func (g *Generator) declareNameAndRest(typeName, name string, offsets, skips []int, composites []Value, cindex []int) {
+	longest := 0
+	for _, o := range offsets {
+		if o > longest {
+			longest = o
+		}
+	}
+	offset := fmt.Sprintf("_%s_offset = [...]uint%d{%s}", typeName, usize(longest), intString(offsets))
+
+	g.Printf("const _%s_name = %q\n", typeName, name)
+	if !g.cache && len(skips) == 0 && len(composites) == 0 {
+		g.Printf("var %s\n", offset)
+		return
+	}
+	g.Printf("var (\n")
+	g.Printf("\t%s\n", offset)
+	if len(skips) != 0 {
+		g.Printf("\t_%s_skips = [...]uint8{%s}\n", typeName, intString(skips))
+	}
+	if len(composites) != 0 {
+		g.Printf("\t_%s_composites = [...]%s{%s}\n", typeName, typeName, compositeMasks(composites, true))
+		g.Printf("\t_%s_cindex = [...]uint%d{%s}\n", typeName, usize(cindex[len(cindex)-1]), intString(cindex))
+	}
+	if g.cache {
+		if g.cacheSize == 0 {
+			g.Printf("\t_%[1]s_cache = make(map[%[1]s]string) // Unbounded: names are never dropped.\n", typeName)
+		} else {
+			g.Printf("\t_%[1]s_cache = make(map[%[1]s]string)\n", typeName)
+			g.Printf("\t_%[1]s_cacheold map[%[1]s]string\n", typeName)
+		}
+		g.Printf("\t_%[1]s_cachemu sync.RWMutex\n", typeName)
+	}
+	g.Printf(")\n\n")
+}
+
+// intString returns the string of int values
+func intString(values []int) string {
+	r := new(bytes.Buffer)
+	sep := ""
+	for _, v := range values {
+		fmt.Fprintf(r, "%s%d", sep, v)
+		sep = ", "
+	}
+	return r.String()
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: method name
+//	[3]: zeroName
+//	[4]: 0 a noop
+//	[5]: initial value : example "(1)"
+//	[6]: shift operator, << or >>
+//	[7]: statement appending the open bracket, if any
+//	[8]: separator, as the last argument of append
+//	[9]: statement appending the close bracket, if any, in the loop
+//	[10]: statement appending the close bracket, if any, after the loop
+//	[11]: name of the bits m with no name
+const stringBitflagCode = `func (m %[1]s) %[2]s() string {
+	if m == 0 {
+		return "%[3]s"
+	}
+
+	var b []byte
+	l := len(_%[1]s_offset)
+	v := %[1]s(%[5]s)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v%[6]s1 {
+		p0 = p1
+		p1 += int(_%[1]s_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _%[1]s_name[p0:p1]
+			}%[7]s
+		} else {
+			b = append(b, %[8]s)
+		}
+		b = append(b, _%[1]s_name[p0:p1]...)
+		if m == 0 {%[9]s
+			return string(b)
+		}
+	}
+	s := %[11]s
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, %[8]s)
+	b = append(b, s...)%[10]s
+	return string(b)
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: method name
+//	[3]: zeroName
+//	[4]: 0 a noop
+//	[5]: initial value : example "(1)"
+//	[6]: shift operator, << or >>
+//	[7]: statement appending the open bracket, if any
+//	[8]: separator, as the last argument of append
+//	[9]: statement appending the close bracket, if any, in the loop
+//	[10]: statement appending the close bracket, if any, after the loop
+//	[11]: name of the bits m with no name
+const stringBitflagCodeWithSkips = `func (m %[1]s) %[2]s() string {
+	if m == 0 {
+		return "%[3]s"
+	}
+
+	var b []byte
+	l := len(_%[1]s_offset)
+	v := %[1]s(%[5]s)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v%[6]s1 {
+		o := _%[1]s_offset[i]
+		if o == 0 {
+			v %[6]s= _%[1]s_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _%[1]s_name[p0:p1]
+			}%[7]s
+		} else {
+			b = append(b, %[8]s)
+		}
+		b = append(b, _%[1]s_name[p0:p1]...)
+		if m == 0 {%[9]s
+			return string(b)
+		}
+	}
+	s := %[11]s
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, %[8]s)
+	b = append(b, s...)%[10]s
+	return string(b)
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: cache size limit
+const stringBitflagCacheWrapper = `func (m %[1]s) String() string {
+	_%[1]s_cachemu.RLock()
+	s, ok := _%[1]s_cache[m]
+	old := false
+	if !ok {
+		s, old = _%[1]s_cacheold[m]
+	}
+	_%[1]s_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_%[1]s_cachemu.Lock()
+	if len(_%[1]s_cache) >= %[2]d {
+		_%[1]s_cacheold = _%[1]s_cache
+		_%[1]s_cache = make(map[%[1]s]string, %[2]d)
+	}
+	_%[1]s_cache[m] = s
+	_%[1]s_cachemu.Unlock()
+	return s
+}
+
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: names, one per line
+//	[3]: mask of the named bits
+//	[4]: index of the names
+const stringBitflagPrecomputed = `// The name of each value of the named bits of %[1]s, indexed by those bits
+// packed together.
+var _%[1]s_strings = [...]string{
+%[2]s}
+
+func (m %[1]s) String() string {
+	if m&^%[3]s != 0 {
+		return m._string()
+	}
+	return _%[1]s_strings[%[4]s]
+}
+
+`
+
+// Argument to format is the type name.
+const stringBitflagCacheUnlimitedWrapper = `func (m %[1]s) String() string {
+	_%[1]s_cachemu.RLock()
+	s, ok := _%[1]s_cache[m]
+	_%[1]s_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	s = m._string()
+	_%[1]s_cachemu.Lock()
+	_%[1]s_cache[m] = s
+	_%[1]s_cachemu.Unlock()
+	return s
+}
+
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: shift operator, << or >>
+const stringBitflagSkip = `
+		if _%[1]s_offset[i] == 0 {
+			v %[2]s= _%[1]s_skips[si] - 1
+			si++
+			continue
+		}`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: method name
+//	[3]: zeroName
+//	[4]: skip handling, when there are skips
+//	[5]: initial value : example "(1)"
+//	[6]: skip index declaration, when there are skips
+//	[7]: shift operator, << or >>
+//	[8]: separator, as the last argument of append
+//	[9]: name of the bits c with no name
+//	[10]: statements returning the names in b, n of them
+const stringBitflagCompositeCode = `func (m %[1]s) %[2]s() string {
+	if m == 0 {
+		return "%[3]s"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _%[1]s_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_%[1]s_offset)
+	v := %[1]s(%[5]s)%[6]s
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v%[7]s1 {%[4]s
+		p0 = p1
+		p1 += int(_%[1]s_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, %[8]s)
+		}
+		b = append(b, _%[1]s_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _%[1]s_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, %[8]s)
+			}
+			b = append(b, _%[1]s_name[_%[1]s_cindex[i]:_%[1]s_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, %[8]s)
+		}
+		b = append(b, %[9]s...)
+		n++
+	}
+	%[10]s
+}
+`
+
+// Arguments to format are:
+//	[1]: package name
+//	[2]: prefix of the common types
+const stringBitflagTableDrivenCommon = `// Code generated by stringer -bitflag; DO NOT EDIT.
+
+package %[1]s
+
+import "strconv"
+import "strings"
+import "sync"
+
+type %[2]sBitflag struct {
+	typename   string
+	zero       string
+	names      string
+	first      uint64
+	offsets    []uint16 // The length of each name, 0 for a skip.
+	skips      []uint8
+	msb        bool   // The names are of the bits from the highest down.
+	sep        string // The separator of several names; "|" if empty.
+	brackets   string // The brackets around several names: paren if empty, square or none.
+	base       int    // The base of the bits with no name: 16 if 0, 10 or 2.
+	composites []uint64
+	cindex     []uint16
+}
+
+type %[2]sBitflagCache struct {
+	sb     %[2]sBitflag
+	size   int               // The most names in a generation, if not 0.
+	cached map[uint64]string // The current generation.
+	old    map[uint64]string // The previous generation.
+	mu     sync.RWMutex
+}
+
+// mstring looks m up in the current generation, then in the previous one,
+// whose names move to the current generation as they are used. When the
+// current generation is full it becomes the previous one, dropping the names
+// that went unused for a whole generation.
+func (c *%[2]sBitflagCache) mstring(m uint64) string {
+	if m == 0 {
+		return c.sb.zero
+	}
+	c.mu.RLock()
+	s, ok := c.cached[m]
+	old := false
+	if !ok {
+		s, old = c.old[m]
+	}
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = c.sb.mstring(m)
+	}
+	c.mu.Lock()
+	if c.cached == nil || c.size != 0 && len(c.cached) >= c.size {
+		c.old = c.cached
+		c.cached = make(map[uint64]string, c.size)
+	}
+	c.cached[m] = s
+	c.mu.Unlock()
+	return s
+}
+
+// mstringUnbounded is mstring for a cache made unbounded, which keeps every
+// name, so it has no previous generation and is never checked for size.
+func (c *%[2]sBitflagCache) mstringUnbounded(m uint64) string {
+	if m == 0 {
+		return c.sb.zero
+	}
+	c.mu.RLock()
+	s, ok := c.cached[m]
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+	s = c.sb.mstring(m)
+	c.mu.Lock()
+	c.cached[m] = s
+	c.mu.Unlock()
+	return s
+}
+
+func (c *%[2]sBitflagCache) mslice(m uint64) []string {
+	return c.sb.mslice(m)
+}
+
+func (sb *%[2]sBitflag) mstring(m uint64) string {
+	if m == 0 {
+		return sb.zero
+	}
+	if len(sb.composites) != 0 {
+		return sb.cstring(m)
+	}
+	sep, open, close := sb.format()
+	var b []byte
+	l := len(sb.offsets)
+	v := sb.first
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, sb.shift(v, 1) {
+		o := sb.offsets[i]
+		if o == 0 {
+			v = sb.shift(v, sb.skips[si]-1)
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return sb.names[p0:p1]
+			}
+			b = append(b, open...)
+		} else {
+			b = append(b, sep...)
+		}
+		b = append(b, sb.names[p0:p1]...)
+		if m == 0 {
+			b = append(b, close...)
+			return string(b)
+		}
+	}
+	s := sb.unknown(m)
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, sep...)
+	b = append(b, s...)
+	b = append(b, close...)
+	return string(b)
+}
+
+// unknown returns the name of the bits m with no name.
+func (sb *%[2]sBitflag) unknown(m uint64) string {
+	switch sb.base {
+	case 10:
+		return sb.typename + "(" + strconv.FormatUint(m, 10) + ")"
+	case 2:
+		return sb.typename + "(0b" + strconv.FormatUint(m, 2) + ")"
+	}
+	return sb.typename + "(0x" + strconv.FormatUint(m, 16) + ")"
+}
+
+// format returns the separator and the brackets of several names.
+func (sb *%[2]sBitflag) format() (sep, open, close string) {
+	sep, open, close = sb.sep, "(", ")"
+	if sep == "" {
+		sep = "|"
+	}
+	switch sb.brackets {
+	case "square":
+		open, close = "[", "]"
+	case "none":
+		open, close = "", ""
+	}
+	return sep, open, close
+}
+
+// shift moves v n bits on, to the next higher bits, or lower with msb.
+func (sb *%[2]sBitflag) shift(v uint64, n uint8) uint64 {
+	if sb.msb {
+		return v >> n
+	}
+	return v << n
+}
+
+// cstring is mstring for types having composite names, which print
+// after the single bits they don't cover.
+func (sb *%[2]sBitflag) cstring(m uint64) string {
+	f := sb.mslice(m)
+	if len(f) == 1 {
+		return f[0]
+	}
+	sep, open, close := sb.format()
+	return open + strings.Join(f, sep) + close
+}
+
+// mslice returns the names of the flags set in m, in the order mstring
+// prints them: the single bits, the composites, then any bits left over.
+func (sb *%[2]sBitflag) mslice(m uint64) []string {
+	c := m
+	for _, k := range sb.composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(sb.offsets)
+	v := sb.first
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, sb.shift(v, 1) {
+		o := sb.offsets[i]
+		if o == 0 {
+			v = sb.shift(v, sb.skips[si]-1)
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, sb.names[p0:p1])
+	}
+	x := m
+	for i, k := range sb.composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, sb.names[sb.cindex[i]:sb.cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, sb.unknown(c))
+	}
+	return f
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: zeroName
+//	[3]: initial value : example "1"
+//	[4]: names
+//	[5]: offsets
+//	[6]: skips and composites, when present
+//	[7]: cache size limit, or the cache when unbounded
+//	[8]: prefix of the common types
+//	[9]: cache lookup method
+const stringBitflagTableDrivenCached = `var _%[1]s_stringer = %[8]sBitflagCache{
+	%[7]s
+	sb: %[8]sBitflag{
+		typename: "%[1]s",
+		zero:     "%[2]s",
+		first:    uint64(%[3]s),
+		names:    "%[4]s",
+		offsets:  []uint16{%[5]s},%[6]s
+	},
+}
+
+func (m %[1]s) String() string {
+	return _%[1]s_stringer.%[9]s(uint64(m))
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: zeroName
+//	[3]: initial value : example "1"
+//	[4]: names
+//	[5]: offsets
+//	[6]: skips and composites, when present
+//	[8]: prefix of the common types
+const stringBitflagTableDrivenNotCached = `var _%[1]s_stringer = %[8]sBitflag{
+	typename: "%[1]s",
+	zero:     "%[2]s",
+	first:    uint64(%[3]s),
+	names:    "%[4]s",
+	offsets:  []uint16{%[5]s},%[6]s
+}
+
+func (m %[1]s) String() string {
+	return _%[1]s_stringer.mstring(uint64(m))
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: prefix of the numbers of the bits with no name, as 0x
+const flagsBitflagTableDriven = `
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "%[1]s(%[2]s..)" element.
+func (m %[1]s) Flags() []string {
+	return _%[1]s_stringer.mslice(uint64(m))
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: initial value : example "(1)"
+//	[3]: skip handling, when there are skips
+//	[4]: skip index declaration, when there are skips
+//	[5]: removal of the composites from c, when there are composites
+//	[6]: composite names, when there are composites
+//	[7]: shift operator, << or >>
+//	[8]: prefix of the numbers of the bits with no name, as 0x
+//	[9]: name of the bits c with no name
+const flagsBitflagCode = `
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "%[1]s(%[8]s..)" element.
+func (m %[1]s) Flags() []string {
+	c := m%[5]s
+	var f []string
+	l := len(_%[1]s_offset)
+	v := %[1]s(%[2]s)%[4]s
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v%[7]s1 {%[3]s
+		p0 = p1
+		p1 += int(_%[1]s_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _%[1]s_name[p0:p1])
+	}%[6]s
+	if c != 0 {
+		f = append(f, %[9]s)
+	}
+	return f
+}
+`
+
+// Argument to format is the type name.
+const flagsBitflagCompositeRemoval = `
+	for _, k := range _%[1]s_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}`
+
+// Argument to format is the type name.
+const flagsBitflagCompositeNames = `
+	x := m
+	for i, k := range _%[1]s_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _%[1]s_name[_%[1]s_cindex[i]:_%[1]s_cindex[i+1]])
+		}
+	}`
@@ -0,0 +1,203 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// This file checks the names, offsets and skips of splitIntoBitflagRuns and
+// nameAndRest by interpreting them as the loop of the generated String method
+// does, and the names bitflagString computes for -precompute, against a
+// formatter that looks up the name of each bit in a map.
+
+// bitflagConstants returns a constant for each bit set in set, the name of
+// bit i of the given length, taken from lengths in turn.
+func bitflagConstants(set uint64, lengths []int) []Value {
+	var values []Value
+	for i := 0; i < 64; i++ {
+		if set&(1<<uint(i)) == 0 {
+			continue
+		}
+		name := "B" + strconv.Itoa(i)
+		if len(lengths) > 0 {
+			n := lengths[len(values)%len(lengths)]
+			if n > len(name) {
+				name += strings.Repeat("x", n-len(name))
+			}
+		}
+		values = append(values, Value{name: name, value: 1 << uint(i)})
+	}
+	return values
+}
+
+// bitflagInterpret formats m as the generated String method does without
+// a cache, table or composites: v starts as the first value and is shifted
+// once for each offset, an offset of 0 shifting it by the next skip instead,
+// right with msb.
+func bitflagInterpret(typeName, name string, offsets, skips []int, first, m uint64, msb bool) string {
+	if m == 0 {
+		return typeName + "(0)"
+	}
+	var b []byte
+	v := first
+	si := 0
+	p0, p1 := 0, 0
+	shift := func(v uint64, n uint8) uint64 {
+		if msb {
+			return v >> n
+		}
+		return v << n
+	}
+	for i := 0; i < len(offsets); i, v = i+1, shift(v, 1) {
+		o := offsets[i]
+		if o == 0 {
+			v = shift(v, uint8(skips[si])-1) // As the skips are uint8.
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += o
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := typeName + "(0x" + strconv.FormatUint(m, 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	return string(b) + "|" + s + ")"
+}
+
+// bitflagNaive formats m by looking up the name of each bit, from the
+// highest down with msb.
+func bitflagNaive(typeName string, names map[uint64]string, m uint64, msb bool) string {
+	if m == 0 {
+		return typeName + "(0)"
+	}
+	var parts []string
+	for rest := m; rest != 0; rest &= rest - 1 {
+		v := rest & -rest
+		if name, ok := names[v]; ok {
+			parts = append(parts, name)
+			m ^= v
+		}
+	}
+	if msb {
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+	}
+	if m != 0 {
+		parts = append(parts, typeName+"(0x"+strconv.FormatUint(m, 16)+")")
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, "|") + ")"
+}
+
+// checkBitflagRuns compares the interpreted and naive strings of each mask
+// for the constants of the bits set in set, in both bit orders.
+func checkBitflagRuns(t *testing.T, set uint64, lengths []int, masks []uint64) {
+	t.Helper()
+	values := bitflagConstants(set, lengths)
+	names := make(map[uint64]string)
+	for _, v := range values {
+		names[v.value] = v.name
+	}
+	_, runs, _, _ := splitIntoBitflagRuns(values)
+	for _, msb := range []bool{false, true} {
+		order := runs
+		if msb {
+			order = reverseRuns(runs)
+		}
+		g := Generator{msb: msb}
+		name, offsets, skips := g.nameAndRest(order)
+		for _, m := range masks {
+			got := bitflagInterpret("T", name, offsets, skips, order[0][0].value, m, msb)
+			want := bitflagNaive("T", names, m, msb)
+			if got != want {
+				t.Fatalf("bits %#x, msb %v, offsets %v, skips %v: mask %#x is %q, want %q", set, msb, offsets, skips, m, got, want)
+			}
+			// The names -precompute computes, for the named bits.
+			if m&^set == 0 {
+				if got := bitflagString("T", nil, order, nil, m, defaultBitflagFormat); got != want {
+					t.Fatalf("bits %#x, msb %v: mask %#x is precomputed as %q, want %q", set, msb, m, got, want)
+				}
+			}
+		}
+	}
+}
+
+// bitflagMasks returns the masks of each bit, of the bits of set and of
+// some others, and n random masks, mostly of bits of set.
+func bitflagMasks(r *rand.Rand, set uint64, n int) []uint64 {
+	masks := []uint64{0, set, ^uint64(0), set &^ (set - 1), 1 << 63}
+	for i := 0; i < 64; i++ {
+		masks = append(masks, 1<<uint(i))
+	}
+	for i := 0; i < n; i++ {
+		m := r.Uint64() & set
+		if r.Intn(4) == 0 {
+			m |= 1 << uint(r.Intn(64))
+		}
+		masks = append(masks, m)
+	}
+	return masks
+}
+
+func TestBitflagRuns(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, test := range []struct {
+		set     uint64
+		lengths []int
+	}{
+		{0x1, nil},
+		{0x7, nil},
+		{0x5, nil},        // A gap of one bit.
+		{0x8001, nil},     // A long gap.
+		{1 << 63, nil},    // The top bit only.
+		{1<<63 | 1, nil},  // The lowest and top bits.
+		{^uint64(0), nil}, // All the bits.
+		{0x6, []int{1, 300}},
+		{0xf0f0f0f0f0f0f0f0, []int{3, 1, 70}},
+		{0x8000000100000060, []int{2, 255, 256}},
+		{0x5555555555555555, nil}, // Every other bit.
+	} {
+		checkBitflagRuns(t, test.set, test.lengths, bitflagMasks(r, test.set, 200))
+	}
+
+	// Random sets of bits and lengths of names.
+	for i := 0; i < 200; i++ {
+		set := r.Uint64() & r.Uint64()
+		if set == 0 || i%8 == 0 {
+			set = 1 << uint(r.Intn(64))
+		}
+		lengths := make([]int, 1+r.Intn(bits.OnesCount64(set)))
+		for j := range lengths {
+			lengths[j] = 1 + r.Intn(40)
+		}
+		checkBitflagRuns(t, set, lengths, bitflagMasks(r, set, 20))
+	}
+}
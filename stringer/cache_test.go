@@ -0,0 +1,151 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// This file benchmarks the policy of the cache in the generated bitflag code
+// against the one it replaced, which emptied the cache whenever it was full,
+// and the cache against the names computed with -precompute. The two caches
+// below follow the generated code, keyed by uint64 as in the table-driven
+// version.
+
+// resetCache empties itself when full.
+type resetCache struct {
+	size   int
+	cached map[uint64]string
+	mu     sync.RWMutex
+}
+
+func (c *resetCache) mstring(m uint64) string {
+	c.mu.RLock()
+	s, ok := c.cached[m]
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+	s = flagNames(m)
+	c.mu.Lock()
+	if c.cached == nil || len(c.cached) >= c.size {
+		c.cached = make(map[uint64]string, c.size)
+	}
+	c.cached[m] = s
+	c.mu.Unlock()
+	return s
+}
+
+// generationCache keeps the previous generation when full, as generated.
+type generationCache struct {
+	size   int
+	cached map[uint64]string
+	old    map[uint64]string
+	mu     sync.RWMutex
+}
+
+func (c *generationCache) mstring(m uint64) string {
+	c.mu.RLock()
+	s, ok := c.cached[m]
+	old := false
+	if !ok {
+		s, old = c.old[m]
+	}
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = flagNames(m)
+	}
+	c.mu.Lock()
+	if c.cached == nil || len(c.cached) >= c.size {
+		c.old = c.cached
+		c.cached = make(map[uint64]string, c.size)
+	}
+	c.cached[m] = s
+	c.mu.Unlock()
+	return s
+}
+
+// flagNames stands for the String method of a bitflag type, naming each bit.
+func flagNames(m uint64) string {
+	var names []string
+	for i := uint(0); i < 64; i++ {
+		if m&(1<<i) != 0 {
+			names = append(names, "Flag"+strconv.Itoa(int(i)))
+		}
+	}
+	return "(" + strings.Join(names, "|") + ")"
+}
+
+func TestGenerationCache(t *testing.T) {
+	c := &generationCache{size: 4}
+	for m := uint64(1); m <= 6; m++ {
+		if got, want := c.mstring(m), flagNames(m); got != want {
+			t.Fatalf("mstring(%d) = %q, want %q", m, got, want)
+		}
+	}
+	// 1 to 4 filled the first generation, now the previous one.
+	if len(c.old) != 4 || len(c.cached) != 2 {
+		t.Fatalf("generations hold %d and %d names, want 4 and 2", len(c.old), len(c.cached))
+	}
+	// A name of the previous generation moves to the current one.
+	c.mstring(1)
+	if _, ok := c.cached[1]; !ok {
+		t.Errorf("1 was not moved to the current generation")
+	}
+}
+
+// The working set is just above the cache size of 256, the default, and is
+// used in turn, the worst case for a cache emptied when full.
+const benchWorkingSet = 300
+
+func benchmarkCache(b *testing.B, mstring func(uint64) string) {
+	for i := 0; i < b.N; i++ {
+		mstring(uint64(i%benchWorkingSet) + 1)
+	}
+}
+
+func BenchmarkCacheReset(b *testing.B) {
+	c := &resetCache{size: defaultCacheSize}
+	benchmarkCache(b, c.mstring)
+}
+
+func BenchmarkCacheGenerations(b *testing.B) {
+	c := &generationCache{size: defaultCacheSize}
+	benchmarkCache(b, c.mstring)
+}
+
+// With 8 flags, the names of all 256 values fit in the cache, and are
+// computed with -precompute.
+const benchPrecomputedBits = 8
+
+func benchmarkSmall(b *testing.B, mstring func(uint64) string) {
+	for i := 0; i < b.N; i++ {
+		mstring(uint64(i % (1 << benchPrecomputedBits)))
+	}
+}
+
+func BenchmarkSmallCache(b *testing.B) {
+	c := &generationCache{size: defaultCacheSize}
+	benchmarkSmall(b, c.mstring)
+}
+
+func BenchmarkSmallPrecomputed(b *testing.B) {
+	var names [1 << benchPrecomputedBits]string
+	for m := range names {
+		names[m] = flagNames(uint64(m))
+	}
+	benchmarkSmall(b, func(m uint64) string {
+		if m&^(1<<benchPrecomputedBits-1) != 0 {
+			return flagNames(m)
+		}
+		return names[m]
+	})
+}
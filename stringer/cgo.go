@@ -0,0 +1,54 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle constants whose values come from the C pseudo-package.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// refersToC reports whether expr refers to a member of the C pseudo-package.
+func refersToC(info *loader.PackageInfo, expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if pkgName, ok := info.Uses[id].(*types.PkgName); ok && pkgName.Imported().Path() == "C" {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// NeedsCgo reports whether a constant declared in package info takes its
+// value from the C pseudo-package, which only cgo processing can provide.
+// Generate fails for such a constant of a type it generates unless the
+// package was loaded with cgo processing or Options.SkipCgo is set.
+func NeedsCgo(info *loader.PackageInfo) bool {
+	for _, file := range info.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				for _, value := range spec.(*ast.ValueSpec).Values {
+					if refersToC(info, value) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines find the build constraints of the files declaring the
+// constants, so the generated file builds only where the constants exist.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// The operating systems and architectures that a file name suffix can
+// name, as listed by go/build.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"js": true, "linux": true, "nacl": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true,
+		"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+		"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+		"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+	// Operating systems that also satisfy the tag of another.
+	impliedOS = map[string]string{
+		"android": "linux",
+		"illumos": "solaris",
+		"ios":     "darwin",
+	}
+)
+
+// fileConstraint returns the build constraint of the file, that of its
+// //go:build or // +build lines and that of the suffixes of its name, or nil
+// if it has none. A malformed line is ignored, as the go command would
+// have rejected the file.
+func fileConstraint(fset *token.FileSet, file *ast.File) constraint.Expr {
+	var lines, plus []constraint.Expr
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					lines = append(lines, x)
+				}
+			case constraint.IsPlusBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					plus = append(plus, x)
+				}
+			}
+		}
+	}
+	if len(lines) == 0 {
+		// A //go:build line supersedes any // +build lines.
+		lines = plus
+	}
+	if x := nameConstraint(fset.Position(file.Package).Filename); x != nil {
+		lines = append(lines, x)
+	}
+	return and(lines)
+}
+
+// nameConstraint returns the constraint of the _GOOS, _GOARCH or
+// _GOOS_GOARCH suffix of the file name, or nil if it has none.
+func nameConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return nil
+	}
+	l := strings.Split(name[i:], "_")
+	n := len(l)
+	switch {
+	case n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]]:
+		return &constraint.AndExpr{X: &constraint.TagExpr{Tag: l[n-2]}, Y: &constraint.TagExpr{Tag: l[n-1]}}
+	case knownOS[l[n-1]] || knownArch[l[n-1]]:
+		return &constraint.TagExpr{Tag: l[n-1]}
+	}
+	return nil
+}
+
+// and returns the conjunction of the constraints, leaving out repeated
+// terms, or nil if there are none.
+func and(list []constraint.Expr) constraint.Expr {
+	var x constraint.Expr
+	seen := make(map[string]bool)
+	for _, y := range conjuncts(list, nil) {
+		if seen[y.String()] {
+			continue
+		}
+		seen[y.String()] = true
+		if x == nil {
+			x = y
+		} else {
+			x = &constraint.AndExpr{X: x, Y: y}
+		}
+	}
+	return x
+}
+
+// conjuncts appends the terms of the conjunctions in list to terms.
+func conjuncts(list, terms []constraint.Expr) []constraint.Expr {
+	for _, x := range list {
+		switch x := x.(type) {
+		case nil:
+		case *constraint.AndExpr:
+			terms = conjuncts([]constraint.Expr{x.X, x.Y}, terms)
+		default:
+			terms = append(terms, x)
+		}
+	}
+	return terms
+}
+
+// satisfiable reports whether some build configuration satisfies x, with
+// at most one operating system, but for those it implies, and at most one
+// architecture. Constraints with many tags are assumed satisfiable.
+func satisfiable(x constraint.Expr) bool {
+	tags := exprTags(x, nil)
+	if len(tags) > 16 {
+		return true
+	}
+	for set := 0; set < 1<<uint(len(tags)); set++ {
+		on := make(map[string]bool)
+		var oses []string
+		arch := 0
+		for i, tag := range tags {
+			if set&(1<<uint(i)) == 0 {
+				continue
+			}
+			on[tag] = true
+			if knownOS[tag] {
+				oses = append(oses, tag)
+			}
+			if knownArch[tag] {
+				arch++
+			}
+		}
+		if arch > 1 || !oneOS(oses) {
+			continue
+		}
+		if x.Eval(func(tag string) bool { return on[tag] }) {
+			return true
+		}
+	}
+	return false
+}
+
+// exprTags appends the tags of x not in list to list.
+func exprTags(x constraint.Expr, list []string) []string {
+	switch x := x.(type) {
+	case *constraint.TagExpr:
+		for _, tag := range list {
+			if tag == x.Tag {
+				return list
+			}
+		}
+		return append(list, x.Tag)
+	case *constraint.NotExpr:
+		return exprTags(x.X, list)
+	case *constraint.AndExpr:
+		return exprTags(x.Y, exprTags(x.X, list))
+	case *constraint.OrExpr:
+		return exprTags(x.Y, exprTags(x.X, list))
+	}
+	return list
+}
+
+// oneOS reports whether the operating system tags can be set together.
+func oneOS(oses []string) bool {
+	switch len(oses) {
+	case 0, 1:
+		return true
+	case 2:
+		return impliedOS[oses[0]] == oses[1] || impliedOS[oses[1]] == oses[0]
+	}
+	return false
+}
+
+// buildConstraint returns the constraint of the generated file: that of
+// the files declaring the constants and that of the -tags flag. It returns
+// nil if there is none, or if the files' constraints conflict.
+func buildConstraint(fset *token.FileSet, files []*ast.File, tags []string) (x constraint.Expr, conflict bool) {
+	var list []constraint.Expr
+	for _, tag := range tags {
+		list = append(list, &constraint.TagExpr{Tag: tag})
+	}
+	for _, file := range files {
+		list = append(list, fileConstraint(fset, file))
+	}
+	x = and(list)
+	if x != nil && !satisfiable(x) {
+		return nil, true
+	}
+	return x, false
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// parseFiles parses the files, given as pairs of name and source.
+func parseFiles(t *testing.T, fset *token.FileSet, pairs ...string) []*ast.File {
+	var files []*ast.File
+	for i := 0; i < len(pairs); i += 2 {
+		f, err := parser.ParseFile(fset, pairs[i], pairs[i+1], parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+func TestFileConstraint(t *testing.T) {
+	for _, test := range []struct {
+		name, src string
+		want      string // "" for no constraint
+	}{
+		{"days.go", "package p\n", ""},
+		{"days_linux.go", "package p\n", "linux"},
+		{"days_amd64.go", "package p\n", "amd64"},
+		{"days_linux_amd64.go", "package p\n", "linux && amd64"},
+		{"days_windows_test.go", "package p\n", "windows"},
+		{"linux.go", "package p\n", ""}, // A suffix follows an underscore.
+		{"days_other.go", "package p\n", ""},
+		{"days.go", "//go:build foo && !bar\n\npackage p\n", "foo && !bar"},
+		{"days.go", "// +build foo bar\n// +build !baz\n\npackage p\n", "(foo || bar) && !baz"},
+		{"days.go", "//go:build foo\n// +build bar\n\npackage p\n", "foo"},
+		{"days.go", "// Package p.\npackage p\n\n//go:build foo\n", ""}, // After the package clause.
+		{"days_linux.go", "//go:build foo\n\npackage p\n", "foo && linux"},
+	} {
+		fset := token.NewFileSet()
+		f := parseFiles(t, fset, test.name, test.src)[0]
+		got := ""
+		if x := fileConstraint(fset, f); x != nil {
+			got = x.String()
+		}
+		if got != test.want {
+			t.Errorf("%s %q: got %q, want %q", test.name, test.src, got, test.want)
+		}
+	}
+}
+
+func TestBuildConstraint(t *testing.T) {
+	for _, test := range []struct {
+		files    []string // pairs of name and source
+		tags     []string
+		want     string // "" for no constraint
+		conflict bool
+	}{
+		{[]string{"days.go", "package p\n"}, nil, "", false},
+		{[]string{"days.go", "package p\n"}, []string{"foo", "bar"}, "foo && bar", false},
+		{[]string{"days.go", "package p\n", "days_linux.go", "package p\n"}, nil, "linux", false},
+		{[]string{"a_linux.go", "package p\n", "b_linux.go", "package p\n"}, nil, "linux", false},
+		{[]string{"days_linux.go", "//go:build foo\n\npackage p\n"}, []string{"foo"}, "foo && linux", false},
+		{[]string{"days_android.go", "package p\n", "days_linux.go", "package p\n"}, nil, "android && linux", false},
+		{[]string{"days_linux.go", "package p\n", "days_windows.go", "package p\n"}, nil, "", true},
+		{[]string{"days_amd64.go", "package p\n", "days_arm64.go", "package p\n"}, nil, "", true},
+		{[]string{"a.go", "//go:build foo\n\npackage p\n", "b.go", "//go:build !foo\n\npackage p\n"}, nil, "", true},
+	} {
+		fset := token.NewFileSet()
+		files := parseFiles(t, fset, test.files...)
+		x, conflict := buildConstraint(fset, files, test.tags)
+		got := ""
+		if x != nil {
+			got = x.String()
+		}
+		if got != test.want || conflict != test.conflict {
+			t.Errorf("%q %q: got %q, %v; want %q, %v", test.files, test.tags, got, conflict, test.want, test.conflict)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the <type>Names and <type>Values arrays of
+// -exporttables, which list the constants of a type without calling String.
+// The names are sliced from the strings of names the String method already
+// uses, so the package holds a single copy of them.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// nameSlices returns the expression of the name of each value of the runs,
+// in order, sliced from the string constant name holding them all, or, if
+// multi is set, from the string of each run, as declared by
+// declareIndexAndNameVars.
+func nameSlices(runs [][]Value, name string, multi bool) []string {
+	var exprs []string
+	n := 0
+	for r, run := range runs {
+		runName := name
+		if multi {
+			runName = fmt.Sprintf("%s_%d", name, r)
+			n = 0
+		}
+		for i := range run {
+			if multi && len(run) == 1 {
+				exprs = append(exprs, runName)
+				continue
+			}
+			exprs = append(exprs, fmt.Sprintf("%s[%d:%d]", runName, n, n+len(run[i].name)))
+			n += len(run[i].name)
+		}
+	}
+	return exprs
+}
+
+// buildBitflagExportTables generates the exported arrays of the values of a
+// bitflag type, whose names are sliced from those of the runs, in the order
+// the String method has them, followed by the composites.
+func (g *Generator) buildBitflagExportTables(zero *Value, runs [][]Value, composites []Value, typeName string) {
+	name := fmt.Sprintf("_%s_name", typeName)
+	switch {
+	case g.table && g.cache:
+		name = fmt.Sprintf("_%s_stringer.sb.names", typeName)
+	case g.table:
+		name = fmt.Sprintf("_%s_stringer.names", typeName)
+	}
+	printed := append(runs[:len(runs):len(runs)], composites)
+	exprs := nameSlices(printed, name, false)
+	byValue := make(map[uint64]string)
+	for _, v := range runValues(printed) {
+		byValue[v.value] = exprs[0]
+		exprs = exprs[1:]
+	}
+	if zero != nil {
+		byValue[0] = strconv.Quote(zero.name)
+	}
+	values := bitflagValues(zero, runs, composites)
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = byValue[v.value]
+	}
+	g.buildExportTables(values, names, typeName)
+}
+
+// buildExportTables generates the exported arrays of the values, which are in
+// increasing order with no duplicates, in the order of the -sort flag, and of
+// their names, the expressions of names.
+func (g *Generator) buildExportTables(values []Value, names []string, typeName string) {
+	index := g.orderIndex(values)
+	ordered := make([]Value, len(values))
+	nameList := new(bytes.Buffer)
+	for i, j := range index {
+		ordered[i] = values[j]
+		fmt.Fprintf(nameList, "\t%s,\n", names[j])
+	}
+	order := "in increasing order"
+	switch g.sort {
+	case sortDecl:
+		order = "in the order declared"
+	case sortName:
+		order = "in the order of their names"
+	}
+	g.Printf(exportTables, typeName, nameList, valueList(ordered), order)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: the names, one per line
+//	[3]: the values, separated by commas
+//	[4]: the order of the values
+const exportTables = `
+// %[1]sNames holds the names of the %[1]s constants, as printed by String,
+// in the order of %[1]sValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var %[1]sNames = [...]string{
+%[2]s}
+
+// %[1]sValues holds the values of the %[1]s constants %[4]s.
+// It is generated; do not modify it.
+var %[1]sValues = [...]%[1]s{%[3]s}
+`
@@ -0,0 +1,227 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// The arrays of -exporttables slice the names from the strings of each
+// layout of the String method: one run, several, a map, bitflags with and
+// without a table, and string constants.
+func TestGoldenExportTables(t *testing.T) {
+	for n, test := range []struct {
+		name   string
+		input  string
+		opts   Options
+		output string // From the declaration of the names.
+	}{
+		{"day", day_in, Options{}, day_out_export},
+		{"gap", gap_in, Options{}, gap_out_export},
+		{"prime", prime_in, Options{Sort: sortName}, prime_out_export_sorted},
+		{"composite", composite_in_bitflag, Options{Bitflag: true, NoCache: true, NoTable: true}, composite_out_bitflag_export},
+		{"composite", composite_in_bitflag, Options{Bitflag: true}, composite_out_bitflag_export_table},
+		{"gap", gap_in_bitflag, Options{Bitflag: true, NoTable: true, BitOrder: bitOrderMSB}, gap_out_bitflag_export_msb},
+		{"region", region_in, Options{}, region_out_export},
+	} {
+		test.opts.ExportTables = true
+		got := goldenGenerate(t, test.opts, test.name, test.input)
+		typeName := strings.SplitN(test.input, " ", 3)[1]
+		i := strings.Index(got, fmt.Sprintf("\n// %sNames ", typeName))
+		if i < 0 {
+			t.Errorf("%s: no names declared in\n%s", test.name, got)
+			continue
+		}
+		if got = got[i:]; got != test.output {
+			t.Errorf("%s #%d: got\n====\n%s====\nexpected\n====\n%s", test.name, n, got, test.output)
+		}
+	}
+}
+
+const day_out_export = `
+// DayNames holds the names of the Day constants, as printed by String,
+// in the order of DayValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var DayNames = [...]string{
+	_Day_name[0:6],
+	_Day_name[6:13],
+	_Day_name[13:22],
+	_Day_name[22:30],
+	_Day_name[30:36],
+	_Day_name[36:44],
+	_Day_name[44:50],
+}
+
+// DayValues holds the values of the Day constants in increasing order.
+// It is generated; do not modify it.
+var DayValues = [...]Day{0, 1, 2, 3, 4, 5, 6}
+`
+
+const gap_out_export = `
+// GapNames holds the names of the Gap constants, as printed by String,
+// in the order of GapValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var GapNames = [...]string{
+	_Gap_name_0[0:3],
+	_Gap_name_0[3:8],
+	_Gap_name_1[0:4],
+	_Gap_name_1[4:7],
+	_Gap_name_1[7:12],
+	_Gap_name_1[12:17],
+	_Gap_name_1[17:21],
+	_Gap_name_2,
+}
+
+// GapValues holds the values of the Gap constants in increasing order.
+// It is generated; do not modify it.
+var GapValues = [...]Gap{2, 3, 5, 6, 7, 8, 9, 11}
+`
+
+const prime_out_export_sorted = `
+// PrimeNames holds the names of the Prime constants, as printed by String,
+// in the order of PrimeValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var PrimeNames = [...]string{
+	_Prime_name[8:11],
+	_Prime_name[11:14],
+	_Prime_name[14:17],
+	_Prime_name[17:20],
+	_Prime_name[0:2],
+	_Prime_name[20:23],
+	_Prime_name[23:26],
+	_Prime_name[2:4],
+	_Prime_name[26:29],
+	_Prime_name[29:32],
+	_Prime_name[32:35],
+	_Prime_name[4:6],
+	_Prime_name[6:8],
+}
+
+// PrimeValues holds the values of the Prime constants in the order of their names.
+// It is generated; do not modify it.
+var PrimeValues = [...]Prime{11, 13, 17, 19, 2, 23, 29, 3, 31, 41, 43, 5, 7}
+`
+
+const composite_out_bitflag_export = `
+// DaysNames holds the names of the Days constants, as printed by String,
+// in the order of DaysValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var DaysNames = [...]string{
+	_Days_name[0:6],
+	_Days_name[6:13],
+	_Days_name[13:22],
+	_Days_name[22:30],
+	_Days_name[30:36],
+	_Days_name[50:58],
+	_Days_name[36:44],
+	_Days_name[44:50],
+	_Days_name[69:76],
+	_Days_name[58:69],
+}
+
+// DaysValues holds the values of the Days constants in increasing order.
+// It is generated; do not modify it.
+var DaysValues = [...]Days{1, 2, 4, 8, 16, 31, 32, 64, 96, 112}
+`
+
+const composite_out_bitflag_export_table = `
+// DaysNames holds the names of the Days constants, as printed by String,
+// in the order of DaysValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var DaysNames = [...]string{
+	_Days_stringer.sb.names[0:6],
+	_Days_stringer.sb.names[6:13],
+	_Days_stringer.sb.names[13:22],
+	_Days_stringer.sb.names[22:30],
+	_Days_stringer.sb.names[30:36],
+	_Days_stringer.sb.names[50:58],
+	_Days_stringer.sb.names[36:44],
+	_Days_stringer.sb.names[44:50],
+	_Days_stringer.sb.names[69:76],
+	_Days_stringer.sb.names[58:69],
+}
+
+// DaysValues holds the values of the Days constants in increasing order.
+// It is generated; do not modify it.
+var DaysValues = [...]Days{1, 2, 4, 8, 16, 31, 32, 64, 96, 112}
+`
+
+const gap_out_bitflag_export_msb = `
+// GapNames holds the names of the Gap constants, as printed by String,
+// in the order of GapValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var GapNames = [...]string{
+	"Zero",
+	_Gap_name[32:35],
+	_Gap_name[27:32],
+	_Gap_name[23:27],
+	_Gap_name[20:23],
+	_Gap_name[15:20],
+	_Gap_name[10:15],
+	_Gap_name[6:10],
+	_Gap_name[0:6],
+}
+
+// GapValues holds the values of the Gap constants in increasing order.
+// It is generated; do not modify it.
+var GapValues = [...]Gap{0, 4, 8, 32, 64, 128, 256, 512, 2048}
+`
+
+const region_out_export = `
+// RegionNames holds the names of the Region constants, as printed by String,
+// in the order of RegionValues. It is generated, sharing the strings String
+// returns; do not modify it.
+var RegionNames = [...]string{
+	"ap-south",
+	"eu-central",
+	"say \"hi\"",
+	"us-west",
+}
+
+// RegionValues holds the values of the Region constants in increasing order.
+// It is generated; do not modify it.
+var RegionValues = [...]Region{"ap-south", "eu-central", "say \"hi\"", "us-west"}
+`
+
+// The arrays add no copy of the names: each is in the generated file once,
+// which grows by less than their length, for each layout of the names.
+func TestExportTablesShareNames(t *testing.T) {
+	in := new(strings.Builder)
+	in.WriteString("type Long uint16\nconst (\n")
+	var names []string
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("Long%d%s", i, strings.Repeat("x", 200))
+		names = append(names, name)
+		fmt.Fprintf(in, "\t%s Long = %d\n", name, 1<<uint(i))
+	}
+	in.WriteString(")\n")
+	for n, opts := range []Options{
+		{},
+		{Bitflag: true, NoTable: true},
+		{Bitflag: true},
+	} {
+		without := goldenGenerate(t, opts, "long", in.String())
+		opts.ExportTables = true
+		with := goldenGenerate(t, opts, "long", in.String())
+		for _, name := range names {
+			if c := strings.Count(with, name); c != 1 {
+				t.Errorf("#%d: %s... is %d times in the file", n, name[:6], c)
+			}
+		}
+		if grown := len(with) - len(without); grown >= len(names)*len(names[0]) {
+			t.Errorf("#%d: the file grows by %d bytes, more than the names", n, grown)
+		}
+	}
+}
+
+func TestExportTablesAndValues(t *testing.T) {
+	opts := Options{ExportTables: true, Values: true}
+	err := opts.check()
+	if err == nil || !strings.Contains(err.Error(), "-exporttables and -values") {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle -bitflagformat, which sets the separator of the names
+// of the bits set in a bitflag value, | by default, and the brackets around
+// several of them, ( and ) by default, and -unknownbase, which sets the base
+// the bits with no name are printed in, hexadecimal by default. The code
+// that depends on the format is passed to the templates of the methods.
+
+package stringer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The brackets of -bitflagformat.
+const (
+	bracketsParen  = "paren"
+	bracketsSquare = "square"
+	bracketsNone   = "none"
+)
+
+// The bases of -unknownbase, and the numbers they stand for.
+var unknownBases = map[string]int{
+	"hex": 16,
+	"dec": 10,
+	"bin": 2,
+}
+
+// A bitflagFormat is how the names of several bits set are printed.
+type bitflagFormat struct {
+	sep      string // Between the names.
+	brackets string // Around the names: paren, square or none.
+	base     int    // Of the bits with no name: 16, 10 or 2.
+}
+
+var defaultBitflagFormat = bitflagFormat{sep: "|", brackets: bracketsParen, base: 16}
+
+// parseUnknownBase returns the number of base, hex, dec or bin, of -unknownbase.
+func parseUnknownBase(base string) (int, error) {
+	n, ok := unknownBases[base]
+	if !ok {
+		return 0, fmt.Errorf("invalid -unknownbase %q; must be hex, dec or bin", base)
+	}
+	return n, nil
+}
+
+// basePrefix returns the prefix of the numbers printed in base, as 0x.
+func basePrefix(base int) string {
+	switch base {
+	case 10:
+		return ""
+	case 2:
+		return "0b"
+	}
+	return "0x"
+}
+
+// unknown returns the name of the bits m with no name, as the conversion of
+// the number to the type.
+func (f bitflagFormat) unknown(typeName string, m uint64) string {
+	return typeName + "(" + basePrefix(f.base) + strconv.FormatUint(m, f.base) + ")"
+}
+
+// parseBitflagFormat returns the format of spec, a comma-separated list of
+// sep=<separator> and brackets=paren|square|none, each defaulting to the
+// format of "(A|B)". The separator runs up to a comma starting the next key,
+// so it may be a comma itself, as in sep=,,brackets=none.
+func parseBitflagFormat(spec string) (bitflagFormat, error) {
+	f := defaultBitflagFormat
+	for spec != "" {
+		eq := strings.Index(spec, "=")
+		if eq < 0 {
+			return f, fmt.Errorf("invalid -bitflagformat %q: want key=value", spec)
+		}
+		key, rest := spec[:eq], spec[eq+1:]
+		value := rest
+		spec = ""
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == ',' && (strings.HasPrefix(rest[i+1:], "sep=") || strings.HasPrefix(rest[i+1:], "brackets=")) {
+				value, spec = rest[:i], rest[i+1:]
+				break
+			}
+		}
+		switch key {
+		case "sep":
+			if value == "" || !utf8.ValidString(value) {
+				return f, fmt.Errorf("invalid -bitflagformat separator %q", value)
+			}
+			f.sep = value
+		case "brackets":
+			switch value {
+			case bracketsParen, bracketsSquare, bracketsNone:
+				f.brackets = value
+			default:
+				return f, fmt.Errorf("invalid -bitflagformat brackets %q; must be %s, %s or %s", value, bracketsParen, bracketsSquare, bracketsNone)
+			}
+		default:
+			return f, fmt.Errorf("invalid -bitflagformat key %q; must be sep or brackets", key)
+		}
+	}
+	return f, nil
+}
+
+// open and close return the brackets around several names, or "".
+func (f bitflagFormat) open() string {
+	switch f.brackets {
+	case bracketsSquare:
+		return "["
+	case bracketsNone:
+		return ""
+	}
+	return "("
+}
+
+func (f bitflagFormat) close() string {
+	switch f.brackets {
+	case bracketsSquare:
+		return "]"
+	case bracketsNone:
+		return ""
+	}
+	return ")"
+}
+
+// join returns the names as printed by the generated String method.
+func (f bitflagFormat) join(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return f.open() + strings.Join(names, f.sep) + f.close()
+}
+
+// tableFields returns the fields of the table of a type setting the format,
+// which the shared code defaults to that of "(A|B)".
+func (f bitflagFormat) tableFields() string {
+	s := ""
+	if f.sep != defaultBitflagFormat.sep {
+		s += fmt.Sprintf("\n\tsep: %q,", f.sep)
+	}
+	if f.brackets != defaultBitflagFormat.brackets {
+		s += fmt.Sprintf("\n\tbrackets: %q,", f.brackets)
+	}
+	if f.base != defaultBitflagFormat.base {
+		s += fmt.Sprintf("\n\tbase: %d,", f.base)
+	}
+	return s
+}
+
+// byteLiteral returns the rune literal of s, of one byte, as is | in the
+// code generated.
+func byteLiteral(s string) string {
+	return strconv.QuoteRune(rune(s[0]))
+}
+
+// stringLiteral returns the string literal of s.
+func stringLiteral(s string) string {
+	return strconv.Quote(s)
+}
+
+// appendLiteral returns the last argument of the call of append adding s to b.
+func appendLiteral(s string) string {
+	if len(s) == 1 {
+		return byteLiteral(s)
+	}
+	return stringLiteral(s) + "..."
+}
+
+// The code below, for the format f, is passed to the templates of the
+// String, Flags, parse and GoString methods of a bitflag type.
+
+// bracketCode returns the statement appending bracket to b, on a line of its
+// own indented by depth tabs, or "" if there is no bracket.
+func bracketCode(bracket string, depth int) string {
+	if bracket == "" {
+		return ""
+	}
+	return "\n" + strings.Repeat("\t", depth) + "b = append(b, " + appendLiteral(bracket) + ")"
+}
+
+// sepCode returns the last argument of the call of append adding the
+// separator to b.
+func (f bitflagFormat) sepCode() string {
+	return appendLiteral(f.sep)
+}
+
+// unknownCode returns the expression of the name of the bits v of the type
+// with no name, as that of unknown.
+func (f bitflagFormat) unknownCode(typeName, v string) string {
+	return fmt.Sprintf("%s + strconv.FormatUint(uint64(%s), %d) + \")\"", stringLiteral(typeName+"("+basePrefix(f.base)), v, f.base)
+}
+
+// joinedCode returns the statements of the String method with composites
+// returning the names in b, n of them, within the brackets if several.
+func (f bitflagFormat) joinedCode() string {
+	if f.open() == "" {
+		return "return string(b)"
+	}
+	return fmt.Sprintf("if n == 1 {\n\t\treturn string(b)\n\t}\n\treturn %s + string(b) + %s", stringLiteral(f.open()), stringLiteral(f.close()))
+}
+
+// parseUnbracketCode returns the statements of the parse function removing
+// the brackets around several names, or "" if there are none.
+func (f bitflagFormat) parseUnbracketCode() string {
+	if f.open() == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\tif len(s) > 2 && s[0] == %s && s[len(s)-1] == %s {\n\t\ts = s[1 : len(s)-1]\n\t}", byteLiteral(f.open()), byteLiteral(f.close()))
+}
+
+// goStringUnbracketCode returns the statements of the GoString method
+// removing the brackets around several names, or "" if there are none.
+func (f bitflagFormat) goStringUnbracketCode() string {
+	if f.open() == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\tif strings.HasPrefix(s, %s) {\n\t\ts = s[1 : len(s)-1]\n\t}", stringLiteral(f.open()))
+}
+
+// splitCode returns the condition of the parse function that s[i] does not
+// start the separator.
+func (f bitflagFormat) splitCode() string {
+	if len(f.sep) == 1 {
+		return fmt.Sprintf("s[i] != %s", byteLiteral(f.sep))
+	}
+	return fmt.Sprintf("!strings.HasPrefix(s[i:], %s)", stringLiteral(f.sep))
+}
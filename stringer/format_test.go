@@ -0,0 +1,348 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBitflagFormat(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		want bitflagFormat
+		err  string
+	}{
+		{"", defaultBitflagFormat, ""},
+		{"sep=,", bitflagFormat{",", bracketsParen, 16}, ""},
+		{"sep=,,brackets=none", bitflagFormat{",", bracketsNone, 16}, ""},
+		{"brackets=square,sep=, ", bitflagFormat{", ", bracketsSquare, 16}, ""},
+		{"sep=a,b", bitflagFormat{"a,b", bracketsParen, 16}, ""},
+		{"sep=%", bitflagFormat{"%", bracketsParen, 16}, ""},
+		{"sep=", bitflagFormat{}, `invalid -bitflagformat separator ""`},
+		{"brackets=curly", bitflagFormat{}, `invalid -bitflagformat brackets "curly"`},
+		{"space= ", bitflagFormat{}, `invalid -bitflagformat key "space"`},
+		{"none", bitflagFormat{}, `invalid -bitflagformat "none"`},
+	} {
+		got, err := parseBitflagFormat(test.spec)
+		switch {
+		case test.err != "":
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q: got error %v, want %q", test.spec, err, test.err)
+			}
+		case err != nil:
+			t.Errorf("%q: %v", test.spec, err)
+		case got != test.want:
+			t.Errorf("%q: got %+v, want %+v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestGoldenBitflagFormat(t *testing.T) {
+	for _, test := range []struct {
+		table  bool
+		output string
+	}{
+		{false, perm_out_bitflag_comma},
+		{true, perm_out_bitflag_comma_table},
+	} {
+		opts := Options{
+			Bitflag:       true,
+			NoCache:       true,
+			NoTable:       !test.table,
+			Parse:         true,
+			BitflagFormat: "sep=,,brackets=none",
+		}
+		got := goldenGenerate(t, opts, "perm", perm_in_bitflag)
+		if got != test.output {
+			t.Errorf("table=%v: got\n====\n%s====\nexpected\n====\n%s", test.table, got, test.output)
+		}
+	}
+}
+
+// The code of a format with a separator of several bytes, holding %, and
+// square brackets.
+func TestBitflagFormatCode(t *testing.T) {
+	for _, test := range []struct {
+		name, input string
+		want        []string
+	}{
+		{"perm", perm_in_bitflag, []string{
+			`b = append(b, "%%"...)`,
+			`return "[" + string(b) + "]"`,
+			`if len(s) > 2 && s[0] == '[' && s[len(s)-1] == ']' {`,
+			`for i < len(s) && !strings.HasPrefix(s[i:], "%%") {`,
+			`s = s[i+2:]`,
+			`if strings.HasPrefix(s, "[") {`,
+			`strings.Replace(s, "%%", "|test.", -1)`,
+		}},
+		{"days", days_in_bitflag, []string{
+			`b = append(b, '[')`,
+			`b = append(b, "%%"...)`,
+			`b = append(b, ']')`,
+		}},
+	} {
+		opts := Options{Bitflag: true, NoCache: true, NoTable: true, Parse: true, GoString: true, BitflagFormat: "brackets=square,sep=%%"}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: no %s in\n%s", test.name, want, got)
+			}
+		}
+	}
+}
+
+// The names of the bitflag values are separated by commas, without brackets,
+// and parsed back in that format.
+const perm_out_bitflag_comma = `
+const _Perm_name = "ReadWriteExecRW"
+
+var (
+	_Perm_offset     = [...]uint8{4, 5, 4}
+	_Perm_composites = [...]Perm{3}
+	_Perm_cindex     = [...]uint8{13, 15}
+)
+
+func (m Perm) String() string {
+	if m == 0 {
+		return "None"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, _Perm_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Perm_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+
+var _Perm_value = map[string]Perm{
+	_Perm_name[0:4]:   1,
+	_Perm_name[4:9]:   2,
+	_Perm_name[9:13]:  4,
+	_Perm_name[13:15]: 3,
+	"Readable":        1,
+}
+
+func _Perm_parse(s string) (Perm, error) {
+	if s == "None" {
+		return 0, nil
+	}
+	var m Perm
+	for {
+		i := 0
+		for i < len(s) && s[i] != ',' {
+			i++
+		}
+		v, ok := _Perm_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Perm values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+// PermString returns the Perm value whose name is s.
+func PermString(s string) (Perm, error) {
+	return _Perm_parse(s)
+}
+`
+
+const perm_out_bitflag_comma_table = `
+var _Perm_stringer = _stringerBitflag{
+	typename:   "Perm",
+	zero:       "None",
+	first:      uint64(1),
+	names:      "ReadWriteExecRW",
+	offsets:    []uint16{4, 5, 4},
+	sep:        ",",
+	brackets:   "none",
+	composites: []uint64{3},
+	cindex:     []uint16{13, 15},
+}
+
+func (m Perm) String() string {
+	return _Perm_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	return _Perm_stringer.mslice(uint64(m))
+}
+
+var _Perm_value = map[string]Perm{
+	"Read":     1,
+	"Write":    2,
+	"Exec":     4,
+	"RW":       3,
+	"Readable": 1,
+}
+
+func _Perm_parse(s string) (Perm, error) {
+	if s == "None" {
+		return 0, nil
+	}
+	var m Perm
+	for {
+		i := 0
+		for i < len(s) && s[i] != ',' {
+			i++
+		}
+		v, ok := _Perm_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Perm values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+// PermString returns the Perm value whose name is s.
+func PermString(s string) (Perm, error) {
+	return _Perm_parse(s)
+}
+`
+
+// A name holding the separator could not be parsed back.
+func TestBitflagFormatSeparatorInName(t *testing.T) {
+	info, fset := loadPackage(t, "perm", map[string]string{
+		"perm.go": "package perm\n" + perm_in_bitflag + "\nconst Both Perm = 16 // Read,Write\n",
+	})
+	_, err := Generate(fset, info, []string{"Perm"}, Options{Bitflag: true, LineComment: true, BitflagFormat: "sep=,"})
+	want := `the name "Read,Write" holds the separator "," of -bitflagformat`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+	if _, err := Generate(fset, info, []string{"Perm"}, Options{Bitflag: true, LineComment: true}); err != nil {
+		t.Errorf("default format: %v", err)
+	}
+}
+
+// The bits with no name print in each base, in the code generated with and
+// without a table and in the names computed by -precompute.
+func TestUnknownBase(t *testing.T) {
+	for _, test := range []struct {
+		base   string
+		inline string // In the code of -notable.
+		table  string // In the table.
+		name   string // Of Days(0x280) with -precompute.
+	}{
+		{"", `"Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"`, "", "Days(0x280)"},
+		{"hex", `"Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"`, "", "Days(0x280)"},
+		{"dec", `"Days(" + strconv.FormatUint(uint64(m), 10) + ")"`, "base:     10,", "Days(640)"},
+		{"bin", `"Days(0b" + strconv.FormatUint(uint64(m), 2) + ")"`, "base:     2,", "Days(0b1010000000)"},
+	} {
+		opts := Options{Bitflag: true, NoCache: true, NoTable: true, UnknownBase: test.base}
+		got := goldenGenerate(t, opts, "days", days_in_bitflag)
+		if !strings.Contains(got, test.inline) {
+			t.Errorf("%q -notable: no %s in\n%s", test.base, test.inline, got)
+		}
+		if strings.Contains(got, "0x..") != (test.name[5:7] == "0x") {
+			t.Errorf("%q -notable: the Flags comment does not match the base in\n%s", test.base, got)
+		}
+		opts.NoTable = false
+		got = goldenGenerate(t, opts, "days", days_in_bitflag)
+		if test.table != "" && !strings.Contains(got, test.table) || test.table == "" && strings.Contains(got, "base:") {
+			t.Errorf("%q table: want %q in\n%s", test.base, test.table, got)
+		}
+		f := defaultBitflagFormat
+		if test.base != "" {
+			f.base, _ = parseUnknownBase(test.base)
+		}
+		if got := bitflagString("Days", nil, nil, nil, 0x280, f); got != test.name {
+			t.Errorf("%q: precomputed %q, want %q", test.base, got, test.name)
+		}
+	}
+
+	opts := Options{UnknownBase: "oct", Bitflag: true}
+	err := opts.check()
+	if err == nil || !strings.Contains(err.Error(), `invalid -unknownbase "oct"`) {
+		t.Errorf("-unknownbase=oct: got error %v", err)
+	}
+}
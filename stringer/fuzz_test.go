@@ -0,0 +1,33 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package stringer
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// FuzzBitflagRuns is the fuzz target of TestBitflagRuns: the bits of set
+// have constants, whose names have lengths from seed, and masks from seed
+// are checked along with m.
+func FuzzBitflagRuns(f *testing.F) {
+	f.Add(uint64(0x5), int64(1), uint64(0x4))
+	f.Add(uint64(1<<63|1), int64(2), uint64(1<<63|2))
+	f.Add(^uint64(0), int64(3), uint64(0xf0))
+	f.Fuzz(func(t *testing.T, set uint64, seed int64, m uint64) {
+		if set == 0 {
+			return
+		}
+		r := rand.New(rand.NewSource(seed))
+		lengths := make([]int, 1+r.Intn(8))
+		for i := range lengths {
+			lengths[i] = 1 + r.Intn(300)
+		}
+		checkBitflagRuns(t, set, lengths, append(bitflagMasks(r, set, 10), m))
+	})
+}
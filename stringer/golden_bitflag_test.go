@@ -0,0 +1,3553 @@
+// Copyright 2018 Frank Rehwinkel
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains simple golden tests for various bitflag examples.
+// Besides validating the results when the implementation changes,
+// it provides a way to look at the generated code without having
+// to execute the print statements in one's head.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGoldenBitflag(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		trimPrefix  string
+		lineComment bool
+		input       string // input; the package clause is provided when running the test.
+
+		// Two outputs when table format is false
+		nocache_notable_output  string // expected output having specified nocache to the generator.
+		yescache_notable_output string // expected output with the generator allowed to use a map to cache results.
+
+		// Two outputs when table format is true
+		nocache_yestable_output  string
+		yescache_yestable_output string
+	}{
+		{"days", "", false, days_in_bitflag,
+			days_out_bitflag, days_out_bitflag_cache,
+			days_out_bitflag_table, days_out_bitflag_cache_table},
+		{"gap", "", false, gap_in_bitflag,
+			gap_out_bitflag, gap_out_bitflag_cache,
+			gap_out_bitflag_table, gap_out_bitflag_cache_table},
+		{"largegap", "", false, largegap_in_bitflag,
+			largegap_out_bitflag, largegap_out_bitflag_cache,
+			largegap_out_bitflag_table, largegap_out_bitflag_cache_table},
+		{"largestgap", "", false, largestgap_in_bitflag,
+			largestgap_out_bitflag, largestgap_out_bitflag_cache,
+			largestgap_out_bitflag_table, largestgap_out_bitflag_cache_table},
+		{"composite", "", false, composite_in_bitflag,
+			composite_out_bitflag, composite_out_bitflag_cache,
+			composite_out_bitflag_table, composite_out_bitflag_cache_table},
+		{"compositegap", "", false, compositegap_in_bitflag,
+			compositegap_out_bitflag, compositegap_out_bitflag_cache,
+			compositegap_out_bitflag_table, compositegap_out_bitflag_cache_table},
+	} {
+
+		// Run two versions of test, one with cache false, other with cache true.
+
+		for _, table := range []bool{false, true} {
+			for _, cache := range []bool{false, true} {
+				var cname, expected string
+				if table {
+					if cache {
+						cname = "cache=y,table=y"
+						expected = test.yescache_yestable_output
+					} else {
+						cname = "cache=n,table=y"
+						expected = test.nocache_yestable_output
+					}
+				} else {
+					if cache {
+						cname = "cache=y,table=n"
+						expected = test.yescache_notable_output
+					} else {
+						cname = "cache=n,table=n"
+						expected = test.nocache_notable_output
+					}
+				}
+				expected = string(formatBytes([]byte(expected)))
+				t.Run(cname+test.name, func(t *testing.T) {
+					opts := Options{
+						TrimPrefix:  test.trimPrefix,
+						LineComment: test.lineComment,
+						Bitflag:     true,
+						NoCache:     !cache,
+						NoTable:     !table,
+					}
+					got := goldenGenerate(t, opts, test.name, test.input)
+					if got != expected {
+						t.Errorf("%s: got\n====\nlen %d====\nexpected\n====len %d",
+							test.name, len(got), len(expected))
+						t.Errorf("%s: got\n====\n%s====\nexpected\n====%s",
+							test.name, got, expected)
+					}
+				})
+			}
+		}
+	}
+}
+
+const days_in_bitflag = `type Days int
+const (
+	Monday Days = 1 << iota
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+)
+`
+
+const days_out_bitflag = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Days_offset = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+
+func (m Days) String() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	var b []byte
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Days_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const days_out_bitflag_cache = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var (
+	_Days_offset   = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_cache    = make(map[Days]string)
+	_Days_cacheold map[Days]string
+	_Days_cachemu  sync.RWMutex
+)
+
+func (m Days) String() string {
+	_Days_cachemu.RLock()
+	s, ok := _Days_cache[m]
+	old := false
+	if !ok {
+		s, old = _Days_cacheold[m]
+	}
+	_Days_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Days_cachemu.Lock()
+	if len(_Days_cache) >= 256 {
+		_Days_cacheold = _Days_cache
+		_Days_cache = make(map[Days]string, 256)
+	}
+	_Days_cache[m] = s
+	_Days_cachemu.Unlock()
+	return s
+}
+
+func (m Days) _string() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	var b []byte
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Days_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+const days_out_bitflag_table = `
+var _Days_stringer = _stringerBitflag{
+	typename: "Days",
+	zero:     "Days(0)",
+	first:    uint64(1),
+	names:    "MondayTuesdayWednesdayThursdayFridaySaturdaySunday",
+	offsets:  []uint16{6, 7, 9, 8, 6, 8, 6},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+const days_out_bitflag_cache_table = `
+var _Days_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename: "Days",
+		zero:     "Days(0)",
+		first:    uint64(1),
+		names:    "MondayTuesdayWednesdayThursdayFridaySaturdaySunday",
+		offsets:  []uint16{6, 7, 9, 8, 6, 8, 6},
+	},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+// Gaps and an offset.
+const gap_in_bitflag = `type Gap int
+const (
+	Zero   Gap = 0
+	Two    Gap = 1<< 2
+	Three  Gap = 1<< 3
+	Five   Gap = 1<< 5
+	Six    Gap = 1<< 6
+	Seven  Gap = 1<< 7
+	Eight  Gap = 1<< 8
+	Nine   Gap = 1<< 9
+	Eleven Gap = 1<< 11
+)
+`
+
+const gap_out_bitflag = `
+const _Gap_name = "TwoThreeFiveSixSevenEightNineEleven"
+
+var (
+	_Gap_offset = [...]uint8{3, 5, 0, 4, 3, 5, 5, 4, 0, 6}
+	_Gap_skips  = [...]uint8{1, 1}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const gap_out_bitflag_cache = `
+const _Gap_name = "TwoThreeFiveSixSevenEightNineEleven"
+
+var (
+	_Gap_offset   = [...]uint8{3, 5, 0, 4, 3, 5, 5, 4, 0, 6}
+	_Gap_skips    = [...]uint8{1, 1}
+	_Gap_cache    = make(map[Gap]string)
+	_Gap_cacheold map[Gap]string
+	_Gap_cachemu  sync.RWMutex
+)
+
+func (m Gap) String() string {
+	_Gap_cachemu.RLock()
+	s, ok := _Gap_cache[m]
+	old := false
+	if !ok {
+		s, old = _Gap_cacheold[m]
+	}
+	_Gap_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Gap_cachemu.Lock()
+	if len(_Gap_cache) >= 256 {
+		_Gap_cacheold = _Gap_cache
+		_Gap_cache = make(map[Gap]string, 256)
+	}
+	_Gap_cache[m] = s
+	_Gap_cachemu.Unlock()
+	return s
+}
+
+func (m Gap) _string() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+const gap_out_bitflag_table = `
+var _Gap_stringer = _stringerBitflag{
+	typename: "Gap",
+	zero:     "Zero",
+	first:    uint64(4),
+	names:    "TwoThreeFiveSixSevenEightNineEleven",
+	offsets:  []uint16{3, 5, 0, 4, 3, 5, 5, 4, 0, 6},
+	skips:    []uint8{1, 1},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+const gap_out_bitflag_cache_table = `
+var _Gap_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename: "Gap",
+		zero:     "Zero",
+		first:    uint64(4),
+		names:    "TwoThreeFiveSixSevenEightNineEleven",
+		offsets:  []uint16{3, 5, 0, 4, 3, 5, 5, 4, 0, 6},
+		skips:    []uint8{1, 1},
+	},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+// Large gap.
+const largegap_in_bitflag = `type Gap uint64
+const (
+	Seven      Gap = 1 << 7
+	ThirtyOne  Gap = 1 << 31
+	SixtyThree Gap = 1 << 63
+)
+`
+
+const largegap_out_bitflag = `
+const _Gap_name = "SevenThirtyOneSixtyThree"
+
+var (
+	_Gap_offset = [...]uint8{5, 0, 9, 0, 10}
+	_Gap_skips  = [...]uint8{23, 31}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Gap(0)"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(128)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(128)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const largegap_out_bitflag_cache = `
+const _Gap_name = "SevenThirtyOneSixtyThree"
+
+var (
+	_Gap_offset   = [...]uint8{5, 0, 9, 0, 10}
+	_Gap_skips    = [...]uint8{23, 31}
+	_Gap_cache    = make(map[Gap]string)
+	_Gap_cacheold map[Gap]string
+	_Gap_cachemu  sync.RWMutex
+)
+
+func (m Gap) String() string {
+	_Gap_cachemu.RLock()
+	s, ok := _Gap_cache[m]
+	old := false
+	if !ok {
+		s, old = _Gap_cacheold[m]
+	}
+	_Gap_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Gap_cachemu.Lock()
+	if len(_Gap_cache) >= 256 {
+		_Gap_cacheold = _Gap_cache
+		_Gap_cache = make(map[Gap]string, 256)
+	}
+	_Gap_cache[m] = s
+	_Gap_cachemu.Unlock()
+	return s
+}
+
+func (m Gap) _string() string {
+	if m == 0 {
+		return "Gap(0)"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(128)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(128)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+const largegap_out_bitflag_table = `
+var _Gap_stringer = _stringerBitflag{
+	typename: "Gap",
+	zero:     "Gap(0)",
+	first:    uint64(128),
+	names:    "SevenThirtyOneSixtyThree",
+	offsets:  []uint16{5, 0, 9, 0, 10},
+	skips:    []uint8{23, 31},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+const largegap_out_bitflag_cache_table = `
+var _Gap_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename: "Gap",
+		zero:     "Gap(0)",
+		first:    uint64(128),
+		names:    "SevenThirtyOneSixtyThree",
+		offsets:  []uint16{5, 0, 9, 0, 10},
+		skips:    []uint8{23, 31},
+	},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+// Largest gap.
+const largestgap_in_bitflag = `type Gap uint64
+const (
+	Zero       Gap = 1 << 0
+	SixtyThree Gap = 1 << 63
+)
+`
+
+const largestgap_out_bitflag = `
+const _Gap_name = "ZeroSixtyThree"
+
+var (
+	_Gap_offset = [...]uint8{4, 0, 10}
+	_Gap_skips  = [...]uint8{62}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Gap(0)"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(1)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(1)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const largestgap_out_bitflag_cache = `
+const _Gap_name = "ZeroSixtyThree"
+
+var (
+	_Gap_offset   = [...]uint8{4, 0, 10}
+	_Gap_skips    = [...]uint8{62}
+	_Gap_cache    = make(map[Gap]string)
+	_Gap_cacheold map[Gap]string
+	_Gap_cachemu  sync.RWMutex
+)
+
+func (m Gap) String() string {
+	_Gap_cachemu.RLock()
+	s, ok := _Gap_cache[m]
+	old := false
+	if !ok {
+		s, old = _Gap_cacheold[m]
+	}
+	_Gap_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Gap_cachemu.Lock()
+	if len(_Gap_cache) >= 256 {
+		_Gap_cacheold = _Gap_cache
+		_Gap_cache = make(map[Gap]string, 256)
+	}
+	_Gap_cache[m] = s
+	_Gap_cachemu.Unlock()
+	return s
+}
+
+func (m Gap) _string() string {
+	if m == 0 {
+		return "Gap(0)"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(1)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(1)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+const largestgap_out_bitflag_table = `
+var _Gap_stringer = _stringerBitflag{
+	typename: "Gap",
+	zero:     "Gap(0)",
+	first:    uint64(1),
+	names:    "ZeroSixtyThree",
+	offsets:  []uint16{4, 0, 10},
+	skips:    []uint8{62},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+const largestgap_out_bitflag_cache_table = `
+var _Gap_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename: "Gap",
+		zero:     "Gap(0)",
+		first:    uint64(1),
+		names:    "ZeroSixtyThree",
+		offsets:  []uint16{4, 0, 10},
+		skips:    []uint8{62},
+	},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+// Composite constants, one of them overlapping another.
+const composite_in_bitflag = `type Days uint8
+const (
+	Monday Days = 1 << iota
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+	Weekend     Days = Saturday | Sunday
+	LongWeekend Days = Friday | Weekend
+	Workweek    Days = Monday | Tuesday | Wednesday | Thursday | Friday
+)
+`
+
+const composite_out_bitflag = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySundayWorkweekLongWeekendWeekend"
+
+var (
+	_Days_offset     = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_composites = [...]Days{31, 112, 96}
+	_Days_cindex     = [...]uint8{50, 58, 69, 76}
+)
+
+func (m Days) String() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const composite_out_bitflag_cache = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySundayWorkweekLongWeekendWeekend"
+
+var (
+	_Days_offset     = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_composites = [...]Days{31, 112, 96}
+	_Days_cindex     = [...]uint8{50, 58, 69, 76}
+	_Days_cache      = make(map[Days]string)
+	_Days_cacheold   map[Days]string
+	_Days_cachemu    sync.RWMutex
+)
+
+func (m Days) String() string {
+	_Days_cachemu.RLock()
+	s, ok := _Days_cache[m]
+	old := false
+	if !ok {
+		s, old = _Days_cacheold[m]
+	}
+	_Days_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Days_cachemu.Lock()
+	if len(_Days_cache) >= 256 {
+		_Days_cacheold = _Days_cache
+		_Days_cache = make(map[Days]string, 256)
+	}
+	_Days_cache[m] = s
+	_Days_cachemu.Unlock()
+	return s
+}
+
+func (m Days) _string() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const composite_out_bitflag_table = `
+var _Days_stringer = _stringerBitflag{
+	typename:   "Days",
+	zero:       "Days(0)",
+	first:      uint64(1),
+	names:      "MondayTuesdayWednesdayThursdayFridaySaturdaySundayWorkweekLongWeekendWeekend",
+	offsets:    []uint16{6, 7, 9, 8, 6, 8, 6},
+	composites: []uint64{31, 112, 96},
+	cindex:     []uint16{50, 58, 69, 76},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+const composite_out_bitflag_cache_table = `
+var _Days_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename:   "Days",
+		zero:       "Days(0)",
+		first:      uint64(1),
+		names:      "MondayTuesdayWednesdayThursdayFridaySaturdaySundayWorkweekLongWeekendWeekend",
+		offsets:    []uint16{6, 7, 9, 8, 6, 8, 6},
+		composites: []uint64{31, 112, 96},
+		cindex:     []uint16{50, 58, 69, 76},
+	},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+// Composite constants with a gap between the single bits.
+const compositegap_in_bitflag = `type Gap uint16
+const (
+	Zero  Gap = 0
+	Two   Gap = 1 << 2
+	Three Gap = 1 << 3
+	Nine  Gap = 1 << 9
+	Low   Gap = Two | Three
+	Span  Gap = Three | Nine
+)
+`
+
+const compositegap_out_bitflag = `
+const _Gap_name = "TwoThreeNineLowSpan"
+
+var (
+	_Gap_offset     = [...]uint8{3, 5, 0, 4}
+	_Gap_skips      = [...]uint8{5}
+	_Gap_composites = [...]Gap{12, 520}
+	_Gap_cindex     = [...]uint8{12, 15, 19}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const compositegap_out_bitflag_cache = `
+const _Gap_name = "TwoThreeNineLowSpan"
+
+var (
+	_Gap_offset     = [...]uint8{3, 5, 0, 4}
+	_Gap_skips      = [...]uint8{5}
+	_Gap_composites = [...]Gap{12, 520}
+	_Gap_cindex     = [...]uint8{12, 15, 19}
+	_Gap_cache      = make(map[Gap]string)
+	_Gap_cacheold   map[Gap]string
+	_Gap_cachemu    sync.RWMutex
+)
+
+func (m Gap) String() string {
+	_Gap_cachemu.RLock()
+	s, ok := _Gap_cache[m]
+	old := false
+	if !ok {
+		s, old = _Gap_cacheold[m]
+	}
+	_Gap_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Gap_cachemu.Lock()
+	if len(_Gap_cache) >= 256 {
+		_Gap_cacheold = _Gap_cache
+		_Gap_cache = make(map[Gap]string, 256)
+	}
+	_Gap_cache[m] = s
+	_Gap_cachemu.Unlock()
+	return s
+}
+
+func (m Gap) _string() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(4)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		if _Gap_offset[i] == 0 {
+			v <<= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const compositegap_out_bitflag_table = `
+var _Gap_stringer = _stringerBitflag{
+	typename:   "Gap",
+	zero:       "Zero",
+	first:      uint64(4),
+	names:      "TwoThreeNineLowSpan",
+	offsets:    []uint16{3, 5, 0, 4},
+	skips:      []uint8{5},
+	composites: []uint64{12, 520},
+	cindex:     []uint16{12, 15, 19},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+const compositegap_out_bitflag_cache_table = `
+var _Gap_stringer = _stringerBitflagCache{
+	size: 256,
+	sb: _stringerBitflag{
+		typename:   "Gap",
+		zero:       "Zero",
+		first:      uint64(4),
+		names:      "TwoThreeNineLowSpan",
+		offsets:    []uint16{3, 5, 0, 4},
+		skips:      []uint8{5},
+		composites: []uint64{12, 520},
+		cindex:     []uint16{12, 15, 19},
+	},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+func TestGoldenBitflagText(t *testing.T) {
+	for _, test := range []struct {
+		table, cache bool
+		output       string
+	}{
+		{false, false, composite_out_bitflag + composite_out_bitflag_text},
+		{false, true, composite_out_bitflag_cache + composite_out_bitflag_text},
+		{true, false, composite_out_bitflag_table + composite_out_bitflag_text_table},
+		{true, true, composite_out_bitflag_cache_table + composite_out_bitflag_text_table},
+	} {
+		opts := Options{
+			Bitflag: true,
+			NoCache: !test.cache,
+			NoTable: !test.table,
+			Text:    true,
+		}
+		got := goldenGenerate(t, opts, "composite", composite_in_bitflag)
+		if got != test.output {
+			t.Errorf("table=%v cache=%v: got\n====\n%s====\nexpected\n====%s",
+				test.table, test.cache, got, test.output)
+		}
+	}
+}
+
+// The -text output for the composite example. The names of the map keys
+// slice the name constant, which table mode does not declare.
+const composite_out_bitflag_text = `
+var _Days_value = map[string]Days{
+	_Days_name[0:6]:   1,
+	_Days_name[6:13]:  2,
+	_Days_name[13:22]: 4,
+	_Days_name[22:30]: 8,
+	_Days_name[30:36]: 16,
+	_Days_name[36:44]: 32,
+	_Days_name[44:50]: 64,
+	_Days_name[50:58]: 31,
+	_Days_name[58:69]: 112,
+	_Days_name[69:76]: 96,
+}
+
+func _Days_parse(s string) (Days, error) {
+	if s == "Days(0)" {
+		return 0, nil
+	}
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	var m Days
+	for {
+		i := 0
+		for i < len(s) && s[i] != '|' {
+			i++
+		}
+		v, ok := _Days_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Days values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+func (i Days) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Days) UnmarshalText(text []byte) error {
+	v, err := _Days_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+const composite_out_bitflag_text_table = `
+var _Days_value = map[string]Days{
+	"Monday":      1,
+	"Tuesday":     2,
+	"Wednesday":   4,
+	"Thursday":    8,
+	"Friday":      16,
+	"Saturday":    32,
+	"Sunday":      64,
+	"Workweek":    31,
+	"LongWeekend": 112,
+	"Weekend":     96,
+}
+
+func _Days_parse(s string) (Days, error) {
+	if s == "Days(0)" {
+		return 0, nil
+	}
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	var m Days
+	for {
+		i := 0
+		for i < len(s) && s[i] != '|' {
+			i++
+		}
+		v, ok := _Days_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Days values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+func (i Days) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Days) UnmarshalText(text []byte) error {
+	v, err := _Days_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+func TestGoldenBitflagParse(t *testing.T) {
+	for _, test := range []struct {
+		table  bool
+		output string
+	}{
+		{false, perm_out_bitflag_parse},
+		{true, perm_out_bitflag_parse_table},
+	} {
+		opts := Options{
+			Bitflag: true,
+			NoCache: true,
+			NoTable: !test.table,
+			Parse:   true,
+		}
+		got := goldenGenerate(t, opts, "perm", perm_in_bitflag)
+		if got != test.output {
+			t.Errorf("table=%v: got\n====\n%s====\nexpected\n====%s", test.table, got, test.output)
+		}
+	}
+}
+
+// Parsing accepts the zero name, single bits, composites and aliases,
+// alone or joined in parentheses.
+const perm_in_bitflag = `type Perm uint8
+const (
+	None     Perm = 0
+	Read     Perm = 1
+	Write    Perm = 2
+	Exec     Perm = 4
+	RW       Perm = Read | Write
+	Readable Perm = Read
+)
+`
+
+const perm_out_bitflag_parse = `
+const _Perm_name = "ReadWriteExecRW"
+
+var (
+	_Perm_offset     = [...]uint8{4, 5, 4}
+	_Perm_composites = [...]Perm{3}
+	_Perm_cindex     = [...]uint8{13, 15}
+)
+
+func (m Perm) String() string {
+	if m == 0 {
+		return "None"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Perm_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Perm_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+
+var _Perm_value = map[string]Perm{
+	_Perm_name[0:4]:   1,
+	_Perm_name[4:9]:   2,
+	_Perm_name[9:13]:  4,
+	_Perm_name[13:15]: 3,
+	"Readable":        1,
+}
+
+func _Perm_parse(s string) (Perm, error) {
+	if s == "None" {
+		return 0, nil
+	}
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	var m Perm
+	for {
+		i := 0
+		for i < len(s) && s[i] != '|' {
+			i++
+		}
+		v, ok := _Perm_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Perm values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+// PermString returns the Perm value whose name is s.
+func PermString(s string) (Perm, error) {
+	return _Perm_parse(s)
+}
+`
+
+const perm_out_bitflag_parse_table = `
+var _Perm_stringer = _stringerBitflag{
+	typename:   "Perm",
+	zero:       "None",
+	first:      uint64(1),
+	names:      "ReadWriteExecRW",
+	offsets:    []uint16{4, 5, 4},
+	composites: []uint64{3},
+	cindex:     []uint16{13, 15},
+}
+
+func (m Perm) String() string {
+	return _Perm_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	return _Perm_stringer.mslice(uint64(m))
+}
+
+var _Perm_value = map[string]Perm{
+	"Read":     1,
+	"Write":    2,
+	"Exec":     4,
+	"RW":       3,
+	"Readable": 1,
+}
+
+func _Perm_parse(s string) (Perm, error) {
+	if s == "None" {
+		return 0, nil
+	}
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	var m Perm
+	for {
+		i := 0
+		for i < len(s) && s[i] != '|' {
+			i++
+		}
+		v, ok := _Perm_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Perm values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+// PermString returns the Perm value whose name is s.
+func PermString(s string) (Perm, error) {
+	return _Perm_parse(s)
+}
+`
+
+func TestGoldenBitflagIsValid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		table  bool
+		output string
+	}{
+		{"largestgap", largestgap_in_bitflag, false, largestgap_out_bitflag + largestgap_out_bitflag_isvalid},
+		{"largestgap", largestgap_in_bitflag, true, largestgap_out_bitflag_table + largestgap_out_bitflag_isvalid},
+		{"composite", composite_in_bitflag, false, composite_out_bitflag + composite_out_bitflag_isvalid},
+		{"composite", composite_in_bitflag, true, composite_out_bitflag_table + composite_out_bitflag_isvalid},
+	} {
+		opts := Options{
+			Bitflag: true,
+			NoCache: true,
+			NoTable: !test.table,
+			IsValid: true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s table=%v: got\n====\n%s====\nexpected\n====%s", test.name, test.table, got, test.output)
+		}
+	}
+}
+
+// The method is the same with or without the table.
+const largestgap_out_bitflag_isvalid = `
+func (m Gap) IsValid() bool {
+	return m&^0x8000000000000001 == 0
+}
+`
+
+// Bits are valid when their composite is set as a whole.
+const composite_out_bitflag_isvalid = `
+func (m Days) IsValid() bool {
+	for _, k := range [...]Days{31, 112, 96} {
+		if m&k == k {
+			m &^= k
+		}
+	}
+	return m&^0x7f == 0
+}
+`
+
+func TestGoldenBitflagValues(t *testing.T) {
+	opts := Options{
+		Bitflag: true,
+		NoCache: true,
+		NoTable: true,
+		Values:  true,
+	}
+	got := goldenGenerate(t, opts, "composite", composite_in_bitflag)
+	if want := composite_out_bitflag + composite_out_bitflag_values; got != want {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, want)
+	}
+}
+
+func TestGoldenBitflagGoString(t *testing.T) {
+	opts := Options{
+		Bitflag:  true,
+		NoCache:  true,
+		NoTable:  true,
+		GoString: true,
+	}
+	got := goldenGenerate(t, opts, "composite", composite_in_bitflag)
+	if want := composite_out_bitflag + composite_out_bitflag_gostring; got != want {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, want)
+	}
+}
+
+const composite_out_bitflag_gostring = `
+// GoString returns the names of the constants whose bits are set, each
+// qualified by the package and separated by |, followed by the conversion of
+// the bits with no name to Days.
+func (i Days) GoString() string {
+	s := i.String()
+	if strings.HasPrefix(s, "(") {
+		s = s[1 : len(s)-1]
+	}
+	return "test." + strings.Replace(s, "|", "|test.", -1)
+}
+`
+
+// The composites are listed among the single bits, in increasing order.
+const composite_out_bitflag_values = `
+var _Days_values = []Days{1, 2, 4, 8, 16, 31, 32, 64, 96, 112}
+
+// DaysValues returns the values of the Days constants in increasing order.
+func DaysValues() []Days {
+	return append([]Days(nil), _Days_values...)
+}
+
+// DaysNames returns the names of the Days constants, as printed by String,
+// in the order of DaysValues.
+func DaysNames() []string {
+	names := make([]string, len(_Days_values))
+	for i, v := range _Days_values {
+		names[i] = v.String()
+	}
+	return names
+}
+`
+
+func TestGoldenBitflagHelpers(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{"composite", composite_in_bitflag, composite_out_bitflag + composite_out_bitflag_helpers},
+		{"declared", declared_in_bitflag, declared_out_bitflag_helpers},
+	} {
+		opts := Options{
+			Bitflag:        true,
+			NoCache:        true,
+			NoTable:        true,
+			BitflagHelpers: true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+const composite_out_bitflag_helpers = `
+// Has reports whether all the Days flags set in f are also set in m.
+func (m Days) Has(f Days) bool {
+	return m&f == f
+}
+
+// Set returns m with the Days flags of f set.
+func (m Days) Set(f Days) Days {
+	return m | f
+}
+
+// Clear returns m with the Days flags of f cleared.
+func (m Days) Clear(f Days) Days {
+	return m &^ f
+}
+
+// Toggle returns m with the Days flags of f toggled.
+func (m Days) Toggle(f Days) Days {
+	return m ^ f
+}
+`
+
+// A method the type declares is left out.
+const declared_in_bitflag = `type Perm uint8
+const (
+	Read  Perm = 1
+	Write Perm = 2
+	Exec  Perm = 4
+)
+
+// Has is declared, so it is not generated.
+func (m Perm) Has(f Perm) bool {
+	return m&f != 0
+}
+`
+
+const declared_out_bitflag_helpers = `
+const _Perm_name = "ReadWriteExec"
+
+var _Perm_offset = [...]uint8{4, 5, 4}
+
+func (m Perm) String() string {
+	if m == 0 {
+		return "Perm(0)"
+	}
+
+	var b []byte
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Perm_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Perm_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Perm(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Perm_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+
+// Set returns m with the Perm flags of f set.
+func (m Perm) Set(f Perm) Perm {
+	return m | f
+}
+
+// Clear returns m with the Perm flags of f cleared.
+func (m Perm) Clear(f Perm) Perm {
+	return m &^ f
+}
+
+// Toggle returns m with the Perm flags of f toggled.
+func (m Perm) Toggle(f Perm) Perm {
+	return m ^ f
+}
+`
+
+func TestGoldenBitflagCacheSize(t *testing.T) {
+	for _, test := range []struct {
+		size   int
+		table  bool
+		output string
+	}{
+		{1000, false, days_out_bitflag_cache_1000},
+		{1000, true, days_out_bitflag_cache_table_1000},
+		{-1, false, days_out_bitflag_cache_0}, // No limit.
+		{-1, true, days_out_bitflag_cache_table_0},
+	} {
+		opts := Options{
+			Bitflag:   true,
+			CacheSize: test.size,
+			NoTable:   !test.table,
+		}
+		got := goldenGenerate(t, opts, "days", days_in_bitflag)
+		if got != test.output {
+			t.Errorf("size=%d table=%v: got\n====\n%s====\nexpected\n====%s",
+				test.size, test.table, got, test.output)
+		}
+	}
+}
+
+const days_out_bitflag_cache_1000 = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var (
+	_Days_offset   = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_cache    = make(map[Days]string)
+	_Days_cacheold map[Days]string
+	_Days_cachemu  sync.RWMutex
+)
+
+func (m Days) String() string {
+	_Days_cachemu.RLock()
+	s, ok := _Days_cache[m]
+	old := false
+	if !ok {
+		s, old = _Days_cacheold[m]
+	}
+	_Days_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	if !old {
+		s = m._string()
+	}
+	_Days_cachemu.Lock()
+	if len(_Days_cache) >= 1000 {
+		_Days_cacheold = _Days_cache
+		_Days_cache = make(map[Days]string, 1000)
+	}
+	_Days_cache[m] = s
+	_Days_cachemu.Unlock()
+	return s
+}
+
+func (m Days) _string() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	var b []byte
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Days_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const days_out_bitflag_cache_table_1000 = `
+var _Days_stringer = _stringerBitflagCache{
+	size: 1000,
+	sb: _stringerBitflag{
+		typename: "Days",
+		zero:     "Days(0)",
+		first:    uint64(1),
+		names:    "MondayTuesdayWednesdayThursdayFridaySaturdaySunday",
+		offsets:  []uint16{6, 7, 9, 8, 6, 8, 6},
+	},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+// With no limit, the cache is never emptied.
+const days_out_bitflag_cache_0 = `
+const _Days_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var (
+	_Days_offset  = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_cache   = make(map[Days]string) // Unbounded: names are never dropped.
+	_Days_cachemu sync.RWMutex
+)
+
+func (m Days) String() string {
+	_Days_cachemu.RLock()
+	s, ok := _Days_cache[m]
+	_Days_cachemu.RUnlock()
+	if ok {
+		return s
+	}
+	s = m._string()
+	_Days_cachemu.Lock()
+	_Days_cache[m] = s
+	_Days_cachemu.Unlock()
+	return s
+}
+
+func (m Days) _string() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	var b []byte
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Days_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Days(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const days_out_bitflag_cache_table_0 = `
+var _Days_stringer = _stringerBitflagCache{
+	cached: make(map[uint64]string), // Unbounded: names are never dropped.
+	sb: _stringerBitflag{
+		typename: "Days",
+		zero:     "Days(0)",
+		first:    uint64(1),
+		names:    "MondayTuesdayWednesdayThursdayFridaySaturdaySunday",
+		offsets:  []uint16{6, 7, 9, 8, 6, 8, 6},
+	},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstringUnbounded(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+// With -precompute, the names of the three named bits of Perm are computed,
+// the composite RW included, and a type of nine named bits is generated as
+// without it.
+func TestGoldenBitflagPrecompute(t *testing.T) {
+	for _, table := range []bool{false, true} {
+		opts := Options{
+			Bitflag:    true,
+			NoTable:    !table,
+			Precompute: true,
+		}
+		got := goldenGenerate(t, opts, "perm", perm_in_bitflag)
+		if got != perm_out_bitflag_precompute {
+			t.Errorf("table=%v: got\n====\n%s====\nexpected\n====%s", table, got, perm_out_bitflag_precompute)
+		}
+	}
+
+	nine := "type Nine uint16\nconst (\n\tN0 Nine = 1 << iota\n"
+	for i := 1; i < 9; i++ {
+		nine += fmt.Sprintf("\tN%d\n", i)
+	}
+	nine += ")\n"
+	opts := Options{Bitflag: true, Precompute: true}
+	got := goldenGenerate(t, opts, "nine", nine)
+	opts.Precompute = false
+	if want := goldenGenerate(t, opts, "nine", nine); got != want {
+		t.Errorf("nine bits: got\n====\n%s====\nexpected\n====%s", got, want)
+	}
+}
+
+const perm_out_bitflag_precompute = `
+const _Perm_name = "ReadWriteExecRW"
+
+var (
+	_Perm_offset     = [...]uint8{4, 5, 4}
+	_Perm_composites = [...]Perm{3}
+	_Perm_cindex     = [...]uint8{13, 15}
+)
+
+// The name of each value of the named bits of Perm, indexed by those bits
+// packed together.
+var _Perm_strings = [...]string{
+	"None",
+	"Read",
+	"Write",
+	"RW",
+	"Exec",
+	"(Read|Exec)",
+	"(Write|Exec)",
+	"(Exec|RW)",
+}
+
+func (m Perm) String() string {
+	if m&^0x7 != 0 {
+		return m._string()
+	}
+	return _Perm_strings[m&0x7]
+}
+
+func (m Perm) _string() string {
+	if m == 0 {
+		return "None"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Perm_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	c := m
+	for _, k := range _Perm_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Perm_offset)
+	v := Perm(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Perm_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Perm_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Perm_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Perm_name[_Perm_cindex[i]:_Perm_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Perm(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+func TestGoldenBitflagTransform(t *testing.T) {
+	for _, test := range []struct {
+		transform string
+		table     bool
+		parse     bool
+		output    string
+	}{
+		{transformSnake, false, true, composite_out_bitflag_snake},
+		{transformUpper, true, false, composite_out_bitflag_table_upper},
+	} {
+		opts := Options{
+			Bitflag:   true,
+			NoCache:   true,
+			NoTable:   !test.table,
+			Parse:     test.parse,
+			Transform: test.transform,
+		}
+		got := goldenGenerate(t, opts, "composite", composite_in_bitflag)
+		if got != test.output {
+			t.Errorf("%s table=%v: got\n====\n%s====\nexpected\n====%s", test.transform, test.table, got, test.output)
+		}
+	}
+}
+
+// The names of the composites and the parse table are transformed alike.
+const composite_out_bitflag_snake = `
+const _Days_name = "mondaytuesdaywednesdaythursdayfridaysaturdaysundayworkweeklong_weekendweekend"
+
+var (
+	_Days_offset     = [...]uint8{6, 7, 9, 8, 6, 8, 6}
+	_Days_composites = [...]Days{31, 112, 96}
+	_Days_cindex     = [...]uint8{50, 58, 70, 77}
+)
+
+func (m Days) String() string {
+	if m == 0 {
+		return "Days(0)"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Days_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	c := m
+	for _, k := range _Days_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Days_offset)
+	v := Days(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Days_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Days_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Days_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Days_name[_Days_cindex[i]:_Days_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Days(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+
+var _Days_value = map[string]Days{
+	_Days_name[0:6]:   1,
+	_Days_name[6:13]:  2,
+	_Days_name[13:22]: 4,
+	_Days_name[22:30]: 8,
+	_Days_name[30:36]: 16,
+	_Days_name[36:44]: 32,
+	_Days_name[44:50]: 64,
+	_Days_name[50:58]: 31,
+	_Days_name[58:70]: 112,
+	_Days_name[70:77]: 96,
+}
+
+func _Days_parse(s string) (Days, error) {
+	if s == "Days(0)" {
+		return 0, nil
+	}
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	var m Days
+	for {
+		i := 0
+		for i < len(s) && s[i] != '|' {
+			i++
+		}
+		v, ok := _Days_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%s does not belong to Days values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+1:]
+	}
+}
+
+// DaysString returns the Days value whose name is s.
+func DaysString(s string) (Days, error) {
+	return _Days_parse(s)
+}
+`
+
+const composite_out_bitflag_table_upper = `
+var _Days_stringer = _stringerBitflag{
+	typename:   "Days",
+	zero:       "Days(0)",
+	first:      uint64(1),
+	names:      "MONDAYTUESDAYWEDNESDAYTHURSDAYFRIDAYSATURDAYSUNDAYWORKWEEKLONGWEEKENDWEEKEND",
+	offsets:    []uint16{6, 7, 9, 8, 6, 8, 6},
+	composites: []uint64{31, 112, 96},
+	cindex:     []uint16{50, 58, 69, 76},
+}
+
+func (m Days) String() string {
+	return _Days_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Days(0x..)" element.
+func (m Days) Flags() []string {
+	return _Days_stringer.mslice(uint64(m))
+}
+`
+
+func TestGoldenBitflagComments(t *testing.T) {
+	opts := Options{
+		Bitflag:     true,
+		NoCache:     true,
+		LineComment: true,
+	}
+	got := goldenGenerate(t, opts, "mode", mode_in_bitflag)
+	if got != mode_out_bitflag_table {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, mode_out_bitflag_table)
+	}
+}
+
+// Names with a quote and a backslash, printed into the table.
+const mode_in_bitflag = `type Mode uint8
+const (
+	None  Mode = 0 // "none"
+	Read  Mode = 1 // r\
+	Write Mode = 2
+)
+`
+
+const mode_out_bitflag_table = `
+var _Mode_stringer = _stringerBitflag{
+	typename: "Mode",
+	zero:     "\"none\"",
+	first:    uint64(1),
+	names:    "r\\Write",
+	offsets:  []uint16{2, 5},
+}
+
+func (m Mode) String() string {
+	return _Mode_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Mode(0x..)" element.
+func (m Mode) Flags() []string {
+	return _Mode_stringer.mslice(uint64(m))
+}
+`
+
+// Types with no single-bit constants, so no runs of them, still print
+// their zero and composite names.
+func TestGoldenBitflagNoSingleBits(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		table  bool
+		output string
+	}{
+		{"zero", zero_in_bitflag, false, zero_out_bitflag},
+		{"rwx", rwx_in_bitflag, true, rwx_out_bitflag_table},
+	} {
+		opts := Options{
+			Bitflag: true,
+			NoCache: true,
+			NoTable: !test.table,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s table=%v: got\n====\n%s====\nexpected\n====%s", test.name, test.table, got, test.output)
+		}
+	}
+}
+
+const zero_in_bitflag = `type Zero uint8
+const ZNone Zero = 0
+`
+
+const zero_out_bitflag = `
+const _Zero_name = ""
+
+var _Zero_offset = [...]uint8{}
+
+func (m Zero) String() string {
+	if m == 0 {
+		return "ZNone"
+	}
+
+	var b []byte
+	l := len(_Zero_offset)
+	v := Zero(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Zero_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Zero_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Zero_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Zero(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Zero(0x..)" element.
+func (m Zero) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Zero_offset)
+	v := Zero(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Zero_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Zero_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Zero(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const rwx_in_bitflag = `type Perm uint8
+const (
+	PermNone Perm = 0
+	RW       Perm = 3
+	RWX      Perm = 7
+)
+`
+
+const rwx_out_bitflag_table = `
+var _Perm_stringer = _stringerBitflag{
+	typename:   "Perm",
+	zero:       "PermNone",
+	first:      uint64(1),
+	names:      "RWXRW",
+	offsets:    []uint16{},
+	composites: []uint64{7, 3},
+	cindex:     []uint16{0, 3, 5},
+}
+
+func (m Perm) String() string {
+	return _Perm_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Perm(0x..)" element.
+func (m Perm) Flags() []string {
+	return _Perm_stringer.mslice(uint64(m))
+}
+`
+
+// A name longer than 255 bytes needs offsets wider than uint8.
+func TestGoldenBitflagLongName(t *testing.T) {
+	long := "L" + strings.Repeat("o", 257) + "ng"
+	for _, test := range []struct {
+		table  bool
+		output string
+	}{
+		{false, long_out_bitflag},
+		{true, long_out_bitflag_table},
+	} {
+		opts := Options{
+			Bitflag: true,
+			NoCache: true,
+			NoTable: !test.table,
+		}
+		got := goldenGenerate(t, opts, "long", strings.Replace(long_in_bitflag, "LONG", long, 1))
+		if want := strings.Replace(test.output, "LONG", long, 1); got != want {
+			t.Errorf("table=%v: got\n====\n%s====\nexpected\n====%s", test.table, got, want)
+		}
+	}
+}
+
+// LONG stands for a name of 260 bytes.
+const long_in_bitflag = `type Long uint8
+const (
+	Short Long = 1 << iota
+	LONG
+	Tall
+)
+`
+
+const long_out_bitflag = `
+const _Long_name = "ShortLONGTall"
+
+var _Long_offset = [...]uint16{5, 260, 4}
+
+func (m Long) String() string {
+	if m == 0 {
+		return "Long(0)"
+	}
+
+	var b []byte
+	l := len(_Long_offset)
+	v := Long(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Long_offset[i])
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Long_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Long_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Long(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Long(0x..)" element.
+func (m Long) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Long_offset)
+	v := Long(1)
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v<<1 {
+		p0 = p1
+		p1 += int(_Long_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Long_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Long(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const long_out_bitflag_table = `
+var _Long_stringer = _stringerBitflag{
+	typename: "Long",
+	zero:     "Long(0)",
+	first:    uint64(1),
+	names:    "ShortLONGTall",
+	offsets:  []uint16{5, 260, 4},
+}
+
+func (m Long) String() string {
+	return _Long_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Long(0x..)" element.
+func (m Long) Flags() []string {
+	return _Long_stringer.mslice(uint64(m))
+}
+`
+
+// The types shared by the tables are named with the prefix.
+func TestGoldenBitflagTablePrefix(t *testing.T) {
+	for _, test := range []struct {
+		cache  bool
+		output string
+	}{
+		{false, composite_out_bitflag_table},
+		{true, composite_out_bitflag_cache_table},
+	} {
+		opts := Options{
+			Bitflag:     true,
+			NoCache:     !test.cache,
+			TablePrefix: "_acmeStringer",
+		}
+		got := goldenGenerate(t, opts, "composite", composite_in_bitflag)
+		want := strings.Replace(test.output, "_stringerBitflag", "_acmeStringerBitflag", -1)
+		if got != want {
+			t.Errorf("cache=%v: got\n====\n%s====\nexpected\n====%s", test.cache, got, want)
+		}
+	}
+}
+
+// The generated file imports the packages its code uses, and none in table
+// mode, whose code is in the common file.
+func TestGoldenImports(t *testing.T) {
+	for _, test := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{Bitflag: true, NoCache: true}, ""},
+		{Options{Bitflag: true}, ""},
+		{Options{Bitflag: true, NoCache: true, JSON: true}, "fmt strconv"},
+		{Options{Bitflag: true, NoCache: true, NoTable: true}, "strconv"},
+		{Options{Bitflag: true, NoTable: true, CacheSize: -1}, "strconv sync"},
+		{Options{Bitflag: true, NoCache: true, NoTable: true, IsValid: true, Values: true}, "strconv"},
+		{Options{Parse: true, SQL: true}, "database/sql/driver fmt strconv"},
+		{Options{}, "strconv"},
+	} {
+		src := goldenFile(t, test.opts, "composite", composite_in_bitflag, "Days")
+		f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, spec := range f.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			paths = append(paths, path)
+		}
+		if got := strings.Join(paths, " "); got != test.want {
+			t.Errorf("%+v: imports %q, want %q", test.opts, got, test.want)
+		}
+	}
+}
+
+const warn_in_bitflag = `type Perm int8
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+	Sign Perm = -128
+	Writable Perm = 2
+	None Perm = 0
+	Zero Perm = 0
+)
+`
+
+func TestBitflagWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	opts := Options{Bitflag: true, NoCache: true}
+	goldenGenerate(t, opts, "warn", warn_in_bitflag)
+	want := "warning: warn.go:7: Sign is negative (-128); its bits are printed as if unsigned\n" +
+		"warning: warn.go:8: Writable is not printed; it has the value of Write\n" +
+		"warning: warn.go:10: Zero is not printed; it has the value of None\n"
+	if got := buf.String(); got != want {
+		t.Errorf("warnings:\n%s\nwant:\n%s", got, want)
+	}
+
+	// With -strict, the warnings are an error.
+	opts.Strict = true
+	info, fset := loadPackage(t, "warn", map[string]string{"warn.go": "package test\n" + warn_in_bitflag})
+	_, err := Generate(fset, info, []string{"Perm"}, opts)
+	if want := "-strict is set and constants are not printed as declared (3)"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("-strict: got error %v, want %q", err, want)
+	}
+}
+
+func TestGoldenBitflagBitOrder(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		table  bool
+		output string
+	}{
+		{"gap", gap_in_bitflag, false, gap_out_bitflag_msb},
+		{"gap", gap_in_bitflag, true, gap_out_bitflag_msb_table},
+		{"compositegap", compositegap_in_bitflag, false, compositegap_out_bitflag_msb},
+	} {
+		opts := Options{
+			Bitflag:  true,
+			NoCache:  true,
+			NoTable:  !test.table,
+			BitOrder: "msb",
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s table=%v: got\n====\n%s====\nexpected\n====\n%s", test.name, test.table, got, test.output)
+		}
+	}
+}
+
+// The names of the msb examples are of the bits from the highest down, and
+// v shifts right from the highest bit, over the gaps too.
+const gap_out_bitflag_msb = `
+const _Gap_name = "ElevenNineEightSevenSixFiveThreeTwo"
+
+var (
+	_Gap_offset = [...]uint8{6, 0, 4, 5, 5, 3, 4, 0, 5, 3}
+	_Gap_skips  = [...]uint8{1, 1}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	var b []byte
+	l := len(_Gap_offset)
+	v := Gap(2048)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v>>1 {
+		o := _Gap_offset[i]
+		if o == 0 {
+			v >>= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(o)
+		if v&m == 0 {
+			continue
+		}
+		m ^= v
+		if len(b) == 0 {
+			if m == 0 {
+				return _Gap_name[p0:p1]
+			}
+			b = append(b, '(')
+		} else {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		if m == 0 {
+			b = append(b, ')')
+			return string(b)
+		}
+	}
+	s := "Gap(0x" + strconv.FormatUint(uint64(m), 16) + ")"
+	if len(b) == 0 {
+		return s
+	}
+	b = append(b, '|')
+	b = append(b, s...)
+	b = append(b, ')')
+	return string(b)
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(2048)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v>>1 {
+		if _Gap_offset[i] == 0 {
+			v >>= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
+
+const gap_out_bitflag_msb_table = `
+var _Gap_stringer = _stringerBitflag{
+	typename: "Gap",
+	zero:     "Zero",
+	first:    uint64(2048),
+	names:    "ElevenNineEightSevenSixFiveThreeTwo",
+	offsets:  []uint16{6, 0, 4, 5, 5, 3, 4, 0, 5, 3},
+	msb:      true,
+	skips:    []uint8{1, 1},
+}
+
+func (m Gap) String() string {
+	return _Gap_stringer.mstring(uint64(m))
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	return _Gap_stringer.mslice(uint64(m))
+}
+`
+
+const compositegap_out_bitflag_msb = `
+const _Gap_name = "NineThreeTwoLowSpan"
+
+var (
+	_Gap_offset     = [...]uint8{4, 0, 5, 3}
+	_Gap_skips      = [...]uint8{5}
+	_Gap_composites = [...]Gap{12, 520}
+	_Gap_cindex     = [...]uint8{12, 15, 19}
+)
+
+func (m Gap) String() string {
+	if m == 0 {
+		return "Zero"
+	}
+
+	// Bits named by a composite print as that name, after the single bits.
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+
+	var b []byte
+	n := 0
+	l := len(_Gap_offset)
+	v := Gap(512)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v>>1 {
+		if _Gap_offset[i] == 0 {
+			v >>= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, _Gap_name[p0:p1]...)
+		n++
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			if n > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]]...)
+			n++
+		}
+	}
+	if c != 0 {
+		if n > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")"...)
+		n++
+	}
+	if n == 1 {
+		return string(b)
+	}
+	return "(" + string(b) + ")"
+}
+
+// Flags returns the names of the flags set in m, in the order String prints
+// them. Bits with no name come last, as one "Gap(0x..)" element.
+func (m Gap) Flags() []string {
+	c := m
+	for _, k := range _Gap_composites {
+		if c&k == k {
+			c &^= k
+		}
+	}
+	var f []string
+	l := len(_Gap_offset)
+	v := Gap(512)
+	si := 0
+	p0 := 0
+	p1 := 0
+	for i := 0; i < l; i, v = i+1, v>>1 {
+		if _Gap_offset[i] == 0 {
+			v >>= _Gap_skips[si] - 1
+			si++
+			continue
+		}
+		p0 = p1
+		p1 += int(_Gap_offset[i])
+		if v&c == 0 {
+			continue
+		}
+		c ^= v
+		f = append(f, _Gap_name[p0:p1])
+	}
+	x := m
+	for i, k := range _Gap_composites {
+		if x&k == k {
+			x &^= k
+			f = append(f, _Gap_name[_Gap_cindex[i]:_Gap_cindex[i+1]])
+		}
+	}
+	if c != 0 {
+		f = append(f, "Gap(0x"+strconv.FormatUint(uint64(c), 16)+")")
+	}
+	return f
+}
+`
@@ -0,0 +1,1987 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains simple golden tests for various examples.
+// Besides validating the results when the implementation changes,
+// it provides a way to look at the generated code without having
+// to execute the print statements in one's head.
+
+package stringer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Golden represents a test case.
+type Golden struct {
+	name        string
+	trimPrefix  string
+	lineComment bool
+	input       string // input; the package clause is provided when running the test.
+	output      string // exected output.
+}
+
+var golden = []Golden{
+	{"day", "", false, day_in, day_out},
+	{"offset", "", false, offset_in, offset_out},
+	{"uday", "", false, uday_in, uday_out},
+	{"uoffset", "", false, uoffset_in, uoffset_out},
+	{"single", "", false, single_in, single_out},
+	{"usingle", "", false, usingle_in, usingle_out},
+	{"gap", "", false, gap_in, gap_out},
+	{"num", "", false, num_in, num_out},
+	{"unum", "", false, unum_in, unum_out},
+	{"neg", "", false, neg_in, neg_out},
+	{"straddle", "", false, straddle_in, straddle_out},
+	{"neggap", "", false, neggap_in, neggap_out},
+	{"low", "", false, low_in, low_out},
+	{"mask", "", false, mask_in, mask_out},
+	{"high", "", false, high_in, high_out},
+	{"prime", "", false, prime_in, prime_out},
+	{"prefix", "Type", false, prefix_in, prefix_out},
+	{"tokens", "", true, tokens_in, tokens_out},
+}
+
+// Each example starts with "type XXX [u]int", with a single space separating them.
+
+// Simple test: enumeration of type int starting at 0.
+const day_in = `type Day int
+const (
+	Monday Day = iota
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+)
+`
+
+const day_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (i Day) String() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return "Day(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+`
+
+// Unsigned enumeration starting at 0, which cannot be less than it.
+const uday_in = `type Uday uint8
+const (
+	Umonday Uday = iota
+	Utuesday
+	Uwednesday
+)
+`
+
+const uday_out = `
+const _Uday_name = "UmondayUtuesdayUwednesday"
+
+var _Uday_index = [...]uint8{0, 7, 15, 25}
+
+func (i Uday) String() string {
+	if i >= Uday(len(_Uday_index)-1) {
+		return "Uday(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+	return _Uday_name[_Uday_index[i]:_Uday_index[i+1]]
+}
+`
+
+// Unsigned enumeration with an offset, compared before it is subtracted
+// so that the values below it do not wrap around.
+const uoffset_in = `type Uoffset uint
+const (
+	Ufive Uoffset = iota + 5
+	Usix
+	Useven
+)
+`
+
+const uoffset_out = `
+const _Uoffset_name = "UfiveUsixUseven"
+
+var _Uoffset_index = [...]uint8{0, 5, 9, 15}
+
+func (i Uoffset) String() string {
+	if i < 5 || i-5 >= Uoffset(len(_Uoffset_index)-1) {
+		return "Uoffset(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+	i -= 5
+	return _Uoffset_name[_Uoffset_index[i]:_Uoffset_index[i+1]]
+}
+`
+
+// A single constant, compared with, with no index.
+const single_in = `type Single int
+const (
+	OnlyOne Single = -3
+)
+`
+
+const single_out = `
+const _Single_name = "OnlyOne"
+
+func (i Single) String() string {
+	if i == -3 {
+		return _Single_name
+	}
+	return "Single(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+`
+
+// A single unsigned constant, not at zero.
+const usingle_in = `type Usingle uint16
+const (
+	Ualone Usingle = 7
+)
+`
+
+const usingle_out = `
+const _Usingle_name = "Ualone"
+
+func (i Usingle) String() string {
+	if i == 7 {
+		return _Usingle_name
+	}
+	return "Usingle(" + strconv.FormatUint(uint64(i), 10) + ")"
+}
+`
+
+// Enumeration with an offset.
+// Also includes a duplicate.
+const offset_in = `type Number int
+const (
+	_ Number = iota
+	One
+	Two
+	Three
+	AnotherOne = One  // Duplicate; note that AnotherOne doesn't appear below.
+)
+`
+
+const offset_out = `
+const _Number_name = "OneTwoThree"
+
+var _Number_index = [...]uint8{0, 3, 6, 11}
+
+func (i Number) String() string {
+	i -= 1
+	if i < 0 || i >= Number(len(_Number_index)-1) {
+		return "Number(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	}
+	return _Number_name[_Number_index[i]:_Number_index[i+1]]
+}
+`
+
+// Gaps and an offset.
+const gap_in = `type Gap int
+const (
+	Two Gap = 2
+	Three Gap = 3
+	Five Gap = 5
+	Six Gap = 6
+	Seven Gap = 7
+	Eight Gap = 8
+	Nine Gap = 9
+	Eleven Gap = 11
+)
+`
+
+const gap_out = `
+const (
+	_Gap_name_0 = "TwoThree"
+	_Gap_name_1 = "FiveSixSevenEightNine"
+	_Gap_name_2 = "Eleven"
+)
+
+var (
+	_Gap_index_0 = [...]uint8{0, 3, 8}
+	_Gap_index_1 = [...]uint8{0, 4, 7, 12, 17, 21}
+)
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_name_0[_Gap_index_0[i]:_Gap_index_0[i+1]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Gap_name_1[_Gap_index_1[i]:_Gap_index_1[i+1]]
+	case i == 11:
+		return _Gap_name_2
+	default:
+		return "Gap(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+// Signed integers spanning zero.
+const num_in = `type Num int
+const (
+	m_2 Num = -2 + iota
+	m_1
+	m0
+	m1
+	m2
+)
+`
+
+const num_out = `
+const _Num_name = "m_2m_1m0m1m2"
+
+var _Num_index = [...]uint8{0, 3, 6, 8, 10, 12}
+
+func (i Num) String() string {
+	i += 2
+	if i < 0 || i >= Num(len(_Num_index)-1) {
+		return "Num(" + strconv.FormatInt(int64(i-2), 10) + ")"
+	}
+	return _Num_name[_Num_index[i]:_Num_index[i+1]]
+}
+`
+
+// Negative integers alone, shifted up to zero by an addition.
+const neg_in = `type Neg int
+const (
+	MinusThree Neg = iota - 3
+	MinusTwo
+	MinusOne
+)
+`
+
+const neg_out = `
+const _Neg_name = "MinusThreeMinusTwoMinusOne"
+
+var _Neg_index = [...]uint8{0, 10, 18, 26}
+
+func (i Neg) String() string {
+	i += 3
+	if i < 0 || i >= Neg(len(_Neg_index)-1) {
+		return "Neg(" + strconv.FormatInt(int64(i-3), 10) + ")"
+	}
+	return _Neg_name[_Neg_index[i]:_Neg_index[i+1]]
+}
+`
+
+// Negative and positive integers, with a gap at zero.
+const straddle_in = `type Straddle int
+const (
+	SMinusThree Straddle = iota - 3
+	SMinusTwo
+	SMinusOne
+	_
+	SOne
+	STwo
+	SThree
+)
+`
+
+const straddle_out = `
+const (
+	_Straddle_name_0 = "SMinusThreeSMinusTwoSMinusOne"
+	_Straddle_name_1 = "SOneSTwoSThree"
+)
+
+var (
+	_Straddle_index_0 = [...]uint8{0, 11, 20, 29}
+	_Straddle_index_1 = [...]uint8{0, 4, 8, 14}
+)
+
+func (i Straddle) String() string {
+	switch {
+	case -3 <= i && i <= -1:
+		i += 3
+		return _Straddle_name_0[_Straddle_index_0[i]:_Straddle_index_0[i+1]]
+	case 1 <= i && i <= 3:
+		i -= 1
+		return _Straddle_name_1[_Straddle_index_1[i]:_Straddle_index_1[i+1]]
+	default:
+		return "Straddle(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+// Negative integers with gaps, one of them a run of one value.
+const neggap_in = `type Neggap int16
+const (
+	Ten Neggap = -10
+	Nine Neggap = -9
+	Five Neggap = -5
+	Four Neggap = -4
+	Three Neggap = -3
+	One Neggap = -1
+)
+`
+
+const neggap_out = `
+const (
+	_Neggap_name_0 = "TenNine"
+	_Neggap_name_1 = "FiveFourThree"
+	_Neggap_name_2 = "One"
+)
+
+var (
+	_Neggap_index_0 = [...]uint8{0, 3, 7}
+	_Neggap_index_1 = [...]uint8{0, 4, 8, 13}
+)
+
+func (i Neggap) String() string {
+	switch {
+	case -10 <= i && i <= -9:
+		i += 10
+		return _Neggap_name_0[_Neggap_index_0[i]:_Neggap_index_0[i+1]]
+	case -5 <= i && i <= -3:
+		i += 5
+		return _Neggap_name_1[_Neggap_index_1[i]:_Neggap_index_1[i+1]]
+	case i == -1:
+		return _Neggap_name_2
+	default:
+		return "Neggap(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+// The lowest value of int8, whose negation does not fit it, is subtracted.
+const low_in = `type Low int8
+const (
+	Lowest Low = -128 + iota
+	Lower
+	LowAgain
+)
+`
+
+const low_out = `
+const _Low_name = "LowestLowerLowAgain"
+
+var _Low_index = [...]uint8{0, 6, 11, 19}
+
+func (i Low) String() string {
+	i -= -128
+	if i < 0 || i >= Low(len(_Low_index)-1) {
+		return "Low(" + strconv.FormatInt(int64(i+-128), 10) + ")"
+	}
+	return _Low_name[_Low_index[i]:_Low_index[i+1]]
+}
+`
+
+// Unsigned integers spanning zero.
+const unum_in = `type Unum uint
+const (
+	m_2 Unum = iota + 253
+	m_1
+)
+
+const (
+	m0 Unum = iota
+	m1
+	m2
+)
+`
+
+const unum_out = `
+const (
+	_Unum_name_0 = "m0m1m2"
+	_Unum_name_1 = "m_2m_1"
+)
+
+var (
+	_Unum_index_0 = [...]uint8{0, 2, 4, 6}
+	_Unum_index_1 = [...]uint8{0, 3, 6}
+)
+
+func (i Unum) String() string {
+	switch {
+	case 0 <= i && i <= 2:
+		return _Unum_name_0[_Unum_index_0[i]:_Unum_index_0[i+1]]
+	case 253 <= i && i <= 254:
+		i -= 253
+		return _Unum_name_1[_Unum_index_1[i]:_Unum_index_1[i+1]]
+	default:
+		return "Unum(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+}
+`
+
+// Unsigned 64-bit values with the top bit set, printed as unsigned when
+// they have no name.
+const mask_in = `type Mask uint64
+const (
+	MaskLow Mask = iota
+	MaskMid
+	MaskTop Mask = 1 << 63
+)
+`
+
+const mask_out = `
+const (
+	_Mask_name_0 = "MaskLowMaskMid"
+	_Mask_name_1 = "MaskTop"
+)
+
+var (
+	_Mask_index_0 = [...]uint8{0, 7, 14}
+)
+
+func (i Mask) String() string {
+	switch {
+	case 0 <= i && i <= 1:
+		return _Mask_name_0[_Mask_index_0[i]:_Mask_index_0[i+1]]
+	case i == 9223372036854775808:
+		return _Mask_name_1
+	default:
+		return "Mask(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+}
+`
+
+// A single run starting at the top bit.
+const high_in = `type High uint64
+const (
+	HighA High = 1<<63 + iota
+	HighB
+)
+`
+
+const high_out = `
+const _High_name = "HighAHighB"
+
+var _High_index = [...]uint8{0, 5, 10}
+
+func (i High) String() string {
+	if i < 9223372036854775808 || i-9223372036854775808 >= High(len(_High_index)-1) {
+		return "High(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+	i -= 9223372036854775808
+	return _High_name[_High_index[i]:_High_index[i+1]]
+}
+`
+
+// Enough gaps to trigger a map implementation of the method.
+// Also includes a duplicate to test that it doesn't cause problems
+const prime_in = `type Prime int
+const (
+	p2 Prime = 2
+	p3 Prime = 3
+	p5 Prime = 5
+	p7 Prime = 7
+	p77 Prime = 7 // Duplicate; note that p77 doesn't appear below.
+	p11 Prime = 11
+	p13 Prime = 13
+	p17 Prime = 17
+	p19 Prime = 19
+	p23 Prime = 23
+	p29 Prime = 29
+	p37 Prime = 31
+	p41 Prime = 41
+	p43 Prime = 43
+)
+`
+
+const prime_out = `
+const _Prime_name = "p2p3p5p7p11p13p17p19p23p29p37p41p43"
+
+var _Prime_map = map[Prime]string{
+	2:  _Prime_name[0:2],
+	3:  _Prime_name[2:4],
+	5:  _Prime_name[4:6],
+	7:  _Prime_name[6:8],
+	11: _Prime_name[8:11],
+	13: _Prime_name[11:14],
+	17: _Prime_name[14:17],
+	19: _Prime_name[17:20],
+	23: _Prime_name[20:23],
+	29: _Prime_name[23:26],
+	31: _Prime_name[26:29],
+	41: _Prime_name[29:32],
+	43: _Prime_name[32:35],
+}
+
+func (i Prime) String() string {
+	if str, ok := _Prime_map[i]; ok {
+		return str
+	}
+	return "Prime(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+`
+
+const prefix_in = `type Type int
+const (
+	TypeInt Type = iota
+	TypeString
+	TypeFloat
+	TypeRune
+	TypeByte
+	TypeStruct
+	TypeSlice
+)
+`
+
+const prefix_out = `
+const _Type_name = "IntStringFloatRuneByteStructSlice"
+
+var _Type_index = [...]uint8{0, 3, 9, 14, 18, 22, 28, 33}
+
+func (i Type) String() string {
+	if i < 0 || i >= Type(len(_Type_index)-1) {
+		return "Type(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Type_name[_Type_index[i]:_Type_index[i+1]]
+}
+`
+
+const tokens_in = `type Token int
+const (
+	And Token = iota // &
+	Or               // |
+	Add              // +
+	Sub              // -
+	Ident
+	Period // .
+
+	// not to be used
+	SingleBefore
+	// not to be used
+	BeforeAndInline // inline
+	InlineGeneral /* inline general */
+)
+`
+
+const tokens_out = `
+const _Token_name = "&|+-Ident.SingleBeforeinlineinline general"
+
+var _Token_index = [...]uint8{0, 1, 2, 3, 4, 9, 10, 22, 28, 42}
+
+func (i Token) String() string {
+	if i < 0 || i >= Token(len(_Token_index)-1) {
+		return "Token(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Token_name[_Token_index[i]:_Token_index[i+1]]
+}
+`
+
+func TestGolden(t *testing.T) {
+	for _, test := range golden {
+		opts := Options{
+			TrimPrefix:  test.trimPrefix,
+			LineComment: test.lineComment,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		// The expected output is formatted as the generated one is.
+		expected := string(formatBytes([]byte(test.output)))
+		if got != expected {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, expected)
+		}
+	}
+}
+
+// GoldenText is a test case for the parse function and methods of -text,
+// whose output follows that of the String method.
+type GoldenText struct {
+	name   string
+	input  string // input; the package clause is provided when running the test.
+	output string // expected output, String method included.
+}
+
+var goldenText = []GoldenText{
+	{"day", day_in, day_out + day_out_text},
+	{"gap", gap_in, gap_out + gap_out_text},
+	{"prime", prime_in, prime_out + prime_out_text},
+}
+
+// One run.
+const day_out_text = `
+func _Day_parse(s string) (Day, error) {
+	switch s {
+	case "Monday":
+		return 0, nil
+	case "Tuesday":
+		return 1, nil
+	case "Wednesday":
+		return 2, nil
+	case "Thursday":
+		return 3, nil
+	case "Friday":
+		return 4, nil
+	case "Saturday":
+		return 5, nil
+	case "Sunday":
+		return 6, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Day values", s)
+}
+
+func (i Day) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Day) UnmarshalText(text []byte) error {
+	v, err := _Day_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+// Multiple runs.
+const gap_out_text = `
+func _Gap_parse(s string) (Gap, error) {
+	switch s {
+	case "Two":
+		return 2, nil
+	case "Three":
+		return 3, nil
+	case "Five":
+		return 5, nil
+	case "Six":
+		return 6, nil
+	case "Seven":
+		return 7, nil
+	case "Eight":
+		return 8, nil
+	case "Nine":
+		return 9, nil
+	case "Eleven":
+		return 11, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Gap values", s)
+}
+
+func (i Gap) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Gap) UnmarshalText(text []byte) error {
+	v, err := _Gap_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+// Map.
+const prime_out_text = `
+var _Prime_value = map[string]Prime{
+	_Prime_name[0:2]:   2,
+	_Prime_name[2:4]:   3,
+	_Prime_name[4:6]:   5,
+	_Prime_name[6:8]:   7,
+	_Prime_name[8:11]:  11,
+	_Prime_name[11:14]: 13,
+	_Prime_name[14:17]: 17,
+	_Prime_name[17:20]: 19,
+	_Prime_name[20:23]: 23,
+	_Prime_name[23:26]: 29,
+	_Prime_name[26:29]: 31,
+	_Prime_name[29:32]: 41,
+	_Prime_name[32:35]: 43,
+	"p77":              7,
+}
+
+func _Prime_parse(s string) (Prime, error) {
+	if v, ok := _Prime_value[s]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Prime values", s)
+}
+
+func (i Prime) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Prime) UnmarshalText(text []byte) error {
+	v, err := _Prime_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+func TestGoldenText(t *testing.T) {
+	for _, test := range goldenText {
+		opts := Options{
+			Text: true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// GoldenParse is a test case for -parse, whose output follows that of String.
+type GoldenParse struct {
+	name        string
+	trimPrefix  string
+	lineComment bool
+	input       string // input; the package clause is provided when running the test.
+	output      string // expected output, String method included.
+}
+
+var goldenParse = []GoldenParse{
+	{"pill", "", false, pill_in, pill_out_parse},
+	{"kind", "Kind", false, kind_in, kind_out_parse},
+	{"tokens", "", true, tokens_in, tokens_out + tokens_out_parse},
+}
+
+// An alias parses to the value of the name it stands for.
+const pill_in = `type Pill int
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+	Acetaminophen Pill = Paracetamol
+)
+`
+
+const pill_out_parse = `
+const _Pill_name = "PlaceboAspirinIbuprofenParacetamol"
+
+var _Pill_index = [...]uint8{0, 7, 14, 23, 34}
+
+func (i Pill) String() string {
+	if i < 0 || i >= Pill(len(_Pill_index)-1) {
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Pill_name[_Pill_index[i]:_Pill_index[i+1]]
+}
+
+func _Pill_parse(s string) (Pill, error) {
+	switch s {
+	case "Placebo":
+		return 0, nil
+	case "Aspirin":
+		return 1, nil
+	case "Ibuprofen":
+		return 2, nil
+	case "Paracetamol":
+		return 3, nil
+	case "Acetaminophen":
+		return 3, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Pill values", s)
+}
+
+// PillString returns the Pill value whose name is s.
+func PillString(s string) (Pill, error) {
+	return _Pill_parse(s)
+}
+`
+
+// The names are those printed, with the prefix trimmed. None of the
+// constants is named KindString, as the function parsing the names is.
+const kind_in = `type Kind int
+const (
+	KindInt Kind = iota
+	KindFloat
+	KindRune
+	KindSlice
+)
+`
+
+const kind_out_parse = `
+const _Kind_name = "IntFloatRuneSlice"
+
+var _Kind_index = [...]uint8{0, 3, 8, 12, 17}
+
+func (i Kind) String() string {
+	if i < 0 || i >= Kind(len(_Kind_index)-1) {
+		return "Kind(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Kind_name[_Kind_index[i]:_Kind_index[i+1]]
+}
+
+func _Kind_parse(s string) (Kind, error) {
+	switch s {
+	case "Int":
+		return 0, nil
+	case "Float":
+		return 1, nil
+	case "Rune":
+		return 2, nil
+	case "Slice":
+		return 3, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Kind values", s)
+}
+
+// KindString returns the Kind value whose name is s.
+func KindString(s string) (Kind, error) {
+	return _Kind_parse(s)
+}
+`
+
+// The names are those of the line comments.
+const tokens_out_parse = `
+func _Token_parse(s string) (Token, error) {
+	switch s {
+	case "&":
+		return 0, nil
+	case "|":
+		return 1, nil
+	case "+":
+		return 2, nil
+	case "-":
+		return 3, nil
+	case "Ident":
+		return 4, nil
+	case ".":
+		return 5, nil
+	case "SingleBefore":
+		return 6, nil
+	case "inline":
+		return 7, nil
+	case "inline general":
+		return 8, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Token values", s)
+}
+
+// TokenString returns the Token value whose name is s.
+func TokenString(s string) (Token, error) {
+	return _Token_parse(s)
+}
+`
+
+func TestGoldenParse(t *testing.T) {
+	for _, test := range goldenParse {
+		opts := Options{
+			TrimPrefix:  test.trimPrefix,
+			LineComment: test.lineComment,
+			Parse:       true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// TestParseSwitch checks that the parse function of at most maxParseSwitch
+// names is a switch, and of more a map.
+func TestParseSwitch(t *testing.T) {
+	for _, n := range []int{maxParseSwitch, maxParseSwitch + 1} {
+		input := "type Level int\nconst (\n"
+		for i := 0; i < n; i++ {
+			input += fmt.Sprintf("\tL%d Level = %d\n", i, i*2) // One run each.
+		}
+		input += ")\n"
+		got := goldenGenerate(t, Options{Parse: true}, "level", input)
+		isSwitch := strings.Contains(got, "\tswitch s {\n")
+		isMap := strings.Contains(got, "var _Level_value = map[string]Level{")
+		if isSwitch != (n <= maxParseSwitch) || isMap == isSwitch {
+			t.Errorf("%d names: got\n%s", n, got)
+		}
+	}
+}
+
+// The -json methods quote the name printed by String.
+const day_out_json = `
+func _Day_parse(s string) (Day, error) {
+	switch s {
+	case "Monday":
+		return 0, nil
+	case "Tuesday":
+		return 1, nil
+	case "Wednesday":
+		return 2, nil
+	case "Thursday":
+		return 3, nil
+	case "Friday":
+		return 4, nil
+	case "Saturday":
+		return 5, nil
+	case "Sunday":
+		return 6, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Day values", s)
+}
+
+func (i Day) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(i.String())), nil
+}
+
+func (i *Day) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("Day should be a string, got %s", data)
+	}
+	v, err := _Day_parse(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+func TestGoldenJSON(t *testing.T) {
+	opts := Options{
+		JSON: true,
+	}
+	got := goldenGenerate(t, opts, "day", day_in)
+	if want := day_out + day_out_json; got != want {
+		t.Errorf("day: got\n====\n%s====\nexpected\n====%s", got, want)
+	}
+}
+
+// GoldenSQL is a test case for the Value and Scan methods of -sql.
+type GoldenSQL struct {
+	name   string
+	input  string // input; the package clause is provided when running the test.
+	output string // expected output, String method included.
+}
+
+var goldenSQL = []GoldenSQL{
+	{"num", num_in, num_out + num_out_sql},
+	{"unum", unum_in, unum_out + unum_out_sql},
+}
+
+// Signed.
+const num_out_sql = `
+func _Num_parse(s string) (Num, error) {
+	switch s {
+	case "m_2":
+		return -2, nil
+	case "m_1":
+		return -1, nil
+	case "m0":
+		return 0, nil
+	case "m1":
+		return 1, nil
+	case "m2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Num values", s)
+}
+
+func (i Num) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+func (i *Num) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into Num")
+	default:
+		return fmt.Errorf("cannot scan %T into Num", src)
+	}
+	v, err := _Num_parse(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+// Unsigned.
+const unum_out_sql = `
+func _Unum_parse(s string) (Unum, error) {
+	switch s {
+	case "m0":
+		return 0, nil
+	case "m1":
+		return 1, nil
+	case "m2":
+		return 2, nil
+	case "m_2":
+		return 253, nil
+	case "m_1":
+		return 254, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Unum values", s)
+}
+
+func (i Unum) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+func (i *Unum) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into Unum")
+	default:
+		return fmt.Errorf("cannot scan %T into Unum", src)
+	}
+	v, err := _Unum_parse(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+func TestGoldenSQL(t *testing.T) {
+	for _, test := range goldenSQL {
+		opts := Options{
+			SQL: true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// GoldenIsValid is a test case for the IsValid method of -isvalid, one for
+// each layout of the String method.
+type GoldenIsValid struct {
+	name   string
+	input  string // input; the package clause is provided when running the test.
+	output string // expected output, String method included.
+}
+
+var goldenIsValid = []GoldenIsValid{
+	{"day", day_in, day_out + day_out_isvalid},
+	{"offset", offset_in, offset_out + offset_out_isvalid},
+	{"gap", gap_in, gap_out + gap_out_isvalid},
+	{"prime", prime_in, prime_out + prime_out_isvalid},
+}
+
+// One run.
+const day_out_isvalid = `
+func (i Day) IsValid() bool {
+	return 0 <= i && i <= 6
+}
+`
+
+// One run with an offset.
+const offset_out_isvalid = `
+func (i Number) IsValid() bool {
+	return 1 <= i && i <= 3
+}
+`
+
+// Multiple runs.
+const gap_out_isvalid = `
+func (i Gap) IsValid() bool {
+	switch {
+	case 2 <= i && i <= 3:
+		return true
+	case 5 <= i && i <= 9:
+		return true
+	case i == 11:
+		return true
+	}
+	return false
+}
+`
+
+// Map.
+const prime_out_isvalid = `
+func (i Prime) IsValid() bool {
+	_, ok := _Prime_map[i]
+	return ok
+}
+`
+
+func TestGoldenIsValid(t *testing.T) {
+	for _, test := range goldenIsValid {
+		opts := Options{
+			IsValid: true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// GoldenValues is a test case for the functions of -values.
+type GoldenValues struct {
+	name        string
+	trimPrefix  string
+	lineComment bool
+	input       string // input; the package clause is provided when running the test.
+	output      string // expected output, String method included.
+}
+
+var goldenValues = []GoldenValues{
+	{"color", "Color", true, color_in, color_out_values},
+	{"gap", "", false, gap_in, gap_out + gap_out_values},
+}
+
+// Names as printed, and a duplicate value listed once.
+const color_in = `type Color int
+const (
+	ColorRed     Color = iota // red
+	ColorGreen
+	ColorBlue                 // blue
+	ColorCrimson Color = ColorRed
+)
+`
+
+const color_out_values = `
+const _Color_name = "redGreenblue"
+
+var _Color_index = [...]uint8{0, 3, 8, 12}
+
+func (i Color) String() string {
+	if i < 0 || i >= Color(len(_Color_index)-1) {
+		return "Color(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Color_name[_Color_index[i]:_Color_index[i+1]]
+}
+
+var _Color_values = []Color{0, 1, 2}
+
+// ColorValues returns the values of the Color constants in increasing order.
+func ColorValues() []Color {
+	return append([]Color(nil), _Color_values...)
+}
+
+// ColorNames returns the names of the Color constants, as printed by String,
+// in the order of ColorValues.
+func ColorNames() []string {
+	names := make([]string, len(_Color_values))
+	for i, v := range _Color_values {
+		names[i] = v.String()
+	}
+	return names
+}
+`
+
+// Multiple runs.
+const gap_out_values = `
+var _Gap_values = []Gap{2, 3, 5, 6, 7, 8, 9, 11}
+
+// GapValues returns the values of the Gap constants in increasing order.
+func GapValues() []Gap {
+	return append([]Gap(nil), _Gap_values...)
+}
+
+// GapNames returns the names of the Gap constants, as printed by String,
+// in the order of GapValues.
+func GapNames() []string {
+	names := make([]string, len(_Gap_values))
+	for i, v := range _Gap_values {
+		names[i] = v.String()
+	}
+	return names
+}
+`
+
+func TestGoldenValues(t *testing.T) {
+	for _, test := range goldenValues {
+		opts := Options{
+			TrimPrefix:  test.trimPrefix,
+			LineComment: test.lineComment,
+			Values:      true,
+		}
+		got := goldenGenerate(t, opts, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+func TestGoldenRune(t *testing.T) {
+	for _, test := range []struct {
+		name, input, output string
+	}{
+		{"token", token_in, token_out},
+		{"letter", letter_in, letter_out},
+	} {
+		got := goldenGenerate(t, Options{}, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// Constants written as rune literals print the character of an unknown value.
+const token_in = `type Token rune
+const (
+	LParen Token = '('
+	RParen Token = ')'
+	Plus Token = '+'
+	Semi Token = ';'
+)
+`
+
+const token_out = `
+const (
+	_Token_name_0 = "LParenRParen"
+	_Token_name_1 = "Plus"
+	_Token_name_2 = "Semi"
+)
+
+var (
+	_Token_index_0 = [...]uint8{0, 6, 12}
+)
+
+func (i Token) String() string {
+	switch {
+	case 40 <= i && i <= 41:
+		i -= 40
+		return _Token_name_0[_Token_index_0[i]:_Token_index_0[i+1]]
+	case i == 43:
+		return _Token_name_1
+	case i == 59:
+		return _Token_name_2
+	default:
+		return "Token(" + strconv.QuoteRune(rune(i)) + ")"
+	}
+}
+`
+
+// A rune literal carried down by iota.
+const letter_in = `type Letter rune
+const (
+	A Letter = 'a' + iota
+	B
+	C
+)
+`
+
+const letter_out = `
+const _Letter_name = "ABC"
+
+var _Letter_index = [...]uint8{0, 1, 2, 3}
+
+func (i Letter) String() string {
+	i -= 97
+	if i < 0 || i >= Letter(len(_Letter_index)-1) {
+		return "Letter(" + strconv.QuoteRune(rune(i+97)) + ")"
+	}
+	return _Letter_name[_Letter_index[i]:_Letter_index[i+1]]
+}
+`
+
+func TestGoldenStringType(t *testing.T) {
+	for _, test := range []struct {
+		opts   Options
+		output string
+	}{
+		{Options{}, region_out},
+		{Options{Values: true, IsValid: true, Text: true}, region_out_values},
+	} {
+		got := goldenGenerate(t, test.opts, "region", region_in)
+		if got != test.output {
+			t.Errorf("%+v: got\n====\n%s====\nexpected\n====%s", test.opts, got, test.output)
+		}
+	}
+}
+
+// String constants, a duplicate among them, print as themselves.
+const region_in = `type Region string
+const (
+	USWest Region = "us-west"
+	EUCentral Region = "eu-central"
+	APSouth Region = "ap-south"
+	Europe Region = "eu-central"
+	Quoted Region = "say \"hi\""
+)
+`
+
+const region_out = `
+func (i Region) String() string {
+	return string(i)
+}
+`
+
+// The values are in lexical order.
+const region_out_values = `
+func (i Region) String() string {
+	return string(i)
+}
+
+var _Region_values = []Region{"ap-south", "eu-central", "say \"hi\"", "us-west"}
+
+// RegionValues returns the values of the Region constants in increasing order.
+func RegionValues() []Region {
+	return append([]Region(nil), _Region_values...)
+}
+
+// RegionNames returns the names of the Region constants, as printed by String,
+// in the order of RegionValues.
+func RegionNames() []string {
+	names := make([]string, len(_Region_values))
+	for i, v := range _Region_values {
+		names[i] = v.String()
+	}
+	return names
+}
+
+func (i Region) IsValid() bool {
+	for _, v := range _Region_values {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+func _Region_parse(s string) (Region, error) {
+	for _, v := range _Region_values {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%s does not belong to Region values", s)
+}
+
+func (i Region) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Region) UnmarshalText(text []byte) error {
+	v, err := _Region_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+func TestGoldenSort(t *testing.T) {
+	opts := Options{Sort: sortName}
+	got := goldenGenerate(t, opts, "prime", prime_in)
+	if got != prime_out_name {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, prime_out_name)
+	}
+}
+
+// The map is in the order of the names, which are sliced as before.
+const prime_out_name = `
+const _Prime_name = "p2p3p5p7p11p13p17p19p23p29p37p41p43"
+
+var _Prime_map = map[Prime]string{
+	11: _Prime_name[8:11],
+	13: _Prime_name[11:14],
+	17: _Prime_name[14:17],
+	19: _Prime_name[17:20],
+	2:  _Prime_name[0:2],
+	23: _Prime_name[20:23],
+	29: _Prime_name[23:26],
+	3:  _Prime_name[2:4],
+	31: _Prime_name[26:29],
+	41: _Prime_name[29:32],
+	43: _Prime_name[32:35],
+	5:  _Prime_name[4:6],
+	7:  _Prime_name[6:8],
+}
+
+func (i Prime) String() string {
+	if str, ok := _Prime_map[i]; ok {
+		return str
+	}
+	return "Prime(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+`
+
+// TestSortDecl checks the order of the values listed by -values for each
+// order of -sort, the constants being declared in two files.
+func TestSortDecl(t *testing.T) {
+	for _, test := range []struct {
+		sort, want string
+	}{
+		{sortValue, "[]Level{1, 2, 3, 9}"},
+		{sortDecl, "[]Level{3, 1, 2, 9}"},
+		{sortName, "[]Level{1, 9, 2, 3}"},
+	} {
+		info, fset := loadPackage(t, "sort", map[string]string{
+			"a.go": "package test\ntype Level int\nconst (\n\tZed Level = 3\n\tAnn Level = 1\n)\n",
+			"b.go": "package test\nconst (\n\tMid Level = 2\n\tBob Level = 9\n)\n",
+		})
+		files, err := Generate(fset, info, []string{"Level"}, Options{Values: true, Sort: test.sort})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(files["level_string.go"]); !strings.Contains(got, test.want) {
+			t.Errorf("-sort=%s: output does not contain %s:\n%s", test.sort, test.want, got)
+		}
+	}
+}
+
+func TestGoldenAliases(t *testing.T) {
+	opts := Options{Aliases: true, Parse: true}
+	got := goldenGenerate(t, opts, "pill", pill_aliases_in)
+	if got != pill_out_aliases {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, pill_out_aliases)
+	}
+}
+
+// Two aliases of one value and one of another, which are parsed too.
+const pill_aliases_in = `type Pill int
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+	Acetaminophen = Paracetamol
+	APAP = Paracetamol
+	ASA = Aspirin
+)
+`
+
+const pill_out_aliases = `
+// The aliases of the Pill constants, which print as the name before them:
+//
+//	Aspirin: ASA
+//	Paracetamol: Acetaminophen, APAP
+
+const _Pill_name = "PlaceboAspirinIbuprofenParacetamol"
+
+var _Pill_index = [...]uint8{0, 7, 14, 23, 34}
+
+func (i Pill) String() string {
+	if i < 0 || i >= Pill(len(_Pill_index)-1) {
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Pill_name[_Pill_index[i]:_Pill_index[i+1]]
+}
+
+func _Pill_parse(s string) (Pill, error) {
+	switch s {
+	case "Placebo":
+		return 0, nil
+	case "Aspirin":
+		return 1, nil
+	case "Ibuprofen":
+		return 2, nil
+	case "Paracetamol":
+		return 3, nil
+	case "Acetaminophen":
+		return 3, nil
+	case "APAP":
+		return 3, nil
+	case "ASA":
+		return 1, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Pill values", s)
+}
+
+// PillString returns the Pill value whose name is s.
+func PillString(s string) (Pill, error) {
+	return _Pill_parse(s)
+}
+`
+
+func TestGoldenFilter(t *testing.T) {
+	opts := Options{
+		TrimPrefix: "Type",
+		Include:    "^S",
+		Exclude:    "ice$",
+	}
+	got := goldenGenerate(t, opts, "prefix", prefix_in)
+	if got != prefix_out_filter {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, prefix_out_filter)
+	}
+}
+
+// The filters match the names once trimmed: TypeSlice is left out as Slice.
+const prefix_out_filter = `
+const (
+	_Type_name_0 = "String"
+	_Type_name_1 = "Struct"
+)
+
+func (i Type) String() string {
+	switch {
+	case i == 1:
+		return _Type_name_0
+	case i == 5:
+		return _Type_name_1
+	default:
+		return "Type(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+func TestGoldenMethod(t *testing.T) {
+	opts := Options{Method: "Name"}
+	got := goldenGenerate(t, opts, "gap", gap_in)
+	if got != gap_out_method {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, gap_out_method)
+	}
+}
+
+// The identifiers are named apart from those of the String method.
+const gap_out_method = `
+const (
+	_Gap_Name_name_0 = "TwoThree"
+	_Gap_Name_name_1 = "FiveSixSevenEightNine"
+	_Gap_Name_name_2 = "Eleven"
+)
+
+var (
+	_Gap_Name_index_0 = [...]uint8{0, 3, 8}
+	_Gap_Name_index_1 = [...]uint8{0, 4, 7, 12, 17, 21}
+)
+
+func (i Gap) Name() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_Name_name_0[_Gap_Name_index_0[i]:_Gap_Name_index_0[i+1]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Gap_Name_name_1[_Gap_Name_index_1[i]:_Gap_Name_index_1[i+1]]
+	case i == 11:
+		return _Gap_Name_name_2
+	default:
+		return "Gap(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+func TestGoldenGoString(t *testing.T) {
+	opts := Options{GoString: true}
+	got := goldenGenerate(t, opts, "day", day_in)
+	if want := day_out + day_out_gostring; got != want {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, want)
+	}
+}
+
+const day_out_gostring = `
+// GoString returns the name of the constant qualified by the package, or
+// the conversion of the value to Day if it has no name.
+func (i Day) GoString() string {
+	return "test." + i.String()
+}
+`
+
+type GoldenTransform struct {
+	transform string
+	parse     bool
+	output    string // expected output, String method included.
+}
+
+var goldenTransform = []GoldenTransform{
+	{transformSnake, true, proto_out_snake},
+	{transformUpper, false, proto_out_upper},
+}
+
+// Runs of capitals and digits within the names.
+const proto_in = `type Proto int
+const (
+	ProtoHTTPServer Proto = iota
+	ProtoFTPServer
+	ProtoUTF8Name
+	ProtoTCP
+)
+`
+
+// The parse function reads back the names as printed.
+const proto_out_snake = `
+const _Proto_name = "http_serverftp_serverutf8_nametcp"
+
+var _Proto_index = [...]uint8{0, 11, 21, 30, 33}
+
+func (i Proto) String() string {
+	if i < 0 || i >= Proto(len(_Proto_index)-1) {
+		return "Proto(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Proto_name[_Proto_index[i]:_Proto_index[i+1]]
+}
+
+func _Proto_parse(s string) (Proto, error) {
+	switch s {
+	case "http_server":
+		return 0, nil
+	case "ftp_server":
+		return 1, nil
+	case "utf8_name":
+		return 2, nil
+	case "tcp":
+		return 3, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Proto values", s)
+}
+
+// ProtoString returns the Proto value whose name is s.
+func ProtoString(s string) (Proto, error) {
+	return _Proto_parse(s)
+}
+`
+
+const proto_out_upper = `
+const _Proto_name = "HTTPSERVERFTPSERVERUTF8NAMETCP"
+
+var _Proto_index = [...]uint8{0, 10, 19, 27, 30}
+
+func (i Proto) String() string {
+	if i < 0 || i >= Proto(len(_Proto_index)-1) {
+		return "Proto(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Proto_name[_Proto_index[i]:_Proto_index[i+1]]
+}
+`
+
+func TestGoldenTransform(t *testing.T) {
+	for _, test := range goldenTransform {
+		opts := Options{
+			TrimPrefix: "Proto",
+			Transform:  test.transform,
+			Parse:      test.parse,
+		}
+		got := goldenGenerate(t, opts, "proto", proto_in)
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.transform, got, test.output)
+		}
+	}
+}
+
+// Two prefixes and a suffix, and an alias trimmed to the same name.
+const state_in = `type State int
+const (
+	StateOldIdleState State = iota
+	StRunningState
+	StateOldDoneState
+	StDoneState = StateOldDoneState
+)
+`
+
+const state_out = `
+const _State_name = "IdleRunningDone"
+
+var _State_index = [...]uint8{0, 4, 11, 15}
+
+func (i State) String() string {
+	if i < 0 || i >= State(len(_State_index)-1) {
+		return "State(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _State_name[_State_index[i]:_State_index[i+1]]
+}
+
+func _State_parse(s string) (State, error) {
+	switch s {
+	case "Idle":
+		return 0, nil
+	case "Running":
+		return 1, nil
+	case "Done":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to State values", s)
+}
+
+// StateString returns the State value whose name is s.
+func StateString(s string) (State, error) {
+	return _State_parse(s)
+}
+`
+
+func TestGoldenTrim(t *testing.T) {
+	opts := Options{
+		TrimPrefix: "StateOld,St",
+		TrimSuffix: "State",
+		Parse:      true,
+	}
+	got := goldenGenerate(t, opts, "state", state_in)
+	if got != state_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, state_out)
+	}
+}
+
+// Two types, each with a prefix of its own.
+const shapes_in = `type Color int
+const (
+	ColRed Color = iota
+	ColGreen
+)
+
+type Shape int
+
+const (
+	ShCircle Shape = iota
+	ShSquare
+)
+`
+
+const shapes_out = `
+const _Color_name = "RedGreen"
+
+var _Color_index = [...]uint8{0, 3, 8}
+
+func (i Color) String() string {
+	if i < 0 || i >= Color(len(_Color_index)-1) {
+		return "Color(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Color_name[_Color_index[i]:_Color_index[i+1]]
+}
+
+const _Shape_name = "CircleSquare"
+
+var _Shape_index = [...]uint8{0, 6, 12}
+
+func (i Shape) String() string {
+	if i < 0 || i >= Shape(len(_Shape_index)-1) {
+		return "Shape(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Shape_name[_Shape_index[i]:_Shape_index[i+1]]
+}
+`
+
+func TestGoldenTrimTypes(t *testing.T) {
+	opts := Options{
+		TrimPrefix: "Color:Col,Shape:Sh",
+	}
+	got := goldenGenerateTypes(t, opts, "shapes", shapes_in, "Color", "Shape")
+	if got != shapes_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, shapes_out)
+	}
+}
+
+func TestGoldenMixedTypes(t *testing.T) {
+	got := goldenGenerateTypes(t, Options{}, "mixed", mixed_in, "Color", "Shape")
+	if got != mixed_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, mixed_out)
+	}
+}
+
+// Two types in one block, each carried down, with the type also written as
+// an alias and in parentheses, and an untyped constant.
+const mixed_in = `type Color int
+type Shape int
+type Hue = Color
+const (
+	Red Color = iota
+	Green
+	Square Shape = iota
+	Circle
+	Blue Hue = iota
+	Cyan
+	Pink (Color) = 9
+	Size = 10
+)
+`
+
+const mixed_out = `
+const (
+	_Color_name_0 = "RedGreen"
+	_Color_name_1 = "BlueCyan"
+	_Color_name_2 = "Pink"
+)
+
+var (
+	_Color_index_0 = [...]uint8{0, 3, 8}
+	_Color_index_1 = [...]uint8{0, 4, 8}
+)
+
+func (i Color) String() string {
+	switch {
+	case 0 <= i && i <= 1:
+		return _Color_name_0[_Color_index_0[i]:_Color_index_0[i+1]]
+	case 4 <= i && i <= 5:
+		i -= 4
+		return _Color_name_1[_Color_index_1[i]:_Color_index_1[i+1]]
+	case i == 9:
+		return _Color_name_2
+	default:
+		return "Color(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+const _Shape_name = "SquareCircle"
+
+var _Shape_index = [...]uint8{0, 6, 12}
+
+func (i Shape) String() string {
+	i -= 2
+	if i < 0 || i >= Shape(len(_Shape_index)-1) {
+		return "Shape(" + strconv.FormatInt(int64(i+2), 10) + ")"
+	}
+	return _Shape_name[_Shape_index[i]:_Shape_index[i+1]]
+}
+`
+
+// A trailing comment with quotes, one of two lines, a doc comment and a
+// trailing comment with a backslash.
+const note_in = `type Note int
+const (
+	Do Note = iota // "do"
+	Re /* re,
+	      once ut */
+	// The note mi,
+	// after re.
+	Mi
+	Fa // fa\sol
+	Sol
+)
+`
+
+const note_out = `
+const _Note_name = "\"do\"re, once utMifa\\solSol"
+
+var _Note_index = [...]uint8{0, 4, 15, 17, 23, 26}
+
+func (i Note) String() string {
+	if i < 0 || i >= Note(len(_Note_index)-1) {
+		return "Note(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Note_name[_Note_index[i]:_Note_index[i+1]]
+}
+`
+
+const note_out_doc = `
+const _Note_name = "\"do\"re, once utThe note mi, after re.fa\\solSol"
+
+var _Note_index = [...]uint8{0, 4, 15, 37, 43, 46}
+
+func (i Note) String() string {
+	if i < 0 || i >= Note(len(_Note_index)-1) {
+		return "Note(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Note_name[_Note_index[i]:_Note_index[i+1]]
+}
+`
+
+func TestGoldenComments(t *testing.T) {
+	for _, test := range []struct {
+		docComment bool
+		output     string
+	}{
+		{false, note_out},
+		{true, note_out_doc},
+	} {
+		opts := Options{
+			LineComment: true,
+			DocComment:  test.docComment,
+		}
+		got := goldenGenerate(t, opts, "note", note_in)
+		if got != test.output {
+			t.Errorf("doccomment=%v: got\n====\n%s====\nexpected\n====%s", test.docComment, got, test.output)
+		}
+	}
+}
+
+// TestGoldenSplitFiles checks the files the code of two types is written to
+// with SplitFiles, and the types each holds: one for each, unless Output
+// names the one file.
+func TestGoldenSplitFiles(t *testing.T) {
+	info, fset := loadPackage(t, "split", map[string]string{
+		"day.go": "package test\n" + day_in,
+		"gap.go": "package test\n" + gap_in,
+	})
+	for _, test := range []struct {
+		output string
+		files  map[string][]string // The types of each file.
+	}{
+		{"", map[string][]string{"day_string.go": {"Day"}, "gap_string.go": {"Gap"}}},
+		{"combined.go", map[string][]string{"combined.go": {"Day", "Gap"}}},
+	} {
+		files, err := Generate(fset, info, []string{"Day", "Gap"}, Options{SplitFiles: true, Output: test.output})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != len(test.files) {
+			t.Errorf("-output=%q: got %d files, want %d", test.output, len(files), len(test.files))
+		}
+		for filename, src := range files {
+			types, ok := test.files[filepath.Base(filename)]
+			if !ok {
+				t.Errorf("-output=%q: unexpected file %s", test.output, filename)
+				continue
+			}
+			for _, typeName := range types {
+				if !strings.Contains(string(src), "func (i "+typeName+") String() string {") {
+					t.Errorf("-output=%q: no String method of %s in %s", test.output, typeName, filename)
+				}
+			}
+			if n := strings.Count(string(src), ") String() string {"); n != len(types) {
+				t.Errorf("-output=%q: %s has %d String methods, want %d", test.output, filename, n, len(types))
+			}
+		}
+	}
+}
+
+// goldenGenerate runs Generate on input, a type declaration and its
+// constants, for the type declared on its first line, and returns the code
+// generated after the package clause and imports.
+func goldenGenerate(t *testing.T, opts Options, name, input string) string {
+	// Extract the name and type of the constant from the first line.
+	tokens := strings.SplitN(input, " ", 3)
+	if len(tokens) != 3 {
+		t.Fatalf("%s: need type declaration on first line", name)
+	}
+	return goldenGenerateTypes(t, opts, name, input, tokens[1])
+}
+
+// goldenGenerateTypes runs Generate on input for the types, as for a -type
+// list, and returns the code generated after the package clause and imports.
+func goldenGenerateTypes(t *testing.T, opts Options, name, input string, typeNames ...string) string {
+	src := goldenFile(t, opts, name, input, typeNames...)
+	return goldenPreamble.ReplaceAllString(src, "")
+}
+
+// The code generated up to the code of the types.
+var goldenPreamble = regexp.MustCompile(`(?s)\A.*?\npackage test\n(\n(import [^\n]*\n)+)?`)
+
+// goldenFile runs Generate on input, declaring the types, and returns the
+// contents of the file generated for them.
+func goldenFile(t *testing.T, opts Options, name, input string, typeNames ...string) string {
+	t.Helper()
+	info, fset := loadPackage(t, name, map[string]string{name + ".go": "package test\n" + input})
+	if opts.Output == "" {
+		opts.Output = name + "_string.go"
+	}
+	files, err := Generate(fset, info, typeNames, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(files[opts.Output])
+}
+
+// loadPackage loads the package of the files, given by name, as the loader
+// does for the files named on the command line.
+func loadPackage(t *testing.T, path string, files map[string]string) (*loader.PackageInfo, *token.FileSet) {
+	t.Helper()
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	conf.TypeChecker.FakeImportC = true
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parsed []*ast.File
+	for _, name := range names {
+		f, err := conf.ParseFile(name, files[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+	conf.CreateFromFiles(path, parsed...)
+	prog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prog.InitialPackages()[0], prog.Fset
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the GoString method of -gostring, which prints
+// a value as Go syntax naming the constants of its package.
+
+package stringer
+
+// buildGoString generates the GoString method from the String method, whose
+// names are those of the constants, qualified by the package name.
+func (g *Generator) buildGoString(typeName, pkgName string) {
+	if g.bitflag {
+		f := g.nameFormat
+		g.Printf(goStringBitflagMethod, typeName, pkgName, f.goStringUnbracketCode(), stringLiteral(f.sep))
+		return
+	}
+	g.Printf(goStringMethod, typeName, pkgName)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: package name
+const goStringMethod = `
+// GoString returns the name of the constant qualified by the package, or
+// the conversion of the value to %[1]s if it has no name.
+func (i %[1]s) GoString() string {
+	return "%[2]s." + i.String()
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: package name
+//	[3]: statements removing the brackets around several names, if any
+//	[4]: separator, as a string literal
+const goStringBitflagMethod = `
+// GoString returns the names of the constants whose bits are set, each
+// qualified by the package and separated by |, followed by the conversion of
+// the bits with no name to %[1]s.
+func (i %[1]s) GoString() string {
+	s := i.String()%[3]s
+	return "%[2]s." + strings.Replace(s, %[4]s, "|%[2]s.", -1)
+}
+`
@@ -0,0 +1,36 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines write the comment starting the generated files, which
+// records the command generating them.
+
+package stringer
+
+import "strings"
+
+// fileHeader returns the comment starting a generated file: the lines of
+// header, if any, then the line marking the file as generated by command,
+// such as
+//
+//	// Code generated by "stringer -type=Pill"; DO NOT EDIT.
+func fileHeader(header, command string) string {
+	return commentLines(header) + "// Code generated by \"" + command + "\"; DO NOT EDIT.\n"
+}
+
+// commentLines returns the lines of text as a comment followed by a blank
+// line, or "" if text is empty. A line already starting with // is kept.
+func commentLines(text string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if !strings.HasPrefix(line, "//") {
+			line = "// " + line
+		}
+		b.WriteString(strings.TrimRight(line, " \t") + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
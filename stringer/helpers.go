@@ -0,0 +1,98 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the Has, Set, Clear and Toggle methods of
+// -bitflaghelpers.
+
+package stringer
+
+import (
+	"go/token"
+	"go/types"
+	"log"
+	"path/filepath"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// The bitflag helper methods, in the order they are generated, and their
+// templates. The argument to each format is the type name.
+var bitflagHelpers = []struct {
+	name, code string
+}{
+	{"Has", `
+// Has reports whether all the %[1]s flags set in f are also set in m.
+func (m %[1]s) Has(f %[1]s) bool {
+	return m&f == f
+}
+`},
+	{"Set", `
+// Set returns m with the %[1]s flags of f set.
+func (m %[1]s) Set(f %[1]s) %[1]s {
+	return m | f
+}
+`},
+	{"Clear", `
+// Clear returns m with the %[1]s flags of f cleared.
+func (m %[1]s) Clear(f %[1]s) %[1]s {
+	return m &^ f
+}
+`},
+	{"Toggle", `
+// Toggle returns m with the %[1]s flags of f toggled.
+func (m %[1]s) Toggle(f %[1]s) %[1]s {
+	return m ^ f
+}
+`},
+}
+
+// buildBitflagHelpers generates the helper methods, skipping with a warning
+// those the type already has.
+func (g *Generator) buildBitflagHelpers(info *loader.PackageInfo, typeName string) {
+	declared := g.declaredMethods(info, typeName)
+	for _, h := range bitflagHelpers {
+		if declared[h.name] {
+			log.Printf("warning: not generating %s.%s: the method is already declared", typeName, h.name)
+			continue
+		}
+		g.Printf(h.code, typeName)
+	}
+}
+
+// declaredMethods returns the names of the methods declared on the named
+// type, leaving out those of the file being generated, which is replaced.
+func (g *Generator) declaredMethods(info *loader.PackageInfo, typeName string) map[string]bool {
+	declared := make(map[string]bool)
+	obj, ok := info.Pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return declared
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return declared
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		if g.replaced(m.Pos()) {
+			continue
+		}
+		declared[m.Name()] = true
+	}
+	return declared
+}
+
+// replaced reports whether pos is in the file being generated, or with
+// inline, in one of its regions.
+func (g *Generator) replaced(pos token.Pos) bool {
+	if g.output == "" || g.fset == nil {
+		return false
+	}
+	output, err := filepath.Abs(g.output)
+	if err != nil {
+		return false
+	}
+	p := g.fset.Position(pos)
+	file, err := filepath.Abs(p.Filename)
+	return err == nil && file == output && (!g.inline || inRegion(g.regions, p.Line))
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle -indexencoding=string, which declares the offsets of
+// the names of a run as a string constant rather than an array variable, so
+// that they are held in read-only data with no relocation. Each offset is of
+// the width of the unsigned integer the array would hold, most significant
+// byte first. An offset of one byte is read as the array element would be, so
+// the code generated is unchanged; wider ones are read byte by byte.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The encodings of -indexencoding.
+const (
+	indexSlice  = "slice"
+	indexString = "string"
+)
+
+// indexWidth returns the number of bytes of each offset of the index of a
+// run of names of nameLen bytes, or 1 for an array, read as one byte is.
+func (g *Generator) indexWidth(nameLen int) int {
+	if !g.indexString {
+		return 1
+	}
+	return usize(nameLen) / 8
+}
+
+// indexLiteral returns the string literal of the offsets, of width bytes.
+func indexLiteral(offsets []int, width int) string {
+	b := new(bytes.Buffer)
+	b.WriteByte('"')
+	for _, offset := range offsets {
+		for shift := 8 * (width - 1); shift >= 0; shift -= 8 {
+			fmt.Fprintf(b, `\x%02x`, byte(offset>>uint(shift)))
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// offsetAt returns the expression of the offset of width bytes starting at
+// byte j+k of index.
+func offsetAt(index string, k, width int) string {
+	var reads []string
+	for b := 0; b < width; b++ {
+		at := "j"
+		if k+b > 0 {
+			at = fmt.Sprintf("j+%d", k+b)
+		}
+		read := fmt.Sprintf("int(%s[%s])", index, at)
+		if shift := 8 * (width - 1 - b); shift > 0 {
+			read += fmt.Sprintf("<<%d", shift)
+		}
+		reads = append(reads, read)
+	}
+	return strings.Join(reads, "|")
+}
+
+// returnName returns the statements, indented by indent, returning the name
+// i of a run from the names name and their offsets index, of width bytes.
+func returnName(indent, name, index string, width int) string {
+	if width == 1 {
+		return fmt.Sprintf("%sreturn %s[%s[i]:%s[i+1]]\n", indent, name, index, index)
+	}
+	return fmt.Sprintf("%sj := %d * int(i)\n%sreturn %s[%s : %s]\n",
+		indent, width, indent, name, offsetAt(index, 0, width), offsetAt(index, width, width))
+}
+
+// indexCode returns the expression of the number of names of a type of one
+// run, from its index of offsets of width bytes, and the statements of the
+// String method returning the name i.
+func indexCode(typeName string, width int) (count, ret string) {
+	name, index := "_"+typeName+"_name", "_"+typeName+"_index"
+	count = fmt.Sprintf("len(%s)-1", index)
+	if width != 1 {
+		count = fmt.Sprintf("len(%s)/%d-1", index, width)
+	}
+	return count, strings.TrimPrefix(strings.TrimSuffix(returnName("\t", name, index, width), "\n"), "\t")
+}
@@ -0,0 +1,362 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// The names of a run of more than 255 bytes, whose offsets are of two bytes.
+const long_in = `type Long int
+const (
+	LongValueA Long = iota + 1
+	LongValueB
+	LongValueC
+	LongValueD
+	LongValueE
+	LongValueF
+	LongValueG
+	LongValueH
+	LongValueI
+	LongValueJ
+	LongValueK
+	LongValueL
+	LongValueM
+	LongValueN
+	LongValueO
+	LongValueP
+	LongValueQ
+	LongValueR
+	LongValueS
+	LongValueT
+	LongValueU
+	LongValueV
+	LongValueW
+	LongValueX
+	LongValueY
+	LongValueZ
+)
+`
+
+// The same names from 0, whose String method has no offset.
+const longzero_in = `type Longzero int
+const (
+	LongValueA Longzero = iota
+	LongValueB
+	LongValueC
+	LongValueD
+	LongValueE
+	LongValueF
+	LongValueG
+	LongValueH
+	LongValueI
+	LongValueJ
+	LongValueK
+	LongValueL
+	LongValueM
+	LongValueN
+	LongValueO
+	LongValueP
+	LongValueQ
+	LongValueR
+	LongValueS
+	LongValueT
+	LongValueU
+	LongValueV
+	LongValueW
+	LongValueX
+	LongValueY
+	LongValueZ
+)
+`
+
+// The same names from 1 of an unsigned type, checked before the subtraction.
+const ulong_in = `type Ulong uint
+const (
+	LongValueA Ulong = iota + 1
+	LongValueB
+	LongValueC
+	LongValueD
+	LongValueE
+	LongValueF
+	LongValueG
+	LongValueH
+	LongValueI
+	LongValueJ
+	LongValueK
+	LongValueL
+	LongValueM
+	LongValueN
+	LongValueO
+	LongValueP
+	LongValueQ
+	LongValueR
+	LongValueS
+	LongValueT
+	LongValueU
+	LongValueV
+	LongValueW
+	LongValueX
+	LongValueY
+	LongValueZ
+)
+`
+
+// The same names, in two runs.
+const longgap_in = `type Longgap int
+const (
+	LongValueA Longgap = iota + 1
+	LongValueB
+	LongValueC
+	LongValueD
+	LongValueE
+	LongValueF
+	LongValueG
+	LongValueH
+	LongValueI
+	LongValueJ
+	LongValueK
+	LongValueL
+	LongValueM
+	LongValueN
+	LongValueO
+	LongValueP
+	LongValueQ
+	LongValueR
+	LongValueS
+	LongValueT
+	LongValueU
+	LongValueV
+	LongValueW
+	LongValueX
+	LongValueY
+	LongValueZ
+	LongValueEnd Longgap = 100
+)
+`
+
+const (
+	day_out_indexstring = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+const _Day_index = "\x00\x06\x0d\x16\x1e\x24\x2c\x32"
+
+func (i Day) String() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return "Day(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+`
+	gap_out_indexstring = `
+const (
+	_Gap_name_0  = "TwoThree"
+	_Gap_name_1  = "FiveSixSevenEightNine"
+	_Gap_name_2  = "Eleven"
+	_Gap_index_0 = "\x00\x03\x08"
+	_Gap_index_1 = "\x00\x04\x07\x0c\x11\x15"
+)
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_name_0[_Gap_index_0[i]:_Gap_index_0[i+1]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Gap_name_1[_Gap_index_1[i]:_Gap_index_1[i+1]]
+	case i == 11:
+		return _Gap_name_2
+	default:
+		return "Gap(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+	num_out_indexstring = `
+const _Num_name = "m_2m_1m0m1m2"
+const _Num_index = "\x00\x03\x06\x08\x0a\x0c"
+
+func (i Num) String() string {
+	i += 2
+	if i < 0 || i >= Num(len(_Num_index)-1) {
+		return "Num(" + strconv.FormatInt(int64(i-2), 10) + ")"
+	}
+	return _Num_name[_Num_index[i]:_Num_index[i+1]]
+}
+`
+	unum_out_indexstring = `
+const (
+	_Unum_name_0  = "m0m1m2"
+	_Unum_name_1  = "m_2m_1"
+	_Unum_index_0 = "\x00\x02\x04\x06"
+	_Unum_index_1 = "\x00\x03\x06"
+)
+
+func (i Unum) String() string {
+	switch {
+	case 0 <= i && i <= 2:
+		return _Unum_name_0[_Unum_index_0[i]:_Unum_index_0[i+1]]
+	case 253 <= i && i <= 254:
+		i -= 253
+		return _Unum_name_1[_Unum_index_1[i]:_Unum_index_1[i+1]]
+	default:
+		return "Unum(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+}
+`
+	long_out_indexslice = `
+const _Long_name = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+
+var _Long_index = [...]uint16{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 200, 210, 220, 230, 240, 250, 260}
+
+func (i Long) String() string {
+	i -= 1
+	if i < 0 || i >= Long(len(_Long_index)-1) {
+		return "Long(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	}
+	return _Long_name[_Long_index[i]:_Long_index[i+1]]
+}
+`
+	long_out_indexstring = `
+const _Long_name = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+const _Long_index = "\x00\x00\x00\x0a\x00\x14\x00\x1e\x00\x28\x00\x32\x00\x3c\x00\x46\x00\x50\x00\x5a\x00\x64\x00\x6e\x00\x78\x00\x82\x00\x8c\x00\x96\x00\xa0\x00\xaa\x00\xb4\x00\xbe\x00\xc8\x00\xd2\x00\xdc\x00\xe6\x00\xf0\x00\xfa\x01\x04"
+
+func (i Long) String() string {
+	i -= 1
+	if i < 0 || i >= Long(len(_Long_index)/2-1) {
+		return "Long(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	}
+	j := 2 * int(i)
+	return _Long_name[int(_Long_index[j])<<8|int(_Long_index[j+1]) : int(_Long_index[j+2])<<8|int(_Long_index[j+3])]
+}
+`
+	longzero_out_indexstring = `
+const _Longzero_name = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+const _Longzero_index = "\x00\x00\x00\x0a\x00\x14\x00\x1e\x00\x28\x00\x32\x00\x3c\x00\x46\x00\x50\x00\x5a\x00\x64\x00\x6e\x00\x78\x00\x82\x00\x8c\x00\x96\x00\xa0\x00\xaa\x00\xb4\x00\xbe\x00\xc8\x00\xd2\x00\xdc\x00\xe6\x00\xf0\x00\xfa\x01\x04"
+
+func (i Longzero) String() string {
+	if i < 0 || i >= Longzero(len(_Longzero_index)/2-1) {
+		return "Longzero(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	j := 2 * int(i)
+	return _Longzero_name[int(_Longzero_index[j])<<8|int(_Longzero_index[j+1]) : int(_Longzero_index[j+2])<<8|int(_Longzero_index[j+3])]
+}
+`
+	ulong_out_indexstring = `
+const _Ulong_name = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+const _Ulong_index = "\x00\x00\x00\x0a\x00\x14\x00\x1e\x00\x28\x00\x32\x00\x3c\x00\x46\x00\x50\x00\x5a\x00\x64\x00\x6e\x00\x78\x00\x82\x00\x8c\x00\x96\x00\xa0\x00\xaa\x00\xb4\x00\xbe\x00\xc8\x00\xd2\x00\xdc\x00\xe6\x00\xf0\x00\xfa\x01\x04"
+
+func (i Ulong) String() string {
+	if i < 1 || i-1 >= Ulong(len(_Ulong_index)/2-1) {
+		return "Ulong(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+	i -= 1
+	j := 2 * int(i)
+	return _Ulong_name[int(_Ulong_index[j])<<8|int(_Ulong_index[j+1]) : int(_Ulong_index[j+2])<<8|int(_Ulong_index[j+3])]
+}
+`
+	longgap_out_indexstring = `
+const (
+	_Longgap_name_0  = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+	_Longgap_name_1  = "LongValueEnd"
+	_Longgap_index_0 = "\x00\x00\x00\x0a\x00\x14\x00\x1e\x00\x28\x00\x32\x00\x3c\x00\x46\x00\x50\x00\x5a\x00\x64\x00\x6e\x00\x78\x00\x82\x00\x8c\x00\x96\x00\xa0\x00\xaa\x00\xb4\x00\xbe\x00\xc8\x00\xd2\x00\xdc\x00\xe6\x00\xf0\x00\xfa\x01\x04"
+)
+
+func (i Longgap) String() string {
+	switch {
+	case 1 <= i && i <= 26:
+		i -= 1
+		j := 2 * int(i)
+		return _Longgap_name_0[int(_Longgap_index_0[j])<<8|int(_Longgap_index_0[j+1]) : int(_Longgap_index_0[j+2])<<8|int(_Longgap_index_0[j+3])]
+	case i == 100:
+		return _Longgap_name_1
+	default:
+		return "Longgap(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+)
+
+func TestGoldenIndexEncoding(t *testing.T) {
+	for _, test := range []struct {
+		encoding, name, input, output string
+	}{
+		{indexString, "day", day_in, day_out_indexstring},
+		{indexString, "gap", gap_in, gap_out_indexstring},
+		{indexString, "num", num_in, num_out_indexstring},
+		{indexString, "unum", unum_in, unum_out_indexstring},
+		{indexSlice, "long", long_in, long_out_indexslice},
+		{indexString, "long", long_in, long_out_indexstring},
+		{indexString, "longzero", longzero_in, longzero_out_indexstring},
+		{indexString, "ulong", ulong_in, ulong_out_indexstring},
+		{indexString, "longgap", longgap_in, longgap_out_indexstring},
+	} {
+		got := goldenGenerate(t, Options{IndexEncoding: test.encoding}, test.name, test.input)
+		if got != test.output {
+			t.Errorf("%s -indexencoding=%s: got\n====\n%s====\nexpected\n====\n%s", test.name, test.encoding, got, test.output)
+		}
+	}
+}
+
+// TestIndexStringOneByte checks that offsets of one byte are read from the
+// string as from the array, so that only the declaration differs.
+func TestIndexStringOneByte(t *testing.T) {
+	slice := goldenGenerate(t, Options{}, "gap", gap_in)
+	str := goldenGenerate(t, Options{IndexEncoding: indexString}, "gap", gap_in)
+	method := func(src string) string { return src[strings.Index(src, "func "):] }
+	if method(slice) != method(str) {
+		t.Errorf("the String methods differ:\n%s\n%s", slice, str)
+	}
+}
+
+// TestIndexWidthPerRun checks that the offsets of each run are of the
+// smallest width holding them, whatever that of the other runs: those of a
+// run of more than 255 bytes are of uint16, those of a short one of uint8.
+func TestIndexWidthPerRun(t *testing.T) {
+	var input bytes.Buffer
+	input.WriteString("type Widths int\nconst (\n")
+	for i := 0; i < 18; i++ {
+		fmt.Fprintf(&input, "\tWidthsLongValue%02d Widths = %d\n", i, i)
+	}
+	input.WriteString("\tWidthsShortA Widths = 100\n\tWidthsShortB Widths = 101\n)\n")
+	got := goldenGenerate(t, Options{}, "widths", input.String())
+	for _, want := range []string{
+		`_Widths_name_0 = "WidthsLongValue00`, // 18 names of 17 bytes, 306 bytes.
+		`_Widths_name_1 = "WidthsShortAWidthsShortB"`,
+		"_Widths_index_0 = [...]uint16{0, 17, ",
+		"_Widths_index_1 = [...]uint8{0, 12, 24}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("no %s in\n%s", want, got)
+		}
+	}
+}
+
+// Models of the String method of Long, with the offsets in an array and in
+// a string.
+const benchLongName = "LongValueALongValueBLongValueCLongValueDLongValueELongValueFLongValueGLongValueHLongValueILongValueJLongValueKLongValueLLongValueMLongValueNLongValueOLongValuePLongValueQLongValueRLongValueSLongValueTLongValueULongValueVLongValueWLongValueXLongValueYLongValueZ"
+
+var benchLongIndex = [...]uint16{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 200, 210, 220, 230, 240, 250, 260}
+
+const benchLongIndexString = "\x00\x00\x00\x0a\x00\x14\x00\x1e\x00\x28\x00\x32\x00\x3c\x00\x46\x00\x50\x00\x5a\x00\x64\x00\x6e\x00\x78\x00\x82\x00\x8c\x00\x96\x00\xa0\x00\xaa\x00\xb4\x00\xbe\x00\xc8\x00\xd2\x00\xdc\x00\xe6\x00\xf0\x00\xfa\x01\x04"
+
+var benchName string
+
+func BenchmarkIndexSlice(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		i := n % (len(benchLongIndex) - 1)
+		benchName = benchLongName[benchLongIndex[i]:benchLongIndex[i+1]]
+	}
+}
+
+func BenchmarkIndexString(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		i := n % (len(benchLongIndexString)/2 - 1)
+		j := 2 * i
+		benchName = benchLongName[int(benchLongIndexString[j])<<8|int(benchLongIndexString[j+1]) : int(benchLongIndexString[j+2])<<8|int(benchLongIndexString[j+3])]
+	}
+}
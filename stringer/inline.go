@@ -0,0 +1,303 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines write, with -inline, the code of each type to a region of
+// one file, between marker comments, replacing the region of a type written
+// before and leaving the rest of the file as it is:
+//
+//	// stringer:begin Pill 5d41402abc4b2a76
+//
+//	func (i Pill) String() string { ... }
+//
+//	// stringer:end Pill
+//
+// The begin marker records a checksum of the code of the region, so that a
+// region edited by hand is not overwritten.
+
+package stringer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// The file of the regions with -inline, in the package directory, unless
+// Options.Output is set.
+const defaultInlineFile = "zz_generated_stringer.go"
+
+// The markers of the region of a type, followed by its name.
+const (
+	inlineBegin = "// stringer:begin "
+	inlineEnd   = "// stringer:end "
+)
+
+// The begin and end markers of a region, with the name of the type and, for
+// begin, the checksum of the code.
+var (
+	beginMarker = regexp.MustCompile(`^// stringer:begin (\S+) ([0-9a-f]{16})$`)
+	endMarker   = regexp.MustCompile(`^// stringer:end (\S+)$`)
+)
+
+// An inlineRegion is the region of a type in the file of -inline.
+type inlineRegion struct {
+	typeName           string
+	start, end         int // The offsets of the begin marker and past the end marker's line.
+	startLine, endLine int // The lines of the markers.
+}
+
+// inlineSum returns the checksum recorded in the begin marker of the code of
+// a region, its text with the blank lines around it left out.
+func inlineSum(code []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(code))
+	return hex.EncodeToString(sum[:8])
+}
+
+// inlineRegions returns the regions of the types in src, the contents of
+// filename. It is an error for the markers not to pair up, for a type to have
+// two regions, or for the code of a region not to match its checksum, as
+// when it was edited by hand.
+func inlineRegions(filename string, src []byte) ([]inlineRegion, error) {
+	var regions []inlineRegion
+	seen := make(map[string]bool)
+	var open *inlineRegion // The region whose end is not yet found.
+	var sum string         // The checksum recorded for open.
+	offset := 0
+	for n, line := range strings.SplitAfter(string(src), "\n") {
+		lineNum := n + 1
+		text := strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(text, inlineBegin):
+			m := beginMarker.FindStringSubmatch(text)
+			switch {
+			case m == nil:
+				return nil, fmt.Errorf("%s:%d: malformed begin marker %q", filename, lineNum, text)
+			case open != nil:
+				return nil, fmt.Errorf("%s:%d: the region of %s begins inside that of %s", filename, lineNum, m[1], open.typeName)
+			case seen[m[1]]:
+				return nil, fmt.Errorf("%s:%d: %s has a second region", filename, lineNum, m[1])
+			}
+			seen[m[1]] = true
+			open = &inlineRegion{typeName: m[1], start: offset, startLine: lineNum}
+			sum = m[2]
+		case strings.HasPrefix(text, inlineEnd):
+			m := endMarker.FindStringSubmatch(text)
+			switch {
+			case m == nil:
+				return nil, fmt.Errorf("%s:%d: malformed end marker %q", filename, lineNum, text)
+			case open == nil || open.typeName != m[1]:
+				return nil, fmt.Errorf("%s:%d: the region of %s ends where it did not begin", filename, lineNum, m[1])
+			}
+			code := src[open.start:offset]
+			code = code[bytes.IndexByte(code, '\n')+1:] // After the begin marker.
+			if inlineSum(code) != sum {
+				return nil, fmt.Errorf("%s:%d: the region of %s was edited since stringer wrote it; move the edits out of it, or delete it, to generate it again", filename, open.startLine, m[1])
+			}
+			open.end = offset + len(line)
+			open.endLine = lineNum
+			regions = append(regions, *open)
+			open = nil
+		}
+		offset += len(line)
+	}
+	if open != nil {
+		return nil, fmt.Errorf("%s:%d: the region of %s has no end marker", filename, open.startLine, open.typeName)
+	}
+	return regions, nil
+}
+
+// inRegion reports whether line is in one of the regions.
+func inRegion(regions []inlineRegion, line int) bool {
+	for _, r := range regions {
+		if r.startLine <= line && line <= r.endLine {
+			return true
+		}
+	}
+	return false
+}
+
+// splitGenerated returns the parts of src, a file generated for a type: up
+// to the package clause, its imports and the code following them.
+func splitGenerated(src []byte) (preamble []byte, imports []*ast.ImportSpec, code []byte, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	end := f.Name.End()
+	for _, decl := range f.Decls {
+		end = decl.End()
+	}
+	return src[:fset.Position(f.Name.End()).Offset], f.Imports, src[fset.Position(end).Offset:], nil
+}
+
+// goBuildLine returns the //go:build line of the preamble of a file, or "".
+func goBuildLine(preamble []byte) string {
+	for _, line := range strings.Split(string(preamble), "\n") {
+		if strings.HasPrefix(line, "//go:build ") {
+			return line
+		}
+	}
+	return ""
+}
+
+// genInline generates the code of each of the types, alone, and splices it
+// into its region of the file of Inline, returning the name and contents of
+// the file, nil if it is not written, and the errors of the types whose code
+// cannot be generated.
+func genInline(fset *token.FileSet, dir string, xtest bool, info *loader.PackageInfo, typeNames []*types.TypeName, opts Options) (filename string, src []byte, errs TypeErrors, err error) {
+	filename = opts.Output
+	if filename == "" {
+		filename = filepath.Join(dir, defaultInlineFile)
+	}
+	if xtest && !strings.HasSuffix(filename, "_test.go") {
+		// An external test package builds only from test files.
+		filename = strings.TrimSuffix(filename, ".go") + "_test.go"
+	}
+	src, err = ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, nil, err
+	}
+	regions, err := inlineRegions(filename, src)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	for _, typeName := range typeNames {
+		code, _, typeErrs, err := genFile(fset, filename, info, []*types.TypeName{typeName}, opts, regions)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		errs = append(errs, typeErrs...)
+		if code == nil {
+			continue
+		}
+		src, err = spliceInline(filename, src, typeName.Name(), code, info)
+		if err != nil {
+			return "", nil, nil, err
+		}
+	}
+	return filename, src, errs, nil
+}
+
+// spliceInline returns old, the contents of filename, with the region of the
+// type replaced by the code of src, generated for it alone, or added at the
+// end, and the imports the code needs added. The imports no code of the file
+// refers to any longer are removed. If old is empty, the file is created from
+// src.
+func spliceInline(filename string, old []byte, typeName string, src []byte, info *loader.PackageInfo) ([]byte, error) {
+	preamble, imports, code, err := splitGenerated(src)
+	if err != nil {
+		return nil, err
+	}
+	code = bytes.TrimSpace(code)
+	region := inlineBegin + typeName + " " + inlineSum(code) + "\n\n" + string(code) + "\n\n" + inlineEnd + typeName + "\n"
+	if len(old) == 0 {
+		old = preamble
+	} else if goBuildLine(preamble) != goBuildLine(old[:bytes.Index(old, []byte("\npackage "))+1]) {
+		return nil, fmt.Errorf("not writing %s: the files declaring the constants of %s build with other constraints than those of the file", filename, typeName)
+	}
+	regions, err := inlineRegions(filename, old)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	found := false
+	for _, r := range regions {
+		if r.typeName == typeName {
+			buf.Write(old[:r.start])
+			buf.WriteString(region)
+			buf.Write(old[r.end:])
+			found = true
+		}
+	}
+	if !found {
+		buf.Write(bytes.TrimRight(old, "\n"))
+		buf.WriteString("\n\n")
+		buf.WriteString(region)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("not writing %s: %s", filename, err)
+	}
+	for _, imp := range imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		astutil.AddNamedImport(fset, f, importSpecName(imp), p)
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			// A package name is left unresolved by the parser.
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	var unused []*ast.ImportSpec
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := importName(info, p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name != "" && name != "_" && name != "." && !used[name] {
+			unused = append(unused, imp)
+		}
+	}
+	for _, imp := range unused {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		astutil.DeleteNamedImport(fset, f, importSpecName(imp), p)
+	}
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, f); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// importName returns the name of the package of path, imported by the file
+// of -inline, or "" if it is not known.
+func importName(info *loader.PackageInfo, p string) string {
+	for name, gp := range generatedImports {
+		if gp == p {
+			return name
+		}
+	}
+	for _, pkg := range info.Pkg.Imports() {
+		if pkg.Path() == p {
+			return pkg.Name()
+		}
+	}
+	if !strings.Contains(p, ".") {
+		// A package of the standard library is named for its path.
+		return path.Base(p)
+	}
+	return ""
+}
+
+// importSpecName returns the name an import is given, or "".
+func importSpecName(imp *ast.ImportSpec) string {
+	if imp.Name == nil {
+		return ""
+	}
+	return imp.Name.Name
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// inlineFile runs Generate with Inline for the type, writing the file of the
+// regions, and returns its contents, or the error.
+func inlineFile(t *testing.T, filename, typeName string, opts Options) (string, error) {
+	t.Helper()
+	info, fset := loadPackage(t, "inline", map[string]string{
+		"day.go": "package test\n" + day_in,
+		"gap.go": "package test\n" + gap_in,
+	})
+	opts.Output = filename
+	opts.Inline = true
+	files, err := Generate(fset, info, []string{typeName}, opts)
+	if err != nil {
+		return "", err
+	}
+	src, ok := files[filename]
+	if !ok {
+		t.Fatalf("%s not generated", filename)
+	}
+	if err := ioutil.WriteFile(filename, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return string(src), nil
+}
+
+// region returns the region of the type in src, markers included.
+func region(t *testing.T, src, typeName string) string {
+	t.Helper()
+	start := strings.Index(src, inlineBegin+typeName+" ")
+	end := strings.Index(src, inlineEnd+typeName+"\n")
+	if start < 0 || end < start {
+		t.Fatalf("no region of %s in\n%s", typeName, src)
+	}
+	return src[start : end+len(inlineEnd+typeName+"\n")]
+}
+
+func TestInline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, defaultInlineFile)
+
+	// The file is created with the region of Day.
+	src, err := inlineFile(t, filename, "Day", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(src, "// Code generated by \"stringer -type=Day\"; DO NOT EDIT.\n\npackage test\n\nimport \"strconv\"\n") {
+		t.Errorf("unexpected start of the file:\n%s", src)
+	}
+	day := region(t, src, "Day")
+	if !strings.Contains(day, "func (i Day) String() string") {
+		t.Errorf("no String method in the region of Day:\n%s", day)
+	}
+
+	// The region of Gap is added, leaving the code out of the regions as it is.
+	hand := "\n// keep is written by hand.\nfunc keep() string { return strings.ToLower(\"KEEP\") }\n"
+	src = strings.Replace(src, "import \"strconv\"\n", "import (\n\t\"strconv\"\n\t\"strings\"\n)\n", 1) + hand
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err = inlineFile(t, filename, "Gap", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := region(t, src, "Day"); got != day {
+		t.Errorf("the region of Day changed to\n%s", got)
+	}
+	if !strings.Contains(src, hand) {
+		t.Errorf("the code written by hand is lost:\n%s", src)
+	}
+	gap := region(t, src, "Gap")
+	if strings.Index(src, hand) > strings.Index(src, gap) {
+		t.Errorf("the region of Gap is not added at the end:\n%s", src)
+	}
+
+	// The region of Day is replaced in place.
+	src, err = inlineFile(t, filename, "Day", Options{Transform: "lower"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := region(t, src, "Day"); got == day || !strings.Contains(got, "monday") {
+		t.Errorf("the region of Day is not replaced:\n%s", got)
+	}
+	if got := region(t, src, "Gap"); got != gap {
+		t.Errorf("the region of Gap changed to\n%s", got)
+	}
+	if !strings.Contains(src, "\t\"strings\"\n") || strings.Index(src, inlineBegin+"Day") > strings.Index(src, hand) {
+		t.Errorf("the region of Day is moved or the imports of the file changed:\n%s", src)
+	}
+
+	// The same code is written again.
+	again, err := inlineFile(t, filename, "Day", Options{Transform: "lower"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != src {
+		t.Errorf("generating again changed the file to\n%s", again)
+	}
+}
+
+func TestInlineCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, defaultInlineFile)
+	src, err := inlineFile(t, filename, "Day", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := region(t, src, "Day")
+	begin := day[:strings.Index(day, "\n")+1]
+	end := inlineEnd + "Day\n"
+	for _, test := range []struct {
+		name string
+		src  string
+		want string // In the error, after the name of the file.
+	}{
+		{"edited", strings.Replace(src, "Monday", "Lundi", 1), ":7: the region of Day was edited since stringer wrote it"},
+		{"no end", strings.Replace(src, end, "", 1), ":7: the region of Day has no end marker"},
+		{"no begin", strings.Replace(src, begin, "", 1), ": the region of Day ends where it did not begin"},
+		{"nested", strings.Replace(src, end, inlineBegin+"Gap 0123456789abcdef\n"+end, 1), ": the region of Gap begins inside that of Day"},
+		{"twice", src + "\n" + day, ": Day has a second region"},
+		{"malformed", strings.Replace(src, begin, inlineBegin+"Day\n", 1), ":7: malformed begin marker"},
+	} {
+		if err := ioutil.WriteFile(filename, []byte(test.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := inlineFile(t, filename, "Day", Options{})
+		if err == nil || !strings.HasPrefix(err.Error(), filename+":") || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %s%s", test.name, err, filename, test.want)
+		}
+	}
+}
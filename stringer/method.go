@@ -0,0 +1,88 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines rename the generated String method to that of the -method
+// flag, along with the identifiers declared for it, so that the code for
+// both can be in one package.
+
+package stringer
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The default of the -method flag.
+const defaultMethod = "String"
+
+// stringMethod returns the name of the String method.
+func (g *Generator) stringMethod() string {
+	if g.method == "" {
+		return defaultMethod
+	}
+	return g.method
+}
+
+// flagsMethod returns the name of the Flags method of a bitflag type,
+// prefixed by the name of the String method if it is not String.
+func (g *Generator) flagsMethod() string {
+	if g.method == "" || g.method == defaultMethod {
+		return "Flags"
+	}
+	return g.method + "Flags"
+}
+
+// renameMethod renames, in the code generated for the type from offset start
+// of the buffer, the String method and its calls to g.method, the Flags
+// method to g.flagsMethod(), the _string method of the cache to _ and the
+// method name with its first letter in lower case, and the identifiers _T_x
+// declared for the type to _T_M_x, for method M.
+func (g *Generator) renameMethod(start int, typeName string) {
+	if g.method == "" || g.method == defaultMethod {
+		return
+	}
+	src := append([]byte(nil), g.buf.Bytes()[start:]...)
+	r, size := utf8.DecodeRuneInString(g.method)
+	unexported := "_" + string(unicode.ToLower(r)) + g.method[size:]
+	prefix := "_" + typeName + "_"
+
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	s.Init(fset.AddFile("", -1, len(src)), src, nil, 0)
+	out := new(bytes.Buffer)
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.IDENT {
+			continue
+		}
+		name := lit
+		switch {
+		case lit == defaultMethod:
+			name = g.method
+		case lit == "Flags":
+			name = g.flagsMethod()
+		case lit == "_string":
+			name = unexported
+		case strings.HasPrefix(lit, prefix):
+			name = prefix + g.method + "_" + lit[len(prefix):]
+		default:
+			continue
+		}
+		offset := fset.Position(pos).Offset
+		out.Write(src[last:offset])
+		out.WriteString(name)
+		last = offset + len(lit)
+	}
+	out.Write(src[last:])
+	g.buf.Truncate(start)
+	g.buf.Write(out.Bytes())
+}
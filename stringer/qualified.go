@@ -0,0 +1,176 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines handle a type given with the package declaring it, as
+// log.Level, for constants declared in a package importing it. Methods
+// cannot be declared on the type there, so the String method is generated
+// as a function of the value instead, as LevelString(i log.Level).
+// So are the types of the package of the constants when the code is
+// generated in another package, with Options.Package and Options.Import.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// LookupType returns the type typeName declared in package pkg or, if it is
+// qualified by the name or path of a package, as log.Level, in that package,
+// which is pkg or one it imports.
+func LookupType(pkg *types.Package, typeName string) (*types.TypeName, error) {
+	decl := pkg
+	if i := strings.LastIndex(typeName, "."); i >= 0 {
+		qualifier := typeName[:i]
+		var found []*types.Package
+		if qualifier == pkg.Name() || qualifier == pkg.Path() {
+			found = append(found, pkg)
+		}
+		for _, imp := range pkg.Imports() {
+			if qualifier == imp.Name() || qualifier == imp.Path() {
+				found = append(found, imp)
+			}
+		}
+		switch len(found) {
+		case 0:
+			return nil, fmt.Errorf("package %s does not import a package %s", pkg.Path(), qualifier)
+		case 1:
+		default:
+			return nil, fmt.Errorf("package %s imports several packages %s; qualify %s by the path", pkg.Path(), qualifier, typeName[i+1:])
+		}
+		decl = found[0]
+		typeName = typeName[i+1:]
+	}
+	obj, ok := decl.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok || (decl != pkg && !obj.Exported()) {
+		return nil, fmt.Errorf("type %s is not declared in package %s", typeName, decl.Path())
+	}
+	return obj, nil
+}
+
+// baseTypeName returns typeName without the package qualifying it, if any.
+func baseTypeName(typeName string) string {
+	return typeName[strings.LastIndex(typeName, ".")+1:]
+}
+
+// qualifiedName returns the name of the type as written in package pkg.
+func qualifiedName(obj *types.TypeName, pkg *types.Package) string {
+	if obj.Pkg() == pkg {
+		return obj.Name()
+	}
+	return obj.Pkg().Name() + "." + obj.Name()
+}
+
+// qualifiedName returns the name of the type as written in the generated
+// code, for constants declared in package pkg: qualified by the name of its
+// package if that is not the package of the code, as with Options.Import.
+func (g *Generator) qualifiedName(obj *types.TypeName, pkg *types.Package) string {
+	if g.importPath != "" {
+		return obj.Pkg().Name() + "." + obj.Name()
+	}
+	return qualifiedName(obj, pkg)
+}
+
+// checkQualified fails if a flag generates code that needs methods on a type
+// of another package, or a function named as the one of its String method.
+func (g *Generator) checkQualified(typeName string) {
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{
+		{g.bitflag, "bitflag"},
+		{g.text, "text"},
+		{g.json, "json"},
+		{g.sql, "sql"},
+		{g.isValid, "isvalid"},
+		{g.goString, "gostring"},
+		{g.parse, "parse"},
+	} {
+		if f.set {
+			failf("-%s cannot be used for %s, whose methods cannot be declared outside of its package", f.name, typeName)
+		}
+	}
+}
+
+// qualifyType rewrites, in the code generated for the type of another package
+// from offset start of the buffer, the String method, or that of -method, as
+// a function of the value named after the type, as func LevelString(i
+// log.Level), its calls, as v.String(), as calls of the function, and the
+// type as qualified by the name of its package.
+func (g *Generator) qualifyType(start int, obj *types.TypeName) {
+	src := append([]byte(nil), g.buf.Bytes()[start:]...)
+	qualified := obj.Pkg().Name() + "." + obj.Name()
+
+	type item struct {
+		offset int
+		tok    token.Token
+		lit    string
+	}
+	var toks []item
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	s.Init(fset.AddFile("", -1, len(src)), src, nil, 0)
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		toks = append(toks, item{fset.Position(pos).Offset, t, lit})
+	}
+	// is reports whether the tokens from i are those given, a string
+	// standing for an identifier of that name.
+	is := func(i int, want ...interface{}) bool {
+		if i+len(want) > len(toks) {
+			return false
+		}
+		for j, w := range want {
+			switch w := w.(type) {
+			case token.Token:
+				if toks[i+j].tok != w {
+					return false
+				}
+			case string:
+				if toks[i+j].tok != token.IDENT || toks[i+j].lit != w {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	out := new(bytes.Buffer)
+	last := 0
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		// func ( i T ) String ( ) becomes func TString ( i pkg.T ).
+		if t.tok == token.FUNC && is(i+1, token.LPAREN, token.IDENT, obj.Name(), token.RPAREN, g.stringMethod(), token.LPAREN, token.RPAREN) {
+			out.Write(src[last:toks[i+1].offset])
+			fmt.Fprintf(out, "%s%s(%s %s)", obj.Name(), g.stringMethod(), toks[i+2].lit, qualified)
+			last = toks[i+7].offset + 1
+			i += 7
+			continue
+		}
+		// v . String ( ) becomes TString ( v ).
+		if t.tok == token.IDENT && is(i+1, token.PERIOD, g.stringMethod(), token.LPAREN, token.RPAREN) {
+			out.Write(src[last:t.offset])
+			fmt.Fprintf(out, "%s%s(%s)", obj.Name(), g.stringMethod(), t.lit)
+			last = toks[i+4].offset + 1
+			i += 4
+			continue
+		}
+		if t.tok != token.IDENT || t.lit != obj.Name() || (i > 0 && toks[i-1].tok == token.PERIOD) {
+			continue
+		}
+		out.Write(src[last:t.offset])
+		out.WriteString(qualified)
+		last = t.offset + len(t.lit)
+	}
+	out.Write(src[last:])
+	g.buf.Truncate(start)
+	g.buf.Write(out.Bytes())
+}
@@ -0,0 +1,191 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Constants of a type of another package, time.Weekday, in one run and
+// in several.
+const weekday_in = `import "time"
+
+const (
+	Sun time.Weekday = iota
+	Mon
+	Tue
+	Fri time.Weekday = 5
+)
+
+// Not of the type.
+const Weekend = 2
+`
+
+const weekday_out = `// Code generated by "stringer -type=time.Weekday"; DO NOT EDIT.
+
+package test
+
+import "strconv"
+import "time"
+
+const (
+	_Weekday_name_0 = "SunMonTue"
+	_Weekday_name_1 = "Fri"
+)
+
+var (
+	_Weekday_index_0 = [...]uint8{0, 3, 6, 9}
+)
+
+func WeekdayString(i time.Weekday) string {
+	switch {
+	case 0 <= i && i <= 2:
+		return _Weekday_name_0[_Weekday_index_0[i]:_Weekday_index_0[i+1]]
+	case i == 5:
+		return _Weekday_name_1
+	default:
+		return "Weekday(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+`
+
+func TestGoldenQualified(t *testing.T) {
+	got := goldenFile(t, Options{}, "weekday", weekday_in, "time.Weekday")
+	if got != weekday_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====\n%s", got, weekday_out)
+	}
+
+	// The names listed by -values are those of the function.
+	got = goldenFile(t, Options{Values: true}, "weekday", weekday_in, "time.Weekday")
+	for _, want := range []string{"func WeekdayValues() []time.Weekday {", "names[i] = WeekdayString(v)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("-values: no %q in\n%s", want, got)
+		}
+	}
+}
+
+// A type of another package cannot have the methods of other flags, and
+// must be of a package imported.
+func TestQualifiedErrors(t *testing.T) {
+	info, fset := loadPackage(t, "weekday", map[string]string{
+		"weekday.go": "package weekday\n" + weekday_in + "\ntype Weekday int\n\nconst Sat Weekday = 6\n",
+	})
+	for _, test := range []struct {
+		opts     Options
+		typeName string
+		want     string
+	}{
+		{Options{Text: true}, "time.Weekday", "-text cannot be used for time.Weekday"},
+		{Options{Parse: true}, "time.Weekday", "-parse cannot be used for time.Weekday"},
+		{Options{Bitflag: true}, "time.Weekday", "-bitflag cannot be used for time.Weekday"},
+		{Options{}, "time.Month", "no values defined for type Month"},
+		{Options{}, "time.month", "type month is not declared in package time"},
+		{Options{}, "time.Weekday,Weekday", "type Weekday has the name of time.Weekday"},
+	} {
+		_, err := Generate(fset, info, strings.Split(test.typeName, ","), test.opts)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %q", test.typeName, err, test.want)
+		}
+	}
+}
+
+func TestLookupType(t *testing.T) {
+	info, _ := loadPackage(t, "weekday", map[string]string{
+		"weekday.go": "package weekday\nimport \"time\"\ntype Weekday time.Weekday\nconst Sun time.Weekday = 0\n",
+	})
+	for _, test := range []struct {
+		typeName string
+		want     string // The package of the type, or the error.
+	}{
+		{"Weekday", "weekday"},
+		{"weekday.Weekday", "weekday"},
+		{"time.Weekday", "time"},
+		{"time.Duration", "time"},
+		{"Duration", "type Duration is not declared in package weekday"},
+		{"fmt.Stringer", "package weekday does not import a package fmt"},
+		{"time.Time.Weekday", "package weekday does not import a package time.Time"},
+	} {
+		obj, err := LookupType(info.Pkg, test.typeName)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		} else {
+			got = obj.Pkg().Path()
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.typeName, got, test.want)
+		}
+	}
+}
+
+// Constants of a type of the package, generated in another package, which
+// imports it by a path not ending in the name of the package, test.
+const enums_in = `type Pill int
+
+const (
+	Placebo Pill = iota
+	Aspirin
+	Ibuprofen
+)
+`
+
+const enums_out = `// Code generated by "stringer -type=Pill"; DO NOT EDIT.
+
+package enumsstr
+
+import test "example.com/enums"
+import "strconv"
+
+const _Pill_name = "PlaceboAspirinIbuprofen"
+
+var _Pill_index = [...]uint8{0, 7, 14, 23}
+
+func PillString(i test.Pill) string {
+	if i < 0 || i >= test.Pill(len(_Pill_index)-1) {
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Pill_name[_Pill_index[i]:_Pill_index[i+1]]
+}
+`
+
+func TestGoldenImport(t *testing.T) {
+	got := goldenFile(t, Options{Package: "enumsstr", Import: "example.com/enums"}, "enums", enums_in, "Pill")
+	if got != enums_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====\n%s", got, enums_out)
+	}
+
+	// A path ending in the name of the package imports it unnamed.
+	got = goldenFile(t, Options{Package: "enumsstr", Import: "example.com/test", Values: true}, "enums", enums_in, "Pill")
+	for _, want := range []string{"import \"example.com/test\"\n", "func PillValues() []test.Pill {", "names[i] = PillString(v)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("-values: no %q in\n%s", want, got)
+		}
+	}
+}
+
+// The code of the package generated in another needs the import path of the
+// package, and cannot have methods.
+func TestImportErrors(t *testing.T) {
+	info, fset := loadPackage(t, "enums", map[string]string{"enums.go": "package enums\n" + enums_in})
+	for _, test := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{Package: "enumsstr"}, "-pkg=enumsstr generates the code of package enums in another package, which needs -import to name its import path"},
+		{Options{Import: "example.com/enums"}, "-import requires -pkg"},
+		{Options{Package: "enumsstr", Import: "example.com/enums", Text: true}, "-text cannot be used for enums.Pill"},
+	} {
+		_, err := Generate(fset, info, []string{"Pill"}, test.opts)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%+v: got error %v, want %q", test.opts, err, test.want)
+		}
+	}
+
+	// Generated in the package, the code needs no import path.
+	if _, err := Generate(fset, info, []string{"Pill"}, Options{Package: "enums", Output: "enums_string.go"}); err != nil {
+		t.Errorf("-pkg=enums: %v", err)
+	}
+}
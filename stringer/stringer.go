@@ -0,0 +1,1580 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stringer generates the code of the stringer command: the String
+// method of a type with constants, and the other methods and functions its
+// flags add. The command golang.org/x/tools/cmd/stringer documents the code
+// generated, for each of the Options.
+//
+// A program that loaded a package generates the code for some of its types
+// with Generate, which returns the contents of the files to write:
+//
+//	files, err := stringer.Generate(prog.Fset, info, []string{"Pill"}, stringer.Options{})
+package stringer // import "golang.org/x/tools/stringer"
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	exact "go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// Options set the code generated. Each is a flag of the stringer command,
+// whose documentation describes it, and the zero Options are the defaults of
+// the flags.
+type Options struct {
+	Output         string // The file of all the types, or an existing directory of the files named as by default; "" for <type>_string.go in the package directory.
+	SplitFiles     bool   // With no Output, or a directory, write each type to a file of its own.
+	Package        string // The name of the package of the generated files; "" for that of the package of the types.
+	Import         string // With Package, the import path of the package of the types, whose String methods are then generated as functions.
+	TrimPrefix     string // Comma-separated prefixes, each for all types or given as Type:prefix, to trim from the names.
+	TrimSuffix     string // Comma-separated suffixes to trim from the names, as for TrimPrefix.
+	LineComment    bool   // Use the text of the line comment of a constant, when present, as its name.
+	DocComment     bool   // With LineComment, use the doc comment when there is no line comment.
+	Transform      string // The case style of the names: snake, kebab, upper, lower, title, or "" for none.
+	Bitflag        bool   // Handle the constants as bitflags.
+	NoCache        bool   // Do not cache the names of bitflag values.
+	NoTable        bool   // Generate self-contained bitflag code rather than tables.
+	TablePrefix    string // The prefix of the types shared by bitflag tables; "" for _stringer.
+	TableCommon    string // The file, in the package directory, or that of Output, unless absolute, of the code shared by bitflag tables; "" for stringerbitflag.go.
+	NoTableCommon  bool   // Leave out the file of the code shared by bitflag tables.
+	CacheSize      int    // The most bitflag names cached; 0 for 256, negative for no limit.
+	Precompute     bool   // Compute the names of bitflag types of at most 8 named bits when generating.
+	Text           bool   // Also generate MarshalText and UnmarshalText.
+	Parse          bool   // Also generate the <type>String function returning the value of a name.
+	JSON           bool   // Also generate MarshalJSON and UnmarshalJSON.
+	SQL            bool   // Also generate the Value and Scan methods of database/sql.
+	IsValid        bool   // Also generate the IsValid method.
+	Values         bool   // Also generate the <type>Values and <type>Names functions.
+	GoString       bool   // Also generate the GoString method.
+	ExportTables   bool   // Also generate the exported <type>Names and <type>Values arrays.
+	Include        string // If set, a regexp the trimmed names of the constants generated match.
+	Exclude        string // If set, a regexp the trimmed names of the constants generated do not match.
+	Header         string // The text of a comment starting the generated files, such as a copyright notice.
+	Command        string // The command recorded in the generated files; "" for stringer -type=<types>.
+	AllowInvalid   bool   // Return generated code that is not valid Go rather than an error.
+	Aliases        bool   // Also list the aliases of the printed names in a comment.
+	Sort           string // The order of the values listed and in maps: value, decl or name; "" for value.
+	Force          bool   // Generate the String method even if the type has one.
+	Method         string // The name of the String method, also in the default file names; "" for String.
+	BitflagHelpers bool   // With Bitflag, also generate Has, Set, Clear and Toggle.
+	BitOrder       string // With Bitflag, the order of the names of the bits set: lsb, lowest first, or msb; "" for lsb.
+	BitflagFormat  string // With Bitflag, the separator and brackets of several names, as sep=,,brackets=none; "" for (A|B).
+	UnknownBase    string // With Bitflag, the base of the bits with no name: hex, dec or bin; "" for hex.
+	Strict         bool   // Fail rather than warn when constants are not printed as declared.
+	Verbose        bool   // Log how the code is generated, and the bitflag constants left out of the names, and why.
+	Tags           string // Comma-separated build tags the generated files are constrained to.
+	SkipCgo        bool   // Omit the constants whose values come from package C.
+	FuncScope      bool   // Also generate the constants declared in function bodies.
+	IndexEncoding  string // The offsets of the names of a run: slice, an array variable, or string, a constant; "" for slice.
+	GenBench       bool   // Also generate a test file of benchmarks of the String method, <file>_bench_test.go.
+	Inline         bool   // Write the code of each type to its region of one file, Output or zz_generated_stringer.go, between marker comments.
+}
+
+// The defaults of Options.CacheSize and Options.TablePrefix.
+const (
+	defaultCacheSize   = 256
+	defaultTablePrefix = "_stringer"
+)
+
+// Check returns an error if the options are invalid, or name in TrimPrefix or
+// TrimSuffix a type not in typeNames, those of all the calls of Generate.
+func (opts *Options) Check(typeNames []string) error {
+	if err := opts.check(); err != nil {
+		return err
+	}
+	for _, spec := range []string{opts.TrimPrefix, opts.TrimSuffix} {
+		if err := checkTrimTypes(spec, typeNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check returns an error if the options are invalid.
+func (opts *Options) check() error {
+	if _, err := compileFilter("include", opts.Include); err != nil {
+		return err
+	}
+	if _, err := compileFilter("exclude", opts.Exclude); err != nil {
+		return err
+	}
+	if opts.Sort != "" && !validSort(opts.Sort) {
+		return fmt.Errorf("invalid -sort %q; must be %s, %s or %s", opts.Sort, sortValue, sortDecl, sortName)
+	}
+	if opts.Package != "" && !token.IsIdentifier(opts.Package) {
+		return fmt.Errorf("invalid -pkg %q; must be an identifier", opts.Package)
+	}
+	if opts.Import != "" && opts.Package == "" {
+		return fmt.Errorf("-import requires -pkg")
+	}
+	if opts.Method != "" && !token.IsIdentifier(opts.Method) {
+		return fmt.Errorf("invalid -method %q; must be an identifier", opts.Method)
+	}
+	if opts.BitflagHelpers && !opts.Bitflag {
+		return fmt.Errorf("-bitflaghelpers requires -bitflag")
+	}
+	if opts.Precompute && !opts.Bitflag {
+		return fmt.Errorf("-precompute requires -bitflag")
+	}
+	switch opts.BitOrder {
+	case "", bitOrderLSB:
+	case bitOrderMSB:
+		if !opts.Bitflag {
+			return fmt.Errorf("-bitorder=%s requires -bitflag", bitOrderMSB)
+		}
+	default:
+		return fmt.Errorf("invalid -bitorder %q; must be %s or %s", opts.BitOrder, bitOrderLSB, bitOrderMSB)
+	}
+	if opts.BitflagFormat != "" {
+		if !opts.Bitflag {
+			return fmt.Errorf("-bitflagformat requires -bitflag")
+		}
+		if _, err := parseBitflagFormat(opts.BitflagFormat); err != nil {
+			return err
+		}
+	}
+	if opts.UnknownBase != "" {
+		if _, err := parseUnknownBase(opts.UnknownBase); err != nil {
+			return err
+		}
+		if opts.UnknownBase != "hex" && !opts.Bitflag {
+			return fmt.Errorf("-unknownbase=%s requires -bitflag", opts.UnknownBase)
+		}
+	}
+	switch opts.IndexEncoding {
+	case "", indexSlice, indexString:
+	default:
+		return fmt.Errorf("invalid -indexencoding %q; must be %s or %s", opts.IndexEncoding, indexSlice, indexString)
+	}
+	if opts.ExportTables && opts.Values {
+		return fmt.Errorf("-exporttables and -values both declare <type>Values; use one")
+	}
+	if opts.DocComment && !opts.LineComment {
+		return fmt.Errorf("-doccomment requires -linecomment")
+	}
+	if opts.Transform != "" && !validTransform(opts.Transform) {
+		return fmt.Errorf("invalid -transform style %q; must be snake, kebab, upper, lower, title or none", opts.Transform)
+	}
+	if opts.TablePrefix != "" && !token.IsIdentifier(opts.TablePrefix+"Bitflag") {
+		return fmt.Errorf("invalid -tableprefix %q; must start an identifier", opts.TablePrefix)
+	}
+	if opts.Inline && opts.SplitFiles {
+		return fmt.Errorf("-inline writes all the types to one file; it cannot be used with -splitfiles")
+	}
+	if opts.Inline && opts.GenBench {
+		return fmt.Errorf("-genbench cannot be used with -inline")
+	}
+	return nil
+}
+
+// compileFilter compiles the regexp expr of the -include or -exclude flag,
+// as named, returning nil if it is empty.
+func compileFilter(flagName, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s: %s", flagName, err)
+	}
+	return re, nil
+}
+
+// keepName reports whether the constant with the trimmed name is generated:
+// it matches include, if set, and does not match exclude, if set. As for
+// regexp.MatchString, a match may be anywhere in the name unless anchored
+// with ^ and $.
+func keepName(name string, include, exclude *regexp.Regexp) bool {
+	if include != nil && !include.MatchString(name) {
+		return false
+	}
+	return exclude == nil || !exclude.MatchString(name)
+}
+
+// tagList returns the build tags of the comma-separated list.
+func tagList(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// A generateError is the error of generating the code for a type, reported
+// by failf and failAt and returned by genFile as a TypeError.
+type generateError struct {
+	pos token.Pos // Of the constant at fault, if any.
+	err error
+}
+
+// failf stops generating the code for the type, so that genFile reports the
+// error and goes on to the next type.
+func failf(format string, args ...interface{}) {
+	failAt(token.NoPos, format, args...)
+}
+
+// failAt is like failf but reports the error at pos.
+func failAt(pos token.Pos, format string, args ...interface{}) {
+	panic(generateError{pos, fmt.Errorf(format, args...)})
+}
+
+// recoverGenerate, deferred, returns in *err the error of a failf or failAt
+// out of the code of any type, and panics again with anything else.
+func recoverGenerate(fset *token.FileSet, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	e, ok := r.(generateError)
+	if !ok {
+		panic(r)
+	}
+	*err = e.err
+	if e.pos.IsValid() {
+		*err = fmt.Errorf("%s: %v", fset.Position(e.pos), e.err)
+	}
+}
+
+// A TypeError is the error of generating the code for a type.
+type TypeError struct {
+	Type string
+	Pos  token.Position // Of the constant at fault, or of the type; invalid if it is not declared.
+	Err  error
+}
+
+func (e *TypeError) Error() string {
+	if e.Pos.IsValid() {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// TypeErrors are the errors of the types whose code Generate did not
+// generate. It still returns the files of the other types.
+type TypeErrors []*TypeError
+
+func (e TypeErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Generate returns the contents of the files generated for the types of
+// package info, loaded in fset, by file name: the file of the types, or of
+// each type with Options.SplitFiles, with Options.GenBench the test file of
+// the benchmarks of each, and, for bitflag tables, the file of the code they
+// share. Warnings, as about constants left out, are printed with
+// the log package.
+//
+// The types whose code cannot be generated, as they are not declared in the
+// package or have no values, are left out of the files, and the error is
+// then of type TypeErrors.
+func Generate(fset *token.FileSet, info *loader.PackageInfo, typeNames []string, opts Options) (files map[string][]byte, err error) {
+	defer recoverGenerate(fset, &err)
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	if opts.Package != "" && opts.Package != info.Pkg.Name() && opts.Import == "" {
+		return nil, fmt.Errorf("-pkg=%s generates the code of package %s in another package, which needs -import to name its import path", opts.Package, info.Pkg.Name())
+	}
+	var errs TypeErrors
+	var names []*types.TypeName
+	byName := make(map[string]*types.TypeName)
+	for _, typeName := range typeNames {
+		t, err := LookupType(info.Pkg, typeName)
+		if err == nil && byName[t.Name()] != nil && byName[t.Name()] != t {
+			// The identifiers declared for them would be the same.
+			err = fmt.Errorf("type %s has the name of %s", qualifiedName(t, info.Pkg), qualifiedName(byName[t.Name()], info.Pkg))
+		}
+		if err != nil {
+			errs = append(errs, &TypeError{Type: typeName, Err: err})
+			continue
+		}
+		byName[t.Name()] = t
+		names = append(names, t)
+	}
+	files = make(map[string][]byte)
+	if len(names) == 0 {
+		return files, errs.err()
+	}
+	dir := PackageDir(fset, info)
+	if opts.Output != "" && isDir(opts.Output) {
+		// The files are named as by default, in the directory of Output.
+		dir, opts.Output = opts.Output, ""
+	}
+
+	// Generate the file of the types, or with SplitFiles a file for each,
+	// unless Output names the one file.
+	groups := [][]*types.TypeName{names}
+	if opts.SplitFiles && opts.Output == "" {
+		groups = nil
+		for _, name := range names {
+			groups = append(groups, []*types.TypeName{name})
+		}
+	}
+	xtest := strings.HasSuffix(info.Pkg.Path(), "_test")
+	if opts.Inline {
+		filename, src, typeErrs, err := genInline(fset, dir, xtest, info, names, opts)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, typeErrs...)
+		if src != nil {
+			files[filename] = src
+		}
+		groups = nil
+	}
+	for _, group := range groups {
+		filename := opts.Output
+		if filename == "" {
+			method := opts.Method
+			if method == "" {
+				method = defaultMethod
+			}
+			suffix := "_" + method + ".go"
+			if xtest {
+				suffix = "_" + method + "_test.go"
+			}
+			baseName := group[0].Name() + suffix
+			filename = filepath.Join(dir, strings.ToLower(baseName))
+		} else if xtest && !strings.HasSuffix(filename, "_test.go") {
+			// An external test package builds only from test files.
+			filename = strings.TrimSuffix(filename, ".go") + "_test.go"
+		}
+		if _, ok := files[filename]; ok {
+			return nil, fmt.Errorf("types %s and %s cannot both be written to %s; set -splitfiles", group[0].Name(), names[0].Name(), filename)
+		}
+		src, bench, typeErrs, err := genFile(fset, filename, info, group, opts, nil)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, typeErrs...)
+		if src != nil {
+			files[filename] = src
+		}
+		if bench != nil {
+			files[benchFilename(filename)] = bench
+		}
+	}
+	if opts.Bitflag && !opts.NoTable && !opts.NoTableCommon {
+		filename, src, err := genStringerBitflagFile(fset, dir, info.Pkg, opts)
+		if err != nil {
+			return nil, err
+		}
+		if src != nil {
+			files[filename] = src
+		}
+	}
+	return files, errs.err()
+}
+
+// PackageDir returns the directory of package info, loaded in fset, where
+// Generate writes its files unless Options.Output is set: that of its file
+// first by name, whatever the order the files were given in.
+func PackageDir(fset *token.FileSet, info *loader.PackageInfo) string {
+	return filepath.Dir(fset.File(sortedFiles(fset, info)[0].Pos()).Name())
+}
+
+// inPackageDir reports whether filename is in the directory of a file of
+// package info, loaded in fset.
+func inPackageDir(fset *token.FileSet, info *loader.PackageInfo, filename string) bool {
+	for _, file := range info.Files {
+		if sameFile(filepath.Dir(fset.File(file.Pos()).Name()), filepath.Dir(filename)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDir reports whether name is an existing directory.
+func isDir(name string) bool {
+	fi, err := os.Stat(name)
+	return err == nil && fi.IsDir()
+}
+
+// sortedFiles returns the files of package info in the order of their names,
+// so that the code generated does not depend on the order they were loaded.
+func sortedFiles(fset *token.FileSet, info *loader.PackageInfo) []*ast.File {
+	files := append([]*ast.File(nil), info.Files...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return fset.File(files[i].Pos()).Name() < fset.File(files[j].Pos()).Name()
+	})
+	return files
+}
+
+// err returns e as an error, nil if it is empty.
+func (e TypeErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// genFile generates a file defining String methods for the specified
+// typeNames belonging to package info. The types whose code cannot be
+// generated are left out and returned as errors; if none is left, the file
+// is not generated. With Inline, only the regions of the file are replaced.
+func genFile(fset *token.FileSet, filename string, info *loader.PackageInfo, typeNames []*types.TypeName, opts Options, regions []inlineRegion) (src, bench []byte, errs TypeErrors, err error) {
+	cacheSize := opts.CacheSize
+	switch {
+	case cacheSize == 0:
+		cacheSize = defaultCacheSize
+	case cacheSize < 0:
+		cacheSize = 0 // No limit.
+	}
+	g := Generator{
+		fset:        fset,
+		output:      filename,
+		importPath:  opts.Import,
+		inline:      opts.Inline,
+		regions:     regions,
+		trimPrefix:  opts.TrimPrefix,
+		trimSuffix:  opts.TrimSuffix,
+		lineComment: opts.LineComment,
+		docComment:  opts.DocComment,
+		transform:   opts.Transform,
+		bitflag:     opts.Bitflag,
+		cache:       opts.Bitflag && !opts.NoCache, // cache is only relevant when bitflag is also set
+		cacheSize:   cacheSize,
+		precompute:  opts.Precompute,
+		table:       !opts.NoTable,
+		tablePrefix: opts.TablePrefix,
+		skipCgo:     opts.SkipCgo,
+		funcScope:   opts.FuncScope,
+		indexString: opts.IndexEncoding == indexString,
+		genBench:    opts.GenBench,
+		text:        opts.Text,
+		parse:       opts.Parse,
+		json:        opts.JSON,
+		sql:         opts.SQL,
+		isValid:     opts.IsValid,
+		values:      opts.Values,
+		goString:    opts.GoString,
+		exported:    opts.ExportTables,
+		method:      opts.Method,
+		force:       opts.Force,
+		sort:        opts.Sort,
+		aliases:     opts.Aliases,
+		helpers:     opts.BitflagHelpers,
+		msb:         opts.BitOrder == bitOrderMSB,
+		strict:      opts.Strict,
+		verbose:     opts.Verbose,
+	}
+	// The options are checked by Generate.
+	g.include, _ = compileFilter("include", opts.Include)
+	g.exclude, _ = compileFilter("exclude", opts.Exclude)
+	g.nameFormat, _ = parseBitflagFormat(opts.BitflagFormat)
+	if opts.UnknownBase != "" {
+		g.nameFormat.base, _ = parseUnknownBase(opts.UnknownBase)
+	}
+
+	// Run generate for each type. The header follows, as it depends on the
+	// files declaring the constants.
+	var names []string
+	imported := make(map[string]bool) // The packages of the types of other packages.
+	for _, typeName := range typeNames {
+		if err := g.generateType(info, typeName); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, qualifiedName(typeName, info.Pkg))
+		if pkg := typeName.Pkg(); pkg != info.Pkg {
+			imported[pkg.Path()] = true
+		} else if opts.Import != "" {
+			imported[opts.Import] = true
+		}
+	}
+	if names == nil {
+		return nil, nil, errs, nil
+	}
+	if g.strict && g.dropped > 0 {
+		return nil, nil, nil, fmt.Errorf("not writing %s: -strict is set and constants are not printed as declared (%d)", filename, g.dropped)
+	}
+	body := append([]byte(nil), g.buf.Bytes()...)
+	g.buf.Reset()
+	if opts.Import == "" && inPackageDir(fset, info, filename) {
+		// Written elsewhere, or with Import, the file is of another package.
+		if err := g.checkDeclared(info, body); err != nil {
+			return nil, nil, nil, fmt.Errorf("not writing %s: %s", filename, err)
+		}
+	}
+
+	// Print the header and package clause, shared by the file of benchmarks.
+	command := opts.Command
+	if command == "" {
+		command = "stringer -type=" + strings.Join(names, ",")
+	}
+	g.Printf("%s", fileHeader(opts.Header, command))
+	g.Printf("\n")
+	// The constants may only exist where their files build.
+	x, conflict := buildConstraint(fset, g.files, tagList(opts.Tags))
+	if conflict {
+		log.Printf("warning: not constraining %s: the build constraints of the files declaring the constants conflict", filename)
+	}
+	if x != nil {
+		g.Printf("//go:build %s\n", x)
+		if lines, err := constraint.PlusBuildLines(x); err == nil {
+			for _, line := range lines {
+				g.Printf("%s\n", line)
+			}
+		}
+		g.Printf("\n")
+	}
+	g.Printf("package %s\n", packageName(info.Pkg, opts))
+	g.Printf("\n")
+	preamble := append([]byte(nil), g.buf.Bytes()...)
+	// The code of a type of another package refers to that package.
+	var typePaths []string
+	for path := range imported {
+		typePaths = append(typePaths, path)
+	}
+	paths := append(usedImports(body), typePaths...)
+	sort.Strings(paths)
+	for _, path := range paths {
+		g.Printf("import %s\n", importSpec(path, info.Pkg, opts))
+	}
+
+	g.buf.Write(body)
+
+	src, err = formatSource(filename, g.buf.Bytes(), opts.AllowInvalid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if g.genBench {
+		g.buf.Reset()
+		g.buf.Write(preamble)
+		paths = append(typePaths, "testing")
+		sort.Strings(paths)
+		for _, path := range paths {
+			g.Printf("import %s\n", importSpec(path, info.Pkg, opts))
+		}
+		g.buf.Write(g.benchBuf.Bytes())
+		bench, err = formatSource(benchFilename(filename), g.buf.Bytes(), opts.AllowInvalid)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return src, bench, errs, nil
+}
+
+// importSpec returns the import spec of the generated files for path. The
+// package of Options.Import, pkg, is named if its path ends otherwise.
+func importSpec(path string, pkg *types.Package, opts Options) string {
+	if path == opts.Import && path[strings.LastIndex(path, "/")+1:] != pkg.Name() {
+		return pkg.Name() + " " + strconv.Quote(path)
+	}
+	return strconv.Quote(path)
+}
+
+// packageName returns the name of the package of the files generated for the
+// types of pkg: that of Options.Package, if set, or else that of pkg.
+func packageName(pkg *types.Package, opts Options) string {
+	if opts.Package != "" {
+		return opts.Package
+	}
+	return pkg.Name()
+}
+
+// formatSource returns src, the contents of filename, formatted. If src does
+// not format, it is invalid Go, which is only returned, as is, if
+// allowInvalid is set.
+func formatSource(filename string, src []byte, allowInvalid bool) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		if !allowInvalid {
+			return nil, fmt.Errorf("invalid Go generated for %s: %s (-allowinvalid writes it anyway)", filename, err)
+		}
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		log.Printf("warning: compile the package to analyze the error")
+		return src, nil
+	}
+	return formatted, nil
+}
+
+// The packages the generated code may use, by the name it refers to them.
+var generatedImports = map[string]string{
+	"driver":  "database/sql/driver",
+	"fmt":     "fmt",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+}
+
+// usedImports returns the paths of the packages the generated code refers
+// to, so that the file imports those alone, whatever code the flags chose.
+func usedImports(body []byte) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n"), body...), 0)
+	if err != nil {
+		// The error is reported when the output is formatted.
+		return nil
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			// A package name is left unresolved by the parser.
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil && generatedImports[x.Name] != "" {
+				used[generatedImports[x.Name]] = true
+			}
+		}
+		return true
+	})
+	var paths []string
+	for path := range used {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// checkDeclared returns an error naming the file and line of a declaration
+// in the package, outside of the file being generated, of an identifier the
+// generated body declares, or of a method it declares on one of the types.
+// A method with a pointer receiver is named as such, as the generated one has
+// a value receiver. The String method, or that of -method, is allowed with
+// -force.
+func (g *Generator) checkDeclared(info *loader.PackageInfo, body []byte) error {
+	f, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n"), body...), 0)
+	if err != nil {
+		// The error is reported when the output is formatted.
+		return nil
+	}
+	scope := info.Pkg.Scope()
+	declared := func(what string, obj types.Object) error {
+		p := g.fset.Position(obj.Pos())
+		return fmt.Errorf("%s is already declared at %s:%d", what, p.Filename, p.Line)
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				if obj := scope.Lookup(decl.Name.Name); obj != nil && !g.replaced(obj.Pos()) {
+					return declared(decl.Name.Name, obj)
+				}
+				continue
+			}
+			recv, ok := decl.Recv.List[0].Type.(*ast.Ident)
+			if !ok || scope.Lookup(recv.Name) == nil {
+				continue
+			}
+			obj, _, _ := types.LookupFieldOrMethod(scope.Lookup(recv.Name).Type(), true, info.Pkg, decl.Name.Name)
+			if obj == nil || g.replaced(obj.Pos()) {
+				continue
+			}
+			what := recv.Name + "." + decl.Name.Name
+			err := declared(what, obj)
+			if fn, ok := obj.(*types.Func); ok && pointerRecv(fn) {
+				// fmt would print a value and a pointer to it with
+				// different methods.
+				err = fmt.Errorf("%s with a pointer receiver, unlike the generated %s", declared("(*"+recv.Name+")."+decl.Name.Name, obj), what)
+			}
+			if decl.Name.Name == g.stringMethod() {
+				if g.force {
+					continue
+				}
+				return fmt.Errorf("%s (use -force to generate it anyway)", err)
+			}
+			return err
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				var names []*ast.Ident
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					names = spec.Names
+				case *ast.TypeSpec:
+					names = []*ast.Ident{spec.Name}
+				}
+				for _, name := range names {
+					if obj := scope.Lookup(name.Name); obj != nil && !g.replaced(obj.Pos()) {
+						return declared(name.Name, obj)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// pointerRecv reports whether the method fn has a pointer receiver.
+func pointerRecv(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return false
+	}
+	_, ok = sig.Recv().Type().(*types.Pointer)
+	return ok
+}
+
+// Generator holds the state of the analysis. Primarily used to buffer
+// the output for format.Source.
+type Generator struct {
+	buf         bytes.Buffer // Accumulated output.
+	benchBuf    bytes.Buffer // Accumulated benchmarks, with genBench.
+	trimPrefix  string       // Comma-separated prefixes to trim from the names, each maybe for one type.
+	trimSuffix  string       // Comma-separated suffixes to trim from the names, each maybe for one type.
+	lineComment bool
+	docComment  bool   // With lineComment, use doc comments if there is no line comment.
+	transform   string // The case style of the printed names.
+	bitflag     bool
+	cache       bool
+	cacheSize   int  // The most names cached, if not 0.
+	precompute  bool // Compute the names of types with few named bits.
+	table       bool
+	tablePrefix string // The prefix of the types shared by the tables, if not the default.
+	skipCgo     bool   // Omit constants whose values come from package C.
+	funcScope   bool   // Also find constants declared in function bodies.
+	indexString bool   // Declare the offsets of the names as string constants.
+	genBench    bool   // Also generate the benchmarks of the String methods.
+	text        bool   // Also generate MarshalText and UnmarshalText.
+	parse       bool   // Also generate the exported parse function.
+	json        bool   // Also generate MarshalJSON and UnmarshalJSON.
+	sql         bool   // Also generate the Value and Scan methods of database/sql.
+	isValid     bool   // Also generate the IsValid method.
+	values      bool   // Also generate the functions listing the values and names.
+	goString    bool   // Also generate the GoString method.
+	exported    bool   // Also generate the exported arrays of the names and values.
+	method      string // The name of the String method, if not String.
+	force       bool   // Generate the String method even if the type has one.
+	runes       bool   // The constants of the type being generated are runes.
+	bits        uint   // The number of bits of the type being generated, as given by intBits.
+	sort        string // The order of the values listed by -values and in maps.
+	aliases     bool   // Also list the aliases of the printed names in a comment.
+
+	include    *regexp.Regexp // If set, only the constants whose names match are printed.
+	exclude    *regexp.Regexp // If set, the constants whose names match are not printed.
+	helpers    bool           // Also generate the bitflag helper methods.
+	msb        bool           // Name the bits set from the highest down.
+	nameFormat bitflagFormat  // The separator and brackets of several bitflag names.
+	strict     bool           // Fail rather than warn when constants are not printed as declared.
+	verbose    bool           // Log how the code is generated, and the bitflag constants left out of the names.
+	dropped    int            // The number of constants warned about.
+
+	fset       *token.FileSet // Positions of the methods declared in the package.
+	output     string         // The file being generated, whose methods are replaced.
+	inline     bool           // Only the regions of the output are replaced.
+	importPath string         // The import path of the package of the types, if the code is generated in another.
+	regions    []inlineRegion // The regions of the output, with inline.
+	files      []*ast.File    // The files declaring the constants.
+}
+
+// parsing reports whether the generated code needs the parse function.
+func (g *Generator) parsing() bool {
+	return g.text || g.parse || g.json || g.sql
+}
+
+// verbosef logs, with -v, how the code is generated.
+func (g *Generator) verbosef(format string, args ...interface{}) {
+	if g.verbose {
+		log.Printf(format, args...)
+	}
+}
+
+func (g *Generator) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// generateType runs generate for typeName. If it fails, the code generated
+// for the type is dropped and the error is returned.
+func (g *Generator) generateType(info *loader.PackageInfo, typeName *types.TypeName) (err *TypeError) {
+	start, files, dropped := g.buf.Len(), len(g.files), g.dropped
+	benchStart := g.benchBuf.Len()
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		e, ok := r.(generateError)
+		if !ok {
+			panic(r)
+		}
+		g.buf.Truncate(start)
+		g.benchBuf.Truncate(benchStart)
+		g.files, g.dropped = g.files[:files], dropped
+		pos := e.pos
+		if !pos.IsValid() {
+			pos = typeName.Pos()
+		}
+		err = &TypeError{Type: qualifiedName(typeName, info.Pkg), Pos: g.fset.Position(pos), Err: e.err}
+	}()
+	// The methods of a type of another package than that of the generated
+	// code are functions.
+	qualified := typeName.Pkg() != info.Pkg || g.importPath != ""
+	if qualified {
+		g.checkQualified(g.qualifiedName(typeName, info.Pkg))
+	}
+	g.generate(info, typeName)
+	if qualified {
+		g.qualifyType(start, typeName)
+	}
+	return nil
+}
+
+// generate produces the String method for the named type, whose constants
+// are declared in package info.
+func (g *Generator) generate(info *loader.PackageInfo, obj *types.TypeName) {
+	typeName := obj.Name()
+	// The code generated for the type, from the current end of the buffer,
+	// is renamed for -method once it is complete.
+	defer g.renameMethod(g.buf.Len(), typeName)
+	values := make([]Value, 0, 100)
+	names := make(printedNames)
+	prefixes := trimList(g.trimPrefix, typeName)
+	suffixes := trimList(g.trimSuffix, typeName)
+	g.runes = false
+	excluded := 0 // By -include and -exclude.
+	addValue := func(vspec *ast.ValueSpec, v Value) {
+		constant := v.name
+		if hasRuneLit(vspec) {
+			g.runes = true
+		}
+		text, comment := g.commentName(vspec)
+		if comment {
+			if text == "" {
+				failAt(v.pos, "the comment naming constant %s is blank", constant)
+			}
+			v.name = text
+		}
+		name := v.name
+		v.name = trimName(v.name, prefixes, suffixes)
+		if v.name == "" && name != "" {
+			failAt(v.pos, "trimming the name of constant %s leaves it empty", constant)
+		}
+		if !keepName(v.name, g.include, g.exclude) {
+			excluded++
+			return
+		}
+		if !comment {
+			// The text of a line comment is printed as written.
+			v.name = transformName(v.name, g.transform)
+		}
+		if err := names.add(constant, v.name, v.str); err != nil {
+			failAt(v.pos, "%s", err)
+		}
+		if g.goString && v.name != constant {
+			failAt(v.pos, "-gostring: %s is printed as %q; GoString needs the names of the constants", constant, v.name)
+		}
+		v.decl = len(values)
+		values = append(values, v)
+	}
+
+	// The type of the constants, maybe declared in another package.
+	typ := obj.Type()
+	var files []string
+	for _, file := range sortedFiles(g.fset, info) {
+		n := len(values)
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok == token.CONST {
+					constValues(decl, info, typ, g.skipCgo, addValue)
+				}
+			case *ast.FuncDecl:
+				if g.funcScope && decl.Body != nil {
+					ast.Inspect(decl.Body, func(node ast.Node) bool {
+						if decl, ok := node.(*ast.GenDecl); ok && decl.Tok == token.CONST {
+							constValues(decl, info, typ, g.skipCgo, addValue)
+							return false
+						}
+						return true
+					})
+				}
+			}
+		}
+		if len(values) > n {
+			g.files = append(g.files, file)
+			if g.fset != nil {
+				files = append(files, g.fset.Position(file.Pos()).Filename)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		if excluded > 0 {
+			failf("no values defined for type %s (%d excluded by -include and -exclude)", typeName, excluded)
+		}
+		failf("no values defined for type %s", typeName)
+	}
+	if excluded > 0 {
+		g.verbosef("%s: %d constants from %s, %d excluded by -include and -exclude", typeName, len(values), strings.Join(files, ", "), excluded)
+	} else {
+		g.verbosef("%s: %d constants from %s", typeName, len(values), strings.Join(files, ", "))
+	}
+	if g.genBench {
+		g.buildBench(obj, g.qualifiedName(obj, info.Pkg), values)
+	}
+	if isStringType(typ) {
+		g.verbosef("%s: string constants: String returns the value", typeName)
+		g.buildStringType(values, typeName)
+		return
+	}
+	if g.bitflag {
+		g.buildBitflag(info, values, typeName)
+		if g.helpers {
+			g.buildBitflagHelpers(info, typeName)
+		}
+		return
+	}
+	g.bits = intBits(typ)
+	all := append([]Value(nil), values...) // Before duplicates are removed.
+	runs := splitIntoRuns(values)
+	if g.aliases {
+		g.buildAliasComment(runs, aliases(all, runs), typeName)
+	}
+	// The decision of which pattern to use depends on the number of
+	// runs in the numbers. If there's only one, it's easy. For more than
+	// one, there's a tradeoff between complexity and size of the data
+	// and code vs. the simplicity of a map. A map takes more space,
+	// but so does the code. The decision here (crossover at 10) is
+	// arbitrary, but considers that for large numbers of runs the cost
+	// of the linear scan in the switch might become important, and
+	// rather than use yet another algorithm such as binary search,
+	// we punt and use a map. In any case, the likelihood of a map
+	// being necessary for any realistic example other than bitmasks
+	// is very low.
+	multi, isMap := false, false
+	switch {
+	case len(runs) == 1 && len(runs[0]) == 1:
+		g.verbosef("%s: one value: a comparison with it", typeName)
+		g.buildOneRun(runs, typeName)
+	case len(runs) == 1:
+		g.verbosef("%s: one run of %d values: a slice of the names", typeName, len(runs[0]))
+		g.buildOneRun(runs, typeName)
+	case len(runs) <= 10:
+		g.verbosef("%s: %d runs, at most 10: a switch over the runs", typeName, len(runs))
+		g.buildMultipleRuns(runs, typeName)
+		multi = true
+	default:
+		g.verbosef("%s: %d runs, more than 10: a map", typeName, len(runs))
+		g.buildMap(runs, typeName)
+		isMap = true
+	}
+	if g.isValid {
+		g.buildIsValid(runs, typeName, isMap)
+	}
+	if g.values {
+		g.buildValues(runValues(runs), typeName)
+	}
+	if g.parsing() {
+		g.buildParse(runs, aliases(all, runs), typeName, multi)
+	}
+	if g.goString {
+		g.buildGoString(typeName, info.Pkg.Name())
+	}
+	if g.exported {
+		names := nameSlices(runs, fmt.Sprintf("_%s_name", typeName), multi)
+		g.buildExportTables(runValues(runs), names, typeName)
+	}
+}
+
+// splitIntoRuns breaks the values into runs of contiguous sequences.
+// For example, given 1,2,3,5,6,7 it returns {1,2,3},{5,6,7}.
+// The input slice is known to be non-empty.
+func splitIntoRuns(values []Value) [][]Value {
+	// We use stable sort so the lexically first name is chosen for equal elements.
+	sort.Stable(byValue(values))
+	// Remove duplicates. Stable sort has put the one we want to print first,
+	// so use that one. The String method won't care about which named constant
+	// was the argument, so the first name for the given value is the only one to keep.
+	// We need to do this because identical values would cause the switch or map
+	// to fail to compile.
+	j := 1
+	for i := 1; i < len(values); i++ {
+		if values[i].value != values[i-1].value {
+			values[j] = values[i]
+			j++
+		}
+	}
+	values = values[:j]
+	runs := make([][]Value, 0, 10)
+	for len(values) > 0 {
+		// One contiguous sequence per outer loop.
+		i := 1
+		for i < len(values) && values[i].value == values[i-1].value+1 {
+			i++
+		}
+		runs = append(runs, values[:i])
+		values = values[i:]
+	}
+	return runs
+}
+
+// format returns the gofmt-ed contents of the generated buffer.
+func (g *Generator) format() []byte {
+	return formatBytes(g.buf.Bytes())
+}
+
+// formatBytes returns the gofmt-ed contents of the buffer.
+func formatBytes(b []byte) []byte {
+	src, err := format.Source(b)
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		// The user can compile the output to see the error.
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		log.Printf("warning: compile the package to analyze the error")
+		return b
+	}
+	return src
+}
+
+// Value represents a declared constant.
+type Value struct {
+	name string // The name of the constant.
+	// The value is stored as a bit pattern alone. The boolean tells us
+	// whether to interpret it as an int64 or a uint64; the only place
+	// this matters is when sorting.
+	// Much of the time the str field is all we need; it is printed
+	// by Value.String.
+	value  uint64    // Will be converted to int64 when needed.
+	signed bool      // Whether the constant is a signed type.
+	str    string    // The string representation given by the "go/exact" package.
+	pos    token.Pos // The position of the name of the constant.
+	decl   int       // The index of the constant in the files in the order of their names.
+}
+
+func (v *Value) String() string {
+	return v.str
+}
+
+// byValue lets us sort the constants into increasing order.
+// We take care in the Less method to sort in signed or unsigned order,
+// as appropriate.
+type byValue []Value
+
+func (b byValue) Len() int      { return len(b) }
+func (b byValue) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byValue) Less(i, j int) bool {
+	if b[i].signed {
+		return int64(b[i].value) < int64(b[j].value)
+	}
+	return b[i].value < b[j].value
+}
+
+// constValues calls addValue for each value of type typ in const declaration decl.
+// If skipCgo is set, constants whose values come from package C are omitted with a warning.
+func constValues(decl *ast.GenDecl, info *loader.PackageInfo, typ types.Type, skipCgo bool, addValue func(*ast.ValueSpec, Value)) {
+	// Whether the values, explicit or carried down, refer to package C.
+	fromC := false
+	// Loop over the elements of the declaration. Each element is a ValueSpec:
+	// a list of names possibly followed by a type, possibly followed by values.
+	// If the type and value are both missing, they are carried down, which
+	// the "go/types" package takes care of; it gives the type of each constant.
+	for _, spec := range decl.Specs {
+		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
+		if len(vspec.Values) > 0 {
+			fromC = false
+			for _, value := range vspec.Values {
+				if refersToC(info, value) {
+					fromC = true
+				}
+			}
+		}
+		// Grab the names and actual values of the constants of the desired
+		// type and store them in f.values.
+		for _, name := range vspec.Names {
+			if name.Name == "_" {
+				continue
+			}
+			// This dance lets the type checker find the values for us. It's a
+			// bit tricky: look up the object declared by the name, find its
+			// types.Const, and extract its type and value.
+			obj, ok := info.Info.Defs[name]
+			if !ok || obj == nil || typ == nil || !types.Identical(obj.Type(), typ) {
+				// This is not the type we're looking for.
+				continue
+			}
+			if fromC && skipCgo {
+				log.Printf("warning: skipping constant %s: its value comes from package C", name)
+				continue
+			}
+			info := obj.Type().Underlying().(*types.Basic).Info()
+			if info&(types.IsInteger|types.IsString) == 0 {
+				failAt(name.Pos(), "can't handle non-integer, non-string constant type %s", types.TypeString(obj.Type(), types.RelativeTo(obj.Pkg())))
+			}
+			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
+			if fromC && value.Kind() == exact.Unknown {
+				failAt(name.Pos(), "no value for constant %s: it comes from package C, which was not processed by cgo (see -cgo)", name)
+			}
+			if value.Kind() == exact.String {
+				// The value is printed as a string literal.
+				addValue(vspec, Value{
+					name: name.Name,
+					pos:  name.Pos(),
+					str:  strconv.Quote(exact.StringVal(value)),
+				})
+				continue
+			}
+			if value.Kind() != exact.Int {
+				failAt(name.Pos(), "can't happen: constant is not an integer %s", name)
+			}
+			i64, isInt := exact.Int64Val(value)
+			u64, isUint := exact.Uint64Val(value)
+			if !isInt && !isUint {
+				failAt(name.Pos(), "internal error: value of %s is not an integer: %s", name, value.String())
+			}
+			if !isInt {
+				u64 = uint64(i64)
+			}
+			v := Value{
+				name:   name.Name,
+				pos:    name.Pos(),
+				value:  u64,
+				signed: info&types.IsUnsigned == 0,
+				str:    value.String(),
+			}
+			addValue(vspec, v)
+		}
+	}
+}
+
+// commentName returns the text of the comment naming the constant with
+// -linecomment: its trailing comment, or with -doccomment its doc comment if
+// it has no trailing comment. The lines of the comment are joined by spaces,
+// and are none for a blank comment.
+func (g *Generator) commentName(vspec *ast.ValueSpec) (string, bool) {
+	if !g.lineComment {
+		return "", false
+	}
+	c := vspec.Comment
+	if c == nil && g.docComment {
+		c = vspec.Doc
+	}
+	if c == nil {
+		return "", false
+	}
+	var lines []string
+	for _, line := range strings.Split(c.Text(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		// A comment of directives, as //nolint:all, does not name the
+		// constant, but a blank one names it with an empty name.
+		return "", isBlank(c)
+	}
+	return strings.Join(lines, " "), true
+}
+
+// isBlank reports whether the comments of c hold only spaces.
+func isBlank(c *ast.CommentGroup) bool {
+	for _, comment := range c.List {
+		text := strings.TrimPrefix(comment.Text, "//")
+		if strings.HasPrefix(text, "/*") {
+			text = strings.TrimSuffix(text[2:], "*/")
+		}
+		if strings.TrimSpace(text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// trimList returns the prefixes or suffixes of the comma-separated spec of
+// -trimprefix or -trimsuffix that apply to the type: those given for it as
+// Type:prefix, and those given alone, for all types.
+func trimList(spec, typeName string) []string {
+	var list []string
+	for _, s := range strings.Split(spec, ",") {
+		if i := strings.Index(s, ":"); i >= 0 {
+			if baseTypeName(s[:i]) != typeName {
+				continue
+			}
+			s = s[i+1:]
+		}
+		if s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// checkTrimTypes returns an error if the spec of -trimprefix or -trimsuffix
+// names a type not among typeNames.
+func checkTrimTypes(spec string, typeNames []string) error {
+	for _, s := range strings.Split(spec, ",") {
+		i := strings.Index(s, ":")
+		if i < 0 {
+			continue
+		}
+		found := false
+		for _, typeName := range typeNames {
+			found = found || baseTypeName(s[:i]) == baseTypeName(typeName)
+		}
+		if !found {
+			return fmt.Errorf("trimming %q for type %s, which is not listed in -type", s[i+1:], s[:i])
+		}
+	}
+	return nil
+}
+
+// trimName returns name without the first of the prefixes it starts with
+// and the first of the suffixes it then ends with.
+func trimName(name string, prefixes, suffixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			name = name[:len(name)-len(suffix)]
+			break
+		}
+	}
+	return name
+}
+
+// trimmedNames records the constants by the names they print as, to catch
+// the names that trimming leaves empty or makes those of other values.
+// printedNames holds the constants of a type by the name they print as, once
+// trimmed, taken from a comment or transformed, so that no value prints as an
+// empty name or as that of another.
+type printedNames map[string]printedName
+
+type printedName struct {
+	constant string
+	value    string // As printed by Value.String.
+}
+
+// add records that the constant with the value prints as name. The constants
+// of a value, its aliases, may print alike.
+func (p printedNames) add(constant, name, value string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("constant %s prints as an empty name", constant)
+	}
+	o, ok := p[name]
+	if !ok {
+		p[name] = printedName{constant, value}
+		return nil
+	}
+	if o.value != value {
+		return fmt.Errorf("constants %s and %s, of different values, both print as %q", o.constant, constant, name)
+	}
+	return nil
+}
+
+// Helpers
+
+// escape returns s escaped to be printed between the quotes of a Go string.
+func escape(s string) string {
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+// usize returns the number of bits of the smallest unsigned integer
+// type that will hold n. Used to create the smallest possible slice of
+// integers to use as indexes into the concatenated strings.
+func usize(n int) int {
+	switch {
+	case n < 1<<8:
+		return 8
+	case n < 1<<16:
+		return 16
+	default:
+		// 2^32 is enough constants for anyone.
+		return 32
+	}
+}
+
+// declareIndexAndNameVars declares the index slices and concatenated names
+// strings representing the runs of values. With -indexencoding=string, the
+// indexes are constants declared with the names.
+func (g *Generator) declareIndexAndNameVars(runs [][]Value, typeName string) {
+	var indexes, names []string
+	for i, run := range runs {
+		index, name := g.createIndexAndNameDecl(run, typeName, fmt.Sprintf("_%d", i))
+		if len(run) != 1 {
+			indexes = append(indexes, index)
+		}
+		names = append(names, name)
+	}
+	if g.indexString {
+		names, indexes = append(names, indexes...), nil
+	}
+	g.Printf("const (\n")
+	for _, name := range names {
+		g.Printf("\t%s\n", name)
+	}
+	g.Printf(")\n\n")
+
+	if len(indexes) > 0 {
+		g.Printf("var (")
+		for _, index := range indexes {
+			g.Printf("\t%s\n", index)
+		}
+		g.Printf(")\n\n")
+	}
+}
+
+// declareIndexAndNameVar is the single-run version of declareIndexAndNameVars
+func (g *Generator) declareIndexAndNameVar(run []Value, typeName string) {
+	index, name := g.createIndexAndNameDecl(run, typeName, "")
+	g.Printf("const %s\n", name)
+	if g.indexString {
+		g.Printf("const %s\n", index)
+		return
+	}
+	g.Printf("var %s\n", index)
+}
+
+// createIndexAndNameDecl returns the pair of declarations for the run. The caller will add "const" and "var".
+// The offsets are of the smallest width holding those of the run, whatever that of the other runs.
+func (g *Generator) createIndexAndNameDecl(run []Value, typeName string, suffix string) (string, string) {
+	b := new(bytes.Buffer)
+	indexes := make([]int, len(run))
+	for i := range run {
+		b.WriteString(run[i].name)
+		indexes[i] = b.Len()
+	}
+	nameConst := fmt.Sprintf("_%s_name%s = %q", typeName, suffix, b.String())
+	nameLen := b.Len()
+	if g.indexString {
+		offsets := append([]int{0}, indexes...)
+		return fmt.Sprintf("_%s_index%s = %s", typeName, suffix, indexLiteral(offsets, g.indexWidth(nameLen))), nameConst
+	}
+	b.Reset()
+	fmt.Fprintf(b, "_%s_index%s = [...]uint%d{0, ", typeName, suffix, usize(nameLen))
+	for i, v := range indexes {
+		if i > 0 {
+			fmt.Fprintf(b, ", ")
+		}
+		fmt.Fprintf(b, "%d", v)
+	}
+	fmt.Fprintf(b, "}")
+	return b.String(), nameConst
+}
+
+// declareNameVars declares the concatenated names string representing all the values in the runs.
+func (g *Generator) declareNameVars(runs [][]Value, typeName string, suffix string) {
+	b := new(bytes.Buffer)
+	for _, run := range runs {
+		for i := range run {
+			b.WriteString(run[i].name)
+		}
+	}
+	g.Printf("const _%s_name%s = %q\n", typeName, suffix, b.String())
+}
+
+// buildOneRun generates the variables and String method for a single run of contiguous values.
+func (g *Generator) buildOneRun(runs [][]Value, typeName string) {
+	values := runs[0]
+	g.Printf("\n")
+	if len(values) == 1 {
+		// A single value needs no index, as a run of one of several
+		// runs has none.
+		g.Printf("const _%s_name = %q\n\n", typeName, values[0].name)
+		g.Printf(stringOneValue, typeName, values[0].String(), g.formatCall("i", values[0].signed))
+		return
+	}
+	g.declareIndexAndNameVar(values, typeName)
+	// The generated code is simple enough to write as a Printf format.
+	lessThanZero := ""
+	if values[0].signed {
+		lessThanZero = "i < 0 || "
+	}
+	count, ret := indexCode(typeName, g.indexWidth(namesLen(values)))
+	switch {
+	case values[0].value == 0: // Signed or unsigned, 0 is still 0.
+		g.Printf(stringOneRun, typeName, usize(len(values)), lessThanZero, g.formatCall("i", values[0].signed), count, ret)
+	case values[0].signed:
+		shift, unshift := g.offset(&values[0])
+		g.Printf(stringOneRunWithOffset, typeName, shift, usize(len(values)), lessThanZero,
+			g.formatCall(unshift, true), count, ret)
+	default:
+		g.Printf(stringOneRunWithOffsetUnsigned, typeName, values[0].String(), g.formatCall("i", false), count, ret)
+	}
+}
+
+// namesLen returns the number of bytes of the names of the values.
+func namesLen(values []Value) int {
+	n := 0
+	for i := range values {
+		n += len(values[i].name)
+	}
+	return n
+}
+
+// hasRuneLit reports whether a value of the constants of vspec is written
+// with a rune literal, such as '('.
+func hasRuneLit(vspec *ast.ValueSpec) bool {
+	found := false
+	for _, value := range vspec.Values {
+		ast.Inspect(value, func(node ast.Node) bool {
+			if lit, ok := node.(*ast.BasicLit); ok && lit.Kind == token.CHAR {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// formatCall returns the call printing the integer expression x in decimal,
+// as a signed or an unsigned value of its type, or as a quoted character if
+// the constants of the type are runes.
+func (g *Generator) formatCall(x string, signed bool) string {
+	if g.runes {
+		return "strconv.QuoteRune(rune(" + x + "))"
+	}
+	if signed {
+		return "strconv.FormatInt(int64(" + x + "), 10)"
+	}
+	return "strconv.FormatUint(uint64(" + x + "), 10)"
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: the value, as a string
+//	[3]: call printing i
+const stringOneValue = `func (i %[1]s) String() string {
+	if i == %[2]s {
+		return _%[1]s_name
+	}
+	return "%[1]s(" + %[3]s + ")"
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: size of index element (8 for uint8 etc.)
+//	[3]: less than zero check (for signed types)
+//	[4]: call printing i
+//	[5]: number of names, from the index
+//	[6]: statements returning the name i
+const stringOneRun = `func (i %[1]s) String() string {
+	if %[3]si >= %[1]s(%[5]s) {
+		return "%[1]s(" + %[4]s + ")"
+	}
+	%[6]s
+}
+`
+
+// offset returns the statement shifting i from the lowest value of a run,
+// lo, to 0, and the expression shifting it back, to print the value i had.
+// The shift by a negative value is an addition, unless the negation of the
+// value does not fit the type, as for -128 in an int8. The shift wraps
+// around for the values far from the run, and back again.
+func (g *Generator) offset(lo *Value) (shift, unshift string) {
+	if lo.signed && int64(lo.value) < 0 && int64(lo.value) > -1<<(g.bits-1) {
+		n := strconv.FormatInt(-int64(lo.value), 10)
+		return "i += " + n, "i - " + n
+	}
+	return "i -= " + lo.String(), "i + " + lo.String()
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: statement shifting i from the lowest defined value to 0
+//	[3]: size of index element (8 for uint8 etc.)
+//	[4]: less than zero check (for signed types)
+//	[5]: call printing i shifted back to the value it had
+//	[6]: number of names, from the index
+//	[7]: statements returning the name i
+/*
+ */
+const stringOneRunWithOffset = `func (i %[1]s) String() string {
+	%[2]s
+	if %[4]si >= %[1]s(%[6]s) {
+		return "%[1]s(" + %[5]s + ")"
+	}
+	%[7]s
+}
+`
+
+// The values below the lowest are checked before the subtraction, which
+// would wrap them around.
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: lowest defined value for type, as a string
+//	[3]: call printing i
+//	[4]: number of names, from the index
+//	[5]: statements returning the name i
+const stringOneRunWithOffsetUnsigned = `func (i %[1]s) String() string {
+	if i < %[2]s || i-%[2]s >= %[1]s(%[4]s) {
+		return "%[1]s(" + %[3]s + ")"
+	}
+	i -= %[2]s
+	%[5]s
+}
+`
+
+// buildMultipleRuns generates the variables and String method for multiple runs of contiguous values.
+// For this pattern, a single Printf format won't do.
+func (g *Generator) buildMultipleRuns(runs [][]Value, typeName string) {
+	g.Printf("\n")
+	g.declareIndexAndNameVars(runs, typeName)
+	g.Printf("func (i %s) String() string {\n", typeName)
+	g.Printf("\tswitch {\n")
+	for i, values := range runs {
+		if len(values) == 1 {
+			g.Printf("\tcase i == %s:\n", &values[0])
+			g.Printf("\t\treturn _%s_name_%d\n", typeName, i)
+			continue
+		}
+		g.Printf("\tcase %s <= i && i <= %s:\n", &values[0], &values[len(values)-1])
+		if values[0].value != 0 {
+			// The default case prints i, shifted only in the case of its run.
+			shift, _ := g.offset(&values[0])
+			g.Printf("\t\t%s\n", shift)
+		}
+		g.Printf("%s", returnName("\t\t", fmt.Sprintf("_%s_name_%d", typeName, i), fmt.Sprintf("_%s_index_%d", typeName, i), g.indexWidth(namesLen(values))))
+	}
+	g.Printf("\tdefault:\n")
+	g.Printf("\t\treturn \"%s(\" + %s + \")\"\n", typeName, g.formatCall("i", runs[0][0].signed))
+	g.Printf("\t}\n")
+	g.Printf("}\n")
+}
+
+// buildMap handles the case where the space is so sparse a map is a reasonable fallback.
+// It's a rare situation but has simple code.
+func (g *Generator) buildMap(runs [][]Value, typeName string) {
+	g.Printf("\n")
+	g.declareNameVars(runs, typeName, "")
+	g.Printf("\nvar _%s_map = map[%s]string{\n", typeName, typeName)
+	// The names are sliced in increasing order of the values.
+	values := runValues(runs)
+	offsets := make([]int, len(values)+1)
+	for i := range values {
+		offsets[i+1] = offsets[i] + len(values[i].name)
+	}
+	for _, i := range g.orderIndex(values) {
+		g.Printf("\t%s: _%s_name[%d:%d],\n", &values[i], typeName, offsets[i], offsets[i+1])
+	}
+	g.Printf("}\n\n")
+	g.Printf(stringMap, typeName, g.formatCall("i", runs[0][0].signed))
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: call printing i
+const stringMap = `func (i %[1]s) String() string {
+	if str, ok := _%[1]s_map[i]; ok {
+		return str
+	}
+	return "%[1]s(" + %[2]s + ")"
+}
+`
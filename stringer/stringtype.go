@@ -0,0 +1,108 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the code for a type whose constants are strings,
+// which print as themselves. The methods of other flags are generated from
+// the slice of the constants, in lexical order.
+
+package stringer
+
+import (
+	"go/types"
+	"sort"
+	"strconv"
+)
+
+// isStringType reports whether typ is a string type.
+func isStringType(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// buildStringType generates the String method of a string type, returning
+// the value, and the code of the other flags.
+func (g *Generator) buildStringType(values []Value, typeName string) {
+	if g.bitflag {
+		failf("-bitflag requires integer constants; the constants of %s are strings", typeName)
+	}
+	if g.goString {
+		failf("-gostring requires integer constants; the constants of %s are strings", typeName)
+	}
+	// We use stable sort so the first name declared is kept for a value.
+	sort.Stable(byString(values))
+	j := 1
+	for i := 1; i < len(values); i++ {
+		if values[i].str != values[i-1].str {
+			values[j] = values[i]
+			j++
+		}
+	}
+	values = values[:j]
+
+	g.Printf(stringTypeString, typeName)
+	switch {
+	case g.values:
+		g.buildValues(values, typeName)
+	case g.isValid || g.parsing():
+		g.Printf("\nvar _%s_values = []%s{%s}\n", typeName, typeName, valueList(values))
+	}
+	if g.isValid {
+		g.Printf(stringTypeIsValid, typeName)
+	}
+	if g.parsing() {
+		g.Printf(stringTypeParse, typeName)
+		g.parseUsers(typeName)
+	}
+	if g.exported {
+		// The names are the values, whose literals the linker shares.
+		names := make([]string, len(values))
+		for i, v := range values {
+			names[i] = v.str
+		}
+		g.buildExportTables(values, names, typeName)
+	}
+}
+
+// byString sorts the values of a string type lexically.
+type byString []Value
+
+func (b byString) Len() int      { return len(b) }
+func (b byString) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byString) Less(i, j int) bool {
+	x, _ := strconv.Unquote(b[i].str)
+	y, _ := strconv.Unquote(b[j].str)
+	return x < y
+}
+
+// Argument to format is the type name.
+const stringTypeString = `
+func (i %[1]s) String() string {
+	return string(i)
+}
+`
+
+// Argument to format is the type name.
+const stringTypeIsValid = `
+func (i %[1]s) IsValid() bool {
+	for _, v := range _%[1]s_values {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+`
+
+// Argument to format is the type name.
+const stringTypeParse = `
+func _%[1]s_parse(s string) (%[1]s, error) {
+	for _, v := range _%[1]s_values {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%%s does not belong to %[1]s values", s)
+}
+
+`
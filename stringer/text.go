@@ -0,0 +1,282 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the code that maps names back to values: the
+// parse function shared by the exported parse function of -parse, the
+// encoding.TextMarshaler and encoding.TextUnmarshaler methods of -text, the
+// json.Marshaler and json.Unmarshaler methods of -json and the driver.Valuer
+// and sql.Scanner methods of -sql.
+
+package stringer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// aliases returns the values of all whose names are not printed, so they
+// can be parsed too. Names that are printed, or that repeat, are left out.
+func aliases(all []Value, printed [][]Value) []Value {
+	seen := make(map[string]bool)
+	for _, run := range printed {
+		for _, v := range run {
+			seen[v.name] = true
+		}
+	}
+	var r []Value
+	for _, v := range all {
+		if !seen[v.name] {
+			seen[v.name] = true
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+// buildAliasComment generates the comment of -aliases listing, after each
+// printed name, the aliases with its value.
+func (g *Generator) buildAliasComment(printed [][]Value, aliases []Value, typeName string) {
+	if len(aliases) == 0 {
+		return
+	}
+	byValue := make(map[uint64][]string)
+	for _, v := range aliases {
+		byValue[v.value] = append(byValue[v.value], v.name)
+	}
+	g.Printf("\n// The aliases of the %s constants, which print as the name before them:\n//\n", typeName)
+	for _, run := range printed {
+		for _, v := range run {
+			if names := byValue[v.value]; len(names) != 0 {
+				g.Printf("//\t%s: %s\n", v.name, strings.Join(names, ", "))
+			}
+		}
+	}
+}
+
+// declareAliases adds the aliases to the map being declared.
+func (g *Generator) declareAliases(aliases []Value) {
+	for i := range aliases {
+		g.Printf("\t%q: %s,\n", aliases[i].name, &aliases[i])
+	}
+}
+
+// declareValueMap declares the map from each name to its value, reusing the
+// name strings already declared for the runs. If multi is set, the runs have
+// names of their own, as declared by declareIndexAndNameVars.
+func (g *Generator) declareValueMap(runs [][]Value, aliases []Value, typeName string, multi bool) {
+	g.Printf("\nvar _%s_value = map[string]%s{\n", typeName, typeName)
+	names := nameSlices(runs, fmt.Sprintf("_%s_name", typeName), multi)
+	for _, v := range runValues(runs) {
+		g.Printf("\t%s: %s,\n", names[0], &v)
+		names = names[1:]
+	}
+	g.declareAliases(aliases)
+	g.Printf("}\n\n")
+}
+
+// maxParseSwitch is the most names the parse function finds with a switch.
+// As for the runs of the String method, the crossover is arbitrary: a switch
+// on a few names is smaller than a map and needs nothing at init, while for
+// more names the map is declared from the name strings already there.
+const maxParseSwitch = 10
+
+// buildParse generates the parse function for the runs of values, whose
+// String method is already generated, and the code of the flags
+// that use it.
+func (g *Generator) buildParse(runs [][]Value, aliases []Value, typeName string, multi bool) {
+	n := len(aliases)
+	for _, run := range runs {
+		n += len(run)
+	}
+	if n <= maxParseSwitch {
+		g.verbosef("%s: %d names to parse, at most %d: a switch", typeName, n, maxParseSwitch)
+		g.buildParseSwitch(runs, aliases, typeName)
+	} else {
+		g.verbosef("%s: %d names to parse, more than %d: a map", typeName, n, maxParseSwitch)
+		g.declareValueMap(runs, aliases, typeName, multi)
+		g.Printf(parseFunc, typeName)
+	}
+	g.parseUsers(typeName)
+}
+
+// buildParseSwitch generates the parse function as a switch on the names of
+// the runs, in order, followed by the aliases.
+func (g *Generator) buildParseSwitch(runs [][]Value, aliases []Value, typeName string) {
+	g.Printf("\nfunc _%[1]s_parse(s string) (%[1]s, error) {\n", typeName)
+	g.Printf("\tswitch s {\n")
+	add := func(v *Value) {
+		g.Printf("\tcase %q:\n", v.name)
+		g.Printf("\t\treturn %s, nil\n", v)
+	}
+	for _, run := range runs {
+		for i := range run {
+			add(&run[i])
+		}
+	}
+	for i := range aliases {
+		add(&aliases[i])
+	}
+	g.Printf("\t}\n")
+	g.Printf(parseFail, typeName)
+}
+
+// parseUsers generates the code that uses the parse function.
+func (g *Generator) parseUsers(typeName string) {
+	if g.parse {
+		g.Printf(exportedParseFunc, typeName)
+	}
+	if g.text {
+		g.Printf(textMethods, typeName)
+	}
+	if g.json {
+		g.Printf(jsonMethods, typeName)
+	}
+	if g.sql {
+		g.Printf(sqlMethods, typeName)
+	}
+}
+
+// declareBitflagValueMap declares the map from each name of a bitflag type
+// to its value. The names of the single bits are those of the runs, in order,
+// followed by those of the composites. If table is set there is no name
+// constant to slice, so the keys are literals.
+func (g *Generator) declareBitflagValueMap(runs [][]Value, composites, aliases []Value, typeName string, table bool) {
+	g.Printf("\nvar _%s_value = map[string]%s{\n", typeName, typeName)
+	printed := append(runs[:len(runs):len(runs)], composites)
+	names := nameSlices(printed, fmt.Sprintf("_%s_name", typeName), false)
+	for _, v := range runValues(printed) {
+		if table {
+			g.Printf("\t%q: %s,\n", v.name, &v)
+		} else {
+			g.Printf("\t%s: %s,\n", names[0], &v)
+		}
+		names = names[1:]
+	}
+	g.declareAliases(aliases)
+	g.Printf("}\n\n")
+}
+
+// buildBitflagParse generates the parse function for a bitflag type, whose
+// String method is already generated, and the code of the flags
+// that use it.
+func (g *Generator) buildBitflagParse(runs [][]Value, composites, aliases []Value, typeName, zeroName string) {
+	g.declareBitflagValueMap(runs, composites, aliases, typeName, g.table)
+	f := g.nameFormat
+	g.Printf(parseBitflagFunc, typeName, zeroName, f.parseUnbracketCode(), f.splitCode(), len(f.sep))
+	g.parseUsers(typeName)
+}
+
+// Argument to format is the type name.
+const parseFunc = `func _%[1]s_parse(s string) (%[1]s, error) {
+	if v, ok := _%[1]s_value[s]; ok {
+		return v, nil
+	}
+` + parseFail
+
+// Argument to format is the type name.
+const parseFail = `	return 0, fmt.Errorf("%%s does not belong to %[1]s values", s)
+}
+
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: zeroName
+//	[3]: statements removing the brackets around several names, if any
+//	[4]: condition of s[i] not starting the separator
+//	[5]: length of the separator
+const parseBitflagFunc = `func _%[1]s_parse(s string) (%[1]s, error) {
+	if s == %[2]q {
+		return 0, nil
+	}%[3]s
+	var m %[1]s
+	for {
+		i := 0
+		for i < len(s) && %[4]s {
+			i++
+		}
+		v, ok := _%[1]s_value[s[:i]]
+		if !ok {
+			return 0, fmt.Errorf("%%s does not belong to %[1]s values", s[:i])
+		}
+		m |= v
+		if i == len(s) {
+			return m, nil
+		}
+		s = s[i+%[5]d:]
+	}
+}
+
+`
+
+// Argument to format is the type name.
+const exportedParseFunc = `// %[1]sString returns the %[1]s value whose name is s.
+func %[1]sString(s string) (%[1]s, error) {
+	return _%[1]s_parse(s)
+}
+`
+
+// Argument to format is the type name.
+const textMethods = `
+func (i %[1]s) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v, err := _%[1]s_parse(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+// Argument to format is the type name. The name is quoted with strconv, not
+// encoding/json, to keep the generated file free of that dependency.
+const jsonMethods = `
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(i.String())), nil
+}
+
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("%[1]s should be a string, got %%s", data)
+	}
+	v, err := _%[1]s_parse(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
+
+// Argument to format is the type name.
+const sqlMethods = `
+func (i %[1]s) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+func (i *%[1]s) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into %[1]s")
+	default:
+		return fmt.Errorf("cannot scan %%T into %[1]s", src)
+	}
+	v, err := _%[1]s_parse(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
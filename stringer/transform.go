@@ -0,0 +1,81 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines rewrite the names of the constants in the case style of
+// the -transform flag, before any code printing them is generated.
+
+package stringer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The styles of the -transform flag.
+const (
+	transformNone  = "none"
+	transformSnake = "snake"
+	transformKebab = "kebab"
+	transformUpper = "upper"
+	transformLower = "lower"
+	transformTitle = "title"
+)
+
+// validTransform reports whether style is one of the -transform flag.
+func validTransform(style string) bool {
+	switch style {
+	case transformNone, transformSnake, transformKebab, transformUpper, transformLower, transformTitle:
+		return true
+	}
+	return false
+}
+
+// transformName returns name in the case style: snake_case, kebab-case,
+// UPPER, lower or Title Case, its words separated by spaces.
+func transformName(name, style string) string {
+	switch style {
+	case transformSnake:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case transformKebab:
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	case transformUpper:
+		return strings.ToUpper(name)
+	case transformLower:
+		return strings.ToLower(name)
+	case transformTitle:
+		words := splitWords(name)
+		for i, w := range words {
+			r := []rune(strings.ToLower(w))
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+		return strings.Join(words, " ")
+	}
+	return name
+}
+
+// splitWords splits the name into words at underscores and where the case
+// changes. A run of capitals is a word of its own, except for the last one
+// when it starts a lower-case word: "HTTPServer" is "HTTP" and "Server". A
+// digit belongs with the letters before it: "utf8Name" is "utf8" and "Name".
+func splitWords(name string) []string {
+	var words []string
+	r := []rune(name)
+	start := 0
+	for i := 0; i <= len(r); i++ {
+		switch {
+		case i == len(r) || r[i] == '_':
+			if i > start {
+				words = append(words, string(r[start:i]))
+			}
+			start = i + 1
+		case i > start && unicode.IsUpper(r[i]):
+			if !unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1]) {
+				words = append(words, string(r[start:i]))
+				start = i
+			}
+		}
+	}
+	return words
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stringer
+
+import "testing"
+
+func TestTransformName(t *testing.T) {
+	for _, test := range []struct {
+		name, style, want string
+	}{
+		{"HTTPServer", transformSnake, "http_server"},
+		{"HTTPServer", transformKebab, "http-server"},
+		{"HTTPServer", transformUpper, "HTTPSERVER"},
+		{"HTTPServer", transformLower, "httpserver"},
+		{"HTTPServer", transformTitle, "Http Server"},
+		{"HTTPServer", transformNone, "HTTPServer"},
+		{"getHTTP", transformSnake, "get_http"},
+		{"utf8Name", transformSnake, "utf8_name"},
+		{"HTTP2Server", transformSnake, "http2_server"},
+		{"Long_Weekend", transformSnake, "long_weekend"},
+		{"Aspirin", transformSnake, "aspirin"},
+		{"A", transformKebab, "a"},
+		{"ABc", transformSnake, "a_bc"},
+		{"maxInt64", transformTitle, "Max Int64"},
+	} {
+		if got := transformName(test.name, test.style); got != test.want {
+			t.Errorf("transformName(%q, %s) = %q, want %q", test.name, test.style, got, test.want)
+		}
+	}
+}
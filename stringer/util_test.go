@@ -0,0 +1,595 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for some of the internal functions.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Helpers to save typing in the test cases.
+type u []uint64
+type uu [][]uint64
+
+type SplitTest struct {
+	input  u
+	output uu
+	signed bool
+}
+
+var (
+	m2  = uint64(2)
+	m1  = uint64(1)
+	m0  = uint64(0)
+	m_1 = ^uint64(0)     // -1 when signed.
+	m_2 = ^uint64(0) - 1 // -2 when signed.
+)
+
+var splitTests = []SplitTest{
+	// No need for a test for the empty case; that's picked off before splitIntoRuns.
+	// Single value.
+	{u{1}, uu{u{1}}, false},
+	// Out of order.
+	{u{3, 2, 1}, uu{u{1, 2, 3}}, true},
+	// Out of order.
+	{u{3, 2, 1}, uu{u{1, 2, 3}}, false},
+	// A gap at the beginning.
+	{u{1, 33, 32, 31}, uu{u{1}, u{31, 32, 33}}, true},
+	// A gap in the middle, in mixed order.
+	{u{33, 7, 32, 31, 9, 8}, uu{u{7, 8, 9}, u{31, 32, 33}}, true},
+	// Gaps throughout
+	{u{33, 44, 1, 32, 45, 31}, uu{u{1}, u{31, 32, 33}, u{44, 45}}, true},
+	// Unsigned values spanning 0.
+	{u{m1, m0, m_1, m2, m_2}, uu{u{m0, m1, m2}, u{m_2, m_1}}, false},
+	// Signed values spanning 0
+	{u{m1, m0, m_1, m2, m_2}, uu{u{m_2, m_1, m0, m1, m2}}, true},
+}
+
+func TestSplitIntoRuns(t *testing.T) {
+Outer:
+	for n, test := range splitTests {
+		values := make([]Value, len(test.input))
+		for i, v := range test.input {
+			values[i] = Value{"", v, test.signed, fmt.Sprint(v), token.NoPos, i}
+		}
+		runs := splitIntoRuns(values)
+		if len(runs) != len(test.output) {
+			t.Errorf("#%d: %v: got %d runs; expected %d", n, test.input, len(runs), len(test.output))
+			continue
+		}
+		for i, run := range runs {
+			if len(run) != len(test.output[i]) {
+				t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
+				continue Outer
+			}
+			for j, v := range run {
+				if v.value != test.output[i][j] {
+					t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
+					continue Outer
+				}
+			}
+		}
+	}
+}
+
+func TestTrimName(t *testing.T) {
+	for _, test := range []struct {
+		typeName, name, prefixes, suffixes, want string
+	}{
+		{"State", "StateOldIdle", "StateOld,St", "", "Idle"},
+		{"State", "StRunning", "StateOld,St", "", "Running"},
+		{"State", "StateOldIdle", "St,StateOld", "", "ateOldIdle"}, // The first prefix that matches.
+		{"State", "IdleState", "", "State", "Idle"},
+		{"State", "StRunningState", "StateOld,St", "State", "Running"},
+		{"State", "Stop", ",x", ",", "Stop"},
+		{"Color", "ColRed", "Color:Col,Shape:Sh", "", "Red"},
+		{"Shape", "ShSquare", "Color:Col,Shape:Sh", "", "Square"},
+		{"Shape", "ColShSquare", "Color:Col,Shape:Sh", "", "ColShSquare"},
+		{"Shape", "ShapeSquare", "Shape:Shape,Sh", "", "Square"},
+		{"Color", "ShapeRed", "Shape:Shape,Sh", "", "apeRed"},
+		{"Color", "RedColor", "", "Shape:Shape,Color:Color", "Red"},
+	} {
+		got := trimName(test.name, trimList(test.prefixes, test.typeName), trimList(test.suffixes, test.typeName))
+		if got != test.want {
+			t.Errorf("%s: trimName(%q) with %q, %q = %q, want %q", test.typeName, test.name, test.prefixes, test.suffixes, got, test.want)
+		}
+	}
+}
+
+func TestCheckTrimTypes(t *testing.T) {
+	typeNames := []string{"Color", "Shape"}
+	for _, test := range []struct {
+		spec, err string
+	}{
+		{"", ""},
+		{"Col,Sh", ""},
+		{"Color:Col,Shape:Sh,X", ""},
+		{"Color:Col,Size:Sz", `trimming "Sz" for type Size, which is not listed in -type`},
+	} {
+		err := checkTrimTypes(test.spec, typeNames)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != test.err {
+			t.Errorf("%q: got error %q, want %q", test.spec, got, test.err)
+		}
+	}
+}
+
+func TestPrintedNames(t *testing.T) {
+	names := make(printedNames)
+	for _, test := range []struct {
+		constant, name, value string
+		err                   string
+	}{
+		{"StIdle", "Idle", "0", ""},
+		{"StateOldIdle", "Idle", "0", ""}, // An alias.
+		{"Done", "Done", "1", ""},
+		{"StDone", "Done", "2", `constants Done and StDone, of different values, both print as "Done"`},
+		{"Stop", "Stop", "4", ""},
+		{"Halt", "Stop", "5", `constants Stop and Halt, of different values, both print as "Stop"`}, // As by -linecomment.
+		{"Quit", "Stop", "4", ""}, // An alias, as by -linecomment.
+		{"Red", `"red"`, `"r"`, ""},
+		{"Rouge", `"red"`, `"rouge"`, `constants Red and Rouge, of different values, both print as "\"red\""`},
+		{"State", "", "3", "constant State prints as an empty name"},
+		{"Blank", " \t", "6", "constant Blank prints as an empty name"},
+	} {
+		err := names.add(test.constant, test.name, test.value)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != test.err {
+			t.Errorf("%s: got error %q, want %q", test.constant, got, test.err)
+		}
+	}
+}
+
+// TestEmptyAndSameNames checks that Generate fails, at the position of the
+// constant, when a name is empty once trimmed or named by a blank comment, or
+// when the names of two values are the same once trimmed, commented or
+// transformed, but not for aliases.
+func TestEmptyAndSameNames(t *testing.T) {
+	for _, test := range []struct {
+		opts  Options
+		decls string
+		want  string // "" for no error
+	}{
+		{Options{TrimPrefix: "Col"}, "Col Color = iota\nColRed\n", "names.go:4:1: trimming the name of constant Col leaves it empty"},
+		{Options{LineComment: true}, "Red Color = iota // red\nBlue //   \n", "names.go:5:1: the comment naming constant Blue is blank"},
+		{Options{LineComment: true}, "Red Color = iota /* */\n", "names.go:4:1: the comment naming constant Red is blank"},
+		{Options{LineComment: true}, "Red Color = iota //nolint:all\n", ""}, // A directive.
+		{Options{LineComment: true}, "Red Color = iota // red\nBlue // red\n", `names.go:5:1: constants Red and Blue, of different values, both print as "red"`},
+		{Options{LineComment: true}, "Red Color = iota // red\nRouge Color = 0 // red\n", ""}, // An alias.
+		{Options{Transform: "lower"}, "Red Color = iota\nRED\n", `names.go:5:1: constants Red and RED, of different values, both print as "red"`},
+		{Options{TrimPrefix: "Color", Transform: "snake"}, "ColorDarkRed Color = iota\nDark_Red\n", `names.go:5:1: constants ColorDarkRed and Dark_Red, of different values, both print as "dark_red"`},
+	} {
+		info, fset := loadPackage(t, "names", map[string]string{
+			"names.go": "package test\ntype Color int\nconst (\n" + test.decls + ")\n",
+		})
+		_, err := Generate(fset, info, []string{"Color"}, test.opts)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != test.want {
+			t.Errorf("%q: got error %q, want %q", test.decls, got, test.want)
+		}
+	}
+}
+
+func TestSplitIntoBitflagRunsRejects(t *testing.T) {
+	// The constants of a signed type, in the order declared.
+	var values []Value
+	for i, c := range []struct {
+		name  string
+		value int64
+	}{
+		{"Read", 1},
+		{"Write", 2},
+		{"ReadWrite", 3},
+		{"Sign", -128},
+		{"Writable", 2},
+		{"None", 0},
+		{"Zero", 0},
+		{"Modify", 3},
+	} {
+		values = append(values, Value{c.name, uint64(c.value), true, fmt.Sprint(c.value), token.Pos(i + 1), i})
+	}
+	_, _, _, rejects := splitIntoBitflagRuns(values)
+	var got []string
+	for _, r := range rejects {
+		got = append(got, r.v.name+" "+r.reason+" "+r.of)
+	}
+	want := []string{
+		"Sign negative ",
+		"Writable duplicate Write",
+		"None zero ",
+		"Zero duplicate None",
+		"Modify duplicate ReadWrite",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rejects %q, want %q", got, want)
+	}
+}
+
+func TestCheckDeclared(t *testing.T) {
+	for _, test := range []struct {
+		decls   string // declared with the constants of Day
+		force   bool
+		bitflag bool   // generate Day as a cached bitflag, with its _string method
+		want    string // "" for no error
+	}{
+		{"", false, false, ""},
+		{"func (Day) Name() string { return \"\" }\n", false, false, ""},
+		{"var _Day_name = 1\n", false, false, "_Day_name is already declared at check.go:5"},
+		{"func (Day) String() string { return \"\" }\n", false, false, "Day.String is already declared at check.go:5 (use -force to generate it anyway)"},
+		{"func (Day) String() string { return \"\" }\n", true, false, ""},
+		{"func (*Day) String() string { return \"\" }\n", false, false, "(*Day).String is already declared at check.go:5 with a pointer receiver, unlike the generated Day.String (use -force to generate it anyway)"},
+		{"func (*Day) String() string { return \"\" }\n", true, false, ""},
+		{"func (*Day) String() string { return \"\" }\n", false, true, "(*Day).String is already declared at check.go:5 with a pointer receiver, unlike the generated Day.String (use -force to generate it anyway)"},
+		{"func (Day) _string() string { return \"\" }\n", true, true, "Day._string is already declared at check.go:5"},
+		{"func (*Day) _string() string { return \"\" }\n", true, true, "(*Day)._string is already declared at check.go:5 with a pointer receiver, unlike the generated Day._string"},
+		{"func (*Day) _string() string { return \"\" }\n", true, false, ""},
+		{"type _Day_index int\n", true, false, "_Day_index is already declared at check.go:5"},
+	} {
+		info, fset := loadPackage(t, "check", map[string]string{
+			"check.go": "package test\ntype Day int\nconst Mon, Tue Day = 1, 2\n\n" + test.decls,
+		})
+		g := Generator{fset: fset, force: test.force, bitflag: test.bitflag, cache: test.bitflag, nameFormat: defaultBitflagFormat}
+		g.generate(info, info.Pkg.Scope().Lookup("Day").(*types.TypeName))
+		got := ""
+		if err := g.checkDeclared(info, g.buf.Bytes()); err != nil {
+			got = err.Error()
+		}
+		if got != test.want {
+			t.Errorf("%q, bitflag %v: got error %q, want %q", test.decls, test.bitflag, got, test.want)
+		}
+	}
+}
+
+// TestGenerateTypeErrors checks that the code of the other types is still
+// generated when that of one type fails, and that the error is reported at
+// the constant at fault, or else at the type.
+func TestGenerateTypeErrors(t *testing.T) {
+	info, fset := loadPackage(t, "errs", map[string]string{
+		"errs.go": `package test
+
+type Day int
+
+const Mon, Tue Day = 0, 1
+
+type Ratio float64
+
+const Half Ratio = 0.5
+
+type Color int
+
+const Red, Green Color = 0, 1
+
+type Empty int
+`,
+	})
+	files, err := Generate(fset, info, []string{"Day", "Ratio", "Color"}, Options{Output: "errs_string.go"})
+	errs, ok := err.(TypeErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("got error %v, want the error of Ratio", err)
+	}
+	if got, want := errs[0].Error(), "errs.go:9:7: can't handle non-integer, non-string constant type Ratio"; errs[0].Type != "Ratio" || got != want {
+		t.Errorf("got error %q of %s, want %q of Ratio", got, errs[0].Type, want)
+	}
+	src := string(files["errs_string.go"])
+	for _, name := range []string{"_Day_name", "_Color_name"} {
+		if !strings.Contains(src, name) {
+			t.Errorf("%s is not generated", name)
+		}
+	}
+	if strings.Contains(src, "Ratio") {
+		t.Errorf("the code of Ratio is generated:\n%s", src)
+	}
+
+	// No file is generated if every type fails.
+	files, err = Generate(fset, info, []string{"Empty", "Month"}, Options{Output: "errs_string.go"})
+	want := "type Month is not declared in package errs\nerrs.go:15:6: no values defined for type Empty"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+	if len(files) != 0 {
+		t.Errorf("got files %q, want none", files)
+	}
+}
+
+// TestBitflagNamesTooLong checks that the names of a bitflag type too long
+// for the offsets of its tables are reported as the error of the type.
+func TestBitflagNamesTooLong(t *testing.T) {
+	long, longer := strings.Repeat("A", 40000), strings.Repeat("B", 1<<16)
+	info, fset := loadPackage(t, "long", map[string]string{
+		"long.go": `package test
+
+type Long uint
+
+const (
+	` + long + ` Long = 1 << iota
+	Short
+	` + long + `Short = ` + long + ` | Short
+)
+
+type Longer uint
+
+const ` + longer + ` Longer = 1
+`,
+	})
+	_, err := Generate(fset, info, []string{"Long", "Longer"}, Options{Bitflag: true, Output: "long_string.go"})
+	errs, ok := err.(TypeErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("got error %v, want those of Long and Longer", err)
+	}
+	for i, want := range []string{
+		"long.go:3:6: the names are too long (80010 bytes, at most 65535)",
+		"long.go:13:7: the name is too long (65536 bytes, at most 65535)",
+	} {
+		if got := errs[i].Error(); got != want {
+			t.Errorf("got error %q, want %q", got, want)
+		}
+	}
+}
+
+// TestRecoverGenerate checks that a failf or failAt out of the code of a
+// type is returned as an error, at its position if any, and that any other
+// panic goes on.
+func TestRecoverGenerate(t *testing.T) {
+	fset := token.NewFileSet()
+	f := fset.AddFile("fail.go", -1, 10)
+	f.SetLines([]int{0, 5})
+	fail := func(pos token.Pos) (err error) {
+		defer recoverGenerate(fset, &err)
+		failAt(pos, "failed %d", 1)
+		return nil
+	}
+	if err := fail(token.NoPos); err == nil || err.Error() != "failed 1" {
+		t.Errorf("got error %v, want %q", err, "failed 1")
+	}
+	if err := fail(f.Pos(6)); err == nil || err.Error() != "fail.go:2:2: failed 1" {
+		t.Errorf("got error %v, want %q", err, "fail.go:2:2: failed 1")
+	}
+	defer func() {
+		if r := recover(); r != "other" {
+			t.Errorf("got panic %v, want %q", r, "other")
+		}
+	}()
+	func() (err error) {
+		defer recoverGenerate(fset, &err)
+		panic("other")
+	}()
+}
+
+// TestOutputDir checks that an Output naming a directory gets the files
+// named as by default, one for each type with SplitFiles, with the package
+// clause of Package if set.
+func TestOutputDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	info, fset := loadPackage(t, "enums", map[string]string{
+		"day.go": "package test\n" + day_in,
+		"gap.go": "package test\n" + gap_in,
+	})
+	for _, test := range []struct {
+		opts  Options
+		files []string
+		pkg   string
+	}{
+		{Options{Output: dir}, []string{"day_string.go"}, "test"},
+		{Options{Output: dir, SplitFiles: true}, []string{"day_string.go", "gap_string.go"}, "test"},
+		{Options{Output: dir, SplitFiles: true, Package: "enumsstr", Import: "example.com/enums"}, []string{"day_string.go", "gap_string.go"}, "enumsstr"},
+		{Options{Output: filepath.Join(dir, "all.go"), SplitFiles: true, Package: "enumsstr", Import: "example.com/enums"}, []string{"all.go"}, "enumsstr"},
+	} {
+		files, err := Generate(fset, info, []string{"Day", "Gap"}, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for filename, src := range files {
+			if filepath.Dir(filename) != dir {
+				t.Errorf("%+v: %s is not written to %s", test.opts, filename, dir)
+			}
+			got = append(got, filepath.Base(filename))
+			if want := "\npackage " + test.pkg + "\n"; !strings.Contains(string(src), want) {
+				t.Errorf("%+v: %s has no package clause %q:\n%s", test.opts, filename, want[1:], src)
+			}
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.files) {
+			t.Errorf("%+v: got files %q, want %q", test.opts, got, test.files)
+		}
+	}
+
+	if _, err := Generate(fset, info, []string{"Day"}, Options{Package: "enums-str"}); err == nil || err.Error() != `invalid -pkg "enums-str"; must be an identifier` {
+		t.Errorf("got error %v for an invalid package name", err)
+	}
+}
+
+// TestVerbose checks the shape of the code generated for each type, as
+// logged with Verbose.
+func TestVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	for _, test := range []struct {
+		opts  Options
+		name  string
+		input string
+		want  string
+	}{
+		{Options{}, "day", day_in, "Day: 7 constants from day.go\nDay: one run of 7 values: a slice of the names\n"},
+		{Options{}, "gap", gap_in, "Gap: 8 constants from gap.go\nGap: 3 runs, at most 10: a switch over the runs\n"},
+		{Options{}, "prime", prime_in, "Prime: 14 constants from prime.go\nPrime: 12 runs, more than 10: a map\n"},
+		{Options{}, "single", single_in, "Single: 1 constants from single.go\nSingle: one value: a comparison with it\n"},
+		{Options{Include: "^[^S]"}, "day", day_in, "Day: 5 constants from day.go, 2 excluded by -include and -exclude\nDay: one run of 5 values: a slice of the names\n"},
+		{
+			Options{Bitflag: true}, "days", days_in_bitflag,
+			"Days: 7 constants from days.go\nDays: bitflag, 7 single bits in 1 runs and 0 composites: table-driven, sharing the code of _stringerBitflag, cached up to 256 names\n",
+		},
+		{
+			Options{Bitflag: true, NoTable: true, NoCache: true}, "days", days_in_bitflag,
+			"Days: 7 constants from days.go\nDays: bitflag, 7 single bits in 1 runs and 0 composites: self-contained, not cached\n",
+		},
+		{
+			Options{Bitflag: true, Precompute: true}, "days", days_in_bitflag,
+			"Days: 7 constants from days.go\nDays: bitflag, 7 single bits in 1 runs and 0 composites: the names of the 7 named bits precomputed, at most 8, falling back to a loop\n",
+		},
+	} {
+		test.opts.Verbose = true
+		buf.Reset()
+		goldenGenerate(t, test.opts, test.name, test.input)
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestKeepName(t *testing.T) {
+	for _, test := range []struct {
+		name, include, exclude string
+		want                   bool
+	}{
+		{"Ready", "", "", true},
+		{"Ready", "Read", "", true}, // Not anchored.
+		{"Ready", "^Read$", "", false},
+		{"Ready", "", "ead", false},
+		{"Ready", "", "^ead", true},
+		{"statusSentinel", "", "Sentinel$", false},
+		{"Ready", "^R", "y$", false}, // Both match: excluded.
+		{"Run", "^R", "y$", true},
+		{"Done", "^R", "y$", false},
+	} {
+		include, err := compileFilter("include", test.include)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exclude, err := compileFilter("exclude", test.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := keepName(test.name, include, exclude); got != test.want {
+			t.Errorf("keepName(%q) with -include=%q -exclude=%q = %v, want %v", test.name, test.include, test.exclude, got, test.want)
+		}
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	const generated = "// Code generated by \"stringer -type=Day\"; DO NOT EDIT.\n"
+	for _, test := range []struct {
+		header, want string
+	}{
+		{"", generated},
+		{"Copyright 2026 Acme.", "// Copyright 2026 Acme.\n\n" + generated},
+		{"Copyright 2026 Acme.\n\n// All rights reserved.\n", "// Copyright 2026 Acme.\n//\n// All rights reserved.\n\n" + generated},
+	} {
+		if got := fileHeader(test.header, "stringer -type=Day"); got != test.want {
+			t.Errorf("%q: got header\n%s\nwant\n%s", test.header, got, test.want)
+		}
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	// A generated template that is not Go is an error, unless allowed.
+	invalidSrc := "package p\n\nfunc (i Day) String() string {\n\treturn _Day_name[\n}\n"
+	if _, err := formatSource("day_string.go", []byte(invalidSrc), false); err == nil {
+		t.Error("no error formatting invalid Go")
+	}
+	got, err := formatSource("day_string.go", []byte(invalidSrc), true)
+	if err != nil || string(got) != invalidSrc {
+		t.Errorf("with allowInvalid: got %q, %v, want %q", got, err, invalidSrc)
+	}
+
+	got, err = formatSource("day_string.go", []byte("package p\nconst  day=2\n"), false)
+	if want := "package p\n\nconst day = 2\n"; err != nil || string(got) != want {
+		t.Errorf("got %q, %v, want %q", got, err, want)
+	}
+}
+
+// TestFuncScope checks that the constants are found whatever declarations
+// precede them, and those in function bodies only with FuncScope.
+func TestFuncScope(t *testing.T) {
+	const input = `import "strings"
+
+var lower = strings.ToLower
+
+type Kind int
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+func init() {
+	const KindC Kind = 2
+	_ = func() {
+		const KindD Kind = 3
+	}
+}
+`
+	for _, test := range []struct {
+		funcScope bool
+		want      string
+	}{
+		{false, "KindAKindB"},
+		{true, "KindAKindBKindCKindD"},
+	} {
+		got := goldenFile(t, Options{FuncScope: test.funcScope}, "kind", input, "Kind")
+		if !strings.Contains(got, `_Kind_name = "`+test.want+`"`) {
+			t.Errorf("with FuncScope %v: got\n%s\nwant the names %s", test.funcScope, got, test.want)
+		}
+	}
+}
+
+// TestTypeExpressions checks that the constants are found whatever the
+// expression of their type, as they are matched by the type checker.
+func TestTypeExpressions(t *testing.T) {
+	const input = `import "time"
+
+type Kind int
+
+type Alias = Kind
+
+const (
+	KindA Kind = iota
+	KindB (Kind) = iota
+	KindC Alias = iota
+	KindD = Kind(3)
+	KindE = (Alias)(4)
+	Day time.Weekday = 0
+)
+`
+	got := goldenFile(t, Options{}, "kind", input, "Kind")
+	if want := `_Kind_name = "KindAKindBKindCKindDKindE"`; !strings.Contains(got, want) {
+		t.Errorf("got\n%s\nwant the names %s", got, want)
+	}
+	got = goldenFile(t, Options{}, "kind", input+"\nconst Sat (time.Weekday) = 6\n", "time.Weekday")
+	if want := `_Weekday_name_1 = "Sat"`; !strings.Contains(got, want) {
+		t.Errorf("got\n%s\nwant the name Sat", got)
+	}
+}
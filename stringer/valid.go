@@ -0,0 +1,90 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the IsValid method of -isvalid, which reports
+// whether a value is one that String prints by name.
+
+package stringer
+
+import "fmt"
+
+// buildIsValid generates the IsValid method for the runs of values. If isMap
+// is set, the String method looks the values up in the map it declares.
+func (g *Generator) buildIsValid(runs [][]Value, typeName string, isMap bool) {
+	g.Printf("\nfunc (i %s) IsValid() bool {\n", typeName)
+	switch {
+	case isMap:
+		g.Printf("\t_, ok := _%s_map[i]\n", typeName)
+		g.Printf("\treturn ok\n")
+	case len(runs) == 1:
+		g.Printf("\treturn %s\n", runCond(runs[0]))
+	default:
+		g.Printf("\tswitch {\n")
+		for _, values := range runs {
+			g.Printf("\tcase %s:\n", runCond(values))
+			g.Printf("\t\treturn true\n")
+		}
+		g.Printf("\t}\n")
+		g.Printf("\treturn false\n")
+	}
+	g.Printf("}\n")
+}
+
+// runCond returns the condition that i is one of the values of the run.
+func runCond(values []Value) string {
+	first, last := &values[0], &values[len(values)-1]
+	switch {
+	case len(values) == 1:
+		return fmt.Sprintf("i == %s", first)
+	case first.value == 0 && !first.signed:
+		return fmt.Sprintf("i <= %s", last)
+	}
+	return fmt.Sprintf("%s <= i && i <= %s", first, last)
+}
+
+// buildBitflagIsValid generates the IsValid method for a bitflag type. A value
+// is valid when each of its bits is that of a single-bit constant or belongs
+// to a composite all of whose bits are set, which is when String prints no
+// hexadecimal remainder. If signed, the type is a signed one.
+func (g *Generator) buildBitflagIsValid(runs [][]Value, composites []Value, typeName string, signed bool) {
+	var mask uint64
+	for _, run := range runs {
+		for _, v := range run {
+			mask |= v.value
+		}
+	}
+	m := fmt.Sprintf("%#x", mask)
+	if signed && int64(mask) < 0 {
+		m = fmt.Sprintf("-%#x", uint64(-int64(mask)))
+	}
+	if len(composites) == 0 {
+		g.Printf(isValidBitflag, typeName, m)
+		return
+	}
+	g.Printf(isValidBitflagComposite, typeName, m, compositeMasks(composites, true))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: mask of the single-bit values
+const isValidBitflag = `
+func (m %[1]s) IsValid() bool {
+	return m&^%[2]s == 0
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: mask of the single-bit values
+//	[3]: composite values, in the order String prefers them
+const isValidBitflagComposite = `
+func (m %[1]s) IsValid() bool {
+	for _, k := range [...]%[1]s{%[3]s} {
+		if m&k == k {
+			m &^= k
+		}
+	}
+	return m&^%[2]s == 0
+}
+`
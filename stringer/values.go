@@ -0,0 +1,121 @@
+// Copyright 2018 Frank Rehwinkel
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// These routines generate the <type>Values and <type>Names functions of
+// -values, which list the constants of a type.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// The orders of the -sort flag.
+const (
+	sortValue = "value"
+	sortDecl  = "decl"
+	sortName  = "name"
+)
+
+// validSort reports whether order is one of the -sort flag.
+func validSort(order string) bool {
+	switch order {
+	case sortValue, sortDecl, sortName:
+		return true
+	}
+	return false
+}
+
+// orderIndex returns the indexes of the values, which are in increasing
+// order, in the order of the -sort flag: that of the values, of the
+// declarations, in the files in the order of their names, or of the printed
+// names.
+func (g *Generator) orderIndex(values []Value) []int {
+	index := make([]int, len(values))
+	for i := range index {
+		index[i] = i
+	}
+	switch g.sort {
+	case sortDecl:
+		sort.SliceStable(index, func(i, j int) bool { return values[index[i]].decl < values[index[j]].decl })
+	case sortName:
+		sort.SliceStable(index, func(i, j int) bool { return values[index[i]].name < values[index[j]].name })
+	}
+	return index
+}
+
+// buildValues generates the functions listing the values, which are in
+// increasing order with no duplicates, in the order of the -sort flag.
+func (g *Generator) buildValues(values []Value, typeName string) {
+	ordered := make([]Value, len(values))
+	for i, j := range g.orderIndex(values) {
+		ordered[i] = values[j]
+	}
+	order := "in increasing order"
+	switch g.sort {
+	case sortDecl:
+		order = "in the order declared"
+	case sortName:
+		order = "in the order of their names"
+	}
+	g.Printf(valuesFuncs, typeName, valueList(ordered), order)
+}
+
+// valueList returns the values separated by commas.
+func valueList(values []Value) string {
+	r := new(bytes.Buffer)
+	sep := ""
+	for i := range values {
+		fmt.Fprintf(r, "%s%s", sep, &values[i])
+		sep = ", "
+	}
+	return r.String()
+}
+
+// runValues returns the values of the runs, in order.
+func runValues(runs [][]Value) []Value {
+	var values []Value
+	for _, run := range runs {
+		values = append(values, run...)
+	}
+	return values
+}
+
+// bitflagValues returns the values of a bitflag type in increasing order:
+// the zero value if declared, the single bits and the composites.
+func bitflagValues(zero *Value, runs [][]Value, composites []Value) []Value {
+	var values []Value
+	if zero != nil {
+		values = append(values, *zero)
+	}
+	values = append(values, runValues(runs)...)
+	values = append(values, composites...)
+	sort.Stable(byValue(values))
+	return values
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: the values, separated by commas
+//	[3]: the order of the values
+const valuesFuncs = `
+var _%[1]s_values = []%[1]s{%[2]s}
+
+// %[1]sValues returns the values of the %[1]s constants %[3]s.
+func %[1]sValues() []%[1]s {
+	return append([]%[1]s(nil), _%[1]s_values...)
+}
+
+// %[1]sNames returns the names of the %[1]s constants, as printed by String,
+// in the order of %[1]sValues.
+func %[1]sNames() []string {
+	names := make([]string, len(_%[1]s_values))
+	for i, v := range _%[1]s_values {
+		names[i] = v.String()
+	}
+	return names
+}
+`